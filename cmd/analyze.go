@@ -2,12 +2,15 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
 	"github.com/alexanderritik/dbgraph/internal/adapters"
 	"github.com/alexanderritik/dbgraph/internal/engine"
 	"github.com/alexanderritik/dbgraph/internal/graph"
+	"github.com/alexanderritik/dbgraph/internal/lint"
+	"github.com/alexanderritik/dbgraph/internal/report"
 
 	"github.com/spf13/cobra"
 )
@@ -19,6 +22,8 @@ var analyzeCmd = &cobra.Command{
 	Long:  `Connects to the database, fetches the schema (FKs and Views), and constructs an in-memory DAG.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		ensureDBConnection()
+		cfg := loadConfig()
+		rules := lintRules(cfg)
 
 		g := graph.NewGraph()
 		// ... existing code ...
@@ -42,6 +47,12 @@ var analyzeCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
+		// Migration creating the missing FK indexes, instead of the report
+		if fixSQL, _ := cmd.Flags().GetBool("fix-sql"); fixSQL {
+			fmt.Print(indexMigration(g, g.SuggestIndexes()))
+			return
+		}
+
 		// DOT Export Logic
 		format, _ := cmd.Flags().GetString("format")
 		if format == "dot" {
@@ -50,14 +61,23 @@ var analyzeCmd = &cobra.Command{
 			fmt.Println("  node [shape=box, style=filled, fillcolor=\"#e2e8f0\", fontname=\"Helvetica\"];")
 			fmt.Println("  edge [color=\"#64748b\"];")
 
-			// Nodes
+			// Nodes. Indexes are left out: they would double the drawing and impact lists them per table.
 			for id, n := range g.Nodes {
+				if n.Type == graph.Index {
+					continue
+				}
 				label := fmt.Sprintf("%s\\n(%s)", n.Name, n.Type)
 				color := "#e2e8f0"
 				if n.Type == graph.Table {
 					color = "#bfdbfe" // Blue
 				} else if n.Type == graph.View {
 					color = "#bbf7d0" // Green
+				} else if n.Type == graph.Function {
+					color = "#fde68a" // Amber
+				} else if n.Type == graph.Enum || n.Type == graph.CompositeType || n.Type == graph.Domain {
+					color = "#ddd6fe" // Violet
+				} else if n.Type == graph.Sequence {
+					color = "#fbcfe8" // Pink
 				}
 				fmt.Printf("  \"%s\" [label=\"%s\", fillcolor=\"%s\"];\n", id, label, color)
 			}
@@ -65,9 +85,16 @@ var analyzeCmd = &cobra.Command{
 			// Edges
 			for src, edges := range g.Edges {
 				for _, e := range edges {
+					if e.Type == graph.IndexOn {
+						continue
+					}
 					style := "solid"
 					if e.Type == graph.ViewDepends {
 						style = "dashed"
+					} else if e.Type == graph.FunctionCall || e.Type == graph.FunctionAccess {
+						style = "dotted"
+					} else if e.Type == graph.TypeUsage || e.Type == graph.SequenceDefault || e.Type == graph.SequenceOwned {
+						style = "bold"
 					}
 					fmt.Printf("  \"%s\" -> \"%s\" [style=%s];\n", src, e.TargetID, style)
 				}
@@ -91,118 +118,140 @@ var analyzeCmd = &cobra.Command{
 				dbName = lastPart
 			}
 		}
-		fmt.Printf("🔍 DB: %s | Objects: %d\n", dbName, stats.Nodes)
-		fmt.Println(strings.Repeat("-", 80))
-
-		fmt.Println("\n🏗️  TOPOLOGICAL CONTEXT")
-		fmt.Printf("Graph Type:  Directed Multigraph\n")
-		denseLabel := "Sparse"
-		if stats.Density > 0.1 {
-			denseLabel = "Dense"
+		result := &report.Analyze{
+			Database:   dbName,
+			Stats:      stats,
+			Cycles:     g.CheckCycles(),
+			Indexes:    g.CheckIndexCoverage(),
+			GodObjects: g.FindGodObjects(godObjectThreshold(rules)),
+			Rules:      ruleIDs(rules),
+			Findings:   cfg.Filter(lint.Run(g, rules)),
+		}
+		// Empty (not nil) slices keep the JSON output stable for consumers
+		if result.Cycles == nil {
+			result.Cycles = [][]string{}
+		}
+		if result.GodObjects == nil {
+			result.GodObjects = []graph.GodMod{}
+		}
+		if result.Indexes.MissingFKIndexes == nil {
+			result.Indexes.MissingFKIndexes = []string{}
+		}
+		if stats.IsolatedGroups == nil {
+			stats.IsolatedGroups = []string{}
 		}
-		fmt.Printf("Density:     %.3f (%s)\n", stats.Density, denseLabel)
-		fmt.Printf("Components:  %d Isolated Sub-graphs\n", stats.Components)
-		fmt.Printf("Centrality:  %s (%.2f)\n", stats.CentralNode, stats.MaxCentrality)
-
-		fmt.Println("\n📦 OBJECT DISTRIBUTION")
-		tables := 0
-		views := 0
-		triggers := 0
 		for _, n := range g.Nodes {
 			switch n.Type {
 			case graph.Table:
-				tables++
+				result.Tables++
 			case graph.View:
-				views++
+				result.Views++
 			case graph.Trigger:
-				triggers++
+				result.Triggers++
+			case graph.Function:
+				result.Functions++
+			case graph.Enum, graph.CompositeType, graph.Domain:
+				result.Types++
+			case graph.Sequence:
+				result.Sequences++
+			case graph.Index:
+				result.IndexNodes++
 			}
 		}
-		fmt.Printf("Tables:      %d\n", tables)
-		fmt.Printf("Views:       %d\n", views)
-		fmt.Printf("Triggers:    %d\n", triggers)
-
-		fmt.Println("\n🔗 DEPENDENCY VECTORS")
-		fkCount := 0
-		viewCount := 0
-		triggerCount := 0
 		for _, edges := range g.Edges {
 			for _, e := range edges {
 				switch e.Type {
 				case graph.ForeignKey:
-					fkCount++
+					result.ForeignKeys++
 				case graph.ViewDepends:
-					viewCount++
+					result.ViewEdges++
 				case graph.TriggerAction:
-					triggerCount++
+					result.TriggerEdges++
+				case graph.FunctionCall:
+					result.CallEdges++
+				case graph.FunctionAccess:
+					result.FunctionEdges++
+				case graph.TypeUsage:
+					result.TypeEdges++
+				case graph.SequenceDefault, graph.SequenceOwned:
+					result.SequenceEdges++
 				}
 			}
 		}
-		fmt.Printf("Foreign Keys:       %d edges\n", fkCount)
-		fmt.Printf("View Definitions:    %d edges\n", viewCount)
-		fmt.Printf("Trigger Actions:     %d edges\n", triggerCount)
-
-		fmt.Println("\n🛰️  ISOLATED SUB-GRAPHS (Island Detection)")
-		for i, iso := range stats.IsolatedGroups {
-			if i >= 5 {
-				break
-			} // Limit output
-			fmt.Printf("%d. Cluster:  %s\n", i+1, iso)
-		}
 
-		fmt.Println("\n🧵 SCHEMA LINEAGE DEPTH")
-		fmt.Printf("Deepest Chain:  %d Levels\n", stats.LongestPath)
+		render(result, printAnalyze)
+	},
+}
 
-		// --- HEALTH CHECK ---
-		fmt.Println("\n🏥 SCHEMA HEALTH REPORT")
-		fmt.Println(strings.Repeat("-", 80))
+// printAnalyze renders the topology and health report
+func printAnalyze(w io.Writer, v any) error {
+	result := v.(*report.Analyze)
+	stats := result.Stats
 
-		cycles := g.CheckCycles()
-		if len(cycles) > 0 {
-			fmt.Printf("🔴 CRITICAL: Found %d circular dependencies (Cycles)!\n", len(cycles))
-			for i, c := range cycles {
-				fmt.Printf("   %d. %v\n", i+1, c)
-			}
-		} else {
-			fmt.Println("✅ Great! No circular dependencies detected.")
-		}
+	fmt.Fprintf(w, "🔍 DB: %s | Objects: %d\n", result.Database, stats.Nodes)
+	fmt.Fprintln(w, strings.Repeat("-", 80))
 
-		// --- INDEX HYGIENE ---
-		idxStats := g.CheckIndexCoverage()
-		if len(idxStats.MissingFKIndexes) > 0 {
-			fmt.Printf("\n⚠️  PERFORMANCE RISKS: Found %d FKs missing indexes\n", len(idxStats.MissingFKIndexes))
-			for i, miss := range idxStats.MissingFKIndexes {
-				if i >= 5 {
-					fmt.Printf("   ... and %d more\n", len(idxStats.MissingFKIndexes)-5)
-					break
-				}
-				fmt.Printf("   - %s\n", miss)
-			}
-			fmt.Printf("   (Suggestion: Add indexes to valid FK columns to prevent locking issues)\n")
-		} else if idxStats.TotalFKs > 0 {
-			fmt.Printf("\n✅ Index Hygiene: Excellent! All %d FKs are indexed.\n", idxStats.TotalFKs)
-		} else {
-			fmt.Println("\nℹ️  No Foreign Keys found to check.")
-		}
+	fmt.Fprintln(w, "\n🏗️  TOPOLOGICAL CONTEXT")
+	fmt.Fprintf(w, "Graph Type:  Directed Multigraph\n")
+	denseLabel := "Sparse"
+	if stats.Density > 0.1 {
+		denseLabel = "Dense"
+	}
+	fmt.Fprintf(w, "Density:     %.3f (%s)\n", stats.Density, denseLabel)
+	fmt.Fprintf(w, "Components:  %d Isolated Sub-graphs\n", stats.Components)
+	fmt.Fprintf(w, "Centrality:  %s (%.2f)\n", stats.CentralNode, stats.MaxCentrality)
 
-		// --- COMPLEXITY / GOD OBJECTS ---
-		gods := g.DetectGodObjects()
-		if len(gods) > 0 {
-			fmt.Printf("\n😈 COMPLEXITY RISKS: Found %d 'God Objects' (High Coupling)\n", len(gods))
-			for _, god := range gods {
-				fmt.Printf("   - %s (Connected to %d others: %d in, %d out)\n",
-					god.ID, god.Degree, god.Dependents, god.Dependencies)
-			}
-			fmt.Printf("   (Suggestion: Consider splitting these tables to reduce architectural coupling)\n")
-		} else {
-			fmt.Println("\n✅ Architecture: No 'God Objects' detected (Clean Separation).")
-		}
+	fmt.Fprintln(w, "\n📦 OBJECT DISTRIBUTION")
+	fmt.Fprintf(w, "Tables:      %d\n", result.Tables)
+	fmt.Fprintf(w, "Views:       %d\n", result.Views)
+	fmt.Fprintf(w, "Triggers:    %d\n", result.Triggers)
+	fmt.Fprintf(w, "Functions:   %d\n", result.Functions)
+	fmt.Fprintf(w, "Types:       %d\n", result.Types)
+	fmt.Fprintf(w, "Sequences:   %d\n", result.Sequences)
+	fmt.Fprintf(w, "Indexes:     %d\n", result.IndexNodes)
 
-		fmt.Println(strings.Repeat("-", 80))
-	},
+	fmt.Fprintln(w, "\n🔗 DEPENDENCY VECTORS")
+	fmt.Fprintf(w, "Foreign Keys:       %d edges\n", result.ForeignKeys)
+	fmt.Fprintf(w, "View Definitions:    %d edges\n", result.ViewEdges)
+	fmt.Fprintf(w, "Trigger Actions:     %d edges\n", result.TriggerEdges)
+	fmt.Fprintf(w, "Function Bodies:     %d edges\n", result.FunctionEdges)
+	fmt.Fprintf(w, "Function Calls:      %d edges\n", result.CallEdges)
+	fmt.Fprintf(w, "Type Usage:          %d edges\n", result.TypeEdges)
+	fmt.Fprintf(w, "Sequence Links:      %d edges\n", result.SequenceEdges)
+
+	fmt.Fprintln(w, "\n🛰️  ISOLATED SUB-GRAPHS (Island Detection)")
+	for i, iso := range stats.IsolatedGroups {
+		if i >= 5 {
+			break
+		} // Limit output
+		fmt.Fprintf(w, "%d. Cluster:  %s\n", i+1, iso)
+	}
+
+	fmt.Fprintln(w, "\n🧵 SCHEMA LINEAGE DEPTH")
+	fmt.Fprintf(w, "Deepest Chain:  %d Levels\n", stats.LongestPath)
+
+	// --- HEALTH CHECK ---
+	fmt.Fprintln(w, "\n🏥 SCHEMA HEALTH REPORT")
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+
+	printFindings(w, result.Rules, result.Findings, 5)
+
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+	return nil
+}
+
+// godObjectThreshold returns the configured god-object threshold, or the default when the rule is disabled
+func godObjectThreshold(rules []lint.Rule) int {
+	for _, r := range rules {
+		if r.ID == "god-object" {
+			return r.Params.Int("threshold")
+		}
+	}
+	return graph.DefaultGodObjectThreshold
 }
 
 func init() {
 	rootCmd.AddCommand(analyzeCmd)
 	analyzeCmd.Flags().String("format", "text", "Output format: text or dot")
+	analyzeCmd.Flags().Bool("fix-sql", false, "Print a migration creating the missing foreign key indexes (see suggest-indexes)")
 }
@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/alexanderritik/dbgraph/internal/adapters"
+	"github.com/alexanderritik/dbgraph/internal/engine"
+	"github.com/alexanderritik/dbgraph/internal/graph"
+	"github.com/alexanderritik/dbgraph/internal/report"
+
+	"github.com/spf13/cobra"
+)
+
+// cascadeCmd represents the cascade command
+var cascadeCmd = &cobra.Command{
+	Use:   "cascade [table_name]",
+	Short: "Estimate how many rows a DELETE would remove through cascading foreign keys",
+	Long: `Follows the ON DELETE CASCADE foreign keys from a table, level by level, and prints the tree of tables
+with the rows a DELETE would remove there, the rows whose foreign key ON DELETE SET NULL / SET DEFAULT
+would update, and the RESTRICT / NO ACTION foreign keys whose referencing rows would make it fail.
+
+Rows are estimated from the table statistics (row counts, n_distinct and null_frac of the FK columns).
+With --where the DELETE is limited to the matching rows (estimated by the planner). With --count each
+level is counted instead, with bounded COUNT queries inside a read-only transaction.
+
+The --where filter is SQL pasted into those queries and runs with the connection's privileges: pass only
+filters you would run yourself. A single expression is accepted; ';' and unbalanced parentheses are refused.
+
+Examples:
+  dbgraph cascade users
+  dbgraph cascade users --where "id = 42" --count`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		where, _ := cmd.Flags().GetString("where")
+		count, _ := cmd.Flags().GetBool("count")
+		limit, _ := cmd.Flags().GetInt64("limit")
+		if limit <= 0 {
+			fmt.Println("Error: --limit must be positive")
+			os.Exit(1)
+		}
+
+		ensureDBConnection()
+
+		g := graph.NewGraph()
+		a, err := adapters.NewAdapter(dbUrl)
+		if err != nil {
+			fmt.Printf("Error creating adapter: %v\n", err)
+			os.Exit(1)
+		}
+
+		e := engine.NewEngine(g, a)
+		defer a.Close()
+
+		if err := e.Connect(dbUrl); err != nil {
+			fmt.Printf("Error connecting to database: %v\n", err)
+			os.Exit(1)
+		}
+
+		if err := e.BuildGraph(); err != nil {
+			fmt.Printf("Error building graph: %v\n", err)
+			os.Exit(1)
+		}
+
+		targetID, found := findNode(g, args[0])
+		if !found || g.Nodes[targetID].Type != graph.Table {
+			fmt.Printf("Error: table '%s' not found in the graph.\n", args[0])
+			os.Exit(1)
+		}
+		target := g.Nodes[targetID]
+
+		counter, canCount := a.(adapters.CascadeCounter)
+		if count && !canCount {
+			fmt.Printf("Error: --count: %v\n", fmt.Errorf("%w: rows cannot be counted without a live PostgreSQL connection", adapters.ErrUnsupported))
+			os.Exit(1)
+		}
+
+		// Rows the DELETE itself removes: all of them, the planner's estimate, or unknown offline
+		rootRows := target.RowCount
+		if where != "" {
+			rootRows = -1
+			if canCount {
+				if rootRows, err = counter.EstimateRows(target.Schema, target.Name, where); err != nil {
+					fmt.Printf("Error: --where: %v\n", err)
+					os.Exit(1)
+				}
+			}
+		}
+
+		tree := g.CascadeTree(targetID, rootRows)
+		if count {
+			if err := counter.CountCascade(g, tree, where, limit); err != nil {
+				fmt.Printf("Error counting rows: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		result := &report.Cascade{
+			Database: redactConnString(dbUrl),
+			Table:    targetID,
+			Where:    where,
+			Counted:  count,
+			Tree:     tree,
+			Unknown:  tree.Unknown,
+			Blockers: []report.CascadeBlocker{},
+		}
+		if count {
+			result.Limit = limit
+		}
+		tree.Walk(func(n, parent *graph.CascadeNode) {
+			switch n.Action {
+			case graph.CascadeRoot, graph.CascadeDelete:
+				if !n.Cycle {
+					result.Deleted += n.Rows
+				}
+			case graph.CascadeNull, graph.CascadeReset:
+				result.Nulled += n.Rows
+			case graph.CascadeBlock:
+				result.Blockers = append(result.Blockers, report.CascadeBlocker{
+					Table: n.Table, Constraint: n.Constraint, DeleteRule: n.DeleteRule, Parent: parent.Table,
+					Rows: n.Rows, Unknown: n.Unknown, Counted: n.Counted,
+				})
+			}
+		})
+		render(result, printCascade)
+	},
+}
+
+// cascadeRows formats the rows of a cascade node: "~1.2k rows" for an estimate, "≥100.0k rows" for
+// a COUNT that hit its limit
+func cascadeRows(n *graph.CascadeNode) string {
+	switch {
+	case n.Unknown:
+		return "? rows"
+	case n.Bounded:
+		return "≥" + formatRows(n.Rows)
+	case n.Counted:
+		return formatRows(n.Rows)
+	}
+	return "~" + formatRows(n.Rows)
+}
+
+// cascadeVerbs describes what happens to the rows of a table in the cascade tree
+var cascadeVerbs = map[graph.CascadeAction]string{
+	graph.CascadeRoot:   "deleted",
+	graph.CascadeDelete: "deleted",
+	graph.CascadeNull:   "set to NULL",
+	graph.CascadeReset:  "reset to default",
+	graph.CascadeBlock:  "referencing",
+}
+
+// printCascade renders the cascade tree and the foreign keys blocking the DELETE
+func printCascade(w io.Writer, v any) error {
+	result := v.(*report.Cascade)
+
+	target := result.Table
+	if result.Where != "" {
+		target += " WHERE " + result.Where
+	}
+	mode := "estimated"
+	if result.Counted {
+		mode = fmt.Sprintf("counted, limit %d per table", result.Limit)
+	}
+	fmt.Fprintf(w, "💥 CASCADE: %s | DELETE FROM %s (%s)\n", result.Database, target, mode)
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+
+	var printTree func(n *graph.CascadeNode, prefix string, isLast bool)
+	printTree = func(n *graph.CascadeNode, prefix string, isLast bool) {
+		childPrefix := prefix
+		if n.Action == graph.CascadeRoot {
+			fmt.Fprintf(w, "🗑️  %s (%s deleted)\n", n.Table, cascadeRows(n))
+		} else {
+			marker := "├──"
+			childPrefix += "│   "
+			if isLast {
+				marker = "└──"
+				childPrefix = prefix + "    "
+			}
+			icon, note := "🗑️ ", ""
+			switch n.Action {
+			case graph.CascadeNull, graph.CascadeReset:
+				icon = "∅ "
+			case graph.CascadeBlock:
+				icon = "⛔"
+				if n.Unknown || n.Rows > 0 {
+					note = " — blocks the DELETE"
+				}
+			}
+			if n.Cycle {
+				note = " (cycle: already deleted above)"
+			}
+			fmt.Fprintf(w, "%s%s %s %s [FK: %s (%s)] %s %s%s\n", prefix, marker, icon, n.Table, n.Constraint,
+				strings.Join(n.Columns, ", "), n.DeleteRule, cascadeRows(n)+" "+cascadeVerbs[n.Action], note)
+		}
+		for i, c := range n.Children {
+			printTree(c, childPrefix, i == len(n.Children)-1)
+		}
+	}
+	printTree(result.Tree, "", true)
+
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+	approx := "~"
+	if result.Counted {
+		approx = ""
+	}
+	if result.Unknown {
+		fmt.Fprintln(w, "ℹ️  Rows matching the filter cannot be estimated without a live PostgreSQL connection")
+	} else {
+		fmt.Fprintf(w, "📉 Deleted: %s%s | Set NULL/default: %s%s\n", approx, formatRows(result.Deleted), approx, formatRows(result.Nulled))
+	}
+
+	blocking := 0
+	for _, b := range result.Blockers {
+		if b.Unknown || b.Rows > 0 {
+			blocking++
+		}
+	}
+	switch {
+	case len(result.Blockers) == 0:
+		fmt.Fprintln(w, "✅ No RESTRICT / NO ACTION foreign key can block the DELETE")
+	case blocking == 0:
+		fmt.Fprintf(w, "✅ %d RESTRICT / NO ACTION foreign keys, but no referencing rows %s\n", len(result.Blockers), map[bool]string{true: "found", false: "expected"}[result.Counted])
+	default:
+		fmt.Fprintf(w, "⛔ BLOCKED: the DELETE fails while these foreign keys have referencing rows:\n")
+		for _, b := range result.Blockers {
+			if b.Unknown || b.Rows > 0 {
+				fmt.Fprintf(w, "   - %s on %s (%s) references %s\n", b.Constraint, b.Table, b.DeleteRule, b.Parent)
+			}
+		}
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(cascadeCmd)
+	cascadeCmd.Flags().String("where", "", "Filter of the DELETE (e.g. \"id = 42\"); trusted SQL run in a read-only transaction; all rows when omitted")
+	cascadeCmd.Flags().Bool("count", false, "Count the affected rows in a read-only transaction instead of estimating them")
+	cascadeCmd.Flags().Int64("limit", 100000, "With --count, stop counting a table at this many rows")
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/alexanderritik/dbgraph/internal/lint"
+	"github.com/alexanderritik/dbgraph/internal/report"
+
+	"github.com/spf13/cobra"
+)
+
+// checkCmd represents the check command
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Run schema rules as a CI gate",
+	Long: `Runs the schema rules enabled in --config (see 'lint') and
+compares the findings with a baseline of accepted ones. Exits 1 only when a finding that is not in the
+baseline is at or above --fail-on. Results can also be written as JUnit XML and SARIF for review tooling.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureDBConnection()
+
+		baselinePath, _ := cmd.Flags().GetString("baseline")
+		updateBaseline, _ := cmd.Flags().GetBool("update-baseline")
+		junitPath, _ := cmd.Flags().GetString("junit")
+		sarifPath, _ := cmd.Flags().GetString("sarif")
+		failOnFlag, _ := cmd.Flags().GetString("fail-on")
+
+		failOn, err := lint.ParseSeverity(failOnFlag)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if updateBaseline && baselinePath == "" {
+			fmt.Println("Error: --update-baseline requires --baseline")
+			os.Exit(1)
+		}
+
+		cfg := loadConfig()
+		rules := lintRules(cfg)
+
+		g, err := loadGraph(dbUrl)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		findings := cfg.Filter(lint.Run(g, rules))
+
+		if updateBaseline {
+			if err := lint.SaveBaseline(baselinePath, lint.NewBaseline(findings)); err != nil {
+				fmt.Printf("Error writing baseline: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("✅ Baseline %s updated with %d findings\n", baselinePath, len(findings))
+			return
+		}
+
+		// A missing baseline file means nothing has been accepted yet
+		var baseline *lint.Baseline
+		if baselinePath != "" {
+			baseline, err = lint.LoadBaseline(baselinePath)
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				fmt.Printf("Error reading baseline: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		result := &report.Check{
+			Database: redactConnString(dbUrl),
+			FailOn:   failOn,
+			Findings: []report.CheckFinding{},
+		}
+		for _, f := range findings {
+			isNew := !baseline.Contains(f)
+			result.Findings = append(result.Findings, report.CheckFinding{Finding: f, New: isNew})
+			if !isNew {
+				result.Baseline++
+				continue
+			}
+			result.New++
+			if f.Severity.AtLeast(failOn) {
+				result.Failed = true
+			}
+		}
+
+		if junitPath != "" {
+			writeCheckReport(junitPath, func(w io.Writer) error { return report.WriteJUnit(w, result, rules) })
+		}
+		if sarifPath != "" {
+			writeCheckReport(sarifPath, func(w io.Writer) error { return report.WriteSARIF(w, result, rules, rootCmd.Version) })
+		}
+
+		render(result, printCheck)
+		if result.Failed {
+			os.Exit(1)
+		}
+	},
+}
+
+// writeCheckReport writes a report file, exiting on failure
+func writeCheckReport(path string, write func(w io.Writer) error) {
+	f, err := os.Create(path)
+	if err != nil {
+		fmt.Printf("Error creating %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	defer f.Close()
+	if err := write(f); err != nil {
+		fmt.Printf("Error writing %s: %v\n", path, err)
+		os.Exit(1)
+	}
+}
+
+// printCheck renders the findings grouped into new and baselined
+func printCheck(w io.Writer, v any) error {
+	result := v.(*report.Check)
+	fmt.Fprintf(w, "🔎 CHECK: %s | Fail on: %s\n", result.Database, result.FailOn)
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+
+	if result.New > 0 {
+		fmt.Fprintf(w, "\nNEW FINDINGS (%d)\n", result.New)
+		for _, f := range result.Findings {
+			if f.New {
+				fmt.Fprintf(w, "%s [%s] %s: %s\n", severityIcons[f.Severity], f.Severity, f.Rule, f.Message)
+			}
+		}
+	}
+	if result.Baseline > 0 {
+		fmt.Fprintf(w, "\nACCEPTED IN BASELINE (%d)\n", result.Baseline)
+		for _, f := range result.Findings {
+			if !f.New {
+				fmt.Fprintf(w, "   [%s] %s: %s\n", f.Severity, f.Rule, f.Message)
+			}
+		}
+	}
+
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+	switch {
+	case result.Failed:
+		fmt.Fprintf(w, "❌ FAILED: new findings at or above %s\n", result.FailOn)
+	case result.New > 0:
+		fmt.Fprintf(w, "✅ PASSED: %d new findings, all below %s\n", result.New, result.FailOn)
+	default:
+		fmt.Fprintln(w, "✅ PASSED: no new findings")
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+	checkCmd.Flags().String("baseline", "", "JSON file of accepted findings (missing file = none accepted)")
+	checkCmd.Flags().Bool("update-baseline", false, "Accept all current findings by writing them to --baseline")
+	checkCmd.Flags().String("junit", "", "Also write a JUnit XML report to this file")
+	checkCmd.Flags().String("sarif", "", "Also write a SARIF 2.1.0 report to this file")
+	checkCmd.Flags().String("fail-on", "warning", "Lowest severity of a new finding that fails the check: info, warning or error")
+}
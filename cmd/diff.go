@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/alexanderritik/dbgraph/internal/graph"
+	"github.com/alexanderritik/dbgraph/internal/report"
+
+	"github.com/spf13/cobra"
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff <source> <target>",
+	Short: "Compare the dependency graphs of two databases or snapshots",
+	Long: `Loads two graphs (any connection string, snapshot://file.json or a bare .json/.sql path) and reports
+added, removed and changed objects: new or dropped FKs, changed delete rules, dropped indexes,
+views whose dependencies changed and tables whose row count or size jumped.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("format")
+		threshold, _ := cmd.Flags().GetFloat64("growth-threshold")
+
+		source := resolveConnString(args[0])
+		target := resolveConnString(args[1])
+
+		a, err := loadGraph(source)
+		if err != nil {
+			fmt.Printf("Error loading %s: %v\n", redactConnString(source), err)
+			os.Exit(1)
+		}
+		b, err := loadGraph(target)
+		if err != nil {
+			fmt.Printf("Error loading %s: %v\n", redactConnString(target), err)
+			os.Exit(1)
+		}
+
+		opts := graph.DefaultDiffOptions
+		opts.GrowthThreshold = threshold / 100
+		d := graph.Diff(a, b, opts)
+
+		// --format json predates --output and is kept as a shorthand
+		renderer := report.New(output(), func(w io.Writer, v any) error {
+			printDiffTree(w, redactConnString(source), redactConnString(target), v.(*graph.GraphDiff))
+			return nil
+		})
+		if format == "json" {
+			renderer = report.JSONRenderer{}
+		}
+		if err := renderer.Render(os.Stdout, d); err != nil {
+			fmt.Printf("Error encoding diff: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// redactConnString hides the password of a connection string for display
+func redactConnString(connString string) string {
+	u, err := url.Parse(connString)
+	if err != nil {
+		return connString
+	}
+	return u.Redacted()
+}
+
+func printDiffTree(w io.Writer, source, target string, d *graph.GraphDiff) {
+	fmt.Fprintf(w, "🔀 DIFF: %s → %s\n", source, target)
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+
+	if d.IsEmpty() {
+		fmt.Fprintln(w, "\n✅ No schema drift detected.")
+		return
+	}
+
+	fmt.Fprintf(w, "\n📊 DRIFT: %d added, %d removed, %d changed objects | %d added, %d removed, %d changed edges\n",
+		len(d.AddedNodes), len(d.RemovedNodes), len(d.ChangedNodes),
+		len(d.AddedEdges), len(d.RemovedEdges), len(d.ChangedEdges))
+
+	// Group every change under the object it belongs to (edges belong to their source)
+	lines := make(map[string][]string)
+	header := make(map[string]string)
+
+	for _, n := range d.AddedNodes {
+		header[n.ID] = fmt.Sprintf("➕ %s (%s)", n.ID, n.Type)
+	}
+	for _, n := range d.RemovedNodes {
+		header[n.ID] = fmt.Sprintf("➖ %s (%s)", n.ID, n.Type)
+	}
+	for _, c := range d.ChangedNodes {
+		header[c.ID] = fmt.Sprintf("✏️  %s (%s)", c.ID, c.Type)
+		if c.TypeBefore != "" {
+			lines[c.ID] = append(lines[c.ID], fmt.Sprintf("Type: %s → %s", c.TypeBefore, c.Type))
+		}
+		if c.RowsJumped {
+			lines[c.ID] = append(lines[c.ID], fmt.Sprintf("Rows: %d → %d (%+d)", c.RowsBefore, c.RowsAfter, c.RowsAfter-c.RowsBefore))
+		}
+		if c.SizeJumped {
+			lines[c.ID] = append(lines[c.ID], fmt.Sprintf("Size: %s → %s", c.SizeBefore, c.SizeAfter))
+		}
+		for _, cols := range c.AddedIndexes {
+			lines[c.ID] = append(lines[c.ID], fmt.Sprintf("➕ Index (%s)", strings.Join(cols, ", ")))
+		}
+		for _, cols := range c.RemovedIndexes {
+			lines[c.ID] = append(lines[c.ID], fmt.Sprintf("➖ Index (%s)", strings.Join(cols, ", ")))
+		}
+		for _, dep := range c.AddedDependencies {
+			lines[c.ID] = append(lines[c.ID], fmt.Sprintf("➕ Reads %s", dep))
+		}
+		for _, dep := range c.RemovedDependencies {
+			lines[c.ID] = append(lines[c.ID], fmt.Sprintf("➖ No longer reads %s", dep))
+		}
+	}
+
+	// View dependency edges of changed views are already listed as Reads lines
+	changedViews := make(map[string]bool)
+	for _, c := range d.ChangedNodes {
+		changedViews[c.ID] = c.Type == graph.View
+	}
+	for _, e := range d.AddedEdges {
+		if e.Type == graph.ViewDepends && changedViews[e.SourceID] {
+			continue
+		}
+		lines[e.SourceID] = append(lines[e.SourceID], "➕ "+describeDiffEdge(e))
+	}
+	for _, e := range d.RemovedEdges {
+		if e.Type == graph.ViewDepends && changedViews[e.SourceID] {
+			continue
+		}
+		lines[e.SourceID] = append(lines[e.SourceID], "➖ "+describeDiffEdge(e))
+	}
+	for _, c := range d.ChangedEdges {
+		desc := "✏️  " + describeDiffEdge(c.After)
+		if c.Before.DeleteRule != c.After.DeleteRule {
+			desc += fmt.Sprintf(" delete rule %s → %s", c.Before.DeleteRule, c.After.DeleteRule)
+		}
+		if before, after := c.Before.MetaData["fk_columns"], c.After.MetaData["fk_columns"]; before != after {
+			desc += fmt.Sprintf(" columns (%s) → (%s)", before, after)
+		}
+		lines[c.After.SourceID] = append(lines[c.After.SourceID], desc)
+	}
+
+	ids := make([]string, 0, len(lines)+len(header))
+	seen := make(map[string]bool)
+	for id := range header {
+		ids = append(ids, id)
+		seen[id] = true
+	}
+	for id := range lines {
+		if !seen[id] {
+			ids = append(ids, id)
+			header[id] = fmt.Sprintf("✏️  %s", id)
+		}
+	}
+	sort.Strings(ids)
+
+	fmt.Fprintln(w, "\nTREE VIEW")
+	for _, id := range ids {
+		fmt.Fprintln(w, header[id])
+		for i, line := range lines[id] {
+			marker := "├──"
+			if i == len(lines[id])-1 {
+				marker = "└──"
+			}
+			fmt.Fprintf(w, "%s %s\n", marker, line)
+		}
+	}
+}
+
+// describeDiffEdge renders an edge the way impact labels its tree branches
+func describeDiffEdge(e *graph.Edge) string {
+	switch e.Type {
+	case graph.ForeignKey:
+		desc := fmt.Sprintf("→ %s [FK: %s]", e.TargetID, e.ConstraintName)
+		if e.DeleteRule == "CASCADE" {
+			desc += " (CASCADE)"
+		}
+		return desc
+	case graph.TriggerAction:
+		return fmt.Sprintf("→ %s (Trigger)", e.TargetID)
+	case graph.Inheritance:
+		return fmt.Sprintf("→ %s (Partition Source)", e.TargetID)
+	case graph.FunctionCall:
+		return fmt.Sprintf("→ %s() (Executes)", e.TargetID)
+	case graph.FunctionAccess:
+		return fmt.Sprintf("→ %s %s", e.TargetID, functionAccessLabel(e))
+	case graph.TypeUsage, graph.SequenceDefault, graph.SequenceOwned:
+		return fmt.Sprintf("→ %s %s", e.TargetID, columnEdgeLabel(columnEdgeLabels[e.Type], e))
+	default:
+		return fmt.Sprintf("→ %s (View)", e.TargetID)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.Flags().String("format", "tree", "Output format: tree or json (same as --output json)")
+	diffCmd.Flags().Float64("growth-threshold", 50, "Percent change in rows or size that counts as a jump")
+}
@@ -1,13 +1,17 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/alexanderritik/dbgraph/internal/adapters"
 	"github.com/alexanderritik/dbgraph/internal/engine"
 	"github.com/alexanderritik/dbgraph/internal/graph"
+	"github.com/alexanderritik/dbgraph/internal/report"
 
 	"github.com/spf13/cobra"
 )
@@ -15,11 +19,32 @@ import (
 // impactCmd represents the impact command
 var impactCmd = &cobra.Command{
 	Use:   "impact [table_name]",
-	Short: "Identify downstream dependencies of a table",
-	Long:  `Finds all database objects (tables, views) that depend on the specified table/view using the dependency graph.`,
-	Args:  cobra.ExactArgs(1),
+	Short: "Identify downstream (or upstream) dependencies of a table",
+	Long: `Finds all database objects (tables, views) that depend on the specified table, view, function, type, sequence or index
+using the dependency graph (e.g. the tables whose columns use an enum, or the foreign keys referencing a unique index).
+With --direction up it lists what the object depends on instead (FK parents, base tables of a view chain,
+partition parents); --direction both prints both trees.`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		tableName := args[0]
+		direction, _ := cmd.Flags().GetString("direction")
+		maxDepth, _ := cmd.Flags().GetInt("depth")
+		edgeTypeNames, _ := cmd.Flags().GetStringSlice("edge-type")
+
+		if direction != "down" && direction != "up" && direction != "both" {
+			fmt.Printf("Error: unknown direction '%s' (expected up, down or both)\n", direction)
+			os.Exit(1)
+		}
+		edgeTypes := make(map[graph.DependencyType]bool)
+		for _, name := range edgeTypeNames {
+			t, ok := impactEdgeTypes[name]
+			if !ok {
+				fmt.Printf("Error: unknown edge type '%s' (expected fk, view, trigger, partition, function, call, type, sequence, owned or index)\n", name)
+				os.Exit(1)
+			}
+			edgeTypes[t] = true
+		}
+
 		ensureDBConnection()
 
 		g := graph.NewGraph()
@@ -44,22 +69,14 @@ var impactCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		// Find ID for the table (we store it as schema.name)
-		targetID := ""
-		found := false
-
-		// Simple heuristic: if input has dot, use as is. If not, try public.<input>.
-		// Or better, search the nodes.
-		for id, node := range g.Nodes {
-			if node.Name == tableName || id == tableName {
-				targetID = id
-				found = true
-				break
-			}
-		}
-
+		// Find ID for the table (we store it as schema.name), or the table of table.column
+		targetID, column, found := resolveImpactTarget(g, tableName)
 		if !found {
-			fmt.Printf("Error: Table or View '%s' not found in the graph.\n", tableName)
+			fmt.Printf("Error: Object '%s' not found in the graph.\n", tableName)
+			os.Exit(1)
+		}
+		if column != "" && direction != "down" {
+			fmt.Println("Error: column impact only supports --direction down")
 			os.Exit(1)
 		}
 
@@ -78,38 +95,25 @@ var impactCmd = &cobra.Command{
 
 		// High-Fidelity Output - DB Metrcs
 		metrics, err := a.GetMetrics()
+		metricsSupported := !errors.Is(err, adapters.ErrUnsupported)
 		if err != nil {
 			// Ignore error, just show empty
 			metrics = &graph.DBMetrics{}
 		}
 
-		nodeRows := g.Nodes[targetID].RowCount
-		rowStr := fmt.Sprintf("%d rows", nodeRows)
-		if nodeRows > 1000 {
-			rowStr = fmt.Sprintf("%.1fk rows", float64(nodeRows)/1000.0)
-		}
-		if nodeRows > 1000000 {
-			rowStr = fmt.Sprintf("%.1fm rows", float64(nodeRows)/1000000.0)
-		}
-
-		fmt.Printf("🔍 DB: %s | Target: %s (%s) | Active Locks: %d\n", dbName, targetID, rowStr, metrics.ActiveLocks)
-		fmt.Println(strings.Repeat("-", 80))
-
 		// 1. Calculate Metrics & Build Tree
-		type TreeNode struct {
-			ID       string
-			Type     graph.NodeType
-			Size     string
-			RowCount int64
-			EdgeMeta *graph.Edge
-			Children []*TreeNode
-			Level    int
+		var buildTree func(id string, level int, visited map[string]bool, up bool) *report.ImpactNode
+		result := &report.Impact{
+			Database:      dbName,
+			Target:        targetID,
+			RowCount:      g.Nodes[targetID].RowCount,
+			AffectedTypes: make(map[graph.NodeType]int),
+			Warnings:      []report.Warning{},
+			Direction:     direction,
+		}
+		if metricsSupported {
+			result.Metrics = metrics
 		}
-
-		var buildTree func(id string, level int, visited map[string]bool) *TreeNode
-		totalAffected := 0
-		warnings := []string{}
-		structures := make(map[graph.NodeType]int)
 
 		// Pre-compute reverse edges for traversal
 		reverseEdges := make(map[string][]*graph.Edge)
@@ -119,194 +123,610 @@ var impactCmd = &cobra.Command{
 			}
 		}
 
-		buildTree = func(id string, level int, visited map[string]bool) *TreeNode {
-			node := &TreeNode{
+		// up walks the edges of id (what it depends on), down walks the edges pointing at it
+		buildTree = func(id string, level int, visited map[string]bool, up bool) *report.ImpactNode {
+			node := &report.ImpactNode{
 				ID:       id,
 				Type:     g.Nodes[id].Type,
 				Size:     g.Nodes[id].Size,
 				RowCount: g.Nodes[id].RowCount,
 				Level:    level,
+				Index:    g.Nodes[id].Index,
 			}
 
-			if level > 0 {
-				totalAffected++
-				structures[node.Type]++
+			if level > 0 && up {
+				result.TotalUpstream++
+			} else if level > 0 {
+				result.TotalAffected++
+				result.AffectedTypes[node.Type]++
 			}
 
 			visited[id] = true
+			if maxDepth > 0 && level >= maxDepth {
+				return node
+			}
 
-			// Find dependents
-			for _, edge := range reverseEdges[id] {
+			// Find dependents (or dependencies)
+			edges := reverseEdges[id]
+			if up {
+				edges = g.Edges[id]
+			}
+			for _, edge := range edges {
+				if len(edgeTypes) > 0 && !edgeTypes[edge.Type] {
+					continue
+				}
+				// A table's own sequences and indexes go with the table; they are only worth listing for the target
+				if !up && level > 0 && (edge.Type == graph.SequenceOwned || edge.Type == graph.IndexOn) {
+					continue
+				}
 				src := edge.SourceID
+				if up {
+					src = edge.TargetID
+				}
 				if !visited[src] {
-					child := buildTree(src, level+1, visited)
-					child.EdgeMeta = edge
+					child := buildTree(src, level+1, visited, up)
+					child.Edge = edge
 					node.Children = append(node.Children, child)
+					if up {
+						// Warnings describe what breaks downstream
+						continue
+					}
 
-					// Detect Warnings
-					// 1. Cascade
-					if edge.DeleteRule == "CASCADE" {
-						desc := fmt.Sprintf("[High] Cascade Delete: Deleting '%s' will recursively delete objects in '%s'", id, src)
-						if node.RowCount > 1000 {
-							desc += fmt.Sprintf(" (~%d rows potentially locked/deleted)", node.RowCount)
-						}
-						desc += "."
-						warnings = append(warnings, desc)
+					result.Warnings = append(result.Warnings, impactWarnings(g, edge, node, level)...)
+				}
+			}
+
+			// Foreign keys do not point at the unique index enforcing their referenced key, but they
+			// stop it from being dropped: list them when the index is the target
+			if !up && level == 0 && node.Type == graph.Index {
+				for _, edge := range g.ReferencingForeignKeys(id) {
+					if (len(edgeTypes) > 0 && !edgeTypes[edge.Type]) || visited[edge.SourceID] {
+						continue
 					}
-					// 2. View Coupling
-					if edge.Type == graph.ViewDepends && level >= 2 {
-						warnings = append(warnings, fmt.Sprintf("[Med] View Coupling: '%s' is %d levels removed but will break on schema change.", src, level))
+					visited[edge.SourceID] = true
+					src := g.Nodes[edge.SourceID]
+					node.Children = append(node.Children, &report.ImpactNode{
+						ID: src.ID, Type: src.Type, Size: src.Size, RowCount: src.RowCount, Level: level + 1, Edge: edge,
+					})
+					result.TotalAffected++
+					result.AffectedTypes[src.Type]++
+					result.Warnings = append(result.Warnings, report.Warning{Severity: "High", Kind: "FK Dependency",
+						Message: fmt.Sprintf("'%s' references this index through '%s'; it cannot be dropped while the constraint exists.", src.ID, edge.ConstraintName)})
+				}
+				for _, edge := range g.SupportedForeignKeys(id) {
+					result.Warnings = append(result.Warnings, report.Warning{Severity: "Med", Kind: "FK Coverage",
+						Message: fmt.Sprintf("Only index covering '%s(%s)' (%s); without it cascades and parent deletes scan the table.", edge.SourceID, edge.MetaData["fk_columns"], edge.ConstraintName)})
+				}
+			}
+
+			// Changing a table fires its triggers, which run functions that write other tables:
+			// follow those effects forward (orders → trigger → log_order_changes() → audit_logs).
+			// Not for the target itself: changing a function does not affect the tables it writes,
+			// only its callers and triggers (its dependents, listed above).
+			if !up && level > 0 && (node.Type == graph.Trigger || node.Type == graph.Function) {
+				for _, edge := range g.Edges[id] {
+					if (len(edgeTypes) > 0 && !edgeTypes[edge.Type]) || visited[edge.TargetID] {
+						continue
 					}
-					// 3. Missing Index
-					// Rule: Source (child in this tree view) has FK to Target (node).
-					// Source should have index on FK columns.
-					if edge.Type == graph.ForeignKey {
-						cols, ok := edge.MetaData["fk_columns"]
-						if ok && cols != "" {
-							// Check if source node (table with FK) has index starting with these cols
-							sourceNode := g.Nodes[src] // child
-							hasIndex := false
-							fkCols := strings.Split(cols, ",")
-
-							for _, idxCols := range sourceNode.Indexes {
-								// Check if fkCols is prefix of idxCols
-								if len(idxCols) >= len(fkCols) {
-									match := true
-									for i, col := range fkCols {
-										if idxCols[i] != col {
-											match = false
-											break
-										}
-									}
-									if match {
-										hasIndex = true
-										break
-									}
-								}
-							}
-
-							if !hasIndex {
-								warnings = append(warnings, fmt.Sprintf("[Med] Missing Index: '%s(%s)' is not indexed. Cascade/Delete operations will be slow.", src, cols))
-							}
-						}
+					switch {
+					case edge.Type == graph.FunctionCall:
+						child := buildTree(edge.TargetID, level+1, visited, up)
+						child.Edge = edge
+						node.Children = append(node.Children, child)
+					case edge.Type == graph.FunctionAccess && strings.Contains(edge.MetaData["access"], "write"):
+						// The written table is affected, but what depends on it is not broken: stop here
+						visited[edge.TargetID] = true
+						target := g.Nodes[edge.TargetID]
+						node.Children = append(node.Children, &report.ImpactNode{
+							ID: target.ID, Type: target.Type, Size: target.Size, RowCount: target.RowCount, Level: level + 1, Edge: edge,
+						})
+						result.TotalAffected++
+						result.AffectedTypes[target.Type]++
 					}
 				}
 			}
 			return node
 		}
 
-		root := buildTree(targetID, 0, make(map[string]bool))
+		if column != "" {
+			w := &columnWalker{g: g, adapter: a, result: result, maxDepth: maxDepth, visited: map[string]bool{}}
+			result.Column = column
+			result.Target = targetID + "." + column
+			result.Tree = &report.ImpactNode{ID: result.Target, Type: "COLUMN", RowCount: result.RowCount}
+			node := g.Nodes[targetID]
+			if err := w.walk(node.Schema, node.Name, column, result.Tree); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			result.Depth = treeDepth(result.Tree)
+		} else if direction != "up" {
+			result.Tree = buildTree(targetID, 0, make(map[string]bool), false)
+			result.Depth = treeDepth(result.Tree)
+		}
+		if direction != "down" {
+			result.Upstream = buildTree(targetID, 0, make(map[string]bool), true)
+			result.UpstreamDepth = treeDepth(result.Upstream)
+		}
+
+		render(result, printImpact)
+	},
+}
+
+// impactWarnings returns the structural risks of a dependent reached through edge;
+// parent is the tree node it hangs under and level how far below the target that parent is
+func impactWarnings(g *graph.Graph, edge *graph.Edge, parent *report.ImpactNode, level int) []report.Warning {
+	var warnings []report.Warning
+	src := edge.SourceID
+
+	// 1. Cascade
+	if edge.DeleteRule == "CASCADE" {
+		desc := fmt.Sprintf("Deleting '%s' will recursively delete objects in '%s'", edge.TargetID, src)
+		if parent.RowCount > 1000 {
+			desc += fmt.Sprintf(" (~%d rows potentially locked/deleted)", parent.RowCount)
+		}
+		desc += "."
+		warnings = append(warnings, report.Warning{Severity: "High", Kind: "Cascade Delete", Message: desc})
+	}
+	// 2. View Coupling
+	if edge.Type == graph.ViewDepends && level >= 2 {
+		warnings = append(warnings, report.Warning{Severity: "Med", Kind: "View Coupling",
+			Message: fmt.Sprintf("'%s' is %d levels removed but will break on schema change.", src, level)})
+	}
+	// 3. Missing Index
+	// Rule: Source (child in this tree view) has FK to Target (node).
+	// Source should have index on FK columns.
+	if edge.Type == graph.ForeignKey {
+		cols, ok := edge.MetaData["fk_columns"]
+		if ok && cols != "" {
+			// Check if source node (table with FK) has index starting with these cols
+			sourceNode := g.Nodes[src] // child
+			hasIndex := false
+			fkCols := strings.Split(cols, ",")
+
+			for _, idxCols := range sourceNode.Indexes {
+				// Check if fkCols is prefix of idxCols
+				if len(idxCols) >= len(fkCols) {
+					match := true
+					for i, col := range fkCols {
+						if idxCols[i] != col {
+							match = false
+							break
+						}
+					}
+					if match {
+						hasIndex = true
+						break
+					}
+				}
+			}
 
-		// Wrapper for depth calculation
-		var getDepth func(n *TreeNode) int
-		getDepth = func(n *TreeNode) int {
-			if len(n.Children) == 0 {
-				return 0
+			if !hasIndex {
+				warnings = append(warnings, report.Warning{Severity: "Med", Kind: "Missing Index",
+					Message: fmt.Sprintf("'%s(%s)' is not indexed. Cascade/Delete operations will be slow.", src, cols)})
 			}
-			max := 0
-			for _, child := range n.Children {
-				d := getDepth(child)
-				if d > max {
-					max = d
+		}
+	}
+	return warnings
+}
+
+// resolveImpactTarget finds the node named by arg (name or schema.name) or, failing that,
+// the table of a table.column / schema.table.column argument and the column
+func resolveImpactTarget(g *graph.Graph, arg string) (string, string, bool) {
+	if id, ok := findNode(g, arg); ok {
+		return id, "", true
+	}
+	if i := strings.LastIndex(arg, "."); i > 0 {
+		if id, ok := findNode(g, arg[:i]); ok {
+			return id, arg[i+1:], true
+		}
+	}
+	return "", "", false
+}
+
+// findNode looks a node up by ID or bare name
+func findNode(g *graph.Graph, name string) (string, bool) {
+	for id, node := range g.Nodes {
+		if node.Name == name || id == name {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// columnWalker builds the column-level impact tree: FKs, indexes and constraints on the column,
+// views reading it (followed through the view columns that carry it), and functions with the
+// triggers executing them
+type columnWalker struct {
+	g        *graph.Graph
+	adapter  adapters.Adapter
+	result   *report.Impact
+	maxDepth int
+	visited  map[string]bool
+}
+
+// walk adds the dependents of schema.table.column under parent
+func (c *columnWalker) walk(schema, table, column string, parent *report.ImpactNode) error {
+	deps, err := c.adapter.GetColumnDependencies(schema, table, column)
+	if err != nil {
+		return err
+	}
+	c.visited[parent.ID] = true
+	tableID := fmt.Sprintf("%s.%s", schema, table)
+
+	for _, dep := range deps {
+		depSchema, depName := dep.Schema, dep.Name
+		// Postgres qualifies regclass names outside the search_path
+		if i := strings.Index(depName, "."); i > 0 {
+			depSchema, depName = depName[:i], depName[i+1:]
+		}
+		id := fmt.Sprintf("%s.%s", depSchema, depName)
+
+		var edge *graph.Edge
+		if dep.Type == "FOREIGN_KEY" {
+			edge = c.referencingFK(tableID, strings.TrimPrefix(dep.Detail, "Constraint: "))
+			if edge != nil {
+				id = edge.SourceID
+			}
+		}
+		if c.visited[id] {
+			continue
+		}
+		c.visited[id] = true
+
+		dep := dep
+		child := &report.ImpactNode{ID: id, Type: graph.NodeType(dep.Type), Level: parent.Level + 1, Edge: edge, Dependency: &dep}
+		// Trigger nodes are keyed by table: schema.name may be the function a trigger is named after
+		if n, ok := c.g.Nodes[id]; ok && (dep.Type != "TRIGGER" || n.Type == graph.Trigger) {
+			child.Type, child.Size, child.RowCount, child.Index = n.Type, n.Size, n.RowCount, n.Index
+		}
+		parent.Children = append(parent.Children, child)
+		c.result.TotalAffected++
+		c.result.AffectedTypes[child.Type]++
+
+		if edge != nil {
+			c.result.Warnings = append(c.result.Warnings, impactWarnings(c.g, edge, parent, parent.Level)...)
+		}
+		if dep.Type == "VIEW" && parent.Level >= 2 {
+			c.result.Warnings = append(c.result.Warnings, report.Warning{Severity: "Med", Kind: "View Coupling",
+				Message: fmt.Sprintf("'%s' is %d levels removed but will break on schema change.", id, parent.Level)})
+		}
+
+		if c.maxDepth > 0 && child.Level >= c.maxDepth {
+			continue
+		}
+		switch dep.Type {
+		case "VIEW":
+			// Views on this view only break if they read a column carrying ours
+			mapper, ok := c.adapter.(adapters.ViewColumnMapper)
+			if !ok {
+				continue
+			}
+			cols, err := mapper.DerivedViewColumns(depSchema, depName, column)
+			if err != nil {
+				c.incomplete(id, err)
+				continue
+			}
+			child.Columns = cols
+			for _, col := range cols {
+				if err := c.walk(depSchema, depName, col, child); err != nil {
+					c.incomplete(fmt.Sprintf("%s.%s", id, col), err)
+				}
+			}
+		case "FUNCTION":
+			fetcher, ok := c.adapter.(adapters.FunctionDependencyFetcher)
+			if !ok {
+				continue
+			}
+			callers, err := fetcher.GetFunctionDependencies(depSchema, depName)
+			if err != nil {
+				c.incomplete(id, err)
+				continue
+			}
+			for _, caller := range callers {
+				triggerID := fmt.Sprintf("%s.%s", caller.Schema, caller.Name)
+				if caller.Type != "TRIGGER" || c.visited[triggerID] {
+					continue
 				}
+				c.visited[triggerID] = true
+				caller := caller
+				child.Children = append(child.Children, &report.ImpactNode{ID: triggerID, Type: graph.Trigger, Level: child.Level + 1, Dependency: &caller})
+				c.result.TotalAffected++
+				c.result.AffectedTypes[graph.Trigger]++
 			}
-			return 1 + max
 		}
+	}
+	return nil
+}
+
+// incomplete records that the dependents below id could not be fetched, so the tree is truncated there
+func (c *columnWalker) incomplete(id string, err error) {
+	c.result.Warnings = append(c.result.Warnings, report.Warning{Severity: "Med", Kind: "Incomplete Tree",
+		Message: fmt.Sprintf("Dependents of '%s' could not be fetched: %v", id, err)})
+}
 
-		// 2. Print Metrics
-		fmt.Printf("\n📊 IMPACT RADIUS: %d Levels Deep [Load: 🔥 System Active]\n", getDepth(root))
-		fmt.Printf("Total Affected Objects: %d (", totalAffected)
+// referencingFK finds the FK edge named constraint that points at tableID
+func (c *columnWalker) referencingFK(tableID, constraint string) *graph.Edge {
+	for _, edges := range c.g.Edges {
+		for _, e := range edges {
+			if e.Type == graph.ForeignKey && e.TargetID == tableID && e.ConstraintName == constraint {
+				return e
+			}
+		}
+	}
+	return nil
+}
+
+// treeDepth returns the number of levels below n
+func treeDepth(n *report.ImpactNode) int {
+	if len(n.Children) == 0 {
+		return 0
+	}
+	max := 0
+	for _, child := range n.Children {
+		d := treeDepth(child)
+		if d > max {
+			max = d
+		}
+	}
+	return 1 + max
+}
+
+// formatRows abbreviates a row count the way the impact tree shows it (e.g. "1.2k rows")
+func formatRows(rows int64) string {
+	rowStr := fmt.Sprintf("%d rows", rows)
+	if rows > 1000 {
+		rowStr = fmt.Sprintf("%.1fk rows", float64(rows)/1000.0)
+	}
+	if rows > 1000000 {
+		rowStr = fmt.Sprintf("%.1fm rows", float64(rows)/1000000.0)
+	}
+	return rowStr
+}
+
+// printImpact renders an impact result as the emoji tree
+func printImpact(w io.Writer, v any) error {
+	result := v.(*report.Impact)
+
+	activeLocks := 0
+	if result.Metrics != nil {
+		activeLocks = result.Metrics.ActiveLocks
+	}
+	fmt.Fprintf(w, "🔍 DB: %s | Target: %s (%s) | Active Locks: %d\n", result.Database, result.Target, formatRows(result.RowCount), activeLocks)
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+
+	// 2. Print Metrics
+	if result.Tree != nil {
+		fmt.Fprintf(w, "\n📊 IMPACT RADIUS: %d Levels Deep [Load: 🔥 System Active]\n", result.Depth)
+		fmt.Fprintf(w, "Total Affected Objects: %d (", result.TotalAffected)
 		counts := []string{}
-		for t, c := range structures {
+		for t, c := range result.AffectedTypes {
 			counts = append(counts, fmt.Sprintf("%d %ss", c, t))
 		}
-		fmt.Printf("%s)\n", strings.Join(counts, ", "))
-		fmt.Println("\nTREE VIEW")
+		sort.Strings(counts)
+		fmt.Fprintf(w, "%s)\n", strings.Join(counts, ", "))
+		fmt.Fprintln(w, "\nTREE VIEW")
 
 		// 3. Print Tree
-		var printTree func(node *TreeNode, prefix string, isLast bool)
-		printTree = func(node *TreeNode, prefix string, isLast bool) {
-			marker := "├──"
-			if isLast {
-				marker = "└──"
+		printImpactTree(w, result.Tree, "📥")
+	}
+
+	// 3.5 Print what the target depends on
+	if result.Upstream != nil {
+		fmt.Fprintf(w, "\n🧬 DEPENDS ON: %d Levels Up\n", result.UpstreamDepth)
+		fmt.Fprintf(w, "Total Dependencies: %d\n", result.TotalUpstream)
+		fmt.Fprintln(w, "\nUPSTREAM TREE VIEW")
+		printImpactTree(w, result.Upstream, "📤")
+	}
+
+	// 4. Print Warnings
+	if len(result.Warnings) > 0 {
+		fmt.Fprintln(w, "\n⚠️  STRUCTURAL WARNINGS")
+		for _, warn := range result.Warnings {
+			fmt.Fprintf(w, "[%s] %s: %s\n", warn.Severity, warn.Kind, warn.Message)
+		}
+	}
+
+	// 5. Resource Metrics (skipped for embedded databases without server stats)
+	if result.Metrics == nil {
+		return nil
+	}
+	fmt.Fprintln(w, "\n📊 RESOURCE METRICS")
+	satLabel := "(Low)"
+	if strings.TrimSuffix(result.Metrics.ConnSaturation, "%") > "80" {
+		satLabel = "(High)"
+	}
+	fmt.Fprintf(w, "Connection Saturation: %s %s\n", result.Metrics.ConnSaturation, satLabel)
+	fmt.Fprintf(w, "Longest Running Query: %s\n", result.Metrics.LongestQuery)
+	return nil
+}
+
+// printImpactTree renders an impact tree with its edge annotations; tableIcon marks table rows
+func printImpactTree(w io.Writer, root *report.ImpactNode, tableIcon string) {
+	var printTree func(node *report.ImpactNode, prefix string, isLast bool)
+	printTree = func(node *report.ImpactNode, prefix string, isLast bool) {
+		marker := "├──"
+		if isLast {
+			marker = "└──"
+		}
+		if node.Level == 0 {
+			// Root
+			fRowStr := fmt.Sprintf("%d rows", node.RowCount)
+			if node.RowCount > 1000 {
+				fRowStr = fmt.Sprintf("%.1fk rows", float64(node.RowCount)/1000.0)
 			}
-			if node.Level == 0 {
-				// Root
-				fRowStr := fmt.Sprintf("%d rows", node.RowCount)
-				if node.RowCount > 1000 {
-					fRowStr = fmt.Sprintf("%.1fk rows", float64(node.RowCount)/1000.0)
-				}
-				fmt.Printf("%s (%s)\n", node.ID, fRowStr)
-			} else {
-				meta := ""
-				if node.EdgeMeta != nil {
-					if node.EdgeMeta.Type == graph.ForeignKey {
-						meta = fmt.Sprintf("[FK: %s]", node.EdgeMeta.ConstraintName)
-						if node.EdgeMeta.DeleteRule == "CASCADE" {
-							meta += " (CASCADE)"
-						}
-					} else if node.EdgeMeta.Type == graph.TriggerAction {
-						meta = "(Trigger)"
-					} else if node.EdgeMeta.Type == graph.Inheritance {
-						meta = "(Partition Source)"
-					} else {
-						meta = "(View)"
+			fmt.Fprintf(w, "%s (%s)\n", node.ID, fRowStr)
+		} else {
+			meta := ""
+			if node.Edge == nil && node.Dependency != nil {
+				meta = dependencyLabel(node)
+			} else if node.Edge != nil {
+				if node.Edge.Type == graph.ForeignKey {
+					meta = fmt.Sprintf("[FK: %s]", node.Edge.ConstraintName)
+					if key := node.Edge.MetaData["referenced_index"]; key != "" {
+						meta = fmt.Sprintf("[FK: %s via %s]", node.Edge.ConstraintName, key)
 					}
-				}
-				icon := "📥"
-				if node.Type == graph.View {
-					icon = "👁️ "
-				} else if node.Type == graph.Trigger {
-					icon = "⚡"
-				} else if strings.Contains(string(node.Type), "Partition") {
-					icon = "🧩"
-				}
-
-				fRowStr := ""
-				if node.Type == graph.Table {
-					fRowStr = fmt.Sprintf("(%d rows)", node.RowCount)
-					if node.RowCount > 1000 {
-						fRowStr = fmt.Sprintf("(%.1fk rows)", float64(node.RowCount)/1000.0)
+					if node.Edge.DeleteRule == "CASCADE" {
+						meta += " (CASCADE)"
 					}
+				} else if node.Edge.Type == graph.TriggerAction {
+					meta = "(Trigger)"
+				} else if node.Edge.Type == graph.Inheritance {
+					meta = "(Partition Source)"
+				} else if node.Edge.Type == graph.FunctionCall {
+					meta = "(Executes)"
+					if node.ID == node.Edge.SourceID {
+						meta = "(Calls)"
+					}
+				} else if node.Edge.Type == graph.FunctionAccess {
+					meta = functionAccessLabel(node.Edge)
+				} else if node.Edge.Type == graph.IndexOn {
+					meta = indexLabel(node)
+				} else if label, ok := columnEdgeLabels[node.Edge.Type]; ok {
+					meta = columnEdgeLabel(label, node.Edge)
+				} else {
+					meta = "(View)"
 				}
-
-				fmt.Printf("%s%s %s %s %s %s\n", prefix, marker, icon, node.ID, fRowStr, meta)
+			}
+			icon := tableIcon
+			if node.Type == graph.View {
+				icon = "👁️ "
+			} else if node.Type == graph.Trigger {
+				icon = "⚡"
+			} else if strings.Contains(string(node.Type), "Partition") {
+				icon = "🧩"
+			} else if depIcon, ok := dependencyIcons[node.Type]; ok {
+				icon = depIcon
 			}
 
-			childPrefix := prefix
-			if node.Level > 0 {
-				if isLast {
-					childPrefix += "    "
-				} else {
-					childPrefix += "│   "
+			fRowStr := ""
+			if node.Type == graph.Table {
+				fRowStr = fmt.Sprintf("(%d rows)", node.RowCount)
+				if node.RowCount > 1000 {
+					fRowStr = fmt.Sprintf("(%.1fk rows)", float64(node.RowCount)/1000.0)
 				}
 			}
 
-			for i, child := range node.Children {
-				printTree(child, childPrefix, i == len(node.Children)-1)
+			name := node.ID
+			if node.Type == graph.Function {
+				name += "()"
 			}
+			fmt.Fprintf(w, "%s%s %s %s %s %s\n", prefix, marker, icon, name, fRowStr, meta)
 		}
-		printTree(root, "", true)
 
-		// 4. Print Warnings
-		if len(warnings) > 0 {
-			fmt.Println("\n⚠️  STRUCTURAL WARNINGS")
-			for _, w := range warnings {
-				fmt.Println(w)
+		childPrefix := prefix
+		if node.Level > 0 {
+			if isLast {
+				childPrefix += "    "
+			} else {
+				childPrefix += "│   "
 			}
 		}
 
-		// 5. Resource Metrics
-		fmt.Println("\n📊 RESOURCE METRICS")
-		satLabel := "(Low)"
-		if strings.TrimSuffix(metrics.ConnSaturation, "%") > "80" {
-			satLabel = "(High)"
+		for i, child := range node.Children {
+			printTree(child, childPrefix, i == len(node.Children)-1)
 		}
-		fmt.Printf("Connection Saturation: %s %s\n", metrics.ConnSaturation, satLabel)
-		fmt.Printf("Longest Running Query: %s\n", metrics.LongestQuery)
-	},
+	}
+	printTree(root, "", true)
+}
+
+// dependencyIcons marks functions, types, sequences and the column dependents that are not graph nodes
+var dependencyIcons = map[graph.NodeType]string{
+	"INDEX":             "🔖",
+	"CONSTRAINT":        "🛡️ ",
+	graph.Function:      "📜",
+	graph.Sequence:      "🔢",
+	graph.Enum:          "🏷️ ",
+	graph.CompositeType: "🧱",
+	graph.Domain:        "📐",
+}
+
+// columnEdgeLabels names the edges that record the columns using a type or a sequence
+var columnEdgeLabels = map[graph.DependencyType]string{
+	graph.TypeUsage:       "Uses Type",
+	graph.SequenceDefault: "Default nextval",
+	graph.SequenceOwned:   "Owned by",
+}
+
+// columnEdgeLabel annotates a type or sequence edge with the columns involved
+func columnEdgeLabel(label string, e *graph.Edge) string {
+	if cols := e.MetaData["columns"]; cols != "" {
+		return fmt.Sprintf("(%s: %s)", label, strings.ReplaceAll(cols, ",", ", "))
+	}
+	return "(" + label + ")"
+}
+
+// functionAccessLabel annotates a function body edge with what the body does to the relation,
+// and how certain that is when the reference comes from dynamic SQL
+func functionAccessLabel(e *graph.Edge) string {
+	label := "Reads"
+	switch e.MetaData["access"] {
+	case "write":
+		label = "Writes"
+	case "read,write":
+		label = "Reads, Writes"
+	}
+	if confidence := e.MetaData["confidence"]; confidence != "" && confidence != "high" {
+		label += ", confidence: " + confidence
+	}
+	return "(" + label + ")"
+}
+
+// dependencyLabel annotates a column dependent the way edges annotate table dependents
+func dependencyLabel(node *report.ImpactNode) string {
+	switch node.Dependency.Type {
+	case "INDEX":
+		if node.Index != nil {
+			return indexLabel(node)
+		}
+		return "(Index)"
+	case "CONSTRAINT":
+		return "(Constraint)"
+	case "FUNCTION":
+		return "(Function Body)"
+	case "TRIGGER":
+		return "(Trigger)"
+	case "VIEW":
+		if len(node.Columns) > 0 {
+			return fmt.Sprintf("(View → %s)", strings.Join(node.Columns, ", "))
+		}
+		return "(View)"
+	}
+	return fmt.Sprintf("(%s)", node.Dependency.Detail)
+}
+
+// indexLabel describes an index: its definition, size and, when the database keeps statistics, scan count
+func indexLabel(node *report.ImpactNode) string {
+	if node.Index == nil {
+		// Upstream tree: node is the table the index is built on
+		return "(Index On)"
+	}
+	parts := []string{"Index: " + node.Index.Definition()}
+	if node.Size != "" {
+		parts = append(parts, node.Size)
+	}
+	if node.Index.HasStats {
+		parts = append(parts, fmt.Sprintf("%d scans", node.Index.Scans))
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+// impactEdgeTypes maps --edge-type names to dependency types
+var impactEdgeTypes = map[string]graph.DependencyType{
+	"fk":        graph.ForeignKey,
+	"view":      graph.ViewDepends,
+	"trigger":   graph.TriggerAction,
+	"partition": graph.Inheritance,
+	"function":  graph.FunctionAccess,
+	"call":      graph.FunctionCall,
+	"type":      graph.TypeUsage,
+	"sequence":  graph.SequenceDefault,
+	"owned":     graph.SequenceOwned,
+	"index":     graph.IndexOn,
 }
 
 func init() {
 	rootCmd.AddCommand(impactCmd)
+	impactCmd.Flags().String("direction", "down", "down: what depends on the object, up: what it depends on, both: both trees")
+	impactCmd.Flags().Int("depth", 0, "Maximum number of levels to follow (0 = unlimited)")
+	impactCmd.Flags().StringSlice("edge-type", nil, "Only follow these edges: fk, view, trigger, partition, function, call, type, sequence, owned, index (default all)")
 }
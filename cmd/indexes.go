@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/alexanderritik/dbgraph/internal/graph"
+	"github.com/alexanderritik/dbgraph/internal/report"
+
+	"github.com/spf13/cobra"
+)
+
+// indexesCmd represents the indexes command
+var indexesCmd = &cobra.Command{
+	Use:   "indexes",
+	Short: "Report unused, duplicate and redundant indexes",
+	Long: `Lists the indexes that can be dropped, with the space each one frees: indexes never scanned since the
+statistics were reset (pg_stat_user_indexes), exact duplicates, and indexes whose key is a left prefix of
+another index on the same table. Indexes enforcing a primary key, unique constraint or uniqueness,
+referenced by a foreign key, or alone in covering a foreign key's columns are listed as must-keep instead.
+Schema files have no statistics, so only duplicates and redundant indexes are reported for them.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureDBConnection()
+
+		g, err := loadGraph(dbUrl)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		result := &report.Indexes{Database: redactConnString(dbUrl), IndexAudit: *g.AuditIndexes()}
+		render(result, printIndexes)
+	},
+}
+
+// indexFindingSections orders and labels the findings of the text report
+var indexFindingSections = []struct {
+	kind  graph.IndexFindingKind
+	title string
+}{
+	{graph.IndexUnused, "🗑️  UNUSED (0 scans since stats reset)"},
+	{graph.IndexDuplicate, "👯 DUPLICATE"},
+	{graph.IndexRedundant, "✂️  REDUNDANT (left prefix of another index)"},
+}
+
+// printIndexes renders the index audit
+func printIndexes(w io.Writer, v any) error {
+	result := v.(*report.Indexes)
+
+	fmt.Fprintf(w, "🔖 INDEXES: %s | Indexes: %d | Droppable: %d\n", result.Database, result.Indexes, len(result.Findings))
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+	if result.Indexes == 0 {
+		fmt.Fprintln(w, "No index definitions available (indexes are modeled for PostgreSQL, schema files and snapshots)")
+		return nil
+	}
+	if !result.HasStats {
+		fmt.Fprintln(w, "ℹ️  No scan statistics available: unused indexes are not reported")
+	}
+
+	for _, section := range indexFindingSections {
+		var found []graph.IndexFinding
+		for _, f := range result.Findings {
+			if f.Kind == section.kind {
+				found = append(found, f)
+			}
+		}
+		if len(found) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s: %d\n", section.title, len(found))
+		for _, f := range found {
+			size := ""
+			if f.Size != "" {
+				size = ", " + f.Size
+			}
+			switch f.Kind {
+			case graph.IndexDuplicate:
+				fmt.Fprintf(w, "   - %s on %s: %s (same as %s%s)\n", f.ID, f.Table, f.Definition, f.CoveredBy, size)
+			case graph.IndexRedundant:
+				fmt.Fprintf(w, "   - %s on %s: %s (covered by %s%s)\n", f.ID, f.Table, f.Definition, f.CoveredBy, size)
+			default:
+				fmt.Fprintf(w, "   - %s on %s: %s (%d scans%s)\n", f.ID, f.Table, f.Definition, f.Scans, size)
+			}
+		}
+	}
+
+	if len(result.Protected) > 0 {
+		fmt.Fprintf(w, "\n🛡️  MUST KEEP: %d\n", len(result.Protected))
+		for _, p := range result.Protected {
+			fmt.Fprintf(w, "   - %s on %s: %s\n", p.ID, p.Table, strings.Join(p.Reasons, "; "))
+		}
+	}
+
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+	if len(result.Findings) == 0 {
+		fmt.Fprintln(w, "✅ No droppable indexes found")
+	} else if result.SavingsBytes > 0 {
+		fmt.Fprintf(w, "💾 Dropping the %d indexes above frees ~%s\n", len(result.Findings), result.Savings)
+	} else {
+		fmt.Fprintf(w, "💾 %d indexes can be dropped (sizes unknown offline)\n", len(result.Findings))
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(indexesCmd)
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/alexanderritik/dbgraph/internal/graph"
+	"github.com/alexanderritik/dbgraph/internal/report"
+
+	"github.com/spf13/cobra"
+)
+
+// lineageCmd represents the lineage command
+var lineageCmd = &cobra.Command{
+	Use:   "lineage <schema.view.column>",
+	Short: "Trace which source columns feed a view column, or which view columns a column feeds",
+	Long: `Follows column-level lineage through view definitions. --direction up (default) lists the columns
+the target is computed from, down to the base table columns; --direction down lists the view columns
+computed from it; --direction both prints both trees.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		direction, _ := cmd.Flags().GetString("direction")
+		if direction != "down" && direction != "up" && direction != "both" {
+			fmt.Printf("Error: unknown direction '%s' (expected up, down or both)\n", direction)
+			os.Exit(1)
+		}
+
+		ensureDBConnection()
+
+		g, err := loadGraph(dbUrl)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		relationID, column, found := resolveImpactTarget(g, args[0])
+		if !found || column == "" {
+			fmt.Printf("Error: column '%s' not found (expected [schema.]relation.column)\n", args[0])
+			os.Exit(1)
+		}
+		columnID := relationID + "." + column
+
+		result := &report.Lineage{
+			Database:  redactConnString(dbUrl),
+			Column:    columnID,
+			Direction: direction,
+		}
+		if direction != "down" {
+			result.Sources = buildLineageTree(g, columnID, 0, g.ColumnSources, map[string]bool{})
+			result.BaseColumns = lineageLeaves(result.Sources)
+		}
+		if direction != "up" {
+			result.Consumers = buildLineageTree(g, columnID, 0, g.ColumnConsumers, map[string]bool{})
+		}
+
+		render(result, func(w io.Writer, v any) error {
+			printLineage(w, v.(*report.Lineage))
+			return nil
+		})
+	},
+}
+
+// buildLineageTree expands a column through next (ColumnSources or ColumnConsumers)
+func buildLineageTree(g *graph.Graph, columnID string, level int, next func(string) []string, visited map[string]bool) *report.LineageNode {
+	node := &report.LineageNode{ID: columnID, Type: lineageRelationType(g, columnID), Level: level}
+	if visited[columnID] {
+		return node
+	}
+	visited[columnID] = true
+	for _, id := range next(columnID) {
+		node.Children = append(node.Children, buildLineageTree(g, id, level+1, next, visited))
+	}
+	return node
+}
+
+// lineageRelationType returns the type of the relation holding a column
+func lineageRelationType(g *graph.Graph, columnID string) graph.NodeType {
+	if i := strings.LastIndex(columnID, "."); i > 0 {
+		if node, ok := g.Nodes[columnID[:i]]; ok {
+			return node.Type
+		}
+	}
+	return graph.Table
+}
+
+// lineageLeaves returns the distinct leaf columns of a source tree, sorted
+func lineageLeaves(root *report.LineageNode) []string {
+	seen := make(map[string]bool)
+	var walk func(n *report.LineageNode)
+	walk = func(n *report.LineageNode) {
+		if len(n.Children) == 0 && n.Level > 0 {
+			seen[n.ID] = true
+		}
+		for _, c := range n.Children {
+			walk(c)
+		}
+	}
+	walk(root)
+
+	leaves := make([]string, 0, len(seen))
+	for id := range seen {
+		leaves = append(leaves, id)
+	}
+	sort.Strings(leaves)
+	return leaves
+}
+
+func printLineage(w io.Writer, l *report.Lineage) {
+	fmt.Fprintf(w, "🧬 LINEAGE: %s (DB: %s)\n", l.Column, l.Database)
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+
+	if l.Sources != nil {
+		fmt.Fprintln(w, "\n📤 COMPUTED FROM")
+		if len(l.Sources.Children) == 0 {
+			fmt.Fprintln(w, "No source columns recorded (not a view column, or its expression reads no columns).")
+		} else {
+			printLineageTree(w, l.Sources)
+			fmt.Fprintf(w, "\nBase columns: %s\n", strings.Join(l.BaseColumns, ", "))
+		}
+	}
+	if l.Consumers != nil {
+		fmt.Fprintln(w, "\n📥 FEEDS")
+		if len(l.Consumers.Children) == 0 {
+			fmt.Fprintln(w, "No view columns are computed from this column.")
+		} else {
+			printLineageTree(w, l.Consumers)
+		}
+	}
+}
+
+func printLineageTree(w io.Writer, root *report.LineageNode) {
+	var printNode func(n *report.LineageNode, prefix string, isLast bool)
+	printNode = func(n *report.LineageNode, prefix string, isLast bool) {
+		childPrefix := ""
+		if n.Level == 0 {
+			fmt.Fprintln(w, n.ID)
+		} else {
+			marker := "├──"
+			childPrefix = prefix + "│   "
+			if isLast {
+				marker = "└──"
+				childPrefix = prefix + "    "
+			}
+			icon := "📋"
+			if n.Type == graph.View {
+				icon = "👁️ "
+			}
+			fmt.Fprintf(w, "%s%s %s %s\n", prefix, marker, icon, n.ID)
+		}
+		for i, c := range n.Children {
+			printNode(c, childPrefix, i == len(n.Children)-1)
+		}
+	}
+	printNode(root, "", true)
+}
+
+func init() {
+	rootCmd.AddCommand(lineageCmd)
+	lineageCmd.Flags().String("direction", "up", "Walk direction: up (source columns), down (derived view columns) or both")
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/alexanderritik/dbgraph/internal/lint"
+	"github.com/alexanderritik/dbgraph/internal/report"
+
+	"github.com/spf13/cobra"
+)
+
+// severityIcons prefixes findings in text output
+var severityIcons = map[lint.Severity]string{lint.Error: "🔴", lint.Warning: "⚠️ ", lint.Info: "ℹ️ "}
+
+// lintCmd represents the lint command
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Run the schema rules configured in .dbgraph.yaml",
+	Long: `Runs every enabled rule (cycles, unindexed FKs, god objects, missing primary keys, nullable FKs,
+cascading deletes, orphan tables, islands) and prints the findings. Rules, severities, thresholds and
+per-schema/table ignores come from --config. Exits 1 when a finding is at or above --fail-on.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureDBConnection()
+
+		failOnFlag, _ := cmd.Flags().GetString("fail-on")
+		failOn, err := lint.ParseSeverity(failOnFlag)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		cfg := loadConfig()
+		rules := lintRules(cfg)
+
+		g, err := loadGraph(dbUrl)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		result := &report.Lint{
+			Database: redactConnString(dbUrl),
+			Rules:    ruleIDs(rules),
+			Findings: cfg.Filter(lint.Run(g, rules)),
+			FailOn:   failOn,
+		}
+		for _, f := range result.Findings {
+			result.Failed = result.Failed || f.Severity.AtLeast(failOn)
+		}
+
+		render(result, printLint)
+		if result.Failed {
+			os.Exit(1)
+		}
+	},
+}
+
+// ruleIDs lists the IDs of rules in order
+func ruleIDs(rules []lint.Rule) []string {
+	ids := []string{}
+	for _, r := range rules {
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
+
+// printLint renders the findings grouped by rule
+func printLint(w io.Writer, v any) error {
+	result := v.(*report.Lint)
+
+	fmt.Fprintf(w, "🧹 LINT: %s | Rules: %d\n", result.Database, len(result.Rules))
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+	printFindings(w, result.Rules, result.Findings, 0)
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+
+	if result.Failed {
+		fmt.Fprintf(w, "❌ FAILED: findings at or above %s\n", result.FailOn)
+	} else {
+		fmt.Fprintf(w, "✅ PASSED: %d findings, none at or above %s\n", len(result.Findings), result.FailOn)
+	}
+	return nil
+}
+
+// printFindings lists the findings under the rule that produced them; limit > 0 caps each rule's list
+func printFindings(w io.Writer, rules []string, findings []lint.Finding, limit int) {
+	byRule := make(map[string][]lint.Finding)
+	for _, f := range findings {
+		byRule[f.Rule] = append(byRule[f.Rule], f)
+	}
+	for _, id := range rules {
+		found := byRule[id]
+		if len(found) == 0 {
+			fmt.Fprintf(w, "✅ %s: no findings\n", id)
+			continue
+		}
+		fmt.Fprintf(w, "%s %s (%d %s)\n", severityIcons[found[0].Severity], id, len(found), found[0].Severity)
+		for i, f := range found {
+			if limit > 0 && i >= limit {
+				fmt.Fprintf(w, "   ... and %d more\n", len(found)-limit)
+				break
+			}
+			fmt.Fprintf(w, "   - %s\n", f.Message)
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+	lintCmd.Flags().String("fail-on", "error", "Lowest severity of a finding that exits 1: info, warning or error")
+}
@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/alexanderritik/dbgraph/internal/adapters"
+	"github.com/alexanderritik/dbgraph/internal/graph"
+	"github.com/alexanderritik/dbgraph/internal/report"
+	"github.com/alexanderritik/dbgraph/internal/sqlparse"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	locksInterval  int
+	locksWatch     bool
+	locksTerminate bool
+)
+
+// locksCmd represents the locks command
+var locksCmd = &cobra.Command{
+	Use:   "locks",
+	Short: "Show who blocks whom: the live lock wait tree",
+	Long: `Builds blocker → waiter trees from pg_locks and pg_blocking_pids(). Each session shows its state,
+how long its transaction has been running, its wait event, its query and the relations it locks, resolved
+against the schema (an index lock shows its table). Roots are the sessions holding everybody else up.
+
+With --terminate, prints a pg_terminate_backend() statement for each root blocker. They are suggestions:
+nothing is run.
+
+Example:
+  dbgraph locks --watch --interval 2`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureDBConnection()
+
+		a, err := adapters.NewAdapter(dbUrl)
+		if err != nil {
+			fmt.Printf("Error creating adapter: %v\n", err)
+			os.Exit(1)
+		}
+		defer a.Close()
+		inspector, ok := a.(adapters.LockInspector)
+		if !ok {
+			fmt.Println("ℹ️  locks is unavailable for this database (lock inspection is supported on PostgreSQL)")
+			os.Exit(1)
+		}
+		if err := a.Connect(dbUrl); err != nil {
+			fmt.Printf("Error connecting to database: %v\n", err)
+			os.Exit(1)
+		}
+
+		// The schema resolves locked relations; without it they are shown by name only
+		g := graph.NewGraph()
+		_ = a.FetchSchema(g)
+
+		for {
+			if locksWatch && output() == report.Text {
+				c := exec.Command("clear")
+				c.Stdout = os.Stdout
+				c.Run()
+			}
+
+			sessions, err := inspector.GetLockSessions()
+			if err != nil {
+				fmt.Printf("Error fetching locks: %v\n", err)
+				if !locksWatch {
+					os.Exit(1)
+				}
+				time.Sleep(time.Duration(locksInterval) * time.Second)
+				continue
+			}
+			g.ResolveLocks(sessions)
+
+			result := &report.Locks{Database: redactConnString(dbUrl), Sessions: len(sessions), Roots: graph.BuildLockTree(sessions)}
+			if result.Roots == nil {
+				result.Roots = []*graph.LockNode{}
+			}
+			for _, s := range sessions {
+				if s.Waiting() {
+					result.Waiting++
+				}
+			}
+			if locksTerminate {
+				for _, root := range result.Roots {
+					result.Terminate = append(result.Terminate, terminateStatement(root))
+				}
+			}
+			render(result, printLocks)
+
+			if !locksWatch {
+				break
+			}
+			time.Sleep(time.Duration(locksInterval) * time.Second)
+		}
+	},
+}
+
+// terminateStatement suggests ending a root blocker, with what it is doing as a comment
+func terminateStatement(root *graph.LockNode) string {
+	what := root.State
+	if root.Query != "" {
+		what += ": " + sqlparse.OneLine(root.Query, 60)
+	}
+	return fmt.Sprintf("SELECT pg_terminate_backend(%d); -- holds up %d sessions, %s (%s)", root.PID, root.Waiters, formatDuration(root.DurationMs), what)
+}
+
+// formatDuration renders milliseconds as seconds, minutes or hours
+func formatDuration(ms float64) string {
+	switch d := time.Duration(ms) * time.Millisecond; {
+	case d < time.Minute:
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	case d < time.Hour:
+		return fmt.Sprintf("%.1fm", d.Minutes())
+	default:
+		return fmt.Sprintf("%.1fh", d.Hours())
+	}
+}
+
+// printLocks renders the blocker → waiter trees
+func printLocks(w io.Writer, v any) error {
+	result := v.(*report.Locks)
+
+	fmt.Fprintf(w, "🔐 LOCKS: %s | Sessions with locks: %d | Waiting: %d\n", result.Database, result.Sessions, result.Waiting)
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+	if len(result.Roots) == 0 {
+		fmt.Fprintln(w, "✅ No session is waiting for a lock")
+		return nil
+	}
+
+	for i, root := range result.Roots {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		printLockNode(w, root, 0)
+	}
+
+	if len(result.Terminate) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "🔪 To end the root blockers (rolls back their transactions; pg_cancel_backend() only cancels the statement):")
+		for _, stmt := range result.Terminate {
+			fmt.Fprintf(w, "   %s\n", stmt)
+		}
+	}
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+	return nil
+}
+
+// printLockNode renders a session and, indented below it, the sessions waiting on it
+func printLockNode(w io.Writer, n *graph.LockNode, depth int) {
+	indent, branch := strings.Repeat("   ", depth), ""
+	icon := "🛑"
+	if depth > 0 {
+		branch, icon = "└─ ", "⏳"
+	}
+	if n.Cycle {
+		fmt.Fprintf(w, "%s%s🔁 pid %d (deadlock: already above)\n", indent, branch, n.PID)
+		return
+	}
+
+	user := ""
+	if n.User != "" {
+		user = " " + n.User
+	}
+	fmt.Fprintf(w, "%s%s%s pid %d%s [%s, %s]", indent, branch, icon, n.PID, user, n.State, formatDuration(n.DurationMs))
+	if n.Waiters > 0 {
+		fmt.Fprintf(w, " holds up %d", n.Waiters)
+	}
+	if n.WaitEvent != "" {
+		fmt.Fprintf(w, " | waits on %s", n.WaitEvent)
+	}
+	fmt.Fprintln(w)
+
+	detail := indent + "      "
+	if n.Query != "" {
+		fmt.Fprintf(w, "%s%s\n", detail, sqlparse.OneLine(n.Query, 80))
+	}
+	for _, l := range n.Locks {
+		state := "holds"
+		if !l.Granted {
+			state = "WAITS FOR"
+		}
+		relation := l.Relation
+		switch {
+		case l.Table != "":
+			relation = fmt.Sprintf("%s (index on %s)", l.Relation, l.Table)
+		case l.Type != "" && l.Type != graph.Table:
+			relation = fmt.Sprintf("%s (%s)", l.Relation, strings.ToLower(string(l.Type)))
+		}
+		fmt.Fprintf(w, "%s- %s %s on %s\n", detail, state, l.Mode, relation)
+	}
+
+	for _, c := range n.Children {
+		printLockNode(w, c, depth+1)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(locksCmd)
+	locksCmd.Flags().IntVar(&locksInterval, "interval", 2, "Seconds between refreshes")
+	locksCmd.Flags().BoolVar(&locksWatch, "watch", false, "Live watch mode")
+	locksCmd.Flags().BoolVar(&locksTerminate, "terminate", false, "Print pg_terminate_backend() statements for the root blockers")
+}
@@ -0,0 +1,379 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/alexanderritik/dbgraph/internal/adapters"
+	"github.com/alexanderritik/dbgraph/internal/engine"
+	"github.com/alexanderritik/dbgraph/internal/graph"
+	"github.com/alexanderritik/dbgraph/internal/report"
+	"github.com/alexanderritik/dbgraph/internal/sqlparse"
+
+	"github.com/spf13/cobra"
+)
+
+// locksPlanCmd represents the locks-plan command
+var locksPlanCmd = &cobra.Command{
+	Use:   "locks-plan",
+	Short: "Show the locks a planned DDL statement takes and who it would block",
+	Long: `Classifies the table lock each statement takes (ACCESS EXCLUSIVE, SHARE ROW EXCLUSIVE, ...) and expands it
+through the dependency graph: partitions locked along with their parent, FK parents locked by new or dropped
+foreign keys, the table of an index. Views and FK children whose queries would queue behind the lock are
+listed too.
+
+On PostgreSQL it then checks the live sessions (pg_locks, pg_stat_activity) the statement would wait for,
+and the frequent queries (pg_stat_statements) that would wait for it. While an ACCESS EXCLUSIVE request
+waits, every later query on the table queues behind it: run such statements with a short lock_timeout.
+The lock levels are PostgreSQL's: MySQL and SQLite connections are refused.
+
+Example:
+  dbgraph locks-plan --sql "ALTER TABLE orders ADD COLUMN note text"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		sql, _ := cmd.Flags().GetString("sql")
+		if strings.TrimSpace(sql) == "" {
+			fmt.Println("Error: --sql flag is required")
+			os.Exit(1)
+		}
+		plans := sqlparse.PlanLocks(sql, "public")
+
+		ensureDBConnection()
+
+		g := graph.NewGraph()
+		a, err := adapters.NewAdapter(dbUrl)
+		if err != nil {
+			fmt.Printf("Error creating adapter: %v\n", err)
+			os.Exit(1)
+		}
+		if !planLocksSupported(a) {
+			fmt.Printf("Error: %v\n", fmt.Errorf("%w: locks-plan models PostgreSQL lock levels and conflicts", adapters.ErrUnsupported))
+			os.Exit(1)
+		}
+
+		e := engine.NewEngine(g, a)
+		defer a.Close()
+
+		if err := e.Connect(dbUrl); err != nil {
+			fmt.Printf("Error connecting to database: %v\n", err)
+			os.Exit(1)
+		}
+
+		if err := e.BuildGraph(); err != nil {
+			fmt.Printf("Error building graph: %v\n", err)
+			os.Exit(1)
+		}
+
+		result := &report.LocksPlan{Database: redactConnString(dbUrl), Statements: []report.LockPlanStatement{}}
+
+		// Live sessions and the workload are only matched against the lock levels on a live
+		// PostgreSQL connection; offline the plan comes from the schema alone
+		var sessions []graph.LockSession
+		var queries []graph.QueryStats
+		if inspector, ok := a.(adapters.LockInspector); ok {
+			if sessions, err = inspector.GetLockSessions(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to fetch live locks: %v\n", err)
+			} else {
+				result.SessionsChecked = true
+			}
+			queries, err = a.GetTopQueries(100, "calls")
+			if err == nil {
+				result.WorkloadChecked = true
+			} else if !errors.Is(err, adapters.ErrUnsupported) {
+				fmt.Fprintf(os.Stderr, "Warning: failed to fetch frequent queries: %v\n", err)
+			}
+		}
+
+		for _, plan := range plans {
+			stmt := planStatementLocks(g, plan)
+			stmt.Running = runningConflicts(stmt.Locks, sessions)
+			stmt.Queued = queuedQueries(g, stmt.Locks, queries)
+			result.Statements = append(result.Statements, stmt)
+		}
+		render(result, printLocksPlan)
+	},
+}
+
+// planLocksSupported reports whether the adapter's database uses PostgreSQL lock levels: MySQL and
+// SQLite lock differently, and their unqualified names do not resolve to "public"
+func planLocksSupported(adapter adapters.Adapter) bool {
+	switch adapter.(type) {
+	case *adapters.MySQLAdapter, *adapters.SQLiteAdapter:
+		return false
+	}
+	return true
+}
+
+// planStatementLocks expands the locks of a statement through the graph, then lists the relations
+// whose queries would queue behind them
+func planStatementLocks(g *graph.Graph, plan sqlparse.StatementLocks) report.LockPlanStatement {
+	stmt := report.LockPlanStatement{
+		Statement: plan.Statement, Line: plan.Line, Locks: []report.PlannedLock{}, Affected: []report.AffectedRelation{},
+		Running: []report.LockConflict{}, Queued: []report.QueuedQuery{}, Notes: plan.Notes,
+	}
+	if stmt.Notes == nil {
+		stmt.Notes = []string{}
+	}
+
+	add := func(relation string, mode sqlparse.LockMode, reason, via string) {
+		for i := range stmt.Locks {
+			l := &stmt.Locks[i]
+			if l.Relation == relation {
+				if mode.Stronger(sqlparse.LockMode(l.Mode)) {
+					l.Mode, l.Reason, l.Via = string(mode), reason, via
+				}
+				return
+			}
+		}
+		stmt.Locks = append(stmt.Locks, report.PlannedLock{Relation: relation, Mode: string(mode), Reason: reason, Via: via})
+	}
+
+	for _, req := range plan.Locks {
+		id := req.Relation.String()
+		add(id, req.Mode, req.Reason, "")
+		node := g.Nodes[id]
+		if node == nil {
+			continue
+		}
+
+		if req.Index && node.Index != nil && req.TableMode != "" {
+			add(node.Index.Table, req.TableMode, req.Reason, id)
+		}
+		if node.Type != graph.Table {
+			continue
+		}
+		if !req.Only {
+			for _, part := range g.GetDependents(id, graph.Inheritance) {
+				add(part, req.Mode, "partition: the statement recurses", id)
+			}
+		}
+
+		for _, e := range g.Edges[id] {
+			if e.Type != graph.ForeignKey {
+				continue
+			}
+			// Dropping a foreign key drops its RI triggers on the referenced table, which takes
+			// ACCESS EXCLUSIVE there: only adding one gets by with SHARE ROW EXCLUSIVE
+			switch {
+			case req.Reason == "DROP":
+				add(e.TargetID, sqlparse.AccessExclusive, fmt.Sprintf("FK %s is dropped with the table (its triggers on the parent too)", e.ConstraintName), id)
+			case req.Constraint == e.ConstraintName && req.Reason == "DROP CONSTRAINT":
+				add(e.TargetID, sqlparse.AccessExclusive, fmt.Sprintf("FK %s is dropped (its triggers on the parent too)", e.ConstraintName), id)
+			case req.Constraint == e.ConstraintName && req.Reason == "VALIDATE CONSTRAINT":
+				add(e.TargetID, sqlparse.RowShare, fmt.Sprintf("FK %s is validated", e.ConstraintName), id)
+			}
+		}
+		// DROP ... CASCADE drops the foreign keys of the referencing tables; TRUNCATE ... CASCADE
+		// empties them, and the tables referencing those in turn
+		switch {
+		case plan.Cascade && req.Reason == "DROP":
+			for _, child := range referencingTables(g, id) {
+				add(child, sqlparse.AccessExclusive, "its FK is dropped by DROP CASCADE", id)
+			}
+		case plan.Cascade && req.Reason == "TRUNCATE":
+			for _, child := range g.GetDependents(id, graph.ForeignKey) {
+				add(child, sqlparse.AccessExclusive, "emptied by TRUNCATE CASCADE", id)
+			}
+		}
+	}
+
+	// Readers of a view wait for ACCESS EXCLUSIVE on its tables; writers of an FK child check the
+	// parent with ROW SHARE, which EXCLUSIVE and ACCESS EXCLUSIVE block
+	locked := make(map[string]bool)
+	for _, l := range stmt.Locks {
+		locked[l.Relation] = true
+	}
+	seen := make(map[string]bool)
+	for _, l := range stmt.Locks {
+		mode := sqlparse.LockMode(l.Mode)
+		if mode.Conflicts(sqlparse.AccessShare) {
+			for _, view := range g.GetDependents(l.Relation, graph.ViewDepends) {
+				if !locked[view] && !seen[view] {
+					seen[view] = true
+					stmt.Affected = append(stmt.Affected, report.AffectedRelation{Relation: view, Via: l.Relation, Blocked: "reads and writes"})
+				}
+			}
+		}
+		if mode.Conflicts(sqlparse.RowShare) {
+			for _, child := range referencingTables(g, l.Relation) {
+				if !locked[child] && !seen[child] {
+					seen[child] = true
+					stmt.Affected = append(stmt.Affected, report.AffectedRelation{Relation: child, Via: l.Relation, Blocked: "writes"})
+				}
+			}
+		}
+	}
+	return stmt
+}
+
+// referencingTables returns the tables with a foreign key to the given one, sorted
+func referencingTables(g *graph.Graph, id string) []string {
+	var children []string
+	for src, edges := range g.Edges {
+		for _, e := range edges {
+			if e.Type == graph.ForeignKey && e.TargetID == id && src != id {
+				children = append(children, src)
+				break
+			}
+		}
+	}
+	sort.Strings(children)
+	return children
+}
+
+// runningConflicts returns the sessions holding or awaiting locks that conflict with the planned ones
+func runningConflicts(locks []report.PlannedLock, sessions []graph.LockSession) []report.LockConflict {
+	conflicts := []report.LockConflict{}
+	for _, s := range sessions {
+		for _, held := range s.Locks {
+			conflict := false
+			for _, l := range locks {
+				conflict = conflict || (l.Relation == held.Relation && sqlparse.LockMode(l.Mode).Conflicts(sqlparse.LockMode(held.Mode)))
+			}
+			if conflict {
+				conflicts = append(conflicts, report.LockConflict{
+					PID: s.PID, State: s.State, Query: s.Query, DurationMs: s.DurationMs,
+					Relation: held.Relation, Mode: held.Mode, Granted: held.Granted,
+				})
+				break
+			}
+		}
+	}
+	sort.SliceStable(conflicts, func(i, j int) bool { return conflicts[i].DurationMs > conflicts[j].DurationMs })
+	return conflicts
+}
+
+// queuedQueries returns the frequent queries taking a lock that conflicts with a planned one. Views
+// are resolved to their tables, and writes to an FK child also lock the parent (ROW SHARE).
+func queuedQueries(g *graph.Graph, locks []report.PlannedLock, queries []graph.QueryStats) []report.QueuedQuery {
+	planned := make(map[string]sqlparse.LockMode)
+	for _, l := range locks {
+		planned[l.Relation] = sqlparse.LockMode(l.Mode)
+	}
+
+	queued := []report.QueuedQuery{}
+	for _, q := range queries {
+		taken := make(map[string]sqlparse.LockMode)
+		take := func(relation string, mode sqlparse.LockMode) {
+			if existing, ok := taken[relation]; !ok || mode.Stronger(existing) {
+				taken[relation] = mode
+			}
+		}
+		for name, mode := range sqlparse.QueryLocks(sqlparse.Tokenize(q.Query), "public") {
+			id := name.String()
+			take(id, mode)
+			if n, ok := g.Nodes[id]; ok && n.Type == graph.View {
+				for _, base := range g.GetUpstream(id, 0, graph.ViewDepends) {
+					take(base, sqlparse.AccessShare)
+				}
+			}
+			if mode == sqlparse.RowExclusive {
+				for _, e := range g.Edges[id] {
+					if e.Type == graph.ForeignKey {
+						take(e.TargetID, sqlparse.RowShare)
+					}
+				}
+			}
+		}
+
+		relations := make([]string, 0, len(taken))
+		for relation := range taken {
+			relations = append(relations, relation)
+		}
+		sort.Strings(relations)
+		for _, relation := range relations {
+			if mode, ok := planned[relation]; ok && mode.Conflicts(taken[relation]) {
+				queued = append(queued, report.QueuedQuery{
+					QueryID: q.QueryID, Query: q.Query, Calls: q.Calls, AvgTime: q.AvgTime,
+					Relation: relation, Mode: string(taken[relation]),
+				})
+				break
+			}
+		}
+	}
+	return queued
+}
+
+// printLocksPlan renders the lock analysis of each planned statement
+func printLocksPlan(w io.Writer, v any) error {
+	result := v.(*report.LocksPlan)
+
+	fmt.Fprintf(w, "🔒 LOCKS PLAN: %s | Statements: %d\n", result.Database, len(result.Statements))
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+
+	for i, stmt := range result.Statements {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "📝 [line %d] %s\n", stmt.Line, sqlparse.OneLine(stmt.Statement, 100))
+		if len(stmt.Locks) == 0 {
+			fmt.Fprintln(w, "   No table locks to plan for")
+			continue
+		}
+		for _, l := range stmt.Locks {
+			icon := "🟡"
+			if sqlparse.LockMode(l.Mode).Conflicts(sqlparse.AccessShare) {
+				icon = "🔴" // Blocks reads too
+			} else if !sqlparse.LockMode(l.Mode).Conflicts(sqlparse.RowExclusive) {
+				icon = "🟢" // Reads and writes go on
+			}
+			via := ""
+			if l.Via != "" {
+				via = fmt.Sprintf(" (via %s)", l.Via)
+			}
+			fmt.Fprintf(w, "   %s %s %s: %s%s\n", icon, l.Mode, l.Relation, l.Reason, via)
+		}
+		for _, a := range stmt.Affected {
+			fmt.Fprintf(w, "   ⏳ %s: %s queue behind the lock on %s\n", a.Relation, a.Blocked, a.Via)
+		}
+		for _, n := range stmt.Notes {
+			fmt.Fprintf(w, "   ℹ️  %s\n", n)
+		}
+
+		if result.SessionsChecked {
+			if len(stmt.Running) == 0 {
+				fmt.Fprintln(w, "   ✅ No running session holds a conflicting lock")
+			} else {
+				fmt.Fprintf(w, "   🚧 WAITS FOR %d running sessions:\n", len(stmt.Running))
+				for _, c := range stmt.Running {
+					state := "holds"
+					if !c.Granted {
+						state = "waits for"
+					}
+					fmt.Fprintf(w, "      - pid %d (%s, %.1fs) %s %s on %s: %s\n", c.PID, c.State, c.DurationMs/1000, state, c.Mode,
+						c.Relation, sqlparse.OneLine(c.Query, 60))
+				}
+			}
+		}
+		if result.WorkloadChecked && len(stmt.Queued) > 0 {
+			fmt.Fprintf(w, "   🚦 QUEUES %d frequent queries:\n", len(stmt.Queued))
+			for _, q := range stmt.Queued {
+				fmt.Fprintf(w, "      - %d calls, avg %.2fms, %s on %s: %s\n", q.Calls, q.AvgTime, q.Mode, q.Relation, sqlparse.OneLine(q.Query, 60))
+			}
+		}
+	}
+
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+	for _, stmt := range result.Statements {
+		exclusive := false
+		for _, l := range stmt.Locks {
+			exclusive = exclusive || l.Mode == string(sqlparse.AccessExclusive)
+		}
+		if exclusive {
+			fmt.Fprintln(w, "💡 Run ACCESS EXCLUSIVE statements with SET lock_timeout = '3s' and retry: a waiting lock queues every query behind it")
+			break
+		}
+	}
+	if !result.SessionsChecked {
+		fmt.Fprintln(w, "ℹ️  Live locks and workload are only checked on PostgreSQL connections")
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(locksPlanCmd)
+	locksPlanCmd.Flags().String("sql", "", "Statement(s) to analyze, e.g. \"ALTER TABLE orders ADD COLUMN note text\"")
+}
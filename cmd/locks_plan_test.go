@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/alexanderritik/dbgraph/internal/graph"
+	"github.com/alexanderritik/dbgraph/internal/sqlparse"
+)
+
+func TestPlanStatementLocksDroppedFKLocksParent(t *testing.T) {
+	g := graph.NewGraph()
+	g.AddNode("public", "users", graph.Table, "", 0)
+	g.AddNode("public", "orders", graph.Table, "", 0)
+	g.AddEdge("public", "orders", "public", "users", graph.ForeignKey, "fk_user", "NO ACTION")
+
+	tests := []struct {
+		sql  string
+		want sqlparse.LockMode
+	}{
+		{"DROP TABLE orders", sqlparse.AccessExclusive},
+		{"ALTER TABLE orders DROP CONSTRAINT fk_user", sqlparse.AccessExclusive},
+		{"ALTER TABLE orders ADD CONSTRAINT fk_user2 FOREIGN KEY (user_id) REFERENCES users (id)", sqlparse.ShareRowExclusive},
+	}
+	for _, tt := range tests {
+		plans := sqlparse.PlanLocks(tt.sql, "public")
+		if len(plans) != 1 {
+			t.Fatalf("%s: expected 1 statement, got %d", tt.sql, len(plans))
+		}
+		stmt := planStatementLocks(g, plans[0])
+		var mode string
+		for _, l := range stmt.Locks {
+			if l.Relation == "public.users" {
+				mode = l.Mode
+			}
+		}
+		if mode != string(tt.want) {
+			t.Errorf("%s: expected %s on public.users, got %q (%+v)", tt.sql, tt.want, mode, stmt.Locks)
+		}
+	}
+}
@@ -1,8 +1,18 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"strings"
+
+	"github.com/alexanderritik/dbgraph/internal/adapters"
+	"github.com/alexanderritik/dbgraph/internal/config"
+	"github.com/alexanderritik/dbgraph/internal/engine"
+	"github.com/alexanderritik/dbgraph/internal/graph"
+	"github.com/alexanderritik/dbgraph/internal/lint"
+	"github.com/alexanderritik/dbgraph/internal/report"
 
 	"github.com/spf13/cobra"
 )
@@ -11,6 +21,8 @@ var rootCmd = &cobra.Command{
 	Use:   "dbgraph",
 	Short: "A graph-based database CLI",
 	Long:  `dbgraph is a CLI tool for managing and querying graph data with pluggable database adapters.`,
+	// Reject a bad --output before connecting to anything
+	PersistentPreRun: func(cmd *cobra.Command, args []string) { output() },
 	// Run: func(cmd *cobra.Command, args []string) { }, // output help by default
 }
 
@@ -23,14 +35,72 @@ func Execute(version string) {
 	}
 }
 
-var dbUrl string
+var (
+	dbUrl        string
+	fromSnapshot string
+	outputFormat string
+	configPath   string
+)
 
 func init() {
 	rootCmd.PersistentFlags().StringVar(&dbUrl, "db", "", "Database connection string (or env DBGRAPH_DB_URL)")
+	rootCmd.PersistentFlags().StringVar(&fromSnapshot, "from-snapshot", "", "Answer from a snapshot file instead of a live database (see 'snapshot save')")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "text", "Output format: text, json or yaml")
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", config.DefaultPath, "Rule set and thresholds file (optional when left at the default)")
+}
+
+// output returns the validated --output format
+func output() report.Format {
+	format, err := report.ParseFormat(outputFormat)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	return format
+}
+
+// render writes a command result to stdout in the --output format; text uses the command's printer
+func render(result any, text report.TextRenderer) {
+	if err := report.New(output(), text).Render(os.Stdout, result); err != nil {
+		fmt.Printf("Error rendering output: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// loadConfig reads --config; a missing .dbgraph.yaml at the default path means defaults
+func loadConfig() *config.Config {
+	cfg, err := config.Load(configPath)
+	if errors.Is(err, fs.ErrNotExist) && !rootCmd.PersistentFlags().Changed("config") {
+		return config.Default()
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	return cfg
+}
+
+// lintRules returns the rules enabled by the config with its overrides applied
+func lintRules(cfg *config.Config) []lint.Rule {
+	rules, err := cfg.LintRules()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	return rules
 }
 
 // ensureDBConnection checks if dbUrl is set, otherwise tries to read from env
 func ensureDBConnection() {
+	if fromSnapshot != "" {
+		if dbUrl != "" {
+			fmt.Println("Error: --db and --from-snapshot are mutually exclusive")
+			os.Exit(1)
+		}
+		dbUrl = "snapshot://" + fromSnapshot
+		return
+	}
+
 	if dbUrl == "" {
 		dbUrl = os.Getenv("DBGRAPH_DB_URL")
 	}
@@ -40,3 +110,37 @@ func ensureDBConnection() {
 		os.Exit(1)
 	}
 }
+
+// resolveConnString lets bare paths stand in for offline sources:
+// *.json is read as a snapshot and *.sql as a schema file
+func resolveConnString(s string) string {
+	if strings.Contains(s, "://") {
+		return s
+	}
+	switch {
+	case strings.HasSuffix(s, ".json"):
+		return "snapshot://" + s
+	case strings.HasSuffix(s, ".sql"):
+		return "file://" + s
+	}
+	return s
+}
+
+// loadGraph connects with the adapter matching connString and builds its graph
+func loadGraph(connString string) (*graph.Graph, error) {
+	a, err := adapters.NewAdapter(connString)
+	if err != nil {
+		return nil, fmt.Errorf("error creating adapter: %w", err)
+	}
+	defer a.Close()
+
+	g := graph.NewGraph()
+	e := engine.NewEngine(g, a)
+	if err := e.Connect(connString); err != nil {
+		return nil, fmt.Errorf("error connecting to database: %w", err)
+	}
+	if err := e.BuildGraph(); err != nil {
+		return nil, fmt.Errorf("error building graph: %w", err)
+	}
+	return g, nil
+}
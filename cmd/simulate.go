@@ -2,11 +2,15 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/alexanderritik/dbgraph/internal/adapters"
 	"github.com/alexanderritik/dbgraph/internal/graph"
+	"github.com/alexanderritik/dbgraph/internal/report"
+	"github.com/alexanderritik/dbgraph/internal/sqlparse"
 	"github.com/spf13/cobra"
 )
 
@@ -14,19 +18,83 @@ import (
 var simulateCmd = &cobra.Command{
 	Use:   "simulate",
 	Short: "Simulate schema changes and predict impact",
-	Long:  `Simulates schema changes (like dropping a column) and reports impacted database objects using strict dependency analysis and code scanning.`,
+	Long: `Simulates schema changes (drops, renames, type changes, SET NOT NULL, DROP TYPE, DROP INDEX) and reports impacted database objects
+using strict dependency analysis and code scanning. Each dependency is classified as breaking, auto-updated
+by the database (e.g. views survive renames), rebuilt by a table rewrite, or dropped along with the target.
+With --drop-type, the columns, composite types, domains and functions using an enum, composite type or domain
+are reported. With --drop-index, the constraint the index enforces, the foreign keys referencing its key (which
+make the DROP fail) and the foreign keys left without a supporting index are reported.
+With --migration, every DROP TABLE/VIEW/FUNCTION/TYPE/DOMAIN/INDEX, DROP COLUMN, ALTER COLUMN TYPE, SET NOT NULL and RENAME
+statement of a migration file is analyzed and the command exits non-zero when any of them would break a dependent object.
+With --execute-dry-run (PostgreSQL), the DDL is also executed inside a transaction that is always rolled back,
+reporting the real errors and the objects a CASCADE would drop next to the catalog-based verdict.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		ensureDBConnection()
 
 		dropCol, _ := cmd.Flags().GetString("drop-column")
 		dropTbl, _ := cmd.Flags().GetString("drop-table")
+		dropType, _ := cmd.Flags().GetString("drop-type")
+		dropIndex, _ := cmd.Flags().GetString("drop-index")
+		renameCol, _ := cmd.Flags().GetString("rename-column")
+		renameTbl, _ := cmd.Flags().GetString("rename-table")
+		alterType, _ := cmd.Flags().GetString("alter-type")
+		setNotNull, _ := cmd.Flags().GetString("set-not-null")
+		migration, _ := cmd.Flags().GetString("migration")
+		dryRun, _ := cmd.Flags().GetBool("execute-dry-run")
 
-		if dropCol == "" && dropTbl == "" {
-			fmt.Println("Error: Either --drop-column or --drop-table flag is required")
+		set := 0
+		for _, f := range []string{dropCol, dropTbl, dropType, dropIndex, renameCol, renameTbl, alterType, setNotNull, migration} {
+			if f != "" {
+				set++
+			}
+		}
+		if set == 0 {
+			fmt.Println("Error: One of --drop-column, --drop-table, --drop-type, --drop-index, --rename-column, --rename-table, --alter-type, --set-not-null or --migration is required")
 			os.Exit(1)
 		}
-		if dropCol != "" && dropTbl != "" {
-			fmt.Println("Error: --drop-column and --drop-table are mutually exclusive")
+		if set > 1 {
+			fmt.Println("Error: only one change can be simulated at a time (use --migration for several)")
+			os.Exit(1)
+		}
+
+		var change sqlparse.Change
+		var err error
+		switch {
+		case dropCol != "":
+			change, err = columnChange(sqlparse.DropColumn, dropCol, "")
+		case dropTbl != "":
+			change, err = tableChange(sqlparse.DropTable, dropTbl, "")
+		case dropType != "":
+			change, err = tableChange(sqlparse.DropType, dropType, "")
+		case dropIndex != "":
+			change, err = tableChange(sqlparse.DropIndex, dropIndex, "")
+		case renameCol != "":
+			ref, newName, ok := strings.Cut(renameCol, "=")
+			if !ok || newName == "" {
+				err = fmt.Errorf("invalid format for --rename-column. Use 'table.column=new_name'")
+				break
+			}
+			change, err = columnChange(sqlparse.RenameColumn, ref, "")
+			change.NewName = newName
+		case renameTbl != "":
+			ref, newName, ok := strings.Cut(renameTbl, "=")
+			if !ok || newName == "" {
+				err = fmt.Errorf("invalid format for --rename-table. Use 'table=new_name'")
+				break
+			}
+			change, err = tableChange(sqlparse.RenameTable, ref, newName)
+		case alterType != "":
+			ref, newType, ok := strings.Cut(alterType, "=")
+			if !ok || newType == "" {
+				err = fmt.Errorf("invalid format for --alter-type. Use 'table.column=new_type'")
+				break
+			}
+			change, err = columnChange(sqlparse.AlterColumnType, ref, newType)
+		case setNotNull != "":
+			change, err = columnChange(sqlparse.SetNotNull, setNotNull, "")
+		}
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
 			os.Exit(1)
 		}
 
@@ -43,87 +111,569 @@ var simulateCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		var deps []graph.ColumnDependency
-		var schema, targetLabel string
-
-		if dropCol != "" {
-			// Parse table.column or schema.table.column
-			parts := strings.Split(dropCol, ".")
-			var table, column string
-			if len(parts) == 3 {
-				schema = parts[0]
-				table = parts[1]
-				column = parts[2]
-			} else if len(parts) == 2 {
-				schema = "public" // Default
-				table = parts[0]
-				column = parts[1]
-			} else {
-				fmt.Println("Error: Invalid format for --drop-column. Use 'table.column' or 'schema.table.column'")
+		var runner adapters.DryRunner
+		if dryRun {
+			var ok bool
+			if runner, ok = adapter.(adapters.DryRunner); !ok {
+				fmt.Printf("Error: --execute-dry-run: %v\n", fmt.Errorf("%w: DDL cannot be executed in a rolled-back transaction", adapters.ErrUnsupported))
 				os.Exit(1)
 			}
-			targetLabel = fmt.Sprintf("%s.%s.%s", schema, table, column)
-			fmt.Printf("🧪 Simulating DROP COLUMN on %s...\n", targetLabel)
-
-			deps, err = adapter.GetColumnDependencies(schema, table, column)
-		} else {
-			// DROP TABLE
-			parts := strings.Split(dropTbl, ".")
-			var table string
-			if len(parts) == 2 {
-				schema = parts[0]
-				table = parts[1]
-			} else if len(parts) == 1 {
-				schema = "public"
-				table = parts[0]
-			} else {
-				fmt.Println("Error: Invalid format for --drop-table. Use 'table' or 'schema.table'")
+		}
+
+		if migration != "" {
+			if !simulateMigration(adapter, migration, runner) {
 				os.Exit(1)
 			}
-			targetLabel = fmt.Sprintf("%s.%s", schema, table)
-			fmt.Printf("🧪 Simulating DROP TABLE on %s...\n", targetLabel)
-
-			deps, err = adapter.GetTableDependencies(schema, table)
+			return
 		}
 
+		renamesFollowed := followsRenames(adapter)
+		deps, err := analyzeChange(adapter, change)
 		if err != nil {
 			fmt.Printf("Error analyzing dependencies: %v\n", err)
 			os.Exit(1)
 		}
+		sim := newSimulation(change, deps, renamesFollowed)
+
+		if runner != nil {
+			results, err := runner.DryRun([]string{change.SQL()})
+			if err != nil {
+				fmt.Printf("Error during dry run: %v\n", err)
+				os.Exit(1)
+			}
+			compareDryRun(&sim, change, deps, results[0], renamesFollowed)
+		}
 
 		// Print Report
-		printSafetyVerdict(targetLabel, deps)
+		render(&sim, printSimulation)
 	},
 }
 
-func printSafetyVerdict(target string, deps []graph.ColumnDependency) {
-	if len(deps) == 0 {
-		fmt.Printf("\n%s\n└── (Safe to drop - No dependencies found) ✅\n\n", target)
-		return
+// columnChange builds a column change from 'table.column' or 'schema.table.column'
+func columnChange(kind sqlparse.ChangeKind, ref, newType string) (sqlparse.Change, error) {
+	parts := strings.Split(ref, ".")
+	c := sqlparse.Change{Kind: kind, NewType: newType}
+	switch len(parts) {
+	case 3:
+		c.Target = sqlparse.QualifiedName{Schema: parts[0], Name: parts[1]}
+		c.Column = parts[2]
+	case 2:
+		c.Target = sqlparse.QualifiedName{Schema: "public", Name: parts[0]} // Default
+		c.Column = parts[1]
+	default:
+		return c, fmt.Errorf("invalid column '%s'. Use 'table.column' or 'schema.table.column'", ref)
+	}
+	return c, nil
+}
+
+// tableChange builds a table change from 'table' or 'schema.table'
+func tableChange(kind sqlparse.ChangeKind, ref, newName string) (sqlparse.Change, error) {
+	parts := strings.Split(ref, ".")
+	c := sqlparse.Change{Kind: kind, NewName: newName}
+	switch len(parts) {
+	case 2:
+		c.Target = sqlparse.QualifiedName{Schema: parts[0], Name: parts[1]}
+	case 1:
+		c.Target = sqlparse.QualifiedName{Schema: "public", Name: parts[0]}
+	default:
+		return c, fmt.Errorf("invalid table '%s'. Use 'table' or 'schema.table'", ref)
+	}
+	return c, nil
+}
+
+// defaultSchema returns the schema unqualified names resolve to on the adapter's database
+func defaultSchema(adapter adapters.Adapter) string {
+	if p, ok := adapter.(adapters.DefaultSchemaProvider); ok {
+		if schema := p.DefaultSchema(); schema != "" {
+			return schema
+		}
+	}
+	return "public"
+}
+
+// followsRenames reports whether the database re-points dependent objects on rename.
+// Postgres and SQLite store views/constraints by object identity; MySQL stores view text.
+func followsRenames(adapter adapters.Adapter) bool {
+	_, isMySQL := adapter.(*adapters.MySQLAdapter)
+	return !isMySQL
+}
+
+// dependencyImpact is what a change does to one dependent object
+type dependencyImpact string
+
+const (
+	impactBreaks      dependencyImpact = "BREAKS"
+	impactRewrite     dependencyImpact = "REWRITE"
+	impactAutoUpdated dependencyImpact = "AUTO-UPDATED"
+	impactDropped     dependencyImpact = "DROPPED"
+	impactReview      dependencyImpact = "REVIEW"
+)
+
+// isCodeReference reports whether the dependency was found by scanning source text
+func isCodeReference(dep graph.ColumnDependency) bool {
+	return strings.Contains(dep.Detail, "Code Reference")
+}
+
+// isDynamicReference reports whether a code reference was only found in SQL built at run time
+func isDynamicReference(dep graph.ColumnDependency) bool {
+	return strings.Contains(dep.Detail, "confidence: "+string(sqlparse.ConfidenceUnknown))
+}
+
+// classifyDependency decides how a dependent object is affected by a change, with a short reason
+func classifyDependency(kind sqlparse.ChangeKind, dep graph.ColumnDependency, renamesFollowed bool) (dependencyImpact, string) {
+	if isDynamicReference(dep) {
+		return impactReview, "mentioned in dynamic SQL; verify by hand"
+	}
+	switch kind {
+	case sqlparse.RenameColumn, sqlparse.RenameTable:
+		if isCodeReference(dep) {
+			return impactBreaks, "source text still uses the old name"
+		}
+		if !renamesFollowed && (dep.Type == "VIEW" || dep.Type == "TRIGGER") {
+			return impactBreaks, "definition is stored as text and keeps the old name"
+		}
+		return impactAutoUpdated, "follows the rename"
+
+	case sqlparse.AlterColumnType:
+		switch dep.Type {
+		case "INDEX":
+			return impactRewrite, "rebuilt with the table"
+		case "VIEW", "RELATION":
+			return impactBreaks, "columns used by views cannot change type"
+		case "FOREIGN_KEY":
+			return impactBreaks, "referencing column keeps the old type"
+		}
+		return impactBreaks, "cached plans and casts may fail"
+
+	case sqlparse.SetNotNull:
+		if isCodeReference(dep) || dep.Type == "TRIGGER" {
+			return impactReview, "check it never writes NULL"
+		}
+		return impactAutoUpdated, "unaffected"
+
+	case sqlparse.DropColumn, sqlparse.DropTable:
+		if dep.Type == "INDEX" || (dep.Type == "TRIGGER" && dep.Detail == "Trigger on table") {
+			return impactDropped, "removed together with it"
+		}
+
+	case sqlparse.DropType:
+		switch graph.NodeType(dep.Type) {
+		case graph.Table:
+			return impactBreaks, "CASCADE would drop these columns and their data"
+		case graph.CompositeType:
+			return impactBreaks, "CASCADE would drop these attributes"
+		case graph.Domain:
+			return impactBreaks, "CASCADE would drop the domain and the columns using it"
+		}
+		return impactBreaks, "CASCADE would drop it"
+
+	case sqlparse.DropIndex:
+		switch {
+		case dep.Type == "CONSTRAINT":
+			return impactBreaks, "DROP INDEX fails; drop the constraint instead"
+		case adapters.IsCoveringIndexDependency(dep):
+			return impactReview, "deletes and key updates on the referenced table will scan this table"
+		}
+		return impactBreaks, "the foreign key needs a unique index on the key it references"
 	}
+	return impactBreaks, ""
+}
+
+// changeNote describes the cost of the change itself, independent of dependencies
+func changeNote(kind sqlparse.ChangeKind) string {
+	switch kind {
+	case sqlparse.AlterColumnType:
+		return "🐢 Forces a full table rewrite under ACCESS EXCLUSIVE lock unless the new type is binary-coercible (e.g. varchar → text)."
+	case sqlparse.SetNotNull:
+		return "🐢 Scans the whole table under ACCESS EXCLUSIVE lock; on large tables add a CHECK (col IS NOT NULL) NOT VALID constraint and VALIDATE it first."
+	}
+	return ""
+}
+
+// reportChange converts a parsed change to its output form
+func reportChange(c sqlparse.Change) report.Change {
+	return report.Change{
+		Kind:      string(c.Kind),
+		Target:    c.Target.String(),
+		Column:    c.Column,
+		NewName:   c.NewName,
+		NewType:   c.NewType,
+		Cascade:   c.Cascade,
+		Statement: c.Statement,
+		Line:      c.Line,
+	}
+}
 
-	fmt.Printf("\n%s\n", target)
+// newSimulation classifies the dependencies of a change
+func newSimulation(change sqlparse.Change, deps []graph.ColumnDependency, renamesFollowed bool) report.Simulation {
+	sim := report.Simulation{
+		Change:       reportChange(change),
+		Label:        changeTarget(change),
+		Dependencies: []report.DependencyVerdict{},
+		Note:         changeNote(change.Kind),
+	}
 	for _, dep := range deps {
+		impact, reason := classifyDependency(change.Kind, dep, renamesFollowed)
+		sim.Breaks = sim.Breaks || impact == impactBreaks
+		sim.Dependencies = append(sim.Dependencies, report.DependencyVerdict{ColumnDependency: dep, Impact: string(impact), Reason: reason})
+	}
+	return sim
+}
+
+// printSimulation renders a single-change simulation
+func printSimulation(w io.Writer, v any) error {
+	sim := v.(*report.Simulation)
+	fmt.Fprintf(w, "🧪 Simulating %s on %s...\n", sim.Change.Kind, sim.Label)
+	printSafetyVerdict(w, sim)
+	if sim.DryRun != nil {
+		printDryRun(w, *sim.DryRun)
+		printDryRunComparison(w, sim)
+	}
+	return nil
+}
+
+// printSafetyVerdict prints the classified dependencies of a simulation
+func printSafetyVerdict(w io.Writer, sim *report.Simulation) {
+	target := fmt.Sprintf("%s (%s)", sim.Label, sim.Change.Kind)
+
+	if len(sim.Dependencies) == 0 {
+		fmt.Fprintf(w, "\n%s\n└── (Safe - No dependencies found) ✅\n", target)
+		if sim.Note != "" {
+			fmt.Fprintln(w, sim.Note)
+		}
+		fmt.Fprintln(w)
+		return
+	}
+
+	icons := map[dependencyImpact]string{
+		impactBreaks:      "❌",
+		impactRewrite:     "🔁",
+		impactAutoUpdated: "🔄",
+		impactDropped:     "🗑️ ",
+		impactReview:      "⚠️ ",
+	}
+
+	fmt.Fprintf(w, "\n%s\n", target)
+	for _, verdict := range sim.Dependencies {
+		dep := verdict.ColumnDependency
 		desc := dep.Detail
 		if dep.Type == "VIEW" && strings.Contains(desc, "Deep Dependency") {
 			desc = "View Dependency"
-		} else if dep.Type == "FUNCTION" && strings.Contains(desc, "Regex") {
+		} else if dep.Type == "FUNCTION" && isCodeReference(dep) {
 			desc = "Used in Function Body"
+			if i := strings.Index(dep.Detail, "confidence: "); i >= 0 && !strings.Contains(dep.Detail, "confidence: high") {
+				desc += ", " + strings.TrimSuffix(dep.Detail[i:], ")")
+			}
+		} else if adapters.IsCoveringIndexDependency(dep) {
+			desc = dep.Detail
 		} else if dep.Type == "FOREIGN_KEY" {
 			desc = "Foreign Key Constraint"
 		} else if dep.Type == "INDEX" {
 			desc = "Used in Index"
 		} else if dep.Type == "RELATION" {
 			desc = "Dependent Object"
+		} else if sim.Change.Kind == string(sqlparse.DropType) && dep.Type == "TABLE" {
+			desc = "Table " + dep.Detail
+		}
+
+		reason := verdict.Reason
+		if reason != "" {
+			reason = ": " + reason
+		}
+		fmt.Fprintf(w, "└── %s %s (%s) [%s%s]\n", icons[dependencyImpact(verdict.Impact)], dep.Name, desc, verdict.Impact, reason)
+	}
+	if sim.Note != "" {
+		fmt.Fprintln(w, sim.Note)
+	}
+	fmt.Fprintln(w)
+}
+
+// analyzeChange runs the dependency analysis matching a migration change
+func analyzeChange(adapter adapters.Adapter, c sqlparse.Change) ([]graph.ColumnDependency, error) {
+	switch c.Kind {
+	case sqlparse.DropColumn, sqlparse.AlterColumnType, sqlparse.RenameColumn, sqlparse.SetNotNull:
+		return adapter.GetColumnDependencies(c.Target.Schema, c.Target.Name, c.Column)
+	case sqlparse.DropFunction:
+		f, ok := adapter.(adapters.FunctionDependencyFetcher)
+		if !ok {
+			return nil, fmt.Errorf("%w: function dependencies cannot be analyzed", adapters.ErrUnsupported)
+		}
+		return f.GetFunctionDependencies(c.Target.Schema, c.Target.Name)
+	case sqlparse.DropType:
+		f, ok := adapter.(adapters.TypeDependencyFetcher)
+		if !ok {
+			return nil, fmt.Errorf("%w: this database has no user-defined types", adapters.ErrUnsupported)
 		}
+		return f.GetTypeDependencies(c.Target.Schema, c.Target.Name)
+	case sqlparse.DropIndex:
+		f, ok := adapter.(adapters.IndexDependencyFetcher)
+		if !ok {
+			return nil, fmt.Errorf("%w: index dependencies cannot be analyzed", adapters.ErrUnsupported)
+		}
+		return f.GetIndexDependencies(c.Target.Schema, c.Target.Name)
+	default:
+		// DROP TABLE, DROP VIEW, RENAME TABLE
+		return adapter.GetTableDependencies(c.Target.Schema, c.Target.Name)
+	}
+}
+
+// changeTarget labels the object a change applies to
+func changeTarget(c sqlparse.Change) string {
+	switch c.Kind {
+	case sqlparse.DropColumn, sqlparse.SetNotNull:
+		return fmt.Sprintf("%s.%s", c.Target, c.Column)
+	case sqlparse.AlterColumnType:
+		return fmt.Sprintf("%s.%s → %s", c.Target, c.Column, c.NewType)
+	case sqlparse.RenameColumn:
+		return fmt.Sprintf("%s.%s → %s", c.Target, c.Column, c.NewName)
+	case sqlparse.RenameTable:
+		return fmt.Sprintf("%s → %s", c.Target, c.NewName)
+	}
+	return c.Target.String()
+}
+
+// printDryRun prints what actually happened when the statement was executed and rolled back
+func printDryRun(w io.Writer, res graph.DryRunResult) {
+	fmt.Fprintf(w, "🔬 Dry run (rolled back): %s\n", sqlparse.OneLine(res.Statement, 100))
+	switch {
+	case res.Error == "":
+		fmt.Fprintln(w, "└── ✅ Succeeded")
+	case res.Cascaded && res.CascadeError == "":
+		fmt.Fprintf(w, "└── ❌ Failed: %s\n", res.Error)
+		fmt.Fprintln(w, "    With CASCADE it would succeed and drop:")
+	case res.Cascaded:
+		fmt.Fprintf(w, "└── ❌ Failed: %s\n", res.Error)
+		fmt.Fprintf(w, "    With CASCADE it still fails: %s\n", res.CascadeError)
+	default:
+		fmt.Fprintf(w, "└── ❌ Failed: %s\n", res.Error)
+	}
+	for _, obj := range res.Dropped {
+		name := fmt.Sprintf("%s.%s", obj.Schema, obj.Name)
+		if obj.Table != "" {
+			name += " on " + obj.Table
+		}
+		fmt.Fprintf(w, "    🗑️  %s %s\n", obj.Type, name)
+	}
+	fmt.Fprintln(w)
+}
+
+// compareDryRun attaches a dry run to a simulation. For DROPs it also records the objects the
+// database dropped that were not predicted, and predicted breakages that did not happen.
+func compareDryRun(sim *report.Simulation, change sqlparse.Change, deps []graph.ColumnDependency, res graph.DryRunResult, renamesFollowed bool) {
+	sim.DryRun = &res
+	if change.Kind != sqlparse.DropTable && change.Kind != sqlparse.DropView && change.Kind != sqlparse.DropColumn {
+		return
+	}
+	if res.Failed() {
+		return
+	}
+
+	predicted := make(map[string]bool)
+	for _, dep := range deps {
+		predicted[dep.Name] = true
+	}
+
+	for _, obj := range res.Dropped {
+		if change.Kind != sqlparse.DropColumn && (obj.Name == change.Target.Name || obj.Table == change.Target.Name) {
+			continue // The target itself and what it owns
+		}
+		if predicted[obj.Name] || (obj.Table != "" && predicted[obj.Table]) {
+			continue
+		}
+		sim.DryRunMissed = append(sim.DryRunMissed, fmt.Sprintf("%s %s.%s", obj.Type, obj.Schema, obj.Name))
+	}
+
+	dropped := make(map[string]bool)
+	for _, obj := range res.Dropped {
+		dropped[obj.Name] = true
+		dropped[obj.Table] = true
+	}
+	for _, dep := range deps {
+		if impact, _ := classifyDependency(change.Kind, dep, renamesFollowed); impact == impactBreaks && !dropped[dep.Name] {
+			sim.DryRunUnconfirmed = append(sim.DryRunUnconfirmed, dep.Name)
+		}
+	}
+}
+
+// printDryRunComparison prints how the dry run of a DROP compares with the catalog analysis
+func printDryRunComparison(w io.Writer, sim *report.Simulation) {
+	switch sim.Change.Kind {
+	case string(sqlparse.DropTable), string(sqlparse.DropView), string(sqlparse.DropColumn):
+	default:
+		return
+	}
+	if sim.DryRun.Failed() {
+		return
+	}
+	if len(sim.DryRunMissed) == 0 && len(sim.DryRunUnconfirmed) == 0 {
+		fmt.Fprintln(w, "📏 Dry run agrees with the catalog analysis")
+		return
+	}
+	for _, m := range sim.DryRunMissed {
+		fmt.Fprintf(w, "📏 Missed by catalog analysis: %s\n", m)
+	}
+	for _, u := range sim.DryRunUnconfirmed {
+		fmt.Fprintf(w, "📏 Not dropped in the dry run (code reference or false positive): %s\n", u)
+	}
+}
+
+// simulateMigration analyzes every change of a migration file and reports whether it is safe.
+// With a runner, the whole file is also executed in a rolled-back transaction.
+func simulateMigration(adapter adapters.Adapter, path string, runner adapters.DryRunner) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Printf("Error reading migration: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Unqualified names take the schema the adapter reports dependents in, so objects dropped
+	// earlier in the migration match them
+	m := sqlparse.ParseMigration(string(data), defaultSchema(adapter))
+	result := &report.MigrationSimulation{
+		File:        filepath.Base(path),
+		Simulations: []report.Simulation{},
+		Skipped:     len(m.Skipped),
+	}
+
+	// Objects dropped earlier in the migration no longer count as dependents
+	dropped := make(map[string]bool)
+	renamesFollowed := followsRenames(adapter)
+
+	for _, c := range m.Changes {
+		deps, err := analyzeChange(adapter, c)
+		if err != nil {
+			result.Simulations = append(result.Simulations, report.Simulation{
+				Change:       reportChange(c),
+				Label:        changeTarget(c),
+				Dependencies: []report.DependencyVerdict{},
+				Error:        err.Error(),
+			})
+			result.Unknown++
+			continue
+		}
+
+		var remaining []graph.ColumnDependency
+		resolved := 0
+		for _, dep := range deps {
+			if dropped[fmt.Sprintf("%s.%s", dep.Schema, dep.Name)] {
+				resolved++
+				continue
+			}
+			remaining = append(remaining, dep)
+		}
+
+		sim := newSimulation(c, remaining, renamesFollowed)
+		sim.AlreadyDropped = resolved
+		if sim.Breaks {
+			result.Unsafe++
+		}
+		result.Simulations = append(result.Simulations, sim)
+
+		switch c.Kind {
+		case sqlparse.DropTable, sqlparse.DropView, sqlparse.DropFunction, sqlparse.DropType, sqlparse.DropIndex:
+			dropped[c.Target.String()] = true
+		}
+	}
+
+	if runner != nil {
+		// Transaction control, SET and data changes could commit the dry run or lift its timeouts:
+		// only the schema changes run
+		var statements []string
+		for _, stmt := range sqlparse.SplitStatements(string(data)) {
+			if err := adapters.CheckDryRunStatement(stmt.Text); err != nil {
+				result.NotExecuted = append(result.NotExecuted, fmt.Sprintf("%s: %v", sqlparse.OneLine(stmt.Text, 60), err))
+				continue
+			}
+			statements = append(statements, stmt.Text)
+		}
+		results, err := runner.DryRun(statements)
+		if err != nil {
+			fmt.Printf("Error during dry run: %v\n", err)
+			os.Exit(1)
+		}
+		result.DryRun = results
+		for _, res := range results {
+			if res.Error != "" {
+				result.Failed++
+			}
+		}
+	}
+
+	switch {
+	case result.Failed > 0 || result.Unsafe > 0:
+		result.Verdict = "UNSAFE"
+	case result.Unknown > 0:
+		result.Verdict = "SAFE WITH GAPS"
+	default:
+		result.Verdict = "SAFE"
+	}
+
+	render(result, printMigrationSimulation)
+	return result.Verdict != "UNSAFE"
+}
+
+// printMigrationSimulation renders the per-statement verdicts of a migration
+func printMigrationSimulation(w io.Writer, v any) error {
+	result := v.(*report.MigrationSimulation)
+	fmt.Fprintf(w, "🧪 Simulating migration %s (%d changes analyzed, %d statements skipped)...\n",
+		result.File, len(result.Simulations), result.Skipped)
+
+	for i := range result.Simulations {
+		sim := &result.Simulations[i]
+		fmt.Fprintf(w, "\n[%d] line %d: %s\n", i+1, sim.Change.Line, sqlparse.OneLine(sim.Change.Statement, 100))
+		if sim.Error != "" {
+			fmt.Fprintf(w, "⚠️  %s %s could not be analyzed: %s\n", sim.Change.Kind, sim.Label, sim.Error)
+			continue
+		}
+
+		printSafetyVerdict(w, sim)
+		if sim.AlreadyDropped > 0 {
+			fmt.Fprintf(w, "ℹ️  %d dependent object(s) already dropped earlier in this migration\n", sim.AlreadyDropped)
+		}
+		if sim.Breaks && sim.Change.Cascade {
+			fmt.Fprintln(w, "⚠️  CASCADE: the broken objects above will be dropped silently instead of failing the migration")
+		}
+	}
+
+	if result.DryRun != nil {
+		fmt.Fprintln(w, strings.Repeat("-", 80))
+		fmt.Fprintf(w, "🔬 Executed %d statements in a transaction that was rolled back\n\n", len(result.DryRun))
+		for _, n := range result.NotExecuted {
+			fmt.Fprintf(w, "⏭️  Not executed: %s\n", n)
+		}
+		for _, res := range result.DryRun {
+			// Statements that ran cleanly without side effects are not worth listing
+			if res.Error == "" && len(res.Dropped) == 0 {
+				continue
+			}
+			printDryRun(w, res)
+		}
+	}
 
-		fmt.Printf("└── %s (%s)\n", dep.Name, desc)
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+	switch {
+	case result.Failed > 0:
+		fmt.Fprintf(w, "❌ UNSAFE: %d statements failed in the dry run (%d of %d changes would break dependent objects)\n", result.Failed, result.Unsafe, len(result.Simulations))
+	case result.Unsafe > 0:
+		fmt.Fprintf(w, "❌ UNSAFE: %d of %d changes would break dependent objects\n", result.Unsafe, len(result.Simulations))
+	case result.Unknown > 0:
+		fmt.Fprintf(w, "⚠️  SAFE WITH GAPS: no breakage found, but %d changes could not be analyzed\n", result.Unknown)
+	default:
+		fmt.Fprintln(w, "✅ SAFE: no dependent objects would break")
 	}
-	fmt.Println()
+	return nil
 }
 
 func init() {
 	rootCmd.AddCommand(simulateCmd)
 	simulateCmd.Flags().String("drop-column", "", "Column to simulate dropping (format: table.column)")
 	simulateCmd.Flags().String("drop-table", "", "Table to simulate dropping (format: table)")
+	simulateCmd.Flags().String("drop-type", "", "Enum, composite type or domain to simulate dropping (format: type or schema.type)")
+	simulateCmd.Flags().String("drop-index", "", "Index to simulate dropping (format: index or schema.index)")
+	simulateCmd.Flags().String("rename-column", "", "Column to simulate renaming (format: table.column=new_name)")
+	simulateCmd.Flags().String("rename-table", "", "Table to simulate renaming (format: table=new_name)")
+	simulateCmd.Flags().String("alter-type", "", "Column type change to simulate (format: table.column=new_type)")
+	simulateCmd.Flags().String("set-not-null", "", "Column to simulate SET NOT NULL on (format: table.column)")
+	simulateCmd.Flags().String("migration", "", "Migration SQL file whose statements should be simulated")
+	simulateCmd.Flags().Bool("execute-dry-run", false, "Also execute the DDL in a rolled-back transaction for ground-truth results (PostgreSQL only)")
 }
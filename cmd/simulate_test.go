@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alexanderritik/dbgraph/internal/graph"
+)
+
+// stubAdapter reports dependents in a fixed schema, like MySQL reports the connected database
+type stubAdapter struct {
+	schema    string
+	tableDeps map[string][]graph.ColumnDependency
+}
+
+func (s *stubAdapter) Connect(string) error                  { return nil }
+func (s *stubAdapter) Close()                                {}
+func (s *stubAdapter) FetchSchema(*graph.Graph) error        { return nil }
+func (s *stubAdapter) GetMetrics() (*graph.DBMetrics, error) { return &graph.DBMetrics{}, nil }
+func (s *stubAdapter) GetColumnDependencies(string, string, string) ([]graph.ColumnDependency, error) {
+	return nil, nil
+}
+func (s *stubAdapter) GetTableDependencies(schema, table string) ([]graph.ColumnDependency, error) {
+	if schema == "public" {
+		schema = s.schema // Like MySQL, which maps the CLI default to the connected database
+	}
+	return s.tableDeps[schema+"."+table], nil
+}
+func (s *stubAdapter) GetTopQueries(int, string) ([]graph.QueryStats, error) { return nil, nil }
+func (s *stubAdapter) TraceQuery(string) (*graph.TraceResult, error)         { return nil, nil }
+func (s *stubAdapter) DefaultSchema() string                                 { return s.schema }
+
+func TestSimulateMigrationNonPublicSchema(t *testing.T) {
+	adapter := &stubAdapter{
+		schema: "shop",
+		tableDeps: map[string][]graph.ColumnDependency{
+			"shop.orders": {{Schema: "shop", Name: "order_totals", Type: "VIEW", Detail: "View Dependency"}},
+		},
+	}
+	path := filepath.Join(t.TempDir(), "migration.sql")
+	if err := os.WriteFile(path, []byte("DROP VIEW order_totals;\nDROP TABLE orders;\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := os.Stdout
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+	os.Stdout = devNull
+	safe := simulateMigration(adapter, path, nil)
+	os.Stdout = stdout
+
+	if !safe {
+		t.Error("expected the view dropped in statement 1 not to break statement 2")
+	}
+}
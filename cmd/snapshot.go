@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/alexanderritik/dbgraph/internal/adapters"
+	"github.com/alexanderritik/dbgraph/internal/engine"
+	"github.com/alexanderritik/dbgraph/internal/graph"
+
+	"github.com/spf13/cobra"
+)
+
+// snapshotCmd groups the snapshot subcommands
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Capture the schema graph to a file for offline analysis",
+	Long: `Snapshots serialize the full dependency graph (nodes, sizes, row counts, indexes and edges)
+so impact, analyze, summary and simulate --drop-table can run without database credentials,
+either with --from-snapshot prod.json or --db snapshot://prod.json.`,
+}
+
+// snapshotSaveCmd represents the snapshot save command
+var snapshotSaveCmd = &cobra.Command{
+	Use:   "save",
+	Short: "Crawl the database and write the graph to a JSON snapshot",
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureDBConnection()
+
+		out, _ := cmd.Flags().GetString("out")
+		if out == "" {
+			fmt.Println("Error: --out flag is required")
+			os.Exit(1)
+		}
+
+		g := graph.NewGraph()
+		a, err := adapters.NewAdapter(dbUrl)
+		if err != nil {
+			fmt.Printf("Error creating adapter: %v\n", err)
+			os.Exit(1)
+		}
+
+		e := engine.NewEngine(g, a)
+		defer a.Close()
+
+		if err := e.Connect(dbUrl); err != nil {
+			fmt.Printf("Error connecting to database: %v\n", err)
+			os.Exit(1)
+		}
+
+		if err := e.BuildGraph(); err != nil {
+			fmt.Printf("Error building graph: %v\n", err)
+			os.Exit(1)
+		}
+
+		serverVersion := ""
+		if v, ok := a.(adapters.ServerVersioner); ok {
+			if serverVersion, err = v.ServerVersion(); err != nil {
+				fmt.Printf("Warning: failed to fetch server version: %v\n", err)
+			}
+		}
+
+		source, _, _ := strings.Cut(dbUrl, "://")
+		snap := graph.NewSnapshot(g, source, serverVersion)
+		if err := graph.SaveSnapshotFile(out, snap); err != nil {
+			fmt.Printf("Error writing snapshot: %v\n", err)
+			os.Exit(1)
+		}
+
+		edgeCount := 0
+		for _, edges := range g.Edges {
+			edgeCount += len(edges)
+		}
+		fmt.Printf("📸 Snapshot saved to %s (%d nodes, %d edges)\n", out, len(g.Nodes), edgeCount)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd)
+	snapshotCmd.AddCommand(snapshotSaveCmd)
+	snapshotSaveCmd.Flags().String("out", "", "Path of the snapshot file to write (e.g. prod.json)")
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/alexanderritik/dbgraph/internal/graph"
+	"github.com/alexanderritik/dbgraph/internal/report"
+	"github.com/alexanderritik/dbgraph/internal/sqlparse"
+
+	"github.com/spf13/cobra"
+)
+
+// suggestIndexesCmd represents the suggest-indexes command
+var suggestIndexesCmd = &cobra.Command{
+	Use:   "suggest-indexes",
+	Short: "Generate CREATE INDEX statements for unindexed foreign keys",
+	Long: `Emits a migration creating an index for every foreign key whose columns no index covers, the same
+foreign keys 'analyze' reports. Statements use CREATE INDEX CONCURRENTLY so the tables stay writable, and
+index names are deterministic (<table>_<columns>_idx), so running the command twice yields the same file.
+The most urgent indexes come first: those whose referenced table has the most rows, then those on the
+tables with the most writes since the statistics were reset (pg_stat_user_tables).
+
+Use --out to write the migration to a file; 'analyze --fix-sql' prints the same script.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ensureDBConnection()
+
+		g, err := loadGraph(dbUrl)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		suggestions := g.SuggestIndexes()
+		result := &report.IndexSuggestions{
+			Database:    redactConnString(dbUrl),
+			Suggestions: suggestions,
+			Migration:   indexMigration(g, suggestions),
+		}
+
+		out, _ := cmd.Flags().GetString("out")
+		if out != "" {
+			if err := os.WriteFile(out, []byte(result.Migration), 0o644); err != nil {
+				fmt.Printf("Error writing migration: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("📝 Migration with %d indexes written to %s\n", len(suggestions), out)
+			return
+		}
+		render(result, printIndexSuggestions)
+	},
+}
+
+// printIndexSuggestions renders the suggested indexes followed by the migration
+func printIndexSuggestions(w io.Writer, v any) error {
+	result := v.(*report.IndexSuggestions)
+
+	fmt.Fprintf(w, "🧭 SUGGESTED INDEXES: %s | Unindexed foreign keys: %d\n", result.Database, len(result.Suggestions))
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+	if len(result.Suggestions) == 0 {
+		fmt.Fprintln(w, "✅ Every foreign key is covered by an index")
+		return nil
+	}
+	for i, s := range result.Suggestions {
+		fmt.Fprintf(w, "%d. %s (%s) for %s -> %s | parent rows: %d, child writes: %d\n",
+			i+1, s.Table, strings.Join(s.Columns, ", "), strings.Join(s.ForeignKeys, ", "),
+			strings.Join(s.Parents, ", "), s.ParentRows, s.ChildWrites)
+	}
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+	fmt.Fprint(w, result.Migration)
+	return nil
+}
+
+// indexMigration renders suggested indexes as a SQL script, one CREATE INDEX CONCURRENTLY per index
+func indexMigration(g *graph.Graph, suggestions []graph.IndexSuggestion) string {
+	var b strings.Builder
+	b.WriteString("-- Indexes for foreign keys without a supporting index, generated by dbgraph.\n")
+	b.WriteString("-- CREATE INDEX CONCURRENTLY cannot run inside a transaction block: run this script\n")
+	b.WriteString("-- without wrapping it in BEGIN/COMMIT (e.g. psql -f, not psql -1).\n")
+	if len(suggestions) == 0 {
+		b.WriteString("-- Every foreign key is covered by an index: nothing to do.\n")
+		return b.String()
+	}
+	for _, s := range suggestions {
+		table := s.Table
+		if n, ok := g.Nodes[s.Table]; ok {
+			table = sqlparse.QuoteIdent(n.Schema) + "." + sqlparse.QuoteIdent(n.Name)
+		}
+		cols := make([]string, len(s.Columns))
+		for i, c := range s.Columns {
+			cols[i] = sqlparse.QuoteIdent(c)
+		}
+		fmt.Fprintf(&b, "\n-- %s -> %s (parent rows: %d, child writes: %d)\n",
+			strings.Join(s.ForeignKeys, ", "), strings.Join(s.Parents, ", "), s.ParentRows, s.ChildWrites)
+		fmt.Fprintf(&b, "CREATE INDEX CONCURRENTLY IF NOT EXISTS %s ON %s (%s);\n",
+			sqlparse.QuoteIdent(s.Name), table, strings.Join(cols, ", "))
+	}
+	return b.String()
+}
+
+func init() {
+	rootCmd.AddCommand(suggestIndexesCmd)
+	suggestIndexesCmd.Flags().String("out", "", "Path of the migration file to write (e.g. 0042_fk_indexes.sql)")
+}
@@ -2,12 +2,14 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
 	"github.com/alexanderritik/dbgraph/internal/adapters"
 	"github.com/alexanderritik/dbgraph/internal/engine"
 	"github.com/alexanderritik/dbgraph/internal/graph"
+	"github.com/alexanderritik/dbgraph/internal/report"
 	"github.com/spf13/cobra"
 )
 
@@ -23,6 +25,7 @@ var summaryCmd = &cobra.Command{
 	Long:  `Displays a ranked table of database objects based on their topological impact (Centrality), Risk, and Connectivity.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		ensureDBConnection()
+		risk := loadConfig().Risk
 
 		g := graph.NewGraph()
 		// ... existing code ...
@@ -49,12 +52,6 @@ var summaryCmd = &cobra.Command{
 		// Perform Analysis
 		stats := g.AnalyzeTopology()
 
-		fmt.Println("\n📊 ARCHITECTURAL TOPOLOGY (Top Impact)")
-		fmt.Println(strings.Repeat("-", 80))
-		fmt.Printf("%-30s %-10s %-10s %-10s %-10s %-10s\n", "OBJECT NAME", "TYPE", "IN/OUT", "ROWS", "IMPACT", "RISK")
-		fmt.Println(strings.Repeat("-", 80))
-
-		count := 0
 		limit := 10
 		if limitRows > 0 {
 			limit = limitRows
@@ -63,50 +60,55 @@ var summaryCmd = &cobra.Command{
 			limit = len(stats.TopNodes)
 		}
 
+		result := &report.Summary{Objects: []report.SummaryRow{}, Total: len(stats.TopNodes)}
 		for _, n := range stats.TopNodes {
-			if count >= limit {
+			if len(result.Objects) >= limit {
 				break
 			}
-			// Risk Calculation
-			risk := "LOW"
-			if n.Centrality > 5 {
-				risk = "MED"
-			}
-			if n.Centrality > 10 {
-				risk = "HIGH"
-			}
-			if n.InDegree > 5 && n.OutDegree > 2 {
-				risk = "CRITICAL"
-			}
+			result.Objects = append(result.Objects, report.SummaryRow{NodeRank: n, Risk: risk.Classify(n)})
+		}
 
-			// Row Count Formatting
-			rowStr := fmt.Sprintf("%d", n.Rows)
-			if n.Type == graph.Trigger {
-				rowStr = "-"
-			} else if n.Type == graph.View {
-				// Standard Views (0 rows) -> "-"
-				// Materialized Views (>0 rows) -> Show count
-				if n.Rows == 0 {
-					rowStr = "-"
-				}
-			}
+		render(result, printSummary)
+	},
+}
 
-			// Type formatting
-			t := string(n.Type)
-			if len(t) > 8 {
-				t = t[:8]
+// printSummary renders the ranked objects as a fixed-width table
+func printSummary(w io.Writer, v any) error {
+	result := v.(*report.Summary)
+
+	fmt.Fprintln(w, "\n📊 ARCHITECTURAL TOPOLOGY (Top Impact)")
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+	fmt.Fprintf(w, "%-30s %-10s %-10s %-10s %-10s %-10s\n", "OBJECT NAME", "TYPE", "IN/OUT", "ROWS", "IMPACT", "RISK")
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+
+	for _, n := range result.Objects {
+		// Row Count Formatting
+		rowStr := fmt.Sprintf("%d", n.Rows)
+		if n.Type != graph.Table && n.Type != graph.View {
+			rowStr = "-"
+		} else if n.Type == graph.View {
+			// Standard Views (0 rows) -> "-"
+			// Materialized Views (>0 rows) -> Show count
+			if n.Rows == 0 {
+				rowStr = "-"
 			}
-
-			inOut := fmt.Sprintf("%d/%d", n.InDegree, n.OutDegree)
-			fmt.Printf("%-30s %-10s %-10s %-10s %-10.2f %-10s\n",
-				n.ID, t, inOut, rowStr, n.Centrality, risk)
-			count++
 		}
-		fmt.Println(strings.Repeat("-", 80))
-		if !showAll && len(stats.TopNodes) > limit {
-			fmt.Printf("... and %d more. Use --all or --limit to see more.\n", len(stats.TopNodes)-limit)
+
+		// Type formatting
+		t := string(n.Type)
+		if len(t) > 8 {
+			t = t[:8]
 		}
-	},
+
+		inOut := fmt.Sprintf("%d/%d", n.InDegree, n.OutDegree)
+		fmt.Fprintf(w, "%-30s %-10s %-10s %-10s %-10.2f %-10s\n",
+			n.ID, t, inOut, rowStr, n.Centrality, n.Risk)
+	}
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+	if more := result.Total - len(result.Objects); more > 0 {
+		fmt.Fprintf(w, "... and %d more. Use --all or --limit to see more.\n", more)
+	}
+	return nil
 }
 
 func init() {
@@ -1,16 +1,22 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"sort"
 	"strings"
 	"text/tabwriter"
 	"time"
 
 	"github.com/alexanderritik/dbgraph/internal/adapters"
 	"github.com/alexanderritik/dbgraph/internal/graph"
+	"github.com/alexanderritik/dbgraph/internal/report"
+	"github.com/alexanderritik/dbgraph/internal/sqlparse"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 var (
@@ -18,15 +24,34 @@ var (
 	topSort     string
 	topLimit    int
 	topWatch    bool
+	topPlain    bool
+	topMode     string
 )
 
+// topDeltaFetchLimit is how many statements delta mode samples: pg_stat_statements keeps 5000 by
+// default, so a sample below the limit holds them all and a statement missing from it is new
+const topDeltaFetchLimit = 5000
+
 // topCmd represents the top command
 var topCmd = &cobra.Command{
 	Use:   "top",
 	Short: "Real-time query performance monitoring (like htop)",
-	Long:  `Displays a ranking of the most resource-intensive queries in real-time.`,
+	Long: `Displays a ranking of the most resource-intensive queries in real-time.
+
+With --watch on a terminal, opens a full-screen view: move through the queries with the arrow keys,
+switch the sort with s (or 1-3), open the full query and the graph nodes it references with Enter,
+EXPLAIN ANALYZE the selected SELECT with e, pause with p and quit with q. --plain keeps the
+reprinting output instead.
+
+pg_stat_statements totals span everything since the last reset. --mode delta shows what happened
+within each sampling interval instead: calls/s, ms/s, the average latency and each query's share of
+the time. The first sample only sets the baseline.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		ensureDBConnection()
+		if topMode != "cumulative" && topMode != "delta" {
+			fmt.Printf("Error: unknown --mode %q (use cumulative or delta)\n", topMode)
+			os.Exit(1)
+		}
 
 		// Connect
 		a, err := adapters.NewAdapter(dbUrl)
@@ -46,21 +71,34 @@ var topCmd = &cobra.Command{
 		// Suppress errors for context fetching, it's optional flair
 		_ = a.FetchSchema(g)
 
+		if topWatch && !topPlain && output() == report.Text && term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd())) {
+			if err := runTopTUI(a, g); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
 		// Loop
+		var sampler graph.QuerySampler
 		for {
-			// Clear Screen if watching
-			if topWatch {
+			// Clear Screen if watching (structured output is streamed, one document per sample)
+			if topWatch && output() == report.Text {
 				c := exec.Command("clear")
 				c.Stdout = os.Stdout
 				c.Run()
 			}
 
-			// Header
-			fmt.Printf("⏱️  Sampling: %ds | Sort: %s | Mode: Cumulative stats\n", topInterval, topSort)
-			fmt.Println(strings.Repeat("-", 80))
-
 			// Fetch Data
-			queries, err := a.GetTopQueries(topLimit, topSort)
+			fetchLimit := topLimit
+			if topMode == "delta" {
+				fetchLimit = topDeltaFetchLimit
+			}
+			queries, err := a.GetTopQueries(fetchLimit, topSort)
+			if errors.Is(err, adapters.ErrUnsupported) {
+				fmt.Printf("ℹ️  top is unavailable for this database (%v)\n", err)
+				os.Exit(1)
+			}
 			if err != nil {
 				fmt.Printf("Error fetching queries: %v\n", err)
 				if !topWatch {
@@ -70,77 +108,31 @@ var topCmd = &cobra.Command{
 				continue
 			}
 
-			if len(queries) == 0 {
-				fmt.Println("No queries recorded yet.")
+			result := &report.Top{Interval: topInterval, Sort: topSort, Mode: topMode, Queries: []report.TopQuery{}}
+			ranked := make([]graph.QueryDelta, 0, len(queries))
+			if topMode == "delta" {
+				deltas, elapsed := sampler.Sample(queries, time.Now(), len(queries) < fetchLimit, topSort)
+				if elapsed == 0 {
+					if output() == report.Text {
+						fmt.Printf("⏱️  Collecting the baseline sample, first rates in %ds...\n", topInterval)
+					}
+					time.Sleep(time.Duration(topInterval) * time.Second)
+					continue
+				}
+				result.Elapsed = elapsed.Seconds()
+				ranked = deltas
 			} else {
-				// 1. Render Summary Table
-				w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
-				fmt.Fprintln(w, "RANK\tLOAD %\tTIME (ms)\tCALLS\tAVG (ms)\tQUERY PREVIEW")
-				fmt.Fprintln(w, "----\t------\t---------\t-----\t--------\t-------------")
-
-				for i, q := range queries {
-					// Preview: truncate nicely
-					preview := strings.ReplaceAll(q.Query, "\n", " ")
-					preview = strings.Join(strings.Fields(preview), " ") // normalize spaces
-					preview = truncate(preview, 50)
-
-					fmt.Fprintf(w, "%d\t%.2f\t%.2f\t%d\t%.2f\t%s\n",
-						i+1, q.LoadPercent, q.TotalTime, q.Calls, q.AvgTime, preview)
+				for _, q := range queries {
+					ranked = append(ranked, graph.QueryDelta{QueryStats: q})
 				}
-				w.Flush()
-
-				// 2. Render Details
-				fmt.Println()
-				fmt.Println(strings.Repeat("-", 80))
-				fmt.Println("QUERY DETAILS")
-				fmt.Println(strings.Repeat("-", 80))
-				fmt.Println()
-
-				for i, q := range queries {
-					fmt.Printf("[RANK %d]\n", i+1)
-
-					// Basic syntax highlighting (very poor man's)
-					formattedQuery := q.Query
-					formattedQuery = strings.ReplaceAll(formattedQuery, "SELECT", "\033[1;34mSELECT\033[0m")
-					formattedQuery = strings.ReplaceAll(formattedQuery, "FROM", "\033[1;34mFROM\033[0m")
-					formattedQuery = strings.ReplaceAll(formattedQuery, "WHERE", "\033[1;34mWHERE\033[0m")
-					formattedQuery = strings.ReplaceAll(formattedQuery, "JOIN", "\033[1;34mJOIN\033[0m")
-					formattedQuery = strings.ReplaceAll(formattedQuery, "LEFT", "\033[1;34mLEFT\033[0m")
-					formattedQuery = strings.ReplaceAll(formattedQuery, "GROUP BY", "\033[1;34mGROUP BY\033[0m")
-					formattedQuery = strings.ReplaceAll(formattedQuery, "ORDER BY", "\033[1;34mORDER BY\033[0m")
-					formattedQuery = strings.ReplaceAll(formattedQuery, "WITH", "\033[1;34mWITH\033[0m")
-					fmt.Println(formattedQuery)
-
-					// Context detection
-					var contexts []string
-					upperQ := strings.ToUpper(q.Query)
-					for _, node := range g.Nodes {
-						if strings.Contains(upperQ, strings.ToUpper(node.Name)) {
-							contexts = append(contexts, fmt.Sprintf("%s (%s)", node.Name, node.Type))
-						}
-					}
-					if len(contexts) > 0 {
-						// Deduplicate
-						unique := make(map[string]bool)
-						var clean []string
-						for _, c := range contexts {
-							if !unique[c] {
-								unique[c] = true
-								clean = append(clean, c)
-							}
-						}
-						// Limit context output
-						if len(clean) > 5 {
-							clean = clean[:5]
-							clean = append(clean, "...")
-						}
-						fmt.Printf("\nLOCAL CONTEXT: %s\n", strings.Join(clean, ", "))
-					}
-
-					fmt.Println()
+			}
+			for i, q := range ranked {
+				if i == topLimit {
+					break
 				}
-				fmt.Println(strings.Repeat("-", 80))
+				result.Queries = append(result.Queries, report.TopQuery{QueryDelta: q, Rank: i + 1, Context: queryContext(g, q.Query)})
 			}
+			render(result, printTop)
 
 			if !topWatch {
 				break
@@ -150,11 +142,121 @@ var topCmd = &cobra.Command{
 	},
 }
 
-func truncate(s string, max int) string {
-	if len(s) > max {
-		return s[:max] + "..."
+// queryContext lists the schema objects a query mentions (at most 5, then "...")
+func queryContext(g *graph.Graph, query string) []string {
+	var contexts []string
+	upperQ := strings.ToUpper(query)
+	for _, node := range g.Nodes {
+		if strings.Contains(upperQ, strings.ToUpper(node.Name)) {
+			contexts = append(contexts, fmt.Sprintf("%s (%s)", node.Name, node.Type))
+		}
+	}
+
+	// Deduplicate
+	unique := make(map[string]bool)
+	var clean []string
+	for _, c := range contexts {
+		if !unique[c] {
+			unique[c] = true
+			clean = append(clean, c)
+		}
+	}
+	sort.Strings(clean)
+	// Limit context output
+	if len(clean) > 5 {
+		clean = clean[:5]
+		clean = append(clean, "...")
+	}
+	return clean
+}
+
+// printTop renders one sample as the summary table followed by the query details
+func printTop(w io.Writer, v any) error {
+	result := v.(*report.Top)
+
+	// Header
+	delta := result.Mode == "delta"
+	if delta {
+		fmt.Fprintf(w, "⏱️  Sampling: %ds | Sort: %s | Mode: Delta over the last %.1fs\n", result.Interval, result.Sort, result.Elapsed)
+	} else {
+		fmt.Fprintf(w, "⏱️  Sampling: %ds | Sort: %s | Mode: Cumulative stats\n", result.Interval, result.Sort)
+	}
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+
+	if len(result.Queries) == 0 {
+		if delta {
+			fmt.Fprintln(w, "No queries ran in the interval.")
+		} else {
+			fmt.Fprintln(w, "No queries recorded yet.")
+		}
+		return nil
+	}
+
+	// 1. Render Summary Table
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	if delta {
+		fmt.Fprintln(tw, "RANK\tLOAD %\tMS/S\tCALLS/S\tAVG (ms)\tQUERY PREVIEW")
+		fmt.Fprintln(tw, "----\t------\t----\t-------\t--------\t-------------")
+	} else {
+		fmt.Fprintln(tw, "RANK\tLOAD %\tTIME (ms)\tCALLS\tAVG (ms)\tQUERY PREVIEW")
+		fmt.Fprintln(tw, "----\t------\t---------\t-----\t--------\t-------------")
+	}
+
+	for _, q := range result.Queries {
+		// Preview: one line, cut on a rune boundary
+		preview := sqlparse.OneLine(q.Query, 50)
+
+		if delta {
+			fmt.Fprintf(tw, "%d\t%.2f\t%.2f\t%.2f\t%.2f\t%s%s\n",
+				q.Rank, q.LoadPercent, q.TimePerSec, q.CallsPerSec, q.AvgTime, deltaMarker(q.QueryDelta), preview)
+		} else {
+			fmt.Fprintf(tw, "%d\t%.2f\t%.2f\t%d\t%.2f\t%s\n",
+				q.Rank, q.LoadPercent, q.TotalTime, q.Calls, q.AvgTime, preview)
+		}
+	}
+	tw.Flush()
+
+	// 2. Render Details
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+	fmt.Fprintln(w, "QUERY DETAILS")
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+	fmt.Fprintln(w)
+
+	for _, q := range result.Queries {
+		fmt.Fprintf(w, "[RANK %d]\n", q.Rank)
+
+		// Basic syntax highlighting (very poor man's)
+		formattedQuery := q.Query
+		formattedQuery = strings.ReplaceAll(formattedQuery, "SELECT", "\033[1;34mSELECT\033[0m")
+		formattedQuery = strings.ReplaceAll(formattedQuery, "FROM", "\033[1;34mFROM\033[0m")
+		formattedQuery = strings.ReplaceAll(formattedQuery, "WHERE", "\033[1;34mWHERE\033[0m")
+		formattedQuery = strings.ReplaceAll(formattedQuery, "JOIN", "\033[1;34mJOIN\033[0m")
+		formattedQuery = strings.ReplaceAll(formattedQuery, "LEFT", "\033[1;34mLEFT\033[0m")
+		formattedQuery = strings.ReplaceAll(formattedQuery, "GROUP BY", "\033[1;34mGROUP BY\033[0m")
+		formattedQuery = strings.ReplaceAll(formattedQuery, "ORDER BY", "\033[1;34mORDER BY\033[0m")
+		formattedQuery = strings.ReplaceAll(formattedQuery, "WITH", "\033[1;34mWITH\033[0m")
+		fmt.Fprintln(w, formattedQuery)
+
+		if len(q.Context) > 0 {
+			fmt.Fprintf(w, "\nLOCAL CONTEXT: %s\n", strings.Join(q.Context, ", "))
+		}
+
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+	return nil
+}
+
+// deltaMarker flags queries counted from zero in the interval
+func deltaMarker(q graph.QueryDelta) string {
+	switch {
+	case q.New:
+		return "[new] "
+	case q.Reset:
+		return "[reset] "
 	}
-	return s
+	return ""
 }
 
 func init() {
@@ -164,4 +266,6 @@ func init() {
 	topCmd.Flags().IntVar(&topLimit, "limit", 10, "How many queries to show")
 
 	topCmd.Flags().BoolVar(&topWatch, "watch", false, "Live watch mode")
+	topCmd.Flags().StringVar(&topMode, "mode", "cumulative", "cumulative (totals since the stats reset) or delta (activity within each interval)")
+	topCmd.Flags().BoolVar(&topPlain, "plain", false, "With --watch, reprint the report instead of the interactive screen")
 }
@@ -0,0 +1,626 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/alexanderritik/dbgraph/internal/adapters"
+	"github.com/alexanderritik/dbgraph/internal/graph"
+	"github.com/alexanderritik/dbgraph/internal/sqlparse"
+
+	"golang.org/x/term"
+)
+
+// topSorts are the orders the interactive screen cycles through, as GetTopQueries names them
+var topSorts = []string{"total", "calls", "avg_time"}
+
+// topSample is one refresh of the interactive screen
+type topSample struct {
+	sort     string
+	complete bool // Fewer queries than requested: the sample holds them all
+	queries  []graph.QueryStats
+	metrics  *graph.DBMetrics
+	err      error
+	at       time.Time
+}
+
+// topExplain is the EXPLAIN output of a query, for the detail pane
+type topExplain struct {
+	queryID string
+	lines   []string
+}
+
+// topScreen is the state of the interactive 'top --watch' screen
+type topScreen struct {
+	a adapters.Adapter
+	g *graph.Graph
+
+	sort     string
+	queries  []graph.QueryDelta // Delta mode: activity within the interval; otherwise cumulative stats only
+	sampler  graph.QuerySampler
+	elapsed  time.Duration // Delta mode: length of the interval shown; 0 until the baseline is taken
+	metrics  *graph.DBMetrics
+	err      error
+	updated  time.Time
+	fetching bool
+	refetch  bool // The sort changed during a fetch: fetch again once it lands
+
+	selected string // QueryID of the selected query, kept across refreshes
+	cursor   int
+	offset   int // First visible row of the query list
+	paused   bool
+	detail   bool     // Show the full query and the graph nodes it references
+	explain  []string // EXPLAIN output of the selected query; nil when closed
+	scroll   int      // First visible line of the detail pane
+}
+
+// runTopTUI runs the full-screen 'top' until q, Esc or Ctrl-C. The terminal is switched to raw mode
+// and the alternate screen, and restored on exit.
+func runTopTUI(a adapters.Adapter, g *graph.Graph) error {
+	in, out := int(os.Stdin.Fd()), int(os.Stdout.Fd())
+	state, err := term.MakeRaw(in)
+	if err != nil {
+		return fmt.Errorf("failed to switch the terminal to raw mode: %w", err)
+	}
+	defer term.Restore(in, state)
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	defer fmt.Print("\x1b[?25h\x1b[?1049l")
+
+	keys := make(chan string)
+	go readKeys(os.Stdin, keys)
+	samples := make(chan topSample, 1)
+	explains := make(chan topExplain, 1)
+	ticker := time.NewTicker(time.Duration(topInterval) * time.Second)
+	defer ticker.Stop()
+
+	s := &topScreen{a: a, g: g, sort: topSort}
+	s.fetch(samples)
+	for {
+		width, height, err := term.GetSize(out)
+		if err != nil {
+			width, height = 80, 24
+		}
+		os.Stdout.WriteString(s.render(width, height))
+
+		select {
+		case k, ok := <-keys:
+			if !ok || s.handleKey(k, height, samples, explains) {
+				return nil
+			}
+		case sample := <-samples:
+			s.apply(sample)
+			if s.refetch {
+				s.refetch = false
+				s.fetch(samples)
+			}
+		case e := <-explains:
+			// Drop the result when the pane was closed or another query was selected meanwhile
+			if s.explain != nil && e.queryID == s.selected {
+				s.explain, s.scroll = e.lines, 0
+			}
+		case <-ticker.C:
+			if !s.paused {
+				s.fetch(samples)
+			}
+		}
+	}
+}
+
+// fetch samples the queries and server metrics in the background; one fetch runs at a time
+func (s *topScreen) fetch(samples chan<- topSample) {
+	if s.fetching {
+		return
+	}
+	s.fetching = true
+	sortBy, limit := s.sort, topLimit
+	if topMode == "delta" {
+		limit = topDeltaFetchLimit
+	}
+	go func() {
+		sample := topSample{sort: sortBy, at: time.Now()}
+		sample.queries, sample.err = s.a.GetTopQueries(limit, sortBy)
+		sample.complete = len(sample.queries) < limit
+		if sample.err == nil {
+			// Metrics are context: a failure leaves the header without them
+			sample.metrics, _ = s.a.GetMetrics()
+		}
+		samples <- sample
+	}()
+}
+
+// apply shows a sample, keeping the selection on the same query
+func (s *topScreen) apply(sample topSample) {
+	s.fetching = false
+	s.err = sample.err
+	if sample.err != nil {
+		return
+	}
+	s.metrics, s.updated = sample.metrics, sample.at
+	if topMode == "delta" {
+		// Every active query is kept, so the sort can change without a new sample
+		s.queries, s.elapsed = s.sampler.Sample(sample.queries, sample.at, sample.complete, s.sort)
+	} else {
+		s.queries = s.queries[:0]
+		for _, q := range sample.queries {
+			s.queries = append(s.queries, graph.QueryDelta{QueryStats: q})
+		}
+		if sample.sort != s.sort {
+			sortQueries(s.queries, s.sort)
+		}
+	}
+	s.reselect()
+}
+
+// reselect moves the cursor to the selected query, or keeps its position when the query is gone
+func (s *topScreen) reselect() {
+	for i, q := range s.queries {
+		if q.QueryID == s.selected {
+			s.cursor = i
+			return
+		}
+	}
+	s.moveTo(s.cursor)
+}
+
+// moveTo selects the query at row i, clamped to the list
+func (s *topScreen) moveTo(i int) {
+	if i >= len(s.queries) {
+		i = len(s.queries) - 1
+	}
+	if i < 0 {
+		i = 0
+	}
+	selected := ""
+	if i < len(s.queries) {
+		selected = s.queries[i].QueryID
+	}
+	if selected != s.selected {
+		s.explain = nil // It described the previous query
+	}
+	s.cursor, s.selected = i, selected
+}
+
+// sortQueries orders queries as GetTopQueries would, to show a new sort before the next fetch lands
+func sortQueries(queries []graph.QueryDelta, sortBy string) {
+	sort.SliceStable(queries, func(i, j int) bool { return graph.QueryLess(queries[i].QueryStats, queries[j].QueryStats, sortBy) })
+}
+
+// handleKey applies a key press and reports whether to quit
+func (s *topScreen) handleKey(k string, height int, samples chan<- topSample, explains chan<- topExplain) bool {
+	page := s.listHeight(height) - 1
+	if page < 1 {
+		page = 1
+	}
+	switch k {
+	case "q", "ctrl-c":
+		return true
+	case "esc":
+		switch {
+		case s.explain != nil:
+			s.explain, s.scroll = nil, 0
+		case s.detail:
+			s.detail, s.scroll = false, 0
+		default:
+			return true
+		}
+	case "up", "k":
+		s.moveTo(s.cursor - 1)
+	case "down", "j":
+		s.moveTo(s.cursor + 1)
+	case "pgup":
+		s.moveTo(s.cursor - page)
+	case "pgdown":
+		s.moveTo(s.cursor + page)
+	case "home", "g":
+		s.moveTo(0)
+	case "end", "G":
+		s.moveTo(len(s.queries) - 1)
+	case "enter", "d":
+		s.detail, s.scroll = !s.detail, 0
+	case "J", "ctrl-d":
+		s.scroll++
+	case "K", "ctrl-u":
+		if s.scroll > 0 {
+			s.scroll--
+		}
+	case "s", "1", "2", "3":
+		next := topSorts[0]
+		for i, name := range topSorts {
+			if k == "s" && name == s.sort {
+				next = topSorts[(i+1)%len(topSorts)]
+			}
+		}
+		if k != "s" {
+			next = topSorts[k[0]-'1']
+		}
+		if next != s.sort {
+			s.sort = next
+			sortQueries(s.queries, s.sort)
+			s.reselect()
+			switch {
+			case topMode == "delta":
+				// The sample holds every active query: nothing to fetch
+			case s.fetching:
+				s.refetch = true
+			default:
+				s.fetch(samples)
+			}
+		}
+	case "p", " ":
+		s.paused = !s.paused
+	case "r":
+		s.fetch(samples)
+	case "e":
+		if s.cursor < len(s.queries) {
+			s.detail, s.scroll = true, 0
+			s.explain = []string{"Running EXPLAIN ANALYZE..."}
+			q := s.queries[s.cursor]
+			go func() { explains <- topExplain{queryID: q.QueryID, lines: explainLines(s.a, q.Query)} }()
+		}
+	}
+	return false
+}
+
+// explainLines traces a query like 'trace' does, for the detail pane. As there, only SELECT queries
+// run: EXPLAIN ANALYZE executes the statement.
+func explainLines(a adapters.Adapter, query string) []string {
+	upperQ := strings.ToUpper(strings.TrimSpace(query))
+	if !strings.HasPrefix(upperQ, "SELECT") && !strings.HasPrefix(upperQ, "WITH") {
+		return []string{"EXPLAIN skipped: only SELECT queries are traced (EXPLAIN ANALYZE executes the statement)"}
+	}
+	result, err := a.TraceQuery(query)
+	if err != nil {
+		lines := []string{fmt.Sprintf("EXPLAIN failed: %v", err)}
+		if strings.Contains(query, "$1") {
+			lines = append(lines, "The statement is normalized: run 'dbgraph trace' on it with literal values instead of $n")
+		}
+		return lines
+	}
+
+	hitRate := 0.0
+	if totalIO := result.CacheHits + result.DiskReads; totalIO > 0 {
+		hitRate = float64(result.CacheHits) / float64(totalIO) * 100.0
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "EXPLAIN ANALYZE: planning %.2f ms | execution %.2f ms | cache hits %d (%.1f%%) | disk reads %d\n",
+		result.PlanningTime, result.ExecutionTime, result.CacheHits, hitRate, result.DiskReads)
+	printExplainTree(&buf, result.Root, "", true)
+	return strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+}
+
+// referencedNodes lists the graph nodes a query reads or writes, with their type and size
+func referencedNodes(g *graph.Graph, query string) []string {
+	var nodes []string
+	for _, name := range sqlparse.RelationReferences(sqlparse.Tokenize(query), "public") {
+		n, ok := g.Nodes[name.String()]
+		if !ok && name.Schema == "public" {
+			// Unqualified names may live in another schema of the search_path
+			for _, id := range sortedNodeIDs(g) {
+				if g.Nodes[id].Name == name.Name && g.Nodes[id].Type != graph.Index {
+					n, ok = g.Nodes[id], true
+					break
+				}
+			}
+		}
+		if !ok {
+			nodes = append(nodes, fmt.Sprintf("%s (not in the schema)", name))
+			continue
+		}
+		info := string(n.Type)
+		if n.Type == graph.Table {
+			info += ", " + formatRows(n.RowCount)
+		}
+		if n.Size != "" {
+			info += ", " + n.Size
+		}
+		deps := 0
+		for _, id := range g.GetDependents(n.ID) {
+			if g.Nodes[id] != nil && g.Nodes[id].Type != graph.Index {
+				deps++
+			}
+		}
+		if deps > 0 {
+			info += fmt.Sprintf(", %d dependents", deps)
+		}
+		nodes = append(nodes, fmt.Sprintf("%s (%s)", n.ID, info))
+	}
+	return nodes
+}
+
+// sortedNodeIDs returns the IDs of the graph nodes, sorted
+func sortedNodeIDs(g *graph.Graph) []string {
+	ids := make([]string, 0, len(g.Nodes))
+	for id := range g.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// listHeight is the number of query rows that fit: the screen less the header, the table header,
+// the footer and the detail pane
+func (s *topScreen) listHeight(height int) int {
+	rows := height - 5
+	if s.detail {
+		rows = (height - 5) / 2
+	}
+	if rows < 1 {
+		rows = 1
+	}
+	return rows
+}
+
+// topKeysHelp is the footer of the interactive screen
+const topKeysHelp = "↑/↓ select  PgUp/PgDn page  s/1-3 sort  Enter detail  J/K scroll  e explain  p pause  r refresh  q quit"
+
+// render draws the whole screen
+func (s *topScreen) render(width, height int) string {
+	if height < 6 {
+		height = 6
+	}
+	var lines []string
+	add := func(format string, args ...any) {
+		lines = append(lines, fitWidth(fmt.Sprintf(format, args...), width))
+	}
+
+	state := "LIVE"
+	switch {
+	case s.paused:
+		state = "PAUSED"
+	case s.fetching:
+		state = "LIVE (refreshing)"
+	}
+	updated := "never"
+	if !s.updated.IsZero() {
+		updated = s.updated.Format("15:04:05")
+	}
+	mode := "cumulative"
+	if topMode == "delta" {
+		mode = fmt.Sprintf("delta over %.1fs", s.elapsed.Seconds())
+		if s.elapsed == 0 {
+			mode = "delta (collecting the baseline)"
+		}
+	}
+	add("dbgraph top | Sort: %s | Mode: %s | Every %ds | %s | Updated %s", s.sort, mode, topInterval, state, updated)
+	switch {
+	case s.err != nil:
+		add("Error: %v", s.err)
+	case s.metrics != nil:
+		m := s.metrics
+		add("Connections: %d/%d (%s) | Locks: %d | Longest query: %s", m.UsedConns, m.MaxConns, m.ConnSaturation, m.ActiveLocks, m.LongestQuery)
+	default:
+		add("")
+	}
+
+	if topMode == "delta" {
+		add("%4s %7s %12s %10s %10s  %s", "RANK", "LOAD %", "MS/S", "CALLS/S", "AVG (ms)", "QUERY")
+	} else {
+		add("%4s %7s %12s %10s %10s  %s", "RANK", "LOAD %", "TIME (ms)", "CALLS", "AVG (ms)", "QUERY")
+	}
+	rows := s.listHeight(height)
+	if s.cursor < s.offset {
+		s.offset = s.cursor
+	}
+	if s.cursor >= s.offset+rows {
+		s.offset = s.cursor - rows + 1
+	}
+	for i := s.offset; i < s.offset+rows; i++ {
+		if i >= len(s.queries) {
+			if i == 0 && topMode == "delta" && s.elapsed > 0 {
+				add("No queries ran in the interval.")
+			} else if i == 0 && topMode != "delta" {
+				add("No queries recorded yet.")
+			} else {
+				add("")
+			}
+			continue
+		}
+		q := s.queries[i]
+		preview := strings.Join(strings.Fields(q.Query), " ")
+		line := fmt.Sprintf("%4d %7.2f %12.2f %10d %10.2f  %s", i+1, q.LoadPercent, q.TotalTime, q.Calls, q.AvgTime, preview)
+		if topMode == "delta" {
+			line = fmt.Sprintf("%4d %7.2f %12.2f %10.2f %10.2f  %s%s", i+1, q.LoadPercent, q.TimePerSec, q.CallsPerSec, q.AvgTime, deltaMarker(q), preview)
+		}
+		line = fitWidth(line, width)
+		if i == s.cursor {
+			line = "\x1b[7m" + line + strings.Repeat(" ", width-displayWidth(line)) + "\x1b[0m"
+		}
+		lines = append(lines, line)
+	}
+
+	if s.detail {
+		pane := s.detailLines(width)
+		paneRows := height - len(lines) - 2
+		if s.scroll > len(pane)-1 {
+			s.scroll = len(pane) - 1
+		}
+		if s.scroll < 0 {
+			s.scroll = 0
+		}
+		add("%s", strings.Repeat("-", width))
+		for i := 0; i < paneRows; i++ {
+			if s.scroll+i < len(pane) {
+				add("%s", pane[s.scroll+i])
+			} else {
+				add("")
+			}
+		}
+	}
+
+	for len(lines) < height-1 {
+		add("")
+	}
+	lines = append(lines[:height-1], "\x1b[2m"+fitWidth(topKeysHelp, width)+"\x1b[0m")
+	return "\x1b[H" + strings.Join(lines, "\x1b[K\r\n") + "\x1b[K\x1b[J"
+}
+
+// detailLines is the content of the detail pane: the full query, its graph nodes and EXPLAIN output
+func (s *topScreen) detailLines(width int) []string {
+	if s.cursor >= len(s.queries) {
+		return []string{"No query selected"}
+	}
+	q := s.queries[s.cursor]
+	lines := []string{fmt.Sprintf("QUERY %s | %d calls | %.2f ms total | %.2f ms avg", q.QueryID, q.Calls, q.TotalTime, q.AvgTime)}
+	if topMode == "delta" {
+		lines[0] += fmt.Sprintf(" (within the last %.1fs)", s.elapsed.Seconds())
+	}
+	for _, l := range strings.Split(strings.TrimSpace(q.Query), "\n") {
+		lines = append(lines, wrapText(strings.TrimRight(l, " \t\r"), width)...)
+	}
+
+	lines = append(lines, "", "REFERENCED NODES")
+	nodes := referencedNodes(s.g, q.Query)
+	if len(nodes) == 0 {
+		lines = append(lines, "  none found")
+	}
+	for _, n := range nodes {
+		lines = append(lines, "  "+n)
+	}
+
+	if s.explain != nil {
+		lines = append(lines, "")
+		lines = append(lines, s.explain...)
+	}
+	return lines
+}
+
+// wrapText splits a line into chunks of at most width display columns
+func wrapText(s string, width int) []string {
+	if width < 1 {
+		return []string{s}
+	}
+	var lines []string
+	for displayWidth(s) > width {
+		cut := fitWidth(s, width)
+		if cut == "" {
+			// A double-width rune wider than the pane: give it a line of its own rather than loop
+			_, size := utf8.DecodeRuneInString(s)
+			cut = s[:size]
+		}
+		lines = append(lines, cut)
+		s = s[len(cut):]
+	}
+	return append(lines, s)
+}
+
+// fitWidth truncates s to width display columns. ANSI escapes count for nothing.
+func fitWidth(s string, width int) string {
+	cols := 0
+	for i := 0; i < len(s); {
+		if s[i] == '\x1b' {
+			if end := strings.IndexByte(s[i:], 'm'); end >= 0 {
+				i += end + 1
+				continue
+			}
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		w := runeWidth(r)
+		if cols+w > width {
+			return s[:i]
+		}
+		cols += w
+		i += size
+	}
+	return s
+}
+
+// displayWidth counts the terminal columns of s
+func displayWidth(s string) int {
+	cols := 0
+	for i := 0; i < len(s); {
+		if s[i] == '\x1b' {
+			if end := strings.IndexByte(s[i:], 'm'); end >= 0 {
+				i += end + 1
+				continue
+			}
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		cols += runeWidth(r)
+		i += size
+	}
+	return cols
+}
+
+// runeWidth approximates the columns a rune takes: emoji and CJK are wide, control characters and
+// variation selectors take none
+func runeWidth(r rune) int {
+	switch {
+	case r < 0x20, r == 0x7f, r >= 0xfe00 && r <= 0xfe0f, r == 0x200d:
+		return 0
+	case r >= 0x1100 && r <= 0x115f, r >= 0x2e80 && r <= 0xa4cf, r >= 0xac00 && r <= 0xd7a3,
+		r >= 0xf900 && r <= 0xfaff, r >= 0xff00 && r <= 0xff60, r >= 0x1f300 && r <= 0x1faff:
+		return 2
+	}
+	return 1
+}
+
+// readKeys decodes key presses from the raw terminal until it closes
+func readKeys(r io.Reader, keys chan<- string) {
+	defer close(keys)
+	buf := make([]byte, 64)
+	for {
+		n, err := r.Read(buf)
+		if err != nil {
+			return
+		}
+		for _, k := range decodeKeys(buf[:n]) {
+			keys <- k
+		}
+	}
+}
+
+// escapeKeys maps the escape sequences of common terminals to key names
+var escapeKeys = map[string]string{
+	"\x1b[A": "up", "\x1b[B": "down", "\x1b[C": "right", "\x1b[D": "left",
+	"\x1bOA": "up", "\x1bOB": "down", "\x1bOC": "right", "\x1bOD": "left",
+	"\x1b[5~": "pgup", "\x1b[6~": "pgdown",
+	"\x1b[H": "home", "\x1b[F": "end", "\x1b[1~": "home", "\x1b[4~": "end", "\x1bOH": "home", "\x1bOF": "end",
+}
+
+// decodeKeys splits one read of terminal input into key names: printable characters as themselves,
+// escape sequences by name, and "enter", "esc" and "ctrl-<letter>"
+func decodeKeys(b []byte) []string {
+	var keys []string
+	for i := 0; i < len(b); {
+		switch c := b[i]; {
+		case c == 0x1b:
+			matched := false
+			for seq, name := range escapeKeys {
+				if bytes.HasPrefix(b[i:], []byte(seq)) {
+					keys, i, matched = append(keys, name), i+len(seq), true
+					break
+				}
+			}
+			if !matched {
+				// A lone Esc, or a sequence we do not know: skip it whole
+				j := i + 1
+				if j < len(b) && (b[j] == '[' || b[j] == 'O') {
+					for j++; j < len(b) && (b[j] < 0x40 || b[j] > 0x7e); j++ {
+					}
+					j++
+				}
+				if j == i+1 {
+					keys = append(keys, "esc")
+				}
+				i = j
+			}
+		case c == '\r' || c == '\n':
+			keys = append(keys, "enter")
+			i++
+		case c < 0x20:
+			keys = append(keys, "ctrl-"+string(rune('a'+c-1)))
+			i++
+		default:
+			r, size := utf8.DecodeRune(b[i:])
+			keys = append(keys, string(r))
+			i += size
+		}
+	}
+	return keys
+}
@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/alexanderritik/dbgraph/internal/graph"
+)
+
+func TestDecodeKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"printable", "qs1", []string{"q", "s", "1"}},
+		{"arrows", "\x1b[A\x1b[B", []string{"up", "down"}},
+		{"ss3 arrows", "\x1bOA\x1bOB", []string{"up", "down"}},
+		{"paging", "\x1b[5~\x1b[6~", []string{"pgup", "pgdown"}},
+		{"home and end", "\x1b[H\x1b[4~\x1bOF", []string{"home", "end", "end"}},
+		{"lone esc", "\x1b", []string{"esc"}},
+		{"esc then key", "\x1bq", []string{"esc", "q"}},
+		{"unknown csi skipped", "\x1b[1;5Aj", []string{"j"}},
+		{"truncated csi", "k\x1b[", []string{"k"}},
+		{"enter", "\r\n", []string{"enter", "enter"}},
+		{"ctrl keys", "\x03\x04\x15", []string{"ctrl-c", "ctrl-d", "ctrl-u"}},
+		{"multi-byte runes", "é→", []string{"é", "→"}},
+	}
+	for _, tt := range tests {
+		if got := decodeKeys([]byte(tt.input)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: decodeKeys(%q) = %q, want %q", tt.name, tt.input, got, tt.want)
+		}
+	}
+}
+
+// testTopScreen returns a screen showing three queries by total time. fetching is set so that no
+// key starts a fetch against the (absent) adapter.
+func testTopScreen(t *testing.T) *topScreen {
+	mode := topMode
+	topMode = "cumulative"
+	t.Cleanup(func() { topMode = mode })
+
+	s := &topScreen{sort: "total", fetching: true}
+	for _, q := range []graph.QueryStats{
+		{QueryID: "a", Calls: 10, TotalTime: 300, AvgTime: 30},
+		{QueryID: "b", Calls: 300, TotalTime: 200, AvgTime: 0.6},
+		{QueryID: "c", Calls: 2, TotalTime: 100, AvgTime: 50},
+	} {
+		s.queries = append(s.queries, graph.QueryDelta{QueryStats: q})
+	}
+	s.moveTo(0)
+	return s
+}
+
+func queryOrder(s *topScreen) string {
+	order := ""
+	for _, q := range s.queries {
+		order += q.QueryID
+	}
+	return order
+}
+
+func TestTopHandleKeySort(t *testing.T) {
+	s := testTopScreen(t)
+	s.handleKey("down", 24, nil, nil) // Select b
+
+	steps := []struct {
+		key, sort, order string
+	}{
+		{"s", "calls", "bac"},
+		{"s", "avg_time", "cab"},
+		{"s", "total", "abc"},
+		{"3", "avg_time", "cab"},
+		{"1", "total", "abc"},
+	}
+	for _, step := range steps {
+		s.handleKey(step.key, 24, nil, nil)
+		if s.sort != step.sort || queryOrder(s) != step.order {
+			t.Errorf("after %q: sort %s, order %s, want %s, %s", step.key, s.sort, queryOrder(s), step.sort, step.order)
+		}
+		if s.selected != "b" || s.queries[s.cursor].QueryID != "b" {
+			t.Errorf("after %q: expected b to stay selected, got %q at row %d", step.key, s.selected, s.cursor)
+		}
+	}
+	if !s.refetch {
+		t.Errorf("expected a sort change during a fetch to ask for another fetch")
+	}
+
+	// Same sort: nothing to do
+	s.refetch = false
+	s.handleKey("1", 24, nil, nil)
+	if s.refetch {
+		t.Errorf("expected no fetch when the sort is unchanged")
+	}
+}
+
+func TestTopHandleKeySelection(t *testing.T) {
+	s := testTopScreen(t)
+	steps := []struct {
+		key    string
+		cursor int
+	}{
+		{"up", 0}, // Clamped at the top
+		{"down", 1},
+		{"j", 2},
+		{"down", 2}, // Clamped at the bottom
+		{"home", 0},
+		{"end", 2},
+		{"k", 1},
+		{"pgup", 0},
+		{"pgdown", 2}, // A page is longer than the list
+		{"g", 0},
+		{"G", 2},
+	}
+	for _, step := range steps {
+		s.handleKey(step.key, 24, nil, nil)
+		if s.cursor != step.cursor || s.selected != s.queries[step.cursor].QueryID {
+			t.Errorf("after %q: cursor %d (%q), want %d", step.key, s.cursor, s.selected, step.cursor)
+		}
+	}
+
+	// A short screen pages by the rows that fit
+	s.moveTo(0)
+	s.handleKey("pgdown", 7, nil, nil)
+	if s.cursor != 1 {
+		t.Errorf("expected a one-row page on a 7-line screen, cursor at %d", s.cursor)
+	}
+
+	// The selected query keeps its row across refreshes, and a vanished one leaves the cursor in place
+	s.queries = append(s.queries[:0:0], s.queries[2], s.queries[0], s.queries[1])
+	s.reselect()
+	if s.cursor != 2 || s.selected != "b" {
+		t.Errorf("expected b to be followed to row 2, got %q at row %d", s.selected, s.cursor)
+	}
+	s.queries = s.queries[:2]
+	s.reselect()
+	if s.cursor != 1 || s.selected != "a" {
+		t.Errorf("expected the cursor to be clamped to a, got %q at row %d", s.selected, s.cursor)
+	}
+}
+
+func TestTopHandleKeyPanes(t *testing.T) {
+	s := testTopScreen(t)
+
+	s.handleKey("enter", 24, nil, nil)
+	if !s.detail {
+		t.Fatalf("expected Enter to open the detail pane")
+	}
+	s.handleKey("J", 24, nil, nil)
+	s.handleKey("ctrl-d", 24, nil, nil)
+	s.handleKey("K", 24, nil, nil)
+	if s.scroll != 1 {
+		t.Errorf("expected scroll 1, got %d", s.scroll)
+	}
+	s.handleKey("K", 24, nil, nil)
+	s.handleKey("ctrl-u", 24, nil, nil)
+	if s.scroll != 0 {
+		t.Errorf("expected the scroll to stop at 0, got %d", s.scroll)
+	}
+
+	// Selecting another query closes its EXPLAIN output
+	s.explain = []string{"Seq Scan on users"}
+	s.handleKey("down", 24, nil, nil)
+	if s.explain != nil {
+		t.Errorf("expected the EXPLAIN output to close with the selection change")
+	}
+
+	// Esc closes the EXPLAIN output, then the detail pane, then quits
+	s.explain, s.scroll = []string{"Seq Scan on users"}, 3
+	if s.handleKey("esc", 24, nil, nil) || s.explain != nil || !s.detail || s.scroll != 0 {
+		t.Errorf("expected Esc to close the EXPLAIN output only, got explain %v, detail %v", s.explain, s.detail)
+	}
+	if s.handleKey("esc", 24, nil, nil) || s.detail {
+		t.Errorf("expected Esc to close the detail pane")
+	}
+	if !s.handleKey("esc", 24, nil, nil) {
+		t.Errorf("expected Esc to quit with no pane open")
+	}
+
+	for _, k := range []string{"q", "ctrl-c"} {
+		if !s.handleKey(k, 24, nil, nil) {
+			t.Errorf("expected %q to quit", k)
+		}
+	}
+	s.handleKey("p", 24, nil, nil)
+	if !s.paused {
+		t.Errorf("expected p to pause")
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		width int
+		want  []string
+	}{
+		{"fits", "abc", 5, []string{"abc"}},
+		{"ascii", "abcdef", 4, []string{"abcd", "ef"}},
+		{"wide runes", "日本語", 4, []string{"日本", "語"}},
+		// A double-width rune on a one-column pane must not stall the wrap
+		{"wide rune wider than the pane", "a日b", 1, []string{"a", "日", "b"}},
+	}
+	for _, tt := range tests {
+		if got := wrapText(tt.input, tt.width); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: wrapText(%q, %d) = %q, want %q", tt.name, tt.input, tt.width, got, tt.want)
+		}
+	}
+}
@@ -2,11 +2,13 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
 	"github.com/alexanderritik/dbgraph/internal/adapters"
 	"github.com/alexanderritik/dbgraph/internal/graph"
+	"github.com/alexanderritik/dbgraph/internal/report"
 	"github.com/spf13/cobra"
 )
 
@@ -46,9 +48,6 @@ var traceCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		fmt.Println("🔍 TRACE: Ad-hoc SELECT")
-		fmt.Println(strings.Repeat("-", 80))
-
 		// Execute Trace
 		result, err := a.TraceQuery(traceQueryString)
 		if err != nil {
@@ -56,54 +55,66 @@ var traceCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		// 1. Latency
-		fmt.Println("⏱️  LATENCY")
-		fmt.Printf("Planning Time:   %.2f ms\n", result.PlanningTime)
-		fmt.Printf("Execution Time:  %.2f ms\n", result.ExecutionTime)
-		fmt.Printf("Total Time:      %.2f ms\n", result.TotalTime)
-		fmt.Println()
-
-		// 2. I/O & Memory
-		fmt.Println("💾 I/O & MEMORY (BUFFERS)")
-
-		hits := result.CacheHits
-		reads := result.DiskReads
-		totalIO := hits + reads
 		hitRate := 0.0
-		if totalIO > 0 {
-			hitRate = float64(hits) / float64(totalIO) * 100.0
+		if totalIO := result.CacheHits + result.DiskReads; totalIO > 0 {
+			hitRate = float64(result.CacheHits) / float64(totalIO) * 100.0
 		}
 
-		fmt.Printf("Cache Hits:      %d  (%.1f%%)\n", hits, hitRate)
-		if hits > 0 && reads == 0 {
-			fmt.Println("                 ⚡ (Fast: Data found in Shared Buffers)")
-		}
+		render(&report.Trace{Query: traceQueryString, HitRate: hitRate, Result: result}, printTrace)
+	},
+}
 
-		fmt.Printf("Disk Reads:      %d\n", reads)
-		if reads > 0 {
-			fmt.Println("                 💾 (Slow: Physical I/O required)")
-		}
+// printTrace renders latency, buffer usage and the plan tree
+func printTrace(w io.Writer, v any) error {
+	trace := v.(*report.Trace)
+	result := trace.Result
 
-		// Memory Usage (Approximation if we had it, for now placeholder if 0)
-		// fmt.Printf("Memory Usage:    %d KB\n", result.MemoryUsage/1024)
-		fmt.Println()
-
-		// 3. Execution Path
-		fmt.Println("🌳 EXECUTION PATH")
-		fmt.Println(strings.Repeat("-", 80))
-		printExplainTree(result.Root, "", true)
-		fmt.Println(strings.Repeat("-", 80))
-
-		// 4. Technical Detail / Tips
-		fmt.Println("🧪 Technical Detail: The \"Shared Buffers\" Secret")
-		if reads == 0 && hits > 0 {
-			fmt.Println("This query is \"warm\". All data was found in RAM (Shared Buffers).")
-		} else if reads > 0 {
-			fmt.Println("This query is \"cold\" or data is too large for cache. Physical disk I/O was required.")
-		} else {
-			fmt.Println("No I/O activity recorded (likely constants or metadata query).")
-		}
-	},
+	fmt.Fprintln(w, "🔍 TRACE: Ad-hoc SELECT")
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+
+	// 1. Latency
+	fmt.Fprintln(w, "⏱️  LATENCY")
+	fmt.Fprintf(w, "Planning Time:   %.2f ms\n", result.PlanningTime)
+	fmt.Fprintf(w, "Execution Time:  %.2f ms\n", result.ExecutionTime)
+	fmt.Fprintf(w, "Total Time:      %.2f ms\n", result.TotalTime)
+	fmt.Fprintln(w)
+
+	// 2. I/O & Memory
+	fmt.Fprintln(w, "💾 I/O & MEMORY (BUFFERS)")
+
+	hits := result.CacheHits
+	reads := result.DiskReads
+
+	fmt.Fprintf(w, "Cache Hits:      %d  (%.1f%%)\n", hits, trace.HitRate)
+	if hits > 0 && reads == 0 {
+		fmt.Fprintln(w, "                 ⚡ (Fast: Data found in Shared Buffers)")
+	}
+
+	fmt.Fprintf(w, "Disk Reads:      %d\n", reads)
+	if reads > 0 {
+		fmt.Fprintln(w, "                 💾 (Slow: Physical I/O required)")
+	}
+
+	// Memory Usage (Approximation if we had it, for now placeholder if 0)
+	// fmt.Fprintf(w, "Memory Usage:    %d KB\n", result.MemoryUsage/1024)
+	fmt.Fprintln(w)
+
+	// 3. Execution Path
+	fmt.Fprintln(w, "🌳 EXECUTION PATH")
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+	printExplainTree(w, result.Root, "", true)
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+
+	// 4. Technical Detail / Tips
+	fmt.Fprintln(w, "🧪 Technical Detail: The \"Shared Buffers\" Secret")
+	if reads == 0 && hits > 0 {
+		fmt.Fprintln(w, "This query is \"warm\". All data was found in RAM (Shared Buffers).")
+	} else if reads > 0 {
+		fmt.Fprintln(w, "This query is \"cold\" or data is too large for cache. Physical disk I/O was required.")
+	} else {
+		fmt.Fprintln(w, "No I/O activity recorded (likely constants or metadata query).")
+	}
+	return nil
 }
 
 func init() {
@@ -112,7 +123,7 @@ func init() {
 }
 
 // printExplainTree recursively prints the plan tree
-func printExplainTree(node *graph.ExplainNode, prefix string, isLast bool) {
+func printExplainTree(w io.Writer, node *graph.ExplainNode, prefix string, isLast bool) {
 	if node == nil {
 		return
 	}
@@ -142,7 +153,7 @@ func printExplainTree(node *graph.ExplainNode, prefix string, isLast bool) {
 		}
 	}
 
-	fmt.Printf("%s%s %s %s\n", prefix, marker, desc, costStr)
+	fmt.Fprintf(w, "%s%s %s %s\n", prefix, marker, desc, costStr)
 
 	// Additional Details (Filter, Index Cond) indented
 
@@ -164,16 +175,16 @@ func printExplainTree(node *graph.ExplainNode, prefix string, isLast bool) {
 		childPrefix += "|   "
 	}
 
-	// Warning for Seq Scan
-	if node.Type == "Seq Scan" {
-		fmt.Printf("%s⚠️  Warning: Full table scan.\n", childPrefix)
+	// Warning for Seq Scan (Postgres) / Table scan (MySQL)
+	if node.Type == "Seq Scan" || node.Type == "Table scan" {
+		fmt.Fprintf(w, "%s⚠️  Warning: Full table scan.\n", childPrefix)
 	}
 
 	if node.IndexCond != "" {
-		fmt.Printf("%sIndex Cond: %s\n", childPrefix, node.IndexCond)
+		fmt.Fprintf(w, "%sIndex Cond: %s\n", childPrefix, node.IndexCond)
 	}
 	if node.Filter != "" {
-		fmt.Printf("%sFilter: %s\n", childPrefix, node.Filter)
+		fmt.Fprintf(w, "%sFilter: %s\n", childPrefix, node.Filter)
 	}
 
 	// Strategies / Extra info
@@ -182,6 +193,6 @@ func printExplainTree(node *graph.ExplainNode, prefix string, isLast bool) {
 	// Children
 	count := len(node.Plans)
 	for i, child := range node.Plans {
-		printExplainTree(child, childPrefix, i == count-1)
+		printExplainTree(w, child, childPrefix, i == count-1)
 	}
 }
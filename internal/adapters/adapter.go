@@ -1,10 +1,14 @@
 package adapters
 
 import (
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/alexanderritik/dbgraph/internal/graph"
+	"github.com/alexanderritik/dbgraph/internal/sqlparse"
 )
 
 // Adapter is the interface that all database adapters must implement
@@ -19,13 +23,343 @@ type Adapter interface {
 	TraceQuery(query string) (*graph.TraceResult, error)
 }
 
+// ServerVersioner is implemented by adapters that can report the database server version
+type ServerVersioner interface {
+	ServerVersion() (string, error)
+}
+
+// DefaultSchemaProvider is implemented by adapters whose unqualified names do not resolve to
+// "public": the connected database on MySQL, "main" on SQLite
+type DefaultSchemaProvider interface {
+	DefaultSchema() string
+}
+
+// FunctionDependencyFetcher is implemented by adapters that can find the objects calling a function
+type FunctionDependencyFetcher interface {
+	GetFunctionDependencies(schema, function string) ([]graph.ColumnDependency, error)
+}
+
+// TypeDependencyFetcher is implemented by adapters that can find the objects using an enum,
+// composite type or domain
+type TypeDependencyFetcher interface {
+	GetTypeDependencies(schema, name string) ([]graph.ColumnDependency, error)
+}
+
+// IndexDependencyFetcher is implemented by adapters that can find the constraints and foreign keys
+// relying on an index
+type IndexDependencyFetcher interface {
+	GetIndexDependencies(schema, name string) ([]graph.ColumnDependency, error)
+}
+
+// ViewColumnMapper is implemented by adapters that can tell which output columns of a view
+// carry a column the view reads, so column impact can follow view-on-view chains
+type ViewColumnMapper interface {
+	DerivedViewColumns(schema, view, column string) ([]string, error)
+}
+
+// DryRunner is implemented by adapters that can execute DDL inside a transaction that is always rolled back
+type DryRunner interface {
+	DryRun(statements []string) ([]graph.DryRunResult, error)
+}
+
+// CascadeCounter is implemented by adapters that can measure the rows a DELETE reaches. Both
+// methods run inside a read-only transaction, so a filter can never modify data.
+type CascadeCounter interface {
+	// EstimateRows returns the planner's estimate of the rows of a table matching a filter
+	EstimateRows(schema, table, where string) (int64, error)
+	// CountCascade replaces the estimates of a cascade tree with COUNTs stopping at limit rows per table
+	CountCascade(g *graph.Graph, root *graph.CascadeNode, where string, limit int64) error
+}
+
+// LockInspector is implemented by adapters that can list the sessions holding or waiting for locks
+type LockInspector interface {
+	GetLockSessions() ([]graph.LockSession, error)
+}
+
+// ErrUnsupported is returned (wrapped) when a database cannot provide a capability,
+// e.g. query statistics on SQLite. Check it with errors.Is.
+var ErrUnsupported = errors.New("not supported by this database")
+
 // NewAdapter creates a new adapter based on the connection string scheme
 func NewAdapter(connString string) (Adapter, error) {
 	if strings.HasPrefix(connString, "postgres://") || strings.HasPrefix(connString, "postgresql://") {
 		return NewPostgresAdapter(), nil
 	}
-	// Future: Add MySQL support here
-	// if strings.HasPrefix(connString, "mysql://") { ... }
+	if strings.HasPrefix(connString, "mysql://") || strings.HasPrefix(connString, "mariadb://") {
+		return NewMySQLAdapter(), nil
+	}
+	if strings.HasPrefix(connString, "sqlite://") {
+		return NewSQLiteAdapter(), nil
+	}
+	if strings.HasPrefix(connString, "file://") {
+		return NewFileAdapter(), nil
+	}
+	if strings.HasPrefix(connString, "snapshot://") {
+		return NewSnapshotAdapter(), nil
+	}
 
 	return nil, fmt.Errorf("unsupported database scheme in connection string: %s", connString)
 }
+
+// addViewLineage parses the SELECT of a view and records which source columns feed each of its
+// output columns. reads lists the columns of each relation the view reads; references to other
+// columns are dropped. Relations the query leaves unqualified (on the search path) match by name.
+// names is the view's explicit column list, if any.
+func addViewLineage(g *graph.Graph, view sqlparse.QualifiedName, query string, names []string, reads map[sqlparse.QualifiedName][]string) {
+	resolve := func(rel sqlparse.QualifiedName) (sqlparse.QualifiedName, bool) {
+		if _, ok := reads[rel]; ok {
+			return rel, true
+		}
+		var match sqlparse.QualifiedName
+		found := 0
+		for r := range reads {
+			if strings.EqualFold(r.Name, rel.Name) {
+				match = r
+				found++
+			}
+		}
+		return match, found == 1
+	}
+	columnsOf := func(rel sqlparse.QualifiedName) []string {
+		if r, ok := resolve(rel); ok {
+			return reads[r]
+		}
+		return nil
+	}
+
+	for _, out := range sqlparse.ColumnLineage(sqlparse.Tokenize(query), names, view.Schema, columnsOf) {
+		for _, src := range out.Sources {
+			rel, _ := resolve(src.Relation)
+			g.AddLineage(graph.ColumnID(rel.Schema, rel.Name, src.Column), graph.ColumnID(view.Schema, view.Name, out.Column))
+		}
+	}
+}
+
+// addBodyReferences links a function (or a trigger with an inline body) to the relations its body
+// reads or writes and the functions it calls. Only objects already in the graph are linked, so
+// local variables, CTEs and built-in functions are ignored.
+func addBodyReferences(g *graph.Graph, owner sqlparse.QualifiedName, body string) {
+	parsed := sqlparse.ParseBody(body, owner.Schema)
+	for _, ref := range parsed.References {
+		node, ok := lookupNode(g, ref.Name)
+		if !ok || (node.Type != graph.Table && node.Type != graph.View) {
+			continue
+		}
+		g.AddFunctionAccess(owner.Schema, owner.Name, node.Schema, node.Name, ref.Write, string(ref.Confidence))
+	}
+	for _, call := range parsed.Calls {
+		node, ok := lookupNode(g, call)
+		if !ok || node.Type != graph.Function || node.ID == owner.String() {
+			continue
+		}
+		addEdgeOnce(g, owner.Schema, owner.Name, node.Schema, node.Name, graph.FunctionCall)
+	}
+}
+
+// routine is a function or procedure with its parsed body
+type routine struct {
+	Name sqlparse.QualifiedName
+	Body *sqlparse.Body
+}
+
+// routineCache holds the routines of a database, fetched and parsed on first use: impact walks
+// many columns and tables, and each lookup would otherwise re-read every body
+type routineCache struct {
+	once     sync.Once
+	routines []routine
+	err      error
+}
+
+// get returns the cached routines, calling fetch the first time
+func (c *routineCache) get(fetch func() ([]routine, error)) ([]routine, error) {
+	c.once.Do(func() { c.routines, c.err = fetch() })
+	return c.routines, c.err
+}
+
+// codeReference describes a routine body dependency for ColumnDependency.Detail
+func codeReference(access string, confidence sqlparse.Confidence) string {
+	return fmt.Sprintf("Code Reference (function body %s, confidence: %s)", access, confidence)
+}
+
+// routineTableDependencies lists the routines whose body reads or writes a relation
+func routineTableDependencies(routines []routine, table sqlparse.QualifiedName) []graph.ColumnDependency {
+	var deps []graph.ColumnDependency
+	for _, r := range routines {
+		if ref, ok := r.Body.Reference(table); ok {
+			deps = appendDependency(deps, graph.ColumnDependency{
+				Schema: r.Name.Schema,
+				Name:   r.Name.Name,
+				Type:   "FUNCTION",
+				Detail: codeReference(ref.Access(), ref.Confidence),
+			})
+		}
+	}
+	return deps
+}
+
+// routineColumnDependencies lists the routines whose body touches table.column
+func routineColumnDependencies(routines []routine, table sqlparse.QualifiedName, column string) []graph.ColumnDependency {
+	var deps []graph.ColumnDependency
+	for _, r := range routines {
+		if ref, ok := r.Body.UsesColumn(table, column); ok {
+			deps = appendDependency(deps, graph.ColumnDependency{
+				Schema: r.Name.Schema,
+				Name:   r.Name.Name,
+				Type:   "FUNCTION",
+				Detail: codeReference(ref.Access(), ref.Confidence),
+			})
+		}
+	}
+	return deps
+}
+
+// routineCallers lists the routines whose body calls a function
+func routineCallers(routines []routine, function sqlparse.QualifiedName) []graph.ColumnDependency {
+	var deps []graph.ColumnDependency
+	for _, r := range routines {
+		if r.Name == function {
+			continue
+		}
+		if confidence, ok := r.Body.CallsFunction(function); ok {
+			deps = appendDependency(deps, graph.ColumnDependency{
+				Schema: r.Name.Schema,
+				Name:   r.Name.Name,
+				Type:   "FUNCTION",
+				Detail: codeReference("calls", confidence),
+			})
+		}
+	}
+	return deps
+}
+
+// lookupNode finds a node by name, falling back to a case-insensitive match for databases
+// (MySQL, SQLite) that keep the declared case of unquoted identifiers
+func lookupNode(g *graph.Graph, name sqlparse.QualifiedName) (*graph.Node, bool) {
+	if node, ok := g.Nodes[name.String()]; ok {
+		return node, true
+	}
+	for id, node := range g.Nodes {
+		if strings.EqualFold(id, name.String()) {
+			return node, true
+		}
+	}
+	return nil, false
+}
+
+// addEdgeOnce adds an edge unless one of the same type already links the two nodes. A node never
+// gets an edge to itself: a self-loop would be reported as a circular dependency.
+func addEdgeOnce(g *graph.Graph, sourceSchema, sourceName, targetSchema, targetName string, depType graph.DependencyType) {
+	sourceID := fmt.Sprintf("%s.%s", sourceSchema, sourceName)
+	targetID := fmt.Sprintf("%s.%s", targetSchema, targetName)
+	if sourceID == targetID {
+		return
+	}
+	for _, e := range g.Edges[sourceID] {
+		if e.Type == depType && e.TargetID == targetID {
+			return
+		}
+	}
+	g.AddEdge(sourceSchema, sourceName, targetSchema, targetName, depType, "", "")
+}
+
+// userTypeNodes maps sqlparse type kinds to graph node types
+var userTypeNodes = map[string]graph.NodeType{
+	sqlparse.EnumType:      graph.Enum,
+	sqlparse.CompositeType: graph.CompositeType,
+	sqlparse.DomainType:    graph.Domain,
+}
+
+// typeDependency describes an object using a type; columns are the columns (or attributes) of that type
+func typeDependency(schema, name string, kind graph.NodeType, columns []string) graph.ColumnDependency {
+	dep := graph.ColumnDependency{Schema: schema, Name: name, Type: string(kind)}
+	switch kind {
+	case graph.Table, graph.View:
+		dep.Detail = fmt.Sprintf("Columns: %s", strings.Join(columns, ", "))
+	case graph.CompositeType:
+		dep.Detail = fmt.Sprintf("Attributes: %s", strings.Join(columns, ", "))
+	case graph.Domain:
+		dep.Detail = "Domain over this type"
+	case graph.Function:
+		dep.Detail = "Function signature uses this type"
+	}
+	return dep
+}
+
+// graphTypeDependencies derives the objects using a type from the TYPE_USAGE edges of a graph
+func graphTypeDependencies(g *graph.Graph, schema, name string) ([]graph.ColumnDependency, error) {
+	targetID := fmt.Sprintf("%s.%s", schema, name)
+	node, ok := g.Nodes[targetID]
+	if !ok || (node.Type != graph.Enum && node.Type != graph.CompositeType && node.Type != graph.Domain) {
+		return nil, fmt.Errorf("type '%s' not found", targetID)
+	}
+
+	// Map iteration order is random; keep the report stable
+	sources := make([]string, 0, len(g.Edges))
+	for src := range g.Edges {
+		sources = append(sources, src)
+	}
+	sort.Strings(sources)
+
+	var deps []graph.ColumnDependency
+	for _, src := range sources {
+		for _, e := range g.Edges[src] {
+			if e.Type != graph.TypeUsage || e.TargetID != targetID {
+				continue
+			}
+			user := g.Nodes[src]
+			var columns []string
+			if cols := e.MetaData["columns"]; cols != "" {
+				columns = strings.Split(cols, ",")
+			}
+			deps = append(deps, typeDependency(user.Schema, user.Name, user.Type, columns))
+		}
+	}
+	return deps, nil
+}
+
+// coveringIndexDetail prefixes the dependency of an FK whose columns only one index covers
+const coveringIndexDetail = "Only index covering FK"
+
+// graphIndexDependencies derives what relies on an index from a graph: the constraint it enforces,
+// the foreign keys referencing its key, and the foreign keys of its table no other index covers
+func graphIndexDependencies(g *graph.Graph, schema, name string) ([]graph.ColumnDependency, error) {
+	targetID := fmt.Sprintf("%s.%s", schema, name)
+	node, ok := g.Nodes[targetID]
+	if !ok || node.Index == nil {
+		return nil, fmt.Errorf("index '%s' not found", targetID)
+	}
+
+	var deps []graph.ColumnDependency
+	if c := node.Index.Constraint; c != "" {
+		detail := "Constraint enforced by this index"
+		if node.Index.Primary {
+			detail = "Primary key enforced by this index"
+		}
+		deps = append(deps, graph.ColumnDependency{Schema: schema, Name: c, Type: "CONSTRAINT", Detail: detail})
+	}
+	for _, e := range g.ReferencingForeignKeys(targetID) {
+		src := g.Nodes[e.SourceID]
+		deps = append(deps, graph.ColumnDependency{
+			Schema: src.Schema,
+			Name:   src.Name,
+			Type:   "FOREIGN_KEY",
+			Detail: fmt.Sprintf("Constraint: %s", e.ConstraintName),
+		})
+	}
+	for _, e := range g.SupportedForeignKeys(targetID) {
+		src := g.Nodes[e.SourceID]
+		deps = append(deps, graph.ColumnDependency{
+			Schema: src.Schema,
+			Name:   src.Name,
+			Type:   "FOREIGN_KEY",
+			Detail: fmt.Sprintf("%s %s (%s)", coveringIndexDetail, e.ConstraintName, strings.ReplaceAll(e.MetaData["fk_columns"], ",", ", ")),
+		})
+	}
+	return deps, nil
+}
+
+// IsCoveringIndexDependency reports whether a dependency from GetIndexDependencies is an FK that
+// loses its only supporting index, rather than one that stops the index from being dropped
+func IsCoveringIndexDependency(dep graph.ColumnDependency) bool {
+	return dep.Type == "FOREIGN_KEY" && strings.HasPrefix(dep.Detail, coveringIndexDetail)
+}
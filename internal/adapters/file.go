@@ -0,0 +1,527 @@
+package adapters
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/alexanderritik/dbgraph/internal/graph"
+	"github.com/alexanderritik/dbgraph/internal/sqlparse"
+)
+
+// FileAdapter builds the graph from a DDL script (e.g. pg_dump --schema-only output)
+// without connecting to a database
+type FileAdapter struct {
+	Path   string
+	Schema *sqlparse.Schema
+	bodies routineCache
+}
+
+// NewFileAdapter creates a new file adapter
+func NewFileAdapter() *FileAdapter {
+	return &FileAdapter{}
+}
+
+// filePath extracts the path from file://path (file:///abs/path for absolute paths)
+func filePath(connString string) (string, error) {
+	path := strings.TrimPrefix(connString, "file://")
+	if path == "" || path == connString {
+		return "", fmt.Errorf("invalid file connection string: %s (expected file://schema.sql)", connString)
+	}
+	return path, nil
+}
+
+// Connect reads and parses the schema file
+func (f *FileAdapter) Connect(connString string) error {
+	path, err := filePath(connString)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to read schema file: %v\n", err)
+		return err
+	}
+
+	f.Path = path
+	f.Schema = sqlparse.ParseSchema(string(data))
+	return nil
+}
+
+// Close is a no-op; the file is read once in Connect
+func (f *FileAdapter) Close() {}
+
+// routines parses the body of every function and procedure in the file, once
+func (f *FileAdapter) routines() []routine {
+	routines, _ := f.bodies.get(func() ([]routine, error) {
+		routines := make([]routine, 0, len(f.Schema.Functions))
+		for _, fn := range f.Schema.Functions {
+			routines = append(routines, routine{Name: fn.QualifiedName, Body: sqlparse.ParseBody(fn.Body, fn.Schema)})
+		}
+		return routines, nil
+	})
+	return routines
+}
+
+// view looks up a parsed view by name
+func (f *FileAdapter) view(name sqlparse.QualifiedName) *sqlparse.View {
+	for _, v := range f.Schema.Views {
+		if v.QualifiedName == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// relationColumns lists the columns of a parsed table or the output columns of a parsed view
+func (f *FileAdapter) relationColumns(name sqlparse.QualifiedName) []string {
+	if t := f.Schema.Table(name); t != nil {
+		cols := make([]string, len(t.Columns))
+		for i, c := range t.Columns {
+			cols[i] = c.Name
+		}
+		return cols
+	}
+	if v := f.view(name); v != nil {
+		if len(v.Columns) > 0 {
+			return v.Columns
+		}
+		var cols []string
+		for _, c := range sqlparse.SelectList(v.Tokens) {
+			cols = append(cols, c.Name)
+		}
+		return cols
+	}
+	return nil
+}
+
+// function looks up a parsed function by name
+func (f *FileAdapter) function(name sqlparse.QualifiedName) *sqlparse.Function {
+	for _, fn := range f.Schema.Functions {
+		if fn.QualifiedName == name {
+			return fn
+		}
+	}
+	return nil
+}
+
+// FetchSchema converts the parsed statements into the same nodes and edges PostgresAdapter produces
+func (f *FileAdapter) FetchSchema(g *graph.Graph) error {
+	if f.Schema == nil {
+		return fmt.Errorf("schema file not loaded")
+	}
+	s := f.Schema
+
+	// 1. Tables & Views (Nodes). Sizes and row counts are unknown offline.
+	for _, t := range s.Tables {
+		g.AddNode(t.Schema, t.Name, graph.Table, "", 0)
+		if len(t.PrimaryKey) > 0 {
+			g.SetPrimaryKey(t.Schema, t.Name, t.PrimaryKey)
+		}
+	}
+	for _, v := range s.Views {
+		g.AddNode(v.Schema, v.Name, graph.View, "", 0)
+	}
+
+	// 1.5 Indexes. Sizes and scan counts are unknown offline.
+	for _, idx := range s.Indexes {
+		g.AddIndexNode(idx.Table.Schema, idx.Table.Name, idx.Name, "", graph.IndexInfo{
+			Method:      idx.Method,
+			Unique:      idx.Unique,
+			Primary:     idx.Primary,
+			Constraint:  idx.Constraint,
+			Columns:     idx.Columns,
+			Expressions: idx.Expressions,
+			Predicate:   idx.Predicate,
+		})
+	}
+
+	// 1.6 Types, Enums, Domains & Sequences, with the columns using them
+	f.addTypesAndSequences(g)
+
+	// 2. Foreign Keys (Table Dependencies)
+	for _, fk := range s.ForeignKeys {
+		g.AddEdge(fk.Table.Schema, fk.Table.Name, fk.RefTable.Schema, fk.RefTable.Name, graph.ForeignKey, fk.Name, fk.DeleteRule)
+
+		edges := g.Edges[fk.Table.String()]
+		lastEdge := edges[len(edges)-1]
+		lastEdge.MetaData = map[string]string{"fk_columns": strings.Join(fk.Columns, ",")}
+		if refCols := f.referencedColumns(fk); len(refCols) > 0 {
+			lastEdge.MetaData["referenced_columns"] = strings.Join(refCols, ",")
+		}
+		if idx := f.referencedIndex(fk); idx != nil {
+			lastEdge.MetaData["referenced_index"] = fmt.Sprintf("%s.%s", idx.Table.Schema, idx.Name)
+		}
+		if t := s.Table(fk.Table); t != nil {
+			var nullable []string
+			for _, c := range fk.Columns {
+				if col := t.Column(c); col != nil && !col.NotNull {
+					nullable = append(nullable, c)
+				}
+			}
+			if len(nullable) > 0 {
+				lastEdge.MetaData["nullable_columns"] = strings.Join(nullable, ",")
+			}
+		}
+	}
+
+	// 3. View Dependencies
+	for _, v := range s.Views {
+		for _, ref := range v.References {
+			if ref == v.QualifiedName {
+				continue
+			}
+			g.AddEdge(v.Schema, v.Name, ref.Schema, ref.Name, graph.ViewDepends, "", "")
+		}
+
+		reads := make(map[sqlparse.QualifiedName][]string)
+		for _, ref := range v.References {
+			if cols := f.relationColumns(ref); len(cols) > 0 {
+				reads[ref] = cols
+			}
+		}
+		addViewLineage(g, v.QualifiedName, v.Query, v.Columns, reads)
+	}
+
+	// 4. Functions & Procedures. Nodes first, so calls between them resolve in any order.
+	for _, fn := range s.Functions {
+		g.AddNode(fn.Schema, fn.Name, graph.Function, "", 0)
+	}
+	for _, fn := range s.Functions {
+		addBodyReferences(g, fn.QualifiedName, fn.Body)
+	}
+
+	// 5. Triggers: Trigger -> Table it fires on, Trigger -> Function it executes
+	for _, trg := range s.Triggers {
+		g.AddTrigger(trg.Table.Schema, trg.Table.Name, trg.Name)
+		g.AddNode(trg.Function.Schema, trg.Function.Name, graph.Function, "", 0)
+		addEdgeOnce(g, trg.Table.Schema, graph.TriggerKey(trg.Table.Name, trg.Name), trg.Function.Schema, trg.Function.Name, graph.FunctionCall)
+	}
+
+	// 6. Table Inheritance (Partitions)
+	for _, t := range s.Tables {
+		if t.Parent != nil {
+			g.AddEdge(t.Schema, t.Name, t.Parent.Schema, t.Parent.Name, graph.Inheritance, "", "")
+		}
+	}
+
+	return nil
+}
+
+// addTypesAndSequences adds user-defined types and sequences with their edges: columns, attributes
+// and domains using a type, column defaults drawing from a sequence, and sequence ownership
+func (f *FileAdapter) addTypesAndSequences(g *graph.Graph) {
+	s := f.Schema
+	for _, t := range s.Types {
+		g.AddNode(t.Schema, t.Name, userTypeNodes[t.Kind], "", 0)
+	}
+	for _, seq := range s.Sequences {
+		g.AddNode(seq.Schema, seq.Name, graph.Sequence, "", 0)
+	}
+
+	usesType := func(user sqlparse.QualifiedName, col sqlparse.Column) {
+		if typ := s.Type(col.TypeRef); typ != nil {
+			g.AddColumnEdge(user.Schema, user.Name, typ.Schema, typ.Name, graph.TypeUsage, col.Name)
+		}
+	}
+	for _, t := range s.Tables {
+		for _, col := range t.Columns {
+			usesType(t.QualifiedName, col)
+			if col.Sequence != nil {
+				g.AddNode(col.Sequence.Schema, col.Sequence.Name, graph.Sequence, "", 0)
+				g.AddColumnEdge(t.Schema, t.Name, col.Sequence.Schema, col.Sequence.Name, graph.SequenceDefault, col.Name)
+			}
+		}
+	}
+	for _, t := range s.Types {
+		for _, attr := range t.Attributes {
+			usesType(t.QualifiedName, attr)
+		}
+		if base := s.Type(t.BaseRef); t.Kind == sqlparse.DomainType && base != nil {
+			g.AddColumnEdge(t.Schema, t.Name, base.Schema, base.Name, graph.TypeUsage, "")
+		}
+	}
+	for _, seq := range s.Sequences {
+		if seq.OwnedBy != nil {
+			owner := seq.OwnedBy.Relation
+			g.AddColumnEdge(seq.Schema, seq.Name, owner.Schema, owner.Name, graph.SequenceOwned, seq.OwnedBy.Column)
+		}
+	}
+}
+
+// GetMetrics is not available: there is no server behind a schema file
+func (f *FileAdapter) GetMetrics() (*graph.DBMetrics, error) {
+	return nil, fmt.Errorf("%w: schema files have no server activity", ErrUnsupported)
+}
+
+// referencesColumn reports whether an FK uses the given column of its referenced table
+func (f *FileAdapter) referencesColumn(fk *sqlparse.ForeignKey, column string) bool {
+	for _, c := range f.referencedColumns(fk) {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// referencedColumns returns the columns a foreign key references: its own list, or the primary key
+// of the referenced table when it names none
+func (f *FileAdapter) referencedColumns(fk *sqlparse.ForeignKey) []string {
+	if len(fk.RefColumns) > 0 {
+		return fk.RefColumns
+	}
+	if t := f.Schema.Table(fk.RefTable); t != nil {
+		return t.PrimaryKey
+	}
+	return nil
+}
+
+// referencedIndex finds the unique index enforcing the key an FK references: a plain, non-partial
+// unique index on exactly the referenced columns, in any order
+func (f *FileAdapter) referencedIndex(fk *sqlparse.ForeignKey) *sqlparse.Index {
+	refCols := f.referencedColumns(fk)
+	for _, idx := range f.Schema.Indexes {
+		if idx.Table != fk.RefTable || !idx.Unique || idx.Predicate != "" || len(idx.Expressions) > 0 ||
+			len(idx.Columns) != len(refCols) {
+			continue
+		}
+		matches := true
+		for _, c := range refCols {
+			found := false
+			for _, ic := range idx.Columns {
+				found = found || ic == c
+			}
+			matches = matches && found
+		}
+		if matches {
+			return idx
+		}
+	}
+	return nil
+}
+
+// GetColumnDependencies identifies all objects that depend on a specific column
+func (f *FileAdapter) GetColumnDependencies(schema, table, column string) ([]graph.ColumnDependency, error) {
+	if f.Schema == nil {
+		return nil, fmt.Errorf("schema file not loaded")
+	}
+	name := sqlparse.QualifiedName{Schema: schema, Name: table}
+	t := f.Schema.Table(name)
+	if (t == nil || t.Column(column) == nil) && f.view(name) == nil {
+		return nil, fmt.Errorf("column '%s.%s' not found", table, column)
+	}
+	var deps []graph.ColumnDependency
+
+	// 1. Foreign Keys referencing this column
+	for _, fk := range f.Schema.ForeignKeys {
+		if fk.RefTable == name && f.referencesColumn(fk, column) {
+			deps = append(deps, graph.ColumnDependency{
+				Schema: fk.Table.Schema,
+				Name:   fk.Table.Name,
+				Type:   "FOREIGN_KEY",
+				Detail: fmt.Sprintf("Constraint: %s", fk.Name),
+			})
+		}
+	}
+
+	// 2. Indexes using this column
+	for _, idx := range f.Schema.Indexes {
+		if idx.Table != name {
+			continue
+		}
+		covers := false
+		for _, c := range idx.Columns {
+			covers = covers || c == column
+		}
+		for _, expr := range idx.Expressions {
+			covers = covers || mentionsIdentifier(expr, column)
+		}
+		if covers {
+			deps = append(deps, graph.ColumnDependency{
+				Schema: schema,
+				Name:   idx.Name,
+				Type:   "INDEX",
+				Detail: "Index covers this column",
+			})
+		}
+	}
+
+	// 3. Views reading the table that mention the column
+	for _, v := range f.Schema.Views {
+		if containsName(v.References, name) && mentionsIdentifier(v.Query, column) {
+			deps = appendDependency(deps, graph.ColumnDependency{
+				Schema: v.Schema,
+				Name:   v.Name,
+				Type:   "VIEW",
+				Detail: "Deep Dependency (view definition)",
+			})
+		}
+	}
+
+	// 4. CHECK constraints referencing the column
+	if t != nil {
+		for _, check := range t.Checks {
+			if mentionsIdentifier(check.Expr, column) {
+				deps = appendDependency(deps, graph.ColumnDependency{
+					Schema: schema,
+					Name:   check.Name,
+					Type:   "CONSTRAINT",
+					Detail: "Check Constraint",
+				})
+			}
+		}
+	}
+
+	// 5. Function Bodies (Soft Dependencies)
+	for _, dep := range routineColumnDependencies(f.routines(), name, column) {
+		deps = appendDependency(deps, dep)
+	}
+
+	return deps, nil
+}
+
+// DerivedViewColumns maps the column through the parsed view query
+func (f *FileAdapter) DerivedViewColumns(schema, view, column string) ([]string, error) {
+	if f.Schema == nil {
+		return nil, fmt.Errorf("schema file not loaded")
+	}
+	v := f.view(sqlparse.QualifiedName{Schema: schema, Name: view})
+	if v == nil {
+		return nil, fmt.Errorf("view '%s.%s' not found", schema, view)
+	}
+	return sqlparse.DerivedColumns(v.Tokens, v.Columns, column), nil
+}
+
+// GetTableDependencies identifies all objects that depend on a specific table
+func (f *FileAdapter) GetTableDependencies(schema, table string) ([]graph.ColumnDependency, error) {
+	if f.Schema == nil {
+		return nil, fmt.Errorf("schema file not loaded")
+	}
+	name := sqlparse.QualifiedName{Schema: schema, Name: table}
+	var deps []graph.ColumnDependency
+
+	// 1. Foreign Keys pointing TO this table
+	for _, fk := range f.Schema.ForeignKeys {
+		if fk.RefTable == name && fk.Table != name {
+			deps = append(deps, graph.ColumnDependency{
+				Schema: fk.Table.Schema,
+				Name:   fk.Table.Name,
+				Type:   "FOREIGN_KEY",
+				Detail: fmt.Sprintf("Constraint: %s", fk.Name),
+			})
+		}
+	}
+
+	// 2. Views & Triggers
+	for _, v := range f.Schema.Views {
+		if containsName(v.References, name) {
+			deps = appendDependency(deps, graph.ColumnDependency{
+				Schema: v.Schema,
+				Name:   v.Name,
+				Type:   "VIEW",
+				Detail: "Hard Dependency (view definition)",
+			})
+		}
+	}
+	for _, trg := range f.Schema.Triggers {
+		if trg.Table == name {
+			deps = appendDependency(deps, graph.ColumnDependency{
+				Schema: trg.Table.Schema,
+				Name:   trg.Name,
+				Type:   "TRIGGER",
+				Detail: "Trigger on table",
+			})
+		}
+	}
+
+	// 3. Function Bodies (Soft Dependencies)
+	for _, dep := range routineTableDependencies(f.routines(), name) {
+		deps = appendDependency(deps, dep)
+	}
+
+	return deps, nil
+}
+
+// containsName reports whether names includes name
+func containsName(names []sqlparse.QualifiedName, name sqlparse.QualifiedName) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// GetTopQueries is not available: a schema file has no workload
+func (f *FileAdapter) GetTopQueries(limit int, sortBy string) ([]graph.QueryStats, error) {
+	return nil, fmt.Errorf("%w: schema files have no query statistics", ErrUnsupported)
+}
+
+// TraceQuery is not available: queries cannot be executed without a database
+func (f *FileAdapter) TraceQuery(query string) (*graph.TraceResult, error) {
+	return nil, fmt.Errorf("%w: queries cannot be traced against a schema file", ErrUnsupported)
+}
+
+// GetFunctionDependencies identifies the triggers, views and function bodies that call a function
+func (f *FileAdapter) GetFunctionDependencies(schema, function string) ([]graph.ColumnDependency, error) {
+	if f.Schema == nil {
+		return nil, fmt.Errorf("schema file not loaded")
+	}
+	name := sqlparse.QualifiedName{Schema: schema, Name: function}
+	var deps []graph.ColumnDependency
+
+	// 1. Triggers executing the function
+	for _, trg := range f.Schema.Triggers {
+		if trg.Function == name {
+			deps = append(deps, graph.ColumnDependency{
+				Schema: trg.Table.Schema,
+				Name:   trg.Name,
+				Type:   "TRIGGER",
+				Detail: fmt.Sprintf("Trigger on %s executes this function", trg.Table.Name),
+			})
+		}
+	}
+
+	// 2. Views calling the function
+	for _, v := range f.Schema.Views {
+		if containsName(sqlparse.FunctionCalls(v.Tokens, v.Schema), name) {
+			deps = appendDependency(deps, graph.ColumnDependency{
+				Schema: v.Schema,
+				Name:   v.Name,
+				Type:   "VIEW",
+				Detail: "Hard Dependency (view definition)",
+			})
+		}
+	}
+
+	// 3. Function Bodies (Soft Dependencies)
+	for _, dep := range routineCallers(f.routines(), name) {
+		deps = appendDependency(deps, dep)
+	}
+
+	return deps, nil
+}
+
+// GetTypeDependencies identifies the tables, composite types and domains using a type
+func (f *FileAdapter) GetTypeDependencies(schema, name string) ([]graph.ColumnDependency, error) {
+	if f.Schema == nil {
+		return nil, fmt.Errorf("schema file not loaded")
+	}
+	g := graph.NewGraph()
+	if err := f.FetchSchema(g); err != nil {
+		return nil, err
+	}
+	return graphTypeDependencies(g, schema, name)
+}
+
+// GetIndexDependencies identifies the constraints and foreign keys relying on an index
+func (f *FileAdapter) GetIndexDependencies(schema, name string) ([]graph.ColumnDependency, error) {
+	if f.Schema == nil {
+		return nil, fmt.Errorf("schema file not loaded")
+	}
+	g := graph.NewGraph()
+	if err := f.FetchSchema(g); err != nil {
+		return nil, err
+	}
+	return graphIndexDependencies(g, schema, name)
+}
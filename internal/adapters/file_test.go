@@ -0,0 +1,306 @@
+package adapters
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/alexanderritik/dbgraph/internal/graph"
+	"github.com/alexanderritik/dbgraph/internal/sqlparse"
+)
+
+// newFileFixture loads the Postgres fixture used by docker-compose
+func newFileFixture(t *testing.T) *FileAdapter {
+	t.Helper()
+	a := NewFileAdapter()
+	if err := a.Connect("file://../../scripts/init.sql"); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	return a
+}
+
+func TestFileFetchSchema(t *testing.T) {
+	a := newFileFixture(t)
+	g := graph.NewGraph()
+	if err := a.FetchSchema(g); err != nil {
+		t.Fatalf("FetchSchema: %v", err)
+	}
+
+	var fk *graph.Edge
+	for _, e := range g.Edges["public.order_items"] {
+		if e.ConstraintName == "fk_order" {
+			fk = e
+		}
+	}
+	if fk == nil || fk.TargetID != "public.orders" || fk.DeleteRule != "CASCADE" || fk.MetaData["fk_columns"] != "order_id" {
+		t.Fatalf("unexpected FK edge: %+v", fk)
+	}
+
+	missing := false
+	for _, issue := range g.CheckIndexCoverage().MissingFKIndexes {
+		if issue == "public.explicit_fk_missing_index (product_id) -> public.products" {
+			missing = true
+		}
+	}
+	if !missing {
+		t.Errorf("expected missing FK index on explicit_fk_missing_index")
+	}
+
+	cycles := g.CheckCycles()
+	if len(cycles) != 1 || cycles[0][0] != "public.employees" {
+		t.Errorf("expected employees self-reference cycle, got %v", cycles)
+	}
+
+	downstream := make(map[string]bool)
+	for _, id := range g.GetDownstream("public.users") {
+		downstream[id] = true
+	}
+	for _, id := range []string{"public.orders", "public.order_items", "public.vip_customers", "public.orders.order_audit_trigger"} {
+		if !downstream[id] {
+			t.Errorf("expected %s downstream of users", id)
+		}
+	}
+
+	// Trigger -> function it executes -> table the function writes
+	calls := false
+	for _, e := range g.Edges["public.orders.order_audit_trigger"] {
+		calls = calls || (e.Type == graph.FunctionCall && e.TargetID == "public.log_order_changes")
+	}
+	if !calls {
+		t.Errorf("expected order_audit_trigger to call log_order_changes")
+	}
+	if n := g.Nodes["public.log_order_changes"]; n == nil || n.Type != graph.Function {
+		t.Fatalf("expected function node, got %+v", n)
+	}
+	var access *graph.Edge
+	for _, e := range g.Edges["public.log_order_changes"] {
+		if e.Type == graph.FunctionAccess && e.TargetID == "public.audit_logs" {
+			access = e
+		}
+	}
+	if access == nil || access.MetaData["access"] != "write" {
+		t.Errorf("expected log_order_changes to write audit_logs, got %+v", access)
+	}
+}
+
+func TestFileDependencies(t *testing.T) {
+	a := newFileFixture(t)
+
+	deps, err := a.GetTableDependencies("public", "orders")
+	if err != nil {
+		t.Fatalf("GetTableDependencies: %v", err)
+	}
+	types := make(map[string]bool)
+	for _, d := range deps {
+		types[d.Type] = true
+	}
+	if !types["FOREIGN_KEY"] || !types["VIEW"] || !types["TRIGGER"] {
+		t.Errorf("expected FK, VIEW and TRIGGER dependencies, got %+v", deps)
+	}
+
+	if _, err := a.GetColumnDependencies("public", "orders", "missing"); err == nil {
+		t.Errorf("expected error for missing column")
+	}
+	deps, err = a.GetFunctionDependencies("public", "log_order_changes")
+	if err != nil || len(deps) != 1 || deps[0].Name != "order_audit_trigger" {
+		t.Errorf("expected trigger dependency on log_order_changes, got %+v (%v)", deps, err)
+	}
+
+	if _, err := a.TraceQuery("SELECT 1"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("TraceQuery error = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestFileDerivedViewColumns(t *testing.T) {
+	a := NewFileAdapter()
+	a.Schema = sqlparse.ParseSchema(`
+CREATE TABLE users (id bigint PRIMARY KEY, email text CHECK (email LIKE '%@%'));
+CREATE VIEW contacts AS SELECT u.id, u.email AS contact FROM users u;
+CREATE VIEW domains AS SELECT split_part(c.contact, '@', 2) AS domain FROM contacts c;
+`)
+
+	cols, err := a.DerivedViewColumns("public", "contacts", "email")
+	if err != nil || len(cols) != 1 || cols[0] != "contact" {
+		t.Fatalf("DerivedViewColumns = %v, %v", cols, err)
+	}
+
+	// View columns have dependents of their own, and CHECK constraints are column dependents
+	deps, err := a.GetColumnDependencies("public", "contacts", "contact")
+	if err != nil || len(deps) != 1 || deps[0].Name != "domains" {
+		t.Errorf("dependencies of contacts.contact = %+v, %v", deps, err)
+	}
+	deps, _ = a.GetColumnDependencies("public", "users", "email")
+	found := false
+	for _, d := range deps {
+		found = found || (d.Type == "CONSTRAINT" && d.Name == "users_email_check")
+	}
+	if !found {
+		t.Errorf("expected users_email_check among %+v", deps)
+	}
+}
+
+func TestFileColumnLineage(t *testing.T) {
+	a := NewFileAdapter()
+	a.Schema = sqlparse.ParseSchema(`
+CREATE SCHEMA reporting;
+CREATE TABLE users (id bigint PRIMARY KEY, email text);
+CREATE TABLE orders (id bigint PRIMARY KEY, user_id bigint REFERENCES users(id), amount numeric);
+CREATE VIEW reporting.spend (customer, total) AS
+	SELECT u.email, sum(amount) FROM users u JOIN orders o ON o.user_id = u.id GROUP BY u.email;
+CREATE VIEW reporting.big_spenders AS SELECT customer FROM reporting.spend WHERE total > 100;
+`)
+	g := graph.NewGraph()
+	if err := a.FetchSchema(g); err != nil {
+		t.Fatalf("FetchSchema: %v", err)
+	}
+
+	// Unqualified relations resolve on the search path, unqualified columns through the FROM clause
+	if got := g.ColumnSources("reporting.spend.total"); len(got) != 1 || got[0] != "public.orders.amount" {
+		t.Errorf("sources of spend.total = %v", got)
+	}
+	if got := g.ColumnSources("reporting.big_spenders.customer"); len(got) != 1 || got[0] != "reporting.spend.customer" {
+		t.Errorf("sources of big_spenders.customer = %v", got)
+	}
+	if got := g.ColumnConsumers("public.users.email"); len(got) != 1 || got[0] != "reporting.spend.customer" {
+		t.Errorf("consumers of users.email = %v", got)
+	}
+}
+
+func TestFileTriggerNamedLikeFunction(t *testing.T) {
+	a := &FileAdapter{Schema: sqlparse.ParseSchema(`
+CREATE TABLE customers (id int PRIMARY KEY, updated_at timestamptz);
+CREATE TABLE orders (id int PRIMARY KEY, customer_id int REFERENCES customers (id), updated_at timestamptz);
+CREATE FUNCTION set_updated_at() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+  NEW.updated_at := now();
+  RETURN NEW;
+END $$;
+CREATE TRIGGER set_updated_at BEFORE UPDATE ON orders FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+CREATE TRIGGER set_updated_at BEFORE UPDATE ON customers FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+`)}
+	g := graph.NewGraph()
+	if err := a.FetchSchema(g); err != nil {
+		t.Fatalf("FetchSchema: %v", err)
+	}
+
+	if n := g.Nodes["public.set_updated_at"]; n == nil || n.Type != graph.Function {
+		t.Fatalf("expected the function node, got %+v", n)
+	}
+	for _, table := range []string{"orders", "customers"} {
+		id := "public." + graph.TriggerKey(table, "set_updated_at")
+		if n := g.Nodes[id]; n == nil || n.Type != graph.Trigger || n.Name != "set_updated_at" {
+			t.Fatalf("expected trigger node %s, got %+v", id, n)
+		}
+		var fires, calls bool
+		for _, e := range g.Edges[id] {
+			fires = fires || (e.Type == graph.TriggerAction && e.TargetID == "public."+table)
+			calls = calls || (e.Type == graph.FunctionCall && e.TargetID == "public.set_updated_at")
+		}
+		if !fires || !calls {
+			t.Errorf("expected %s to fire on %s and call the function, got %+v", id, table, g.Edges[id])
+		}
+	}
+	for _, e := range g.Edges["public.set_updated_at"] {
+		if e.Type == graph.TriggerAction || e.TargetID == e.SourceID {
+			t.Errorf("unexpected edge on the function: %+v", e)
+		}
+	}
+	if cycles := g.CheckCycles(); len(cycles) != 0 {
+		t.Errorf("expected no cycles, got %v", cycles)
+	}
+}
+
+func TestFileTypesAndSequences(t *testing.T) {
+	a := &FileAdapter{Schema: sqlparse.ParseSchema(`
+CREATE TYPE order_status AS ENUM ('pending', 'shipped');
+CREATE TYPE address AS (street text, status order_status);
+CREATE DOMAIN positive_int AS integer CHECK (VALUE > 0);
+CREATE TABLE orders (id serial PRIMARY KEY, status order_status, history order_status[], qty positive_int);
+CREATE TABLE customers (id bigint, home address);
+`)}
+	g := graph.NewGraph()
+	if err := a.FetchSchema(g); err != nil {
+		t.Fatalf("FetchSchema: %v", err)
+	}
+
+	for id, want := range map[string]graph.NodeType{
+		"public.order_status":  graph.Enum,
+		"public.address":       graph.CompositeType,
+		"public.positive_int":  graph.Domain,
+		"public.orders_id_seq": graph.Sequence,
+	} {
+		if n, ok := g.Nodes[id]; !ok || n.Type != want {
+			t.Errorf("expected %s node %s, got %+v", want, id, n)
+		}
+	}
+
+	edges := make(map[string]string)
+	for src, list := range g.Edges {
+		for _, e := range list {
+			edges[src+" -> "+e.TargetID+" "+string(e.Type)] = e.MetaData["columns"]
+		}
+	}
+	for edge, cols := range map[string]string{
+		"public.orders -> public.order_status TYPE_USAGE":        "status,history",
+		"public.address -> public.order_status TYPE_USAGE":       "status",
+		"public.orders -> public.orders_id_seq SEQUENCE_DEFAULT": "id",
+		"public.orders_id_seq -> public.orders SEQUENCE_OWNED":   "id",
+	} {
+		if got, ok := edges[edge]; !ok || got != cols {
+			t.Errorf("expected edge %s with columns %q, got %q (present: %v)", edge, cols, got, ok)
+		}
+	}
+
+	deps, err := a.GetTypeDependencies("public", "order_status")
+	if err != nil || len(deps) != 2 {
+		t.Fatalf("expected address and orders to use order_status, got %+v (%v)", deps, err)
+	}
+	if deps[0].Name != "address" || deps[1].Name != "orders" || deps[1].Detail != "Columns: status, history" {
+		t.Errorf("unexpected type dependencies: %+v", deps)
+	}
+	if _, err := a.GetTypeDependencies("public", "orders"); err == nil {
+		t.Errorf("expected error for a table passed as a type")
+	}
+}
+
+func TestFileIndexes(t *testing.T) {
+	a := &FileAdapter{Schema: sqlparse.ParseSchema(`
+CREATE TABLE accounts (id serial PRIMARY KEY, code text UNIQUE, email text);
+CREATE UNIQUE INDEX accounts_email_live ON accounts (lower(email)) WHERE email IS NOT NULL;
+CREATE TABLE invoices (id serial PRIMARY KEY, account_code text REFERENCES accounts (code), account_id int REFERENCES accounts);
+CREATE INDEX invoices_account_idx ON invoices (account_id);
+`)}
+	g := graph.NewGraph()
+	if err := a.FetchSchema(g); err != nil {
+		t.Fatalf("FetchSchema: %v", err)
+	}
+
+	if idx := g.Nodes["public.accounts_email_live"]; idx == nil || idx.Index == nil ||
+		idx.Index.Definition() != "UNIQUE btree (lower(email)) WHERE email IS NOT NULL" {
+		t.Fatalf("unexpected expression index: %+v", idx)
+	}
+	refs := make(map[string]string)
+	for _, e := range g.Edges["public.invoices"] {
+		if e.Type == graph.ForeignKey {
+			refs[e.MetaData["fk_columns"]] = e.MetaData["referenced_index"]
+		}
+	}
+	if refs["account_code"] != "public.accounts_code_key" || refs["account_id"] != "public.accounts_pkey" {
+		t.Errorf("expected FKs linked to the unique indexes they reference, got %v", refs)
+	}
+
+	deps, err := a.GetIndexDependencies("public", "accounts_code_key")
+	if err != nil || len(deps) != 2 {
+		t.Fatalf("expected the constraint and the referencing FK, got %+v (%v)", deps, err)
+	}
+	if deps[0].Type != "CONSTRAINT" || deps[1].Type != "FOREIGN_KEY" || deps[1].Name != "invoices" || IsCoveringIndexDependency(deps[1]) {
+		t.Errorf("unexpected index dependencies: %+v", deps)
+	}
+
+	deps, err = a.GetIndexDependencies("public", "invoices_account_idx")
+	if err != nil || len(deps) != 1 || !IsCoveringIndexDependency(deps[0]) {
+		t.Errorf("expected the FK it alone covers, got %+v (%v)", deps, err)
+	}
+	if _, err := a.GetIndexDependencies("public", "accounts"); err == nil {
+		t.Errorf("expected error for a table passed as an index")
+	}
+}
@@ -0,0 +1,204 @@
+package adapters
+
+const (
+	// mysqlQueryVersion fetches the server version
+	mysqlQueryVersion = "SELECT VERSION()"
+
+	// mysqlSystemSchemas lists schemas that are never part of the user graph
+	mysqlSystemSchemas = "('mysql', 'information_schema', 'performance_schema', 'sys')"
+
+	// mysqlQueryFetchNodes fetches tables and views with their size and estimated row count
+	mysqlQueryFetchNodes = `
+		SELECT
+			TABLE_SCHEMA,
+			TABLE_NAME,
+			TABLE_TYPE,
+			COALESCE(DATA_LENGTH, 0) + COALESCE(INDEX_LENGTH, 0) AS size_bytes,
+			COALESCE(TABLE_ROWS, 0) AS row_count
+		FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA NOT IN ` + mysqlSystemSchemas
+
+	// mysqlQueryFetchPartitions fetches the partitions of partitioned tables
+	mysqlQueryFetchPartitions = `
+		SELECT
+			TABLE_SCHEMA,
+			TABLE_NAME,
+			PARTITION_NAME,
+			COALESCE(DATA_LENGTH, 0) + COALESCE(INDEX_LENGTH, 0) AS size_bytes,
+			COALESCE(TABLE_ROWS, 0) AS row_count
+		FROM information_schema.PARTITIONS
+		WHERE PARTITION_NAME IS NOT NULL
+		  AND TABLE_SCHEMA NOT IN ` + mysqlSystemSchemas
+
+	// mysqlQueryFetchIndexes fetches index columns (in key order) for every table
+	mysqlQueryFetchIndexes = `
+		SELECT
+			TABLE_SCHEMA,
+			TABLE_NAME,
+			GROUP_CONCAT(COLUMN_NAME ORDER BY SEQ_IN_INDEX SEPARATOR ',') AS columns
+		FROM information_schema.STATISTICS
+		WHERE TABLE_SCHEMA NOT IN ` + mysqlSystemSchemas + `
+		GROUP BY TABLE_SCHEMA, TABLE_NAME, INDEX_NAME`
+
+	// mysqlQueryFetchPrimaryKeys fetches the primary key columns (in key order) of every table
+	mysqlQueryFetchPrimaryKeys = `
+		SELECT
+			TABLE_SCHEMA,
+			TABLE_NAME,
+			GROUP_CONCAT(COLUMN_NAME ORDER BY ORDINAL_POSITION SEPARATOR ',') AS columns
+		FROM information_schema.KEY_COLUMN_USAGE
+		WHERE CONSTRAINT_NAME = 'PRIMARY'
+		  AND TABLE_SCHEMA NOT IN ` + mysqlSystemSchemas + `
+		GROUP BY TABLE_SCHEMA, TABLE_NAME`
+
+	// mysqlQueryFetchForeignKeys fetches foreign key constraints, their delete rule and columns
+	mysqlQueryFetchForeignKeys = `
+		SELECT
+			rc.CONSTRAINT_SCHEMA,
+			rc.TABLE_NAME,
+			rc.UNIQUE_CONSTRAINT_SCHEMA,
+			rc.REFERENCED_TABLE_NAME,
+			rc.CONSTRAINT_NAME,
+			rc.DELETE_RULE,
+			GROUP_CONCAT(kcu.COLUMN_NAME ORDER BY kcu.ORDINAL_POSITION SEPARATOR ',') AS fk_columns,
+			GROUP_CONCAT(CASE WHEN c.IS_NULLABLE = 'YES' THEN kcu.COLUMN_NAME END ORDER BY kcu.ORDINAL_POSITION SEPARATOR ',') AS nullable_columns
+		FROM information_schema.REFERENTIAL_CONSTRAINTS rc
+		JOIN information_schema.KEY_COLUMN_USAGE kcu
+		  ON kcu.CONSTRAINT_SCHEMA = rc.CONSTRAINT_SCHEMA
+		 AND kcu.CONSTRAINT_NAME = rc.CONSTRAINT_NAME
+		 AND kcu.TABLE_NAME = rc.TABLE_NAME
+		JOIN information_schema.COLUMNS c
+		  ON c.TABLE_SCHEMA = kcu.TABLE_SCHEMA
+		 AND c.TABLE_NAME = kcu.TABLE_NAME
+		 AND c.COLUMN_NAME = kcu.COLUMN_NAME
+		WHERE rc.CONSTRAINT_SCHEMA NOT IN ` + mysqlSystemSchemas + `
+		GROUP BY rc.CONSTRAINT_SCHEMA, rc.TABLE_NAME, rc.UNIQUE_CONSTRAINT_SCHEMA,
+		         rc.REFERENCED_TABLE_NAME, rc.CONSTRAINT_NAME, rc.DELETE_RULE`
+
+	// mysqlQueryFetchViews fetches view -> table usage (MySQL 8.0.13+)
+	mysqlQueryFetchViews = `
+		SELECT VIEW_SCHEMA, VIEW_NAME, TABLE_SCHEMA, TABLE_NAME
+		FROM information_schema.VIEW_TABLE_USAGE
+		WHERE VIEW_SCHEMA NOT IN ` + mysqlSystemSchemas
+
+	// mysqlQueryFetchViewDefinitions fetches view bodies (fallback for MariaDB / older MySQL)
+	mysqlQueryFetchViewDefinitions = `
+		SELECT TABLE_SCHEMA, TABLE_NAME, VIEW_DEFINITION
+		FROM information_schema.VIEWS
+		WHERE TABLE_SCHEMA NOT IN ` + mysqlSystemSchemas
+
+	// mysqlQueryFetchColumns lists the columns of every table and view
+	mysqlQueryFetchColumns = `
+		SELECT TABLE_SCHEMA, TABLE_NAME, COLUMN_NAME
+		FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA NOT IN ` + mysqlSystemSchemas + `
+		ORDER BY TABLE_SCHEMA, TABLE_NAME, ORDINAL_POSITION`
+
+	// mysqlQueryFetchRoutines fetches stored functions and procedures with their body
+	mysqlQueryFetchRoutines = `
+		SELECT ROUTINE_SCHEMA, ROUTINE_NAME, COALESCE(ROUTINE_DEFINITION, '')
+		FROM information_schema.ROUTINES
+		WHERE ROUTINE_SCHEMA NOT IN ` + mysqlSystemSchemas + `
+		ORDER BY ROUTINE_SCHEMA, ROUTINE_NAME`
+
+	// mysqlQueryFetchTriggers fetches triggers together with their body
+	mysqlQueryFetchTriggers = `
+		SELECT
+			TRIGGER_SCHEMA,
+			EVENT_OBJECT_TABLE,
+			TRIGGER_NAME,
+			ACTION_STATEMENT
+		FROM information_schema.TRIGGERS
+		WHERE TRIGGER_SCHEMA NOT IN ` + mysqlSystemSchemas
+
+	// mysqlQueryActiveLocks counts granted InnoDB locks (MySQL 8.0+)
+	mysqlQueryActiveLocks = "SELECT COUNT(*) FROM performance_schema.data_locks WHERE LOCK_STATUS = 'GRANTED'"
+
+	// mysqlQueryMaxConns fetches the max_connections setting
+	mysqlQueryMaxConns = "SELECT @@max_connections"
+
+	// mysqlQueryUsedConns counts open connections
+	mysqlQueryUsedConns = "SELECT COUNT(*) FROM information_schema.PROCESSLIST"
+
+	// mysqlQueryLongestRunning fetches the longest running statement (excluding our own session)
+	mysqlQueryLongestRunning = `
+		SELECT TIME, ID
+		FROM information_schema.PROCESSLIST
+		WHERE COMMAND = 'Query' AND ID != CONNECTION_ID()
+		ORDER BY TIME DESC
+		LIMIT 1`
+
+	// mysqlQueryCurrentDatabase fetches the database selected in the DSN
+	mysqlQueryCurrentDatabase = "SELECT COALESCE(DATABASE(), '')"
+
+	// mysqlQueryColumnExists checks that a column exists
+	mysqlQueryColumnExists = `
+		SELECT COUNT(*)
+		FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND COLUMN_NAME = ?`
+
+	// mysqlQueryFKRefsByColumn finds FKs that reference this specific column
+	mysqlQueryFKRefsByColumn = `
+		SELECT CONSTRAINT_NAME, TABLE_SCHEMA, TABLE_NAME
+		FROM information_schema.KEY_COLUMN_USAGE
+		WHERE REFERENCED_TABLE_SCHEMA = ?
+		  AND REFERENCED_TABLE_NAME = ?
+		  AND REFERENCED_COLUMN_NAME = ?`
+
+	// mysqlQueryFKRefsByTable finds FKs pointing TO this table
+	mysqlQueryFKRefsByTable = `
+		SELECT CONSTRAINT_NAME, CONSTRAINT_SCHEMA, TABLE_NAME
+		FROM information_schema.REFERENTIAL_CONSTRAINTS
+		WHERE UNIQUE_CONSTRAINT_SCHEMA = ?
+		  AND REFERENCED_TABLE_NAME = ?`
+
+	// mysqlQueryIndexesByColumn finds indexes using this column
+	mysqlQueryIndexesByColumn = `
+		SELECT DISTINCT INDEX_NAME
+		FROM information_schema.STATISTICS
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND COLUMN_NAME = ?`
+
+	// mysqlQueryViewDefinition fetches the normalized SELECT of a view
+	mysqlQueryViewDefinition = `
+		SELECT VIEW_DEFINITION
+		FROM information_schema.VIEWS
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?`
+
+	// mysqlQueryViewsByTable finds views whose definition references `schema`.`table`: MySQL stores
+	// definitions fully qualified. Callers re-check the match, since LIKE treats _ as a wildcard.
+	mysqlQueryViewsByTable = `
+		SELECT TABLE_SCHEMA, TABLE_NAME, VIEW_DEFINITION
+		FROM information_schema.VIEWS
+		WHERE TABLE_SCHEMA NOT IN ` + mysqlSystemSchemas + `
+		  AND VIEW_DEFINITION LIKE CONCAT('%', ?, '%')`
+
+	// mysqlQueryTriggersByTable finds triggers defined on a table
+	mysqlQueryTriggersByTable = `
+		SELECT TRIGGER_NAME, ACTION_STATEMENT
+		FROM information_schema.TRIGGERS
+		WHERE EVENT_OBJECT_SCHEMA = ? AND EVENT_OBJECT_TABLE = ?`
+
+	// mysqlQueryCheckConstraintsByTable fetches CHECK constraints of a table (MySQL 8.0.16+)
+	mysqlQueryCheckConstraintsByTable = `
+		SELECT cc.CONSTRAINT_NAME, cc.CHECK_CLAUSE
+		FROM information_schema.CHECK_CONSTRAINTS cc
+		JOIN information_schema.TABLE_CONSTRAINTS tc
+		  ON tc.CONSTRAINT_SCHEMA = cc.CONSTRAINT_SCHEMA
+		 AND tc.CONSTRAINT_NAME = cc.CONSTRAINT_NAME
+		WHERE tc.TABLE_SCHEMA = ? AND tc.TABLE_NAME = ? AND tc.CONSTRAINT_TYPE = 'CHECK'`
+
+	// mysqlQueryTopQueries fetches top statement digests from performance_schema.
+	// Timer columns are in picoseconds; they are converted to milliseconds.
+	// Note: The ordering clause will be injected dynamically.
+	mysqlQueryTopQueries = `
+		SELECT
+			COALESCE(DIGEST, '') AS queryid,
+			COALESCE(DIGEST_TEXT, '') AS query,
+			COUNT_STAR AS calls,
+			SUM_TIMER_WAIT / 1000000000 AS total_time,
+			AVG_TIMER_WAIT / 1000000000 AS avg_time,
+			COALESCE(SUM_TIMER_WAIT * 100 / NULLIF(SUM(SUM_TIMER_WAIT) OVER(), 0), 0) AS load_percent
+		FROM performance_schema.events_statements_summary_by_digest
+		WHERE SCHEMA_NAME = DATABASE()
+	`
+)
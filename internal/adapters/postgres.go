@@ -3,18 +3,25 @@ package adapters
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/alexanderritik/dbgraph/internal/graph"
+	"github.com/alexanderritik/dbgraph/internal/sqlparse"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // PostgresAdapter handles PostgreSQL interactions
 type PostgresAdapter struct {
-	Pool *pgxpool.Pool
+	Pool   *pgxpool.Pool
+	bodies routineCache
 }
 
 // NewPostgresAdapter creates a new postgres adapter
@@ -40,6 +47,16 @@ func (p *PostgresAdapter) Close() {
 	}
 }
 
+// ServerVersion returns the PostgreSQL server version
+func (p *PostgresAdapter) ServerVersion() (string, error) {
+	if p.Pool == nil {
+		return "", fmt.Errorf("database connection not established")
+	}
+	var version string
+	err := p.Pool.QueryRow(context.Background(), queryDBVersion).Scan(&version)
+	return version, err
+}
+
 // FetchSchema queries the database schema and populates the graph
 func (p *PostgresAdapter) FetchSchema(g *graph.Graph) error {
 	if p.Pool == nil {
@@ -59,7 +76,8 @@ func (p *PostgresAdapter) FetchSchema(g *graph.Graph) error {
 	for rows.Next() {
 		var schema, name, kind, size string
 		var rowCount float64 // reltuples is float4
-		if err := rows.Scan(&schema, &name, &kind, &size, &rowCount); err != nil {
+		var writes int64
+		if err := rows.Scan(&schema, &name, &kind, &size, &rowCount, &writes); err != nil {
 			return err
 		}
 		var nodeType graph.NodeType
@@ -76,22 +94,39 @@ func (p *PostgresAdapter) FetchSchema(g *graph.Graph) error {
 			rc = 0
 		}
 		g.AddNode(schema, name, nodeType, size, rc)
+		g.SetWrites(schema, name, writes)
 	}
 
-	// 1.5 Fetch Indexes (for Structural Warnings)
-	// Simplified robust query to get index columns per table
+	// 1.5 Fetch Indexes, with their definitions and scan counts
 	ixRows, err := p.Pool.Query(ctx, queryFetchIndexes)
 	if err != nil {
-		fmt.Printf("Warning: failed to fetch indexes: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Warning: failed to fetch indexes: %v\n", err)
 	} else {
 		defer ixRows.Close()
 		for ixRows.Next() {
+			var schema, table, name, size string
+			info := graph.IndexInfo{HasStats: true}
+			if err := ixRows.Scan(&schema, &table, &name, &info.Method, &info.Unique, &info.Primary, &info.Constraint,
+				&info.Columns, &info.Expressions, &info.Predicate, &size, &info.SizeBytes, &info.Scans, &info.TuplesRead); err != nil {
+				continue
+			}
+			g.AddIndexNode(schema, table, name, size, info)
+		}
+	}
+
+	// 1.6 Fetch Primary Keys (for Lint Rules)
+	pkRows, err := p.Pool.Query(ctx, queryFetchPrimaryKeys)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to fetch primary keys: %v\n", err)
+	} else {
+		defer pkRows.Close()
+		for pkRows.Next() {
 			var schema, table string
 			var cols []string
-			if err := ixRows.Scan(&schema, &table, &cols); err != nil {
+			if err := pkRows.Scan(&schema, &table, &cols); err != nil {
 				continue
 			}
-			g.AddIndex(schema, table, cols)
+			g.SetPrimaryKey(schema, table, cols)
 		}
 	}
 
@@ -104,9 +139,11 @@ func (p *PostgresAdapter) FetchSchema(g *graph.Graph) error {
 	defer fkRows.Close()
 
 	for fkRows.Next() {
-		var schema, table, fSchema, fTable, constraintName, deleteRule string
-		var fkCols []string
-		if err := fkRows.Scan(&schema, &table, &fSchema, &fTable, &constraintName, &deleteRule, &fkCols); err != nil {
+		var schema, table, fSchema, fTable, constraintName, deleteRule, refIndex string
+		var fkCols, nullableCols, refCols []string
+		var nDistinct, nullFrac *float64
+		if err := fkRows.Scan(&schema, &table, &fSchema, &fTable, &constraintName, &deleteRule, &fkCols, &nullableCols, &refIndex,
+			&refCols, &nDistinct, &nullFrac); err != nil {
 			return err
 		}
 
@@ -124,6 +161,18 @@ func (p *PostgresAdapter) FetchSchema(g *graph.Graph) error {
 				lastEdge.MetaData = make(map[string]string)
 			}
 			lastEdge.MetaData["fk_columns"] = strings.Join(fkCols, ",")
+			if len(nullableCols) > 0 {
+				lastEdge.MetaData["nullable_columns"] = strings.Join(nullableCols, ",")
+			}
+			if refIndex != "" {
+				// An index lives in the schema of its table
+				lastEdge.MetaData["referenced_index"] = fmt.Sprintf("%s.%s", fSchema, refIndex)
+			}
+			lastEdge.MetaData["referenced_columns"] = strings.Join(refCols, ",")
+			if nDistinct != nil && nullFrac != nil {
+				lastEdge.MetaData["n_distinct"] = strconv.FormatFloat(*nDistinct, 'g', -1, 64)
+				lastEdge.MetaData["null_frac"] = strconv.FormatFloat(*nullFrac, 'g', -1, 64)
+			}
 		}
 	}
 
@@ -160,55 +209,60 @@ func (p *PostgresAdapter) FetchSchema(g *graph.Graph) error {
 		g.AddEdge(vSchema, vName, tSchema, tName, graph.ViewDepends, "", "")
 	}
 
-	// 4. Fetch Triggers & Analyze Function Bodies
+	// 3b. Column lineage: pg_depend says which columns a view reads, the definition says which
+	// output column each of them feeds
+	if err := p.fetchViewLineage(ctx, g); err != nil {
+		return fmt.Errorf("failed to fetch view column lineage: %w", err)
+	}
+
+	// 4. Fetch Functions & Procedures. Nodes first, so calls between them resolve in any order.
+	fRows, err := p.Pool.Query(ctx, queryFetchFunctions)
+	if err != nil {
+		return fmt.Errorf("failed to fetch functions: %w", err)
+	}
+	defer fRows.Close()
+
+	bodies := make(map[sqlparse.QualifiedName]string)
+	for fRows.Next() {
+		var schema, name, language, body string
+		if err := fRows.Scan(&schema, &name, &language, &body); err != nil {
+			return err
+		}
+		g.AddNode(schema, name, graph.Function, "", 0)
+		// C and internal functions have no SQL to parse
+		if language == "sql" || language == "plpgsql" {
+			fn := sqlparse.QualifiedName{Schema: schema, Name: name}
+			bodies[fn] += body + ";\n" // Overloads share a node
+		}
+	}
+	if err := fRows.Err(); err != nil {
+		return err
+	}
+	for fn, body := range bodies {
+		addBodyReferences(g, fn, body)
+	}
+
+	// 5. Fetch Triggers: Trigger -> Table it fires on, Trigger -> Function it executes
 	tRows, err := p.Pool.Query(ctx, queryFetchTriggers)
 	if err == nil {
 		defer tRows.Close()
 		for tRows.Next() {
-			var schema, table, trigger, funcName, level string
-			if err := tRows.Scan(&schema, &table, &trigger, &funcName, &level); err == nil {
-				// Add Trigger Node
-				g.AddNode(schema, trigger, graph.Trigger, "", 0)
-				// Link: Table -> Trigger (Trigger actions ON table)
-				// Note: Originally we did Trigger->Table. But topologically, the Trigger is downstream of Table instructions.
-				// However, if we view it as "Trigger Acts On Table", it might be Trigger -> Table.
-				// Let's keep existing direction: Trigger -> Table (Dependency: Trigger depends on Table existence)
-				g.AddEdge(schema, trigger, schema, table, graph.TriggerAction, "", "")
-
-				// NEW: Fetch Function Body to find downstream dependencies (e.g., Audit Logs)
-				var body string
-				err := p.Pool.QueryRow(ctx, queryFetchFunctionBody, funcName, schema).Scan(&body)
-				if err == nil {
-					// Simple Heuristic: Look for "INSERT INTO <table>", "UPDATE <table>"
-					// We can iterate over all known nodes to see if they are mentioned?
-					// Or just basic regex for "INSERT INTO table"
-					// Let's check against all existing nodes to be safe and accurate.
-					upperBody := strings.ToUpper(body)
-					for id, node := range g.Nodes {
-						// generic check: "INSERT INTO <name>" or "UPDATE <name>"
-						// schema.name or just name
-						// crude check: matches name and is not the source table
-						if id == fmt.Sprintf("%s.%s", schema, table) {
-							continue
-						}
-
-						// Check for "Schema.Name" or "Name" if schema matches
-						// This is expensive O(Triggers * Nodes), but N is small.
-						targetName := node.Name
-						if strings.Contains(upperBody, strings.ToUpper(targetName)) {
-							// Check if it looks like a SQL command
-							// "INSERT INTO target", "UPDATE target"
-							// We'll simplisticly assume if the table name is present, it's a dependency.
-							// Add Edge: Trigger -> TargetTable
-							g.AddEdge(schema, trigger, node.Schema, node.Name, graph.TriggerAction, "Function Call", "")
-						}
-					}
-				}
+			var schema, table, trigger, funcSchema, funcName, level string
+			if err := tRows.Scan(&schema, &table, &trigger, &funcSchema, &funcName, &level); err == nil {
+				// Trigger -> Table (the trigger depends on the table it is defined on)
+				g.AddTrigger(schema, table, trigger)
+				g.AddNode(funcSchema, funcName, graph.Function, "", 0)
+				addEdgeOnce(g, schema, graph.TriggerKey(table, trigger), funcSchema, funcName, graph.FunctionCall)
 			}
 		}
 	}
 
-	// 5. Fetch Table Inheritance (Partitions)
+	// 5b. Types, Enums, Domains & Sequences, with the columns using them
+	if err := p.fetchTypesAndSequences(ctx, g); err != nil {
+		return fmt.Errorf("failed to fetch types and sequences: %w", err)
+	}
+
+	// 6. Fetch Table Inheritance (Partitions)
 	iRows, err := p.Pool.Query(ctx, queryFetchInheritance)
 	if err == nil {
 		defer iRows.Close()
@@ -224,6 +278,151 @@ func (p *PostgresAdapter) FetchSchema(g *graph.Graph) error {
 	return nil
 }
 
+// fetchTypesAndSequences adds user-defined types and sequences with their edges: columns, domains
+// and function signatures using a type, column defaults drawing from a sequence, and sequence ownership
+func (p *PostgresAdapter) fetchTypesAndSequences(ctx context.Context, g *graph.Graph) error {
+	rows, err := p.Pool.Query(ctx, queryFetchTypes)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var schema, name, kind string
+		if err := rows.Scan(&schema, &name, &kind); err != nil {
+			return err
+		}
+		g.AddNode(schema, name, userTypeNodes[kind], "", 0)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	uRows, err := p.Pool.Query(ctx, queryTypeUsage)
+	if err != nil {
+		return err
+	}
+	defer uRows.Close()
+	for uRows.Next() {
+		var schema, name, kind, column, typeSchema, typeName string
+		if err := uRows.Scan(&schema, &name, &kind, &column, &typeSchema, &typeName); err != nil {
+			return err
+		}
+		// Only link objects in the graph (extension members and foreign tables are left out)
+		_, userOK := g.Nodes[fmt.Sprintf("%s.%s", schema, name)]
+		_, typeOK := g.Nodes[fmt.Sprintf("%s.%s", typeSchema, typeName)]
+		if userOK && typeOK {
+			g.AddColumnEdge(schema, name, typeSchema, typeName, graph.TypeUsage, column)
+		}
+	}
+	if err := uRows.Err(); err != nil {
+		return err
+	}
+
+	sRows, err := p.Pool.Query(ctx, queryFetchSequences)
+	if err != nil {
+		return err
+	}
+	defer sRows.Close()
+	for sRows.Next() {
+		var schema, name, ownerSchema, ownerTable, ownerColumn string
+		if err := sRows.Scan(&schema, &name, &ownerSchema, &ownerTable, &ownerColumn); err != nil {
+			return err
+		}
+		g.AddNode(schema, name, graph.Sequence, "", 0)
+		if ownerTable != "" {
+			g.AddColumnEdge(schema, name, ownerSchema, ownerTable, graph.SequenceOwned, ownerColumn)
+		}
+	}
+	if err := sRows.Err(); err != nil {
+		return err
+	}
+
+	dRows, err := p.Pool.Query(ctx, querySequenceDefaults)
+	if err != nil {
+		return err
+	}
+	defer dRows.Close()
+	for dRows.Next() {
+		var schema, table, column, seqSchema, seqName string
+		if err := dRows.Scan(&schema, &table, &column, &seqSchema, &seqName); err != nil {
+			return err
+		}
+		if _, ok := g.Nodes[fmt.Sprintf("%s.%s", seqSchema, seqName)]; ok {
+			g.AddColumnEdge(schema, table, seqSchema, seqName, graph.SequenceDefault, column)
+		}
+	}
+	return dRows.Err()
+}
+
+// fetchViewLineage records column lineage for every view
+func (p *PostgresAdapter) fetchViewLineage(ctx context.Context, g *graph.Graph) error {
+	rows, err := p.Pool.Query(ctx, queryFetchViewColumns)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	type viewColumns struct {
+		name       sqlparse.QualifiedName
+		definition string
+		reads      map[sqlparse.QualifiedName][]string
+	}
+	var views []*viewColumns
+	for rows.Next() {
+		var vSchema, vName, def, sSchema, sName, column string
+		if err := rows.Scan(&vSchema, &vName, &def, &sSchema, &sName, &column); err != nil {
+			return err
+		}
+		name := sqlparse.QualifiedName{Schema: vSchema, Name: vName}
+		if len(views) == 0 || views[len(views)-1].name != name {
+			views = append(views, &viewColumns{name: name, definition: def, reads: make(map[sqlparse.QualifiedName][]string)})
+		}
+		v := views[len(views)-1]
+		source := sqlparse.QualifiedName{Schema: sSchema, Name: sName}
+		v.reads[source] = append(v.reads[source], column)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, v := range views {
+		// pg_get_viewdef aliases every output column, so no explicit column list is needed
+		addViewLineage(g, v.name, v.definition, nil, v.reads)
+	}
+	return nil
+}
+
+// routines returns the parsed bodies of all SQL and PL/pgSQL functions and procedures, fetched once
+func (p *PostgresAdapter) routines(ctx context.Context) ([]routine, error) {
+	return p.bodies.get(func() ([]routine, error) { return p.fetchRoutines(ctx) })
+}
+
+// fetchRoutines parses the bodies of all SQL and PL/pgSQL functions and procedures
+func (p *PostgresAdapter) fetchRoutines(ctx context.Context) ([]routine, error) {
+	rows, err := p.Pool.Query(ctx, queryFetchFunctions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var routines []routine
+	for rows.Next() {
+		var schema, name, language, body string
+		if err := rows.Scan(&schema, &name, &language, &body); err != nil {
+			return nil, err
+		}
+		// C and internal functions have no SQL to parse
+		if language != "sql" && language != "plpgsql" {
+			continue
+		}
+		routines = append(routines, routine{
+			Name: sqlparse.QualifiedName{Schema: schema, Name: name},
+			Body: sqlparse.ParseBody(body, schema),
+		})
+	}
+	return routines, rows.Err()
+}
+
 // GetMetrics returns real-time database statistics
 func (p *PostgresAdapter) GetMetrics() (*graph.DBMetrics, error) {
 	if p.Pool == nil {
@@ -326,7 +525,7 @@ func (p *PostgresAdapter) GetColumnDependencies(schema, table, column string) ([
 	// 3. Direct Dependencies via pg_depend (Views, Triggers, etc.)
 	rows, err := p.Pool.Query(ctx, queryColumnDependencies, table, schema, column)
 	if err != nil {
-		fmt.Printf("Warning: failed to fetch pg_depend: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Warning: failed to fetch pg_depend: %v\n", err)
 	} else {
 		defer rows.Close()
 		for rows.Next() {
@@ -341,6 +540,8 @@ func (p *PostgresAdapter) GetColumnDependencies(schema, table, column string) ([
 				readableType = "VIEW"
 			} else if strings.Contains(depType, "trigger") {
 				readableType = "TRIGGER"
+			} else if strings.Contains(depType, "constraint") {
+				readableType = "CONSTRAINT" // CHECK, and the table's own keys on the column
 			} else if strings.Contains(depType, "class") {
 				readableType = "RELATION" // Could be View, Table, Index
 				if depName == table || strings.Contains(depName, table) {
@@ -369,33 +570,33 @@ func (p *PostgresAdapter) GetColumnDependencies(schema, table, column string) ([
 			}
 		}
 		if err := rows.Err(); err != nil {
-			fmt.Printf("Warning: error iterating pg_depend: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Warning: error iterating pg_depend: %v\n", err)
 		}
 	}
 
-	// 4. Scan Function Bodies (Soft Dependencies)
-	fRows, err := p.Pool.Query(ctx, queryScanFunctionsForColumn, column)
-	if err == nil {
-		defer fRows.Close()
-		for fRows.Next() {
-			var fSchema, fName, fSrc string
-			if err := fRows.Scan(&fSchema, &fName, &fSrc); err != nil {
-				continue
-			}
-			if strings.Contains(strings.ToUpper(fSrc), strings.ToUpper(table)) {
-				deps = append(deps, graph.ColumnDependency{
-					Schema: fSchema,
-					Name:   fName,
-					Type:   "FUNCTION",
-					Detail: "Code Reference (Regex Scan)",
-				})
-			}
+	// 4. Parse Function Bodies (Soft Dependencies)
+	if routines, err := p.routines(ctx); err == nil {
+		for _, dep := range routineColumnDependencies(routines, sqlparse.QualifiedName{Schema: schema, Name: table}, column) {
+			deps = appendDependency(deps, dep)
 		}
 	}
 
 	return deps, nil
 }
 
+// DerivedViewColumns parses the view definition for the output columns that read column
+func (p *PostgresAdapter) DerivedViewColumns(schema, view, column string) ([]string, error) {
+	if p.Pool == nil {
+		return nil, fmt.Errorf("database connection not established")
+	}
+	var def string
+	if err := p.Pool.QueryRow(context.Background(), queryViewDefinition, schema, view).Scan(&def); err != nil {
+		return nil, fmt.Errorf("view '%s.%s' not found: %w", schema, view, err)
+	}
+	// pg_get_viewdef aliases every output column, so no explicit column list is needed
+	return sqlparse.DerivedColumns(sqlparse.Tokenize(def), nil, column), nil
+}
+
 // GetTableDependencies identifies all objects that depend on a specific table
 func (p *PostgresAdapter) GetTableDependencies(schema, table string) ([]graph.ColumnDependency, error) {
 	if p.Pool == nil {
@@ -424,7 +625,7 @@ func (p *PostgresAdapter) GetTableDependencies(schema, table string) ([]graph.Co
 	// 2. Direct Dependencies via pg_depend (Views, Triggers, Mat Views)
 	rows, err := p.Pool.Query(ctx, queryTableDependencies, table, schema)
 	if err != nil {
-		fmt.Printf("Warning: failed to fetch table dependencies: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Warning: failed to fetch table dependencies: %v\n", err)
 	} else {
 		defer rows.Close()
 		for rows.Next() {
@@ -475,37 +676,141 @@ func (p *PostgresAdapter) GetTableDependencies(schema, table string) ([]graph.Co
 			}
 		}
 		if err := rows.Err(); err != nil {
-			fmt.Printf("Warning: error iterating pg_depend table: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Warning: error iterating pg_depend table: %v\n", err)
 		}
 	}
 
-	// 3. Scan Function Bodies (Soft Dependencies)
-	fRows, err := p.Pool.Query(ctx, queryScanFunctionsForColumn, table)
-	if err == nil {
-		defer fRows.Close()
-		for fRows.Next() {
-			var fSchema, fName, fSrc string
-			if err := fRows.Scan(&fSchema, &fName, &fSrc); err != nil {
-				continue
-			}
-			// Strict check: contains "schem.table" or "table"
-			// Case insensitive
-			upperSrc := strings.ToUpper(fSrc)
-			upperTable := strings.ToUpper(table)
-			if strings.Contains(upperSrc, upperTable) {
-				deps = append(deps, graph.ColumnDependency{
-					Schema: fSchema,
-					Name:   fName,
-					Type:   "FUNCTION",
-					Detail: "Code Reference (Regex Scan)",
-				})
-			}
+	// 3. Parse Function Bodies (Soft Dependencies)
+	if routines, err := p.routines(ctx); err == nil {
+		for _, dep := range routineTableDependencies(routines, sqlparse.QualifiedName{Schema: schema, Name: table}) {
+			deps = appendDependency(deps, dep)
+		}
+	}
+
+	return deps, nil
+}
+
+// GetFunctionDependencies identifies the triggers, views, defaults and function bodies that call a function
+func (p *PostgresAdapter) GetFunctionDependencies(schema, function string) ([]graph.ColumnDependency, error) {
+	if p.Pool == nil {
+		return nil, fmt.Errorf("database connection not established")
+	}
+	ctx := context.Background()
+	var deps []graph.ColumnDependency
+
+	// 1. Hard Dependencies via pg_trigger / pg_depend
+	rows, err := p.Pool.Query(ctx, queryFunctionDependents, schema, function)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch function dependencies: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var depType, depSchema, depName, relName string
+		if err := rows.Scan(&depType, &depSchema, &depName, &relName); err != nil {
+			continue
+		}
+		switch depType {
+		case "TRIGGER":
+			deps = append(deps, graph.ColumnDependency{
+				Schema: depSchema,
+				Name:   depName,
+				Type:   "TRIGGER",
+				Detail: fmt.Sprintf("Trigger on %s executes this function", relName),
+			})
+		case "VIEW":
+			deps = appendDependency(deps, graph.ColumnDependency{
+				Schema: depSchema,
+				Name:   depName,
+				Type:   "VIEW",
+				Detail: "Hard Dependency (n)",
+			})
+		case "DEFAULT":
+			deps = append(deps, graph.ColumnDependency{
+				Schema: depSchema,
+				Name:   fmt.Sprintf("%s.%s", relName, depName),
+				Type:   "DEFAULT",
+				Detail: "Column default calls this function",
+			})
 		}
 	}
 
+	// 2. Parse Function Bodies (Soft Dependencies)
+	if routines, err := p.routines(ctx); err == nil {
+		for _, dep := range routineCallers(routines, sqlparse.QualifiedName{Schema: schema, Name: function}) {
+			deps = appendDependency(deps, dep)
+		}
+	}
+
+	return deps, nil
+}
+
+// GetTypeDependencies identifies the columns, composite types, domains and function signatures using a type
+func (p *PostgresAdapter) GetTypeDependencies(schema, name string) ([]graph.ColumnDependency, error) {
+	if p.Pool == nil {
+		return nil, fmt.Errorf("database connection not established")
+	}
+	rows, err := p.Pool.Query(context.Background(), queryTypeUsage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch type dependencies: %w", err)
+	}
+	defer rows.Close()
+
+	// Rows are ordered by user, so the columns of one relation are adjacent
+	var deps []graph.ColumnDependency
+	var columns []string
+	var last sqlparse.QualifiedName
+	var lastKind string
+	flush := func() {
+		if last.Name != "" {
+			deps = append(deps, typeDependency(last.Schema, last.Name, postgresTypeUsers[lastKind], columns))
+		}
+	}
+	for rows.Next() {
+		var depSchema, depName, kind, column, typeSchema, typeName string
+		if err := rows.Scan(&depSchema, &depName, &kind, &column, &typeSchema, &typeName); err != nil {
+			return nil, err
+		}
+		if typeSchema != schema || typeName != name {
+			continue
+		}
+		user := sqlparse.QualifiedName{Schema: depSchema, Name: depName}
+		if user != last || kind != lastKind {
+			flush()
+			last, lastKind, columns = user, kind, nil
+		}
+		if column != "" {
+			columns = append(columns, column)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	flush()
 	return deps, nil
 }
 
+// GetIndexDependencies identifies the constraint an index enforces, the foreign keys referencing its
+// key and the foreign keys it alone covers. Coverage needs every index of the table, so the graph is built.
+func (p *PostgresAdapter) GetIndexDependencies(schema, name string) ([]graph.ColumnDependency, error) {
+	if p.Pool == nil {
+		return nil, fmt.Errorf("database connection not established")
+	}
+	g := graph.NewGraph()
+	if err := p.FetchSchema(g); err != nil {
+		return nil, err
+	}
+	return graphIndexDependencies(g, schema, name)
+}
+
+// postgresTypeUsers maps the user kinds of queryTypeUsage to node types
+var postgresTypeUsers = map[string]graph.NodeType{
+	"TABLE":    graph.Table,
+	"VIEW":     graph.View,
+	"TYPE":     graph.CompositeType,
+	"DOMAIN":   graph.Domain,
+	"FUNCTION": graph.Function,
+}
+
 // GetTopQueries fetches the top costly queries from pg_stat_statements
 func (p *PostgresAdapter) GetTopQueries(limit int, sortBy string) ([]graph.QueryStats, error) {
 	if p.Pool == nil {
@@ -630,3 +935,383 @@ func (p *PostgresAdapter) TraceQuery(query string) (*graph.TraceResult, error) {
 
 	return traceResult, nil
 }
+
+// catalogObjects snapshots the user objects visible to a transaction, keyed by OID
+func catalogObjects(ctx context.Context, tx pgx.Tx) (map[string]graph.DroppedObject, error) {
+	rows, err := tx.Query(ctx, queryCatalogObjects)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	objects := make(map[string]graph.DroppedObject)
+	for rows.Next() {
+		var key string
+		var obj graph.DroppedObject
+		if err := rows.Scan(&key, &obj.Type, &obj.Schema, &obj.Name, &obj.Table); err != nil {
+			return nil, err
+		}
+		objects[key] = obj
+	}
+	return objects, rows.Err()
+}
+
+// withCascade rewrites a DROP statement to end in CASCADE (replacing RESTRICT)
+func withCascade(stmt string) string {
+	stmt = strings.TrimRight(strings.TrimSpace(stmt), ";")
+	stmt = strings.TrimSpace(stmt)
+	upper := strings.ToUpper(stmt)
+	if strings.HasSuffix(upper, "CASCADE") {
+		return stmt
+	}
+	if strings.HasSuffix(upper, "RESTRICT") {
+		stmt = strings.TrimSpace(stmt[:len(stmt)-len("RESTRICT")])
+	}
+	return stmt + " CASCADE"
+}
+
+// pgErrorText renders an error with the server's DETAIL, which lists the blocking objects
+func pgErrorText(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		if pgErr.Detail != "" {
+			return fmt.Sprintf("%s (%s)", pgErr.Message, strings.ReplaceAll(pgErr.Detail, "\n", "; "))
+		}
+		return pgErr.Message
+	}
+	return err.Error()
+}
+
+// dryRunCommands are the statements a dry run executes: schema changes, which PostgreSQL runs inside
+// the transaction the dry run rolls back
+var dryRunCommands = map[string]bool{"CREATE": true, "ALTER": true, "DROP": true, "COMMENT": true, "GRANT": true, "REVOKE": true}
+
+// CheckDryRunStatement refuses a statement that could escape or weaken the rolled-back transaction:
+// transaction control (a COMMIT would make the changes real), SET (it would override the safety
+// timeouts), data changes and other non-DDL commands, several statements in one string, and DDL
+// that cannot run in a transaction block (CONCURRENTLY, ALTER SYSTEM, databases, tablespaces)
+func CheckDryRunStatement(stmt string) error {
+	statements := sqlparse.SplitStatements(stmt)
+	if len(statements) != 1 {
+		return fmt.Errorf("expected one statement, got %d", len(statements))
+	}
+	toks := statements[0].Tokens
+	if !dryRunCommands[strings.ToUpper(toks[0].Text)] || toks[0].Kind != sqlparse.Word {
+		return fmt.Errorf("only schema changes (CREATE, ALTER, DROP, COMMENT, GRANT, REVOKE) are dry-run, not %s", strings.ToUpper(toks[0].Text))
+	}
+	if len(toks) > 1 {
+		for _, kw := range []string{"SYSTEM", "DATABASE", "TABLESPACE"} {
+			if toks[1].IsKeyword(kw) {
+				return fmt.Errorf("%s %s cannot be rolled back", strings.ToUpper(toks[0].Text), kw)
+			}
+		}
+	}
+	for _, t := range toks {
+		if t.IsKeyword("CONCURRENTLY") {
+			return fmt.Errorf("CONCURRENTLY cannot run inside the dry-run transaction")
+		}
+	}
+	return nil
+}
+
+// DryRun executes the statements in order inside a single transaction and always rolls it back.
+// A statement blocked by dependent objects is retried with CASCADE to enumerate what it would
+// drop; the objects that disappear are found by comparing catalog snapshots taken before and after.
+// Statements CheckDryRunStatement refuses fail the whole dry run before anything is executed.
+func (p *PostgresAdapter) DryRun(statements []string) ([]graph.DryRunResult, error) {
+	for i, stmt := range statements {
+		if err := CheckDryRunStatement(stmt); err != nil {
+			return nil, fmt.Errorf("statement %d refused (%q): %w", i+1, sqlparse.OneLine(stmt, 60), err)
+		}
+	}
+	if p.Pool == nil {
+		return nil, fmt.Errorf("database connection not established")
+	}
+
+	ctx := context.Background()
+
+	tx, err := p.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction for dry run: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// 1. Apply Safety Wrappers
+	// Give up instead of queueing behind live traffic for an ACCESS EXCLUSIVE lock
+	if _, err := tx.Exec(ctx, "SET local lock_timeout = '2000ms'"); err != nil {
+		return nil, fmt.Errorf("failed to set lock_timeout: %w", err)
+	}
+	// Kill statements that would rewrite a large table (>5s)
+	if _, err := tx.Exec(ctx, "SET local statement_timeout = '5000ms'"); err != nil {
+		return nil, fmt.Errorf("failed to set statement_timeout: %w", err)
+	}
+
+	// 2. Execute each statement behind a savepoint so a failure does not abort the rest
+	results := make([]graph.DryRunResult, 0, len(statements))
+	for _, stmt := range statements {
+		res := graph.DryRunResult{Statement: stmt, Dropped: []graph.DroppedObject{}}
+
+		before, err := catalogObjects(ctx, tx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to snapshot catalog: %w", err)
+		}
+
+		if _, err := tx.Exec(ctx, "SAVEPOINT dbgraph_dry_run"); err != nil {
+			return nil, fmt.Errorf("failed to create savepoint: %w", err)
+		}
+		if _, err := tx.Exec(ctx, stmt); err != nil {
+			res.Error = pgErrorText(err)
+			if _, err := tx.Exec(ctx, "ROLLBACK TO SAVEPOINT dbgraph_dry_run"); err != nil {
+				return nil, fmt.Errorf("failed to roll back to savepoint: %w", err)
+			}
+
+			// 2BP01 = dependent_objects_still_exist
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "2BP01" {
+				res.Cascaded = true
+				if _, err := tx.Exec(ctx, withCascade(stmt)); err != nil {
+					res.CascadeError = pgErrorText(err)
+					if _, err := tx.Exec(ctx, "ROLLBACK TO SAVEPOINT dbgraph_dry_run"); err != nil {
+						return nil, fmt.Errorf("failed to roll back to savepoint: %w", err)
+					}
+				}
+			}
+		}
+
+		after, err := catalogObjects(ctx, tx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to snapshot catalog: %w", err)
+		}
+		for key, obj := range before {
+			if _, ok := after[key]; !ok {
+				res.Dropped = append(res.Dropped, obj)
+			}
+		}
+		sort.Slice(res.Dropped, func(i, j int) bool {
+			a, b := res.Dropped[i], res.Dropped[j]
+			if a.Type != b.Type {
+				return a.Type < b.Type
+			}
+			return a.Schema+"."+a.Name < b.Schema+"."+b.Name
+		})
+
+		results = append(results, res)
+	}
+
+	return results, nil
+}
+
+// readOnlyTx starts a read-only transaction that gives up on statements running over 5s
+func (p *PostgresAdapter) readOnlyTx(ctx context.Context) (pgx.Tx, error) {
+	if p.Pool == nil {
+		return nil, fmt.Errorf("database connection not established")
+	}
+	tx, err := p.Pool.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin read-only transaction: %w", err)
+	}
+	if _, err := tx.Exec(ctx, "SET local statement_timeout = '5000ms'"); err != nil {
+		tx.Rollback(ctx)
+		return nil, fmt.Errorf("failed to set statement_timeout: %w", err)
+	}
+	return tx, nil
+}
+
+// CheckWhereFilter refuses a --where filter that could escape the expression it is pasted into:
+// a semicolon (a second statement such as COMMIT) or parentheses that close the surrounding query.
+// The filter is otherwise trusted: the functions it calls run, inside the read-only transaction.
+func CheckWhereFilter(where string) error {
+	depth := 0
+	for _, t := range sqlparse.Tokenize(where) {
+		switch {
+		case t.IsPunct(";"):
+			return fmt.Errorf("the filter must be a single expression, without ';'")
+		case t.IsPunct("("):
+			depth++
+		case t.IsPunct(")"):
+			if depth--; depth < 0 {
+				return fmt.Errorf("unbalanced ')' in the filter")
+			}
+		}
+	}
+	if depth != 0 {
+		return fmt.Errorf("unbalanced '(' in the filter")
+	}
+	return nil
+}
+
+// EstimateRows returns the planner's row estimate for the rows of a table matching a filter,
+// from EXPLAIN: nothing is executed
+func (p *PostgresAdapter) EstimateRows(schema, table, where string) (int64, error) {
+	if err := CheckWhereFilter(where); err != nil {
+		return 0, err
+	}
+	ctx := context.Background()
+	tx, err := p.readOnlyTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := "EXPLAIN (FORMAT JSON) SELECT 1 FROM " + qualifiedTable(schema, table)
+	if where != "" {
+		query += " WHERE " + where
+	}
+	var jsonOutput []byte
+	if err := tx.QueryRow(ctx, query).Scan(&jsonOutput); err != nil {
+		return 0, fmt.Errorf("failed to estimate rows: %s", pgErrorText(err))
+	}
+	var plans []graph.ExplainOutput
+	if err := json.Unmarshal(jsonOutput, &plans); err != nil {
+		return 0, fmt.Errorf("failed to parse plan: %w", err)
+	}
+	if len(plans) == 0 || plans[0].Plan == nil {
+		return 0, fmt.Errorf("empty plan")
+	}
+	return int64(plans[0].Plan.PlanRows), nil
+}
+
+// CountCascade counts the rows each table of a cascade tree would lose, have updated or block the
+// DELETE with. A table's rows are selected by nesting the filters of its ancestors:
+// (fk columns) IN (SELECT referenced columns FROM parent WHERE <parent's filter>).
+// Each COUNT stops at limit rows so a huge table cannot stall the database.
+func (p *PostgresAdapter) CountCascade(g *graph.Graph, root *graph.CascadeNode, where string, limit int64) error {
+	if err := CheckWhereFilter(where); err != nil {
+		return err
+	}
+	ctx := context.Background()
+	tx, err := p.readOnlyTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	filters := make(map[*graph.CascadeNode]string)
+	var walkErr error
+	root.Walk(func(n, parent *graph.CascadeNode) {
+		if walkErr != nil {
+			return
+		}
+		node, ok := g.Nodes[n.Table]
+		if !ok {
+			walkErr = fmt.Errorf("table %s not found", n.Table)
+			return
+		}
+		table := qualifiedTable(node.Schema, node.Name)
+
+		filter := where
+		if parent != nil {
+			if len(n.Columns) == 0 || len(n.Columns) != len(n.RefColumns) {
+				walkErr = fmt.Errorf("columns of foreign key %s unknown", n.Constraint)
+				return
+			}
+			parentNode := g.Nodes[parent.Table]
+			filter = fmt.Sprintf("(%s) IN (SELECT %s FROM %s", quoteIdents(n.Columns), quoteIdents(n.RefColumns),
+				qualifiedTable(parentNode.Schema, parentNode.Name))
+			if filters[parent] != "" {
+				filter += " WHERE " + filters[parent]
+			}
+			filter += ")"
+		}
+		filters[n] = filter
+
+		query := "SELECT count(*) FROM (SELECT 1 FROM " + table
+		if filter != "" {
+			query += " WHERE " + filter
+		}
+		query += " LIMIT $1) bounded"
+		var rows int64
+		if err := tx.QueryRow(ctx, query, limit).Scan(&rows); err != nil {
+			walkErr = fmt.Errorf("failed to count rows of %s: %s", n.Table, pgErrorText(err))
+			return
+		}
+		n.Rows, n.Counted, n.Bounded, n.Unknown = rows, true, rows >= limit, false
+	})
+	return walkErr
+}
+
+// qualifiedTable quotes schema.table for generated SQL
+func qualifiedTable(schema, table string) string {
+	return sqlparse.QuoteIdent(schema) + "." + sqlparse.QuoteIdent(table)
+}
+
+// quoteIdents quotes and joins a column list for generated SQL
+func quoteIdents(columns []string) string {
+	quoted := make([]string, len(columns))
+	for i, c := range columns {
+		quoted[i] = sqlparse.QuoteIdent(c)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// GetLockSessions returns the sessions holding or waiting for relation locks, blocked by another
+// session or blocking one, with the relation locks of each
+func (p *PostgresAdapter) GetLockSessions() ([]graph.LockSession, error) {
+	if p.Pool == nil {
+		return nil, fmt.Errorf("database connection not established")
+	}
+	ctx := context.Background()
+
+	locks := make(map[int][]graph.RelationLock)
+	lRows, err := p.Pool.Query(ctx, queryRelationLocks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch locks: %w", err)
+	}
+	defer lRows.Close()
+	for lRows.Next() {
+		var pid int32
+		var schema, name, mode string
+		var granted bool
+		if err := lRows.Scan(&pid, &schema, &name, &mode, &granted); err != nil {
+			return nil, err
+		}
+		if m, ok := sqlparse.ParseLockMode(mode); ok {
+			mode = string(m)
+		}
+		locks[int(pid)] = append(locks[int(pid)], graph.RelationLock{Relation: fmt.Sprintf("%s.%s", schema, name), Mode: mode, Granted: granted})
+	}
+	if err := lRows.Err(); err != nil {
+		return nil, err
+	}
+
+	sRows, err := p.Pool.Query(ctx, queryLockSessions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sessions: %w", err)
+	}
+	defer sRows.Close()
+
+	var all []graph.LockSession
+	blocking := make(map[int]bool)
+	for sRows.Next() {
+		var pid int32
+		var blockedBy []int32
+		s := graph.LockSession{}
+		if err := sRows.Scan(&pid, &s.User, &s.State, &s.Query, &s.DurationMs, &s.WaitEvent, &blockedBy); err != nil {
+			return nil, err
+		}
+		s.PID = int(pid)
+		for _, b := range blockedBy {
+			s.BlockedBy = append(s.BlockedBy, int(b))
+		}
+		for _, b := range s.BlockedBy {
+			blocking[b] = true
+		}
+		s.Locks = locks[s.PID]
+		if s.Locks == nil {
+			s.Locks = []graph.RelationLock{}
+		}
+		all = append(all, s)
+	}
+	if err := sRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Blockers may hold only row or transaction locks: keep them along with the lock holders
+	var sessions []graph.LockSession
+	for _, s := range all {
+		if len(s.Locks) > 0 || len(s.BlockedBy) > 0 || blocking[s.PID] {
+			sessions = append(sessions, s)
+		}
+	}
+	return sessions, nil
+}
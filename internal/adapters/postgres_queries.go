@@ -1,8 +1,7 @@
 package adapters
 
 const (
-	// queryFetchNodes fetches tables, views, and materialized views with their size and row count
-	// queryFetchNodes fetches tables, views, and materialized views with their size and row count
+	// queryFetchNodes fetches tables, views, and materialized views with their size, row count and write volume
 	queryFetchNodes = `
 		SELECT
 			ns.nspname AS schema_name,
@@ -19,7 +18,8 @@ const (
 				WHEN cl.relkind = 'r' THEN COALESCE(stat.n_live_tup, cl.reltuples, 0)
 				WHEN cl.relkind = 'p' THEN COALESCE(cl.reltuples, 0)
 				ELSE COALESCE(cl.reltuples, 0)
-			END AS row_count
+			END AS row_count,
+			COALESCE(stat.n_tup_ins + stat.n_tup_upd + stat.n_tup_del, 0) AS writes
 		FROM pg_class cl
 		JOIN pg_namespace ns ON cl.relnamespace = ns.oid
 		LEFT JOIN pg_stat_user_tables stat ON stat.relid = cl.oid
@@ -33,6 +33,7 @@ const (
 			n.nspname as schema,
 			c.relname as table_name,
 			t.tgname as trigger_name,
+			pn.nspname as function_schema,
 			p.proname as function_name,
 			CASE t.tgtype & 1
 				WHEN 1 THEN 'ROW'
@@ -42,27 +43,178 @@ const (
 		JOIN pg_class c ON t.tgrelid = c.oid
 		JOIN pg_namespace n ON c.relnamespace = n.oid
 		JOIN pg_proc p ON t.tgfoid = p.oid
+		JOIN pg_namespace pn ON p.pronamespace = pn.oid
 		WHERE NOT t.tgisinternal
 		AND n.nspname NOT IN ('information_schema', 'pg_catalog');
 	`
 
+	// queryFetchFunctions fetches user functions and procedures (extension members excluded) with their source.
+	// SQL-standard bodies (BEGIN ATOMIC ... END, RETURN expr; PG14+) leave prosrc empty: the full definition is used.
+	queryFetchFunctions = `
+		SELECT
+			n.nspname AS schema,
+			p.proname AS name,
+			l.lanname AS language,
+			CASE WHEN p.prosrc = '' THEN pg_get_functiondef(p.oid) ELSE p.prosrc END AS body
+		FROM pg_proc p
+		JOIN pg_namespace n ON p.pronamespace = n.oid
+		JOIN pg_language l ON p.prolang = l.oid
+		WHERE n.nspname NOT IN ('information_schema', 'pg_catalog')
+		  AND n.nspname NOT LIKE 'pg_toast%'
+		  AND NOT EXISTS (
+			SELECT 1 FROM pg_depend d
+			WHERE d.classid = 'pg_proc'::regclass AND d.objid = p.oid AND d.deptype = 'e'
+		  )
+		ORDER BY 1, 2;
+	`
+
+	// queryFetchTypes fetches enums, domains and standalone composite types (not the row types of tables)
+	queryFetchTypes = `
+		SELECT
+			n.nspname AS schema,
+			t.typname AS name,
+			CASE t.typtype WHEN 'e' THEN 'enum' WHEN 'd' THEN 'domain' ELSE 'composite' END AS kind
+		FROM pg_type t
+		JOIN pg_namespace n ON t.typnamespace = n.oid
+		LEFT JOIN pg_class c ON t.typrelid = c.oid
+		WHERE (t.typtype IN ('e', 'd') OR c.relkind = 'c')
+		  AND n.nspname NOT IN ('information_schema', 'pg_catalog')
+		  AND n.nspname NOT LIKE 'pg_toast%'
+		  AND NOT EXISTS (
+			SELECT 1 FROM pg_depend d
+			WHERE d.classid = 'pg_type'::regclass AND d.objid = t.oid AND d.deptype = 'e'
+		  );
+	`
+
+	// queryTypeUsage fetches the columns (of tables, views and composite types), domains and
+	// function signatures using an enum, domain or composite type, directly or as an array
+	queryTypeUsage = `
+		WITH user_types AS (
+			SELECT t.oid, t.typarray, n.nspname AS type_schema, t.typname AS type_name
+			FROM pg_type t
+			JOIN pg_namespace n ON t.typnamespace = n.oid
+			LEFT JOIN pg_class c ON t.typrelid = c.oid
+			WHERE (t.typtype IN ('e', 'd') OR c.relkind = 'c')
+			  AND n.nspname NOT IN ('information_schema', 'pg_catalog')
+		)
+		SELECT cn.nspname, c.relname,
+			CASE c.relkind WHEN 'c' THEN 'TYPE' WHEN 'v' THEN 'VIEW' WHEN 'm' THEN 'VIEW' ELSE 'TABLE' END,
+			a.attname, ut.type_schema, ut.type_name
+		FROM pg_attribute a
+		JOIN pg_class c ON a.attrelid = c.oid
+		JOIN pg_namespace cn ON c.relnamespace = cn.oid
+		JOIN user_types ut ON a.atttypid IN (ut.oid, ut.typarray)
+		WHERE a.attnum > 0 AND NOT a.attisdropped
+		  AND c.relkind IN ('r', 'p', 'v', 'm', 'c')
+		UNION ALL
+		SELECT dn.nspname, d.typname, 'DOMAIN', '', ut.type_schema, ut.type_name
+		FROM pg_type d
+		JOIN pg_namespace dn ON d.typnamespace = dn.oid
+		JOIN user_types ut ON d.typbasetype IN (ut.oid, ut.typarray)
+		WHERE d.typtype = 'd'
+		UNION ALL
+		SELECT DISTINCT pn.nspname, p.proname, 'FUNCTION', '', ut.type_schema, ut.type_name
+		FROM pg_depend dep
+		JOIN pg_proc p ON dep.classid = 'pg_proc'::regclass AND dep.objid = p.oid
+		JOIN pg_namespace pn ON p.pronamespace = pn.oid
+		JOIN user_types ut ON dep.refclassid = 'pg_type'::regclass AND dep.refobjid IN (ut.oid, ut.typarray)
+		WHERE dep.deptype = 'n'
+		ORDER BY 1, 2, 4;
+	`
+
+	// queryFetchSequences fetches sequences with the column owning them, if any
+	// (identity sequences are part of their column and left out)
+	queryFetchSequences = `
+		SELECT
+			n.nspname AS schema,
+			s.relname AS name,
+			COALESCE(tn.nspname, '') AS owner_schema,
+			COALESCE(t.relname, '') AS owner_table,
+			COALESCE(a.attname, '') AS owner_column
+		FROM pg_class s
+		JOIN pg_namespace n ON s.relnamespace = n.oid
+		LEFT JOIN pg_depend d ON d.classid = 'pg_class'::regclass AND d.objid = s.oid
+			AND d.refclassid = 'pg_class'::regclass AND d.deptype = 'a'
+		LEFT JOIN pg_class t ON d.refobjid = t.oid
+		LEFT JOIN pg_namespace tn ON t.relnamespace = tn.oid
+		LEFT JOIN pg_attribute a ON a.attrelid = d.refobjid AND a.attnum = d.refobjsubid
+		WHERE s.relkind = 'S'
+		  AND n.nspname NOT IN ('information_schema', 'pg_catalog')
+		  AND NOT EXISTS (
+			SELECT 1 FROM pg_depend i
+			WHERE i.classid = 'pg_class'::regclass AND i.objid = s.oid AND i.deptype IN ('i', 'e')
+		  );
+	`
+
+	// querySequenceDefaults fetches the column defaults that draw from a sequence (nextval)
+	querySequenceDefaults = `
+		SELECT tn.nspname, t.relname, a.attname, sn.nspname, s.relname
+		FROM pg_depend d
+		JOIN pg_attrdef ad ON d.classid = 'pg_attrdef'::regclass AND d.objid = ad.oid
+		JOIN pg_class t ON ad.adrelid = t.oid
+		JOIN pg_namespace tn ON t.relnamespace = tn.oid
+		JOIN pg_attribute a ON a.attrelid = ad.adrelid AND a.attnum = ad.adnum
+		JOIN pg_class s ON d.refclassid = 'pg_class'::regclass AND d.refobjid = s.oid
+		JOIN pg_namespace sn ON s.relnamespace = sn.oid
+		WHERE s.relkind = 'S'
+		  AND tn.nspname NOT IN ('information_schema', 'pg_catalog');
+	`
+
 	// queryDBVersion fetches the PostgreSQL version
 	queryDBVersion = "SHOW server_version"
 
-	// queryFetchIndexes fetches index columns for tables to detect missing indexes
+	// queryFetchIndexes fetches every index with its key columns and expressions (in key order, without
+	// INCLUDE columns), predicate, the constraint it enforces, size and scan counts
 	queryFetchIndexes = `
-		select
-			ns.nspname as schema_name,
-			t.relname as table_name,
+		SELECT
+			ns.nspname AS schema_name,
+			t.relname AS table_name,
+			i.relname AS index_name,
+			am.amname AS method,
+			ix.indisunique,
+			ix.indisprimary,
+			COALESCE(con.conname, '') AS constraint_name,
+			COALESCE((
+				SELECT array_agg(a.attname ORDER BY k.ord)
+				FROM unnest(ix.indkey::int2[]) WITH ORDINALITY AS k(attnum, ord)
+				JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+				WHERE k.ord <= ix.indnkeyatts
+			), '{}') AS columns,
+			COALESCE((
+				SELECT array_agg(pg_get_indexdef(i.oid, k.ord::int, true) ORDER BY k.ord)
+				FROM unnest(ix.indkey::int2[]) WITH ORDINALITY AS k(attnum, ord)
+				WHERE k.attnum = 0 AND k.ord <= ix.indnkeyatts
+			), '{}') AS expressions,
+			COALESCE(pg_get_expr(ix.indpred, ix.indrelid, true), '') AS predicate,
+			pg_size_pretty(pg_relation_size(i.oid)) AS size,
+			pg_relation_size(i.oid) AS size_bytes,
+			COALESCE(s.idx_scan, 0) AS scans,
+			COALESCE(s.idx_tup_read, 0) AS tuples_read
+		FROM pg_index ix
+		JOIN pg_class i ON ix.indexrelid = i.oid
+		JOIN pg_class t ON ix.indrelid = t.oid
+		JOIN pg_namespace ns ON t.relnamespace = ns.oid
+		JOIN pg_am am ON i.relam = am.oid
+		LEFT JOIN pg_constraint con ON con.conindid = i.oid AND con.conrelid = t.oid AND con.contype IN ('p', 'u', 'x')
+		LEFT JOIN pg_stat_user_indexes s ON s.indexrelid = i.oid
+		WHERE ns.nspname NOT IN ('information_schema', 'pg_catalog', 'pg_toast');
+	`
+
+	// queryFetchPrimaryKeys fetches the primary key columns of every table
+	queryFetchPrimaryKeys = `
+		SELECT
+			ns.nspname AS table_schema,
+			cl.relname AS table_name,
 			(
-				select array_agg(a.attname order by array_position(ix.indkey, a.attnum))
-				from pg_attribute a
-				where a.attrelid = t.oid and a.attnum = any(ix.indkey)
-			) as columns
-		from pg_index ix
-		join pg_class t on ix.indrelid = t.oid
-		join pg_namespace ns on t.relnamespace = ns.oid
-		where ns.nspname not in ('information_schema', 'pg_catalog', 'pg_toast');
+				SELECT array_agg(a.attname ORDER BY array_position(con.conkey, a.attnum))
+				FROM pg_attribute a
+				WHERE a.attrelid = cl.oid AND a.attnum = ANY(con.conkey)
+			) AS pk_columns
+		FROM pg_constraint con
+		JOIN pg_class cl ON con.conrelid = cl.oid
+		JOIN pg_namespace ns ON cl.relnamespace = ns.oid
+		WHERE con.contype = 'p'
+		  AND ns.nspname NOT IN ('information_schema', 'pg_catalog');
 	`
 
 	// queryFetchForeignKeys fetches foreign key constraints and their metadata
@@ -84,12 +236,30 @@ const (
 				SELECT array_agg(a.attname ORDER BY array_position(con.conkey, a.attnum))
 				FROM pg_attribute a
 				WHERE a.attrelid = cl.oid AND a.attnum = ANY(con.conkey)
-			) AS fk_columns
+			) AS fk_columns,
+			(
+				SELECT array_agg(a.attname ORDER BY array_position(con.conkey, a.attnum))
+				FROM pg_attribute a
+				WHERE a.attrelid = cl.oid AND a.attnum = ANY(con.conkey) AND NOT a.attnotnull
+			) AS nullable_columns,
+			COALESCE(ci.relname, '') AS referenced_index,
+			(
+				SELECT array_agg(a.attname ORDER BY array_position(con.confkey, a.attnum))
+				FROM pg_attribute a
+				WHERE a.attrelid = fcl.oid AND a.attnum = ANY(con.confkey)
+			) AS referenced_columns,
+			st.n_distinct AS fk_n_distinct,
+			st.null_frac AS fk_null_frac
 		FROM pg_constraint con
+		LEFT JOIN pg_class ci ON con.conindid = ci.oid
 		JOIN pg_class cl ON con.conrelid = cl.oid
 		JOIN pg_namespace ns ON cl.relnamespace = ns.oid
 		JOIN pg_class fcl ON con.confrelid = fcl.oid
 		JOIN pg_namespace fns ON fcl.relnamespace = fns.oid
+		-- Planner statistics of the leading FK column, to estimate the rows a cascading delete reaches
+		LEFT JOIN pg_attribute fa ON fa.attrelid = cl.oid AND fa.attnum = con.conkey[1]
+		LEFT JOIN pg_stats st ON st.schemaname = ns.nspname AND st.tablename = cl.relname
+			AND st.attname = fa.attname AND NOT st.inherited
 		WHERE con.contype = 'f'
 		  AND ns.nspname NOT IN ('information_schema', 'pg_catalog')
 		  AND fns.nspname NOT IN ('information_schema', 'pg_catalog');
@@ -116,6 +286,59 @@ const (
 		  AND ref.relnamespace::regnamespace::text NOT IN ('information_schema', 'pg_catalog');
 	`
 
+	// queryFetchViewColumns fetches the columns each view reads (pg_depend.refobjsubid) and its definition
+	queryFetchViewColumns = `
+		SELECT
+			v.relnamespace::regnamespace::text AS view_schema,
+			v.relname AS view_name,
+			pg_get_viewdef(v.oid, true) AS definition,
+			ref.relnamespace::regnamespace::text AS source_schema,
+			ref.relname AS source_name,
+			a.attname AS source_column
+		FROM pg_depend d
+		JOIN pg_rewrite r ON d.objid = r.oid
+		JOIN pg_class v ON r.ev_class = v.oid
+		JOIN pg_class ref ON d.refobjid = ref.oid
+		JOIN pg_attribute a ON a.attrelid = d.refobjid AND a.attnum = d.refobjsubid
+		WHERE v.relkind IN ('v', 'm')
+		  AND d.classid = 'pg_rewrite'::regclass
+		  AND d.refclassid = 'pg_class'::regclass
+		  AND d.refobjsubid > 0
+		  AND v.oid != ref.oid
+		  AND v.relnamespace::regnamespace::text NOT IN ('information_schema', 'pg_catalog')
+		ORDER BY 1, 2;
+	`
+
+	// queryLockSessions lists the other client sessions and autovacuum workers of the database, with
+	// the PIDs blocking them
+	queryLockSessions = `
+		SELECT
+			a.pid,
+			COALESCE(a.usename, '') AS usename,
+			COALESCE(a.state, '') AS state,
+			COALESCE(a.query, '') AS query,
+			COALESCE(EXTRACT(EPOCH FROM now() - COALESCE(a.xact_start, a.query_start)) * 1000, 0)::float8 AS duration_ms,
+			COALESCE(a.wait_event_type || ': ' || a.wait_event, '') AS wait_event,
+			pg_blocking_pids(a.pid) AS blocked_by
+		FROM pg_stat_activity a
+		WHERE a.datname = current_database()
+		  AND a.pid <> pg_backend_pid()
+		  AND a.backend_type IN ('client backend', 'autovacuum worker');
+	`
+
+	// queryRelationLocks lists the relation locks held or awaited in the database, except our own
+	queryRelationLocks = `
+		SELECT l.pid, ns.nspname, cl.relname, l.mode, l.granted
+		FROM pg_locks l
+		JOIN pg_class cl ON cl.oid = l.relation
+		JOIN pg_namespace ns ON ns.oid = cl.relnamespace
+		WHERE l.locktype = 'relation'
+		  AND l.database = (SELECT oid FROM pg_database WHERE datname = current_database())
+		  AND l.pid <> pg_backend_pid()
+		  AND ns.nspname NOT IN ('pg_catalog', 'information_schema')
+		ORDER BY l.pid, ns.nspname, cl.relname;
+	`
+
 	// queryActiveLocks counts active locks in the database
 	queryActiveLocks = "SELECT count(*) FROM pg_locks WHERE granted = true"
 
@@ -151,15 +374,6 @@ const (
 		  AND n.nspname NOT IN ('information_schema', 'pg_catalog');
 	`
 
-	// queryFetchFunctionBody fetches the definition of a function
-	queryFetchFunctionBody = `
-		SELECT p.prosrc 
-		FROM pg_proc p 
-		JOIN pg_namespace n ON p.pronamespace = n.oid 
-		WHERE p.proname = $1 
-		  AND n.nspname = $2
-	`
-
 	// queryGetColumnAttNum fetches specific attribute number
 	queryGetColumnAttNum = `
 		SELECT a.attnum
@@ -199,6 +413,14 @@ const (
 		  -- Removed strict deptype filtering to catch everything
 	`
 
+	// queryViewDefinition fetches the normalized SELECT of a view or materialized view
+	queryViewDefinition = `
+		SELECT pg_get_viewdef(c.oid, true)
+		FROM pg_class c
+		JOIN pg_namespace n ON c.relnamespace = n.oid
+		WHERE n.nspname = $1 AND c.relname = $2 AND c.relkind IN ('v', 'm')
+	`
+
 	// queryFKRefsByColumn finds FKs that reference this specific column
 	queryFKRefsByColumn = `
 		SELECT 
@@ -225,16 +447,34 @@ const (
 		  AND $3 = ANY(ix.indkey)
 	`
 
-	// queryScanFunctionsForColumn usage scans function bodies for column usage (regex/text search)
-	queryScanFunctionsForColumn = `
-		SELECT
-			n.nspname AS schema_name,
-			p.proname AS function_name,
-			p.prosrc AS source_code
-		FROM pg_proc p
-		JOIN pg_namespace n ON p.pronamespace = n.oid
-		WHERE n.nspname NOT IN ('information_schema', 'pg_catalog')
-		  AND p.prosrc ILIKE '%' || $1 || '%'
+	// queryFunctionDependents fetches triggers, views and column defaults that call a function
+	queryFunctionDependents = `
+		SELECT 'TRIGGER' AS dep_type, n.nspname, t.tgname, c.relname
+		FROM pg_trigger t
+		JOIN pg_class c ON t.tgrelid = c.oid
+		JOIN pg_namespace n ON c.relnamespace = n.oid
+		JOIN pg_proc p ON t.tgfoid = p.oid
+		JOIN pg_namespace pn ON p.pronamespace = pn.oid
+		WHERE NOT t.tgisinternal AND pn.nspname = $1 AND p.proname = $2
+		UNION
+		SELECT 'VIEW', vn.nspname, v.relname, v.relname
+		FROM pg_depend d
+		JOIN pg_rewrite r ON d.classid = 'pg_rewrite'::regclass AND d.objid = r.oid
+		JOIN pg_class v ON r.ev_class = v.oid
+		JOIN pg_namespace vn ON v.relnamespace = vn.oid
+		JOIN pg_proc p ON d.refclassid = 'pg_proc'::regclass AND d.refobjid = p.oid
+		JOIN pg_namespace pn ON p.pronamespace = pn.oid
+		WHERE pn.nspname = $1 AND p.proname = $2
+		UNION
+		SELECT 'DEFAULT', cn.nspname, a.attname, c.relname
+		FROM pg_depend d
+		JOIN pg_attrdef ad ON d.classid = 'pg_attrdef'::regclass AND d.objid = ad.oid
+		JOIN pg_attribute a ON a.attrelid = ad.adrelid AND a.attnum = ad.adnum
+		JOIN pg_class c ON ad.adrelid = c.oid
+		JOIN pg_namespace cn ON c.relnamespace = cn.oid
+		JOIN pg_proc p ON d.refclassid = 'pg_proc'::regclass AND d.refobjid = p.oid
+		JOIN pg_namespace pn ON p.pronamespace = pn.oid
+		WHERE pn.nspname = $1 AND p.proname = $2
 	`
 
 	// queryTableDependencies fetches objects that depend on a whole table
@@ -299,4 +539,46 @@ const (
 			(total_time * 100 / SUM(total_time) OVER()) as load_percent
 		FROM stats
 	`
+
+	// queryCatalogObjects lists user objects keyed by OID, so renamed objects keep their identity
+	// between the before/after snapshots of a dry run
+	queryCatalogObjects = `
+		SELECT 'pg_class:' || c.oid,
+			CASE c.relkind
+				WHEN 'r' THEN 'TABLE'
+				WHEN 'p' THEN 'TABLE'
+				WHEN 'v' THEN 'VIEW'
+				WHEN 'm' THEN 'MATERIALIZED VIEW'
+				WHEN 'i' THEN 'INDEX'
+				WHEN 'I' THEN 'INDEX'
+				WHEN 'S' THEN 'SEQUENCE'
+				ELSE 'FOREIGN TABLE'
+			END,
+			n.nspname, c.relname, COALESCE(t.relname, '')
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN pg_index i ON i.indexrelid = c.oid
+		LEFT JOIN pg_class t ON t.oid = i.indrelid
+		WHERE c.relkind IN ('r', 'p', 'v', 'm', 'i', 'I', 'S', 'f')
+		  AND n.nspname NOT IN ('information_schema', 'pg_catalog')
+		  AND n.nspname NOT LIKE 'pg_toast%'
+		UNION ALL
+		SELECT 'pg_constraint:' || con.oid, 'CONSTRAINT', n.nspname, con.conname, COALESCE(c.relname, '')
+		FROM pg_constraint con
+		JOIN pg_namespace n ON n.oid = con.connamespace
+		LEFT JOIN pg_class c ON c.oid = con.conrelid
+		WHERE n.nspname NOT IN ('information_schema', 'pg_catalog')
+		UNION ALL
+		SELECT 'pg_trigger:' || t.oid, 'TRIGGER', n.nspname, t.tgname, c.relname
+		FROM pg_trigger t
+		JOIN pg_class c ON c.oid = t.tgrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE NOT t.tgisinternal
+		  AND n.nspname NOT IN ('information_schema', 'pg_catalog')
+		UNION ALL
+		SELECT 'pg_proc:' || p.oid, 'FUNCTION', n.nspname, p.proname, ''
+		FROM pg_proc p
+		JOIN pg_namespace n ON n.oid = p.pronamespace
+		WHERE n.nspname NOT IN ('information_schema', 'pg_catalog')
+	`
 )
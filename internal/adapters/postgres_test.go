@@ -0,0 +1,84 @@
+package adapters
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithCascade(t *testing.T) {
+	tests := []struct {
+		stmt string
+		want string
+	}{
+		{"DROP TABLE users", "DROP TABLE users CASCADE"},
+		{"DROP VIEW v RESTRICT;", "DROP VIEW v CASCADE"},
+		{"ALTER TABLE users DROP COLUMN email cascade", "ALTER TABLE users DROP COLUMN email cascade"},
+		{"  DROP FUNCTION f(int) ;\n", "DROP FUNCTION f(int) CASCADE"},
+	}
+	for _, tt := range tests {
+		if got := withCascade(tt.stmt); got != tt.want {
+			t.Errorf("withCascade(%q) = %q, want %q", tt.stmt, got, tt.want)
+		}
+	}
+}
+
+func TestCheckDryRunStatement(t *testing.T) {
+	tests := []struct {
+		stmt string
+		ok   bool
+	}{
+		{"DROP TABLE users", true},
+		{"ALTER TABLE users DROP COLUMN email", true},
+		{"create view v as select 1", true},
+		{"COMMENT ON TABLE users IS 'x'", true},
+		{"COMMIT", false},
+		{"BEGIN", false},
+		{"ROLLBACK", false},
+		{"END", false},
+		{"SET lock_timeout = 0", false},
+		{"RESET statement_timeout", false},
+		{"DELETE FROM users", false},
+		{"INSERT INTO users VALUES (1)", false},
+		{"DO $$ BEGIN COMMIT; END $$", false},
+		{"CALL archive()", false},
+		{"CREATE INDEX CONCURRENTLY idx ON users (email)", false},
+		{"ALTER SYSTEM SET work_mem = '1GB'", false},
+		{"DROP DATABASE app", false},
+		{"DROP TABLE a; COMMIT", false},
+		{"-- only a comment", false},
+	}
+	for _, tt := range tests {
+		if err := CheckDryRunStatement(tt.stmt); (err == nil) != tt.ok {
+			t.Errorf("CheckDryRunStatement(%q) = %v, want ok=%v", tt.stmt, err, tt.ok)
+		}
+	}
+}
+
+func TestCheckWhereFilter(t *testing.T) {
+	tests := []struct {
+		where string
+		ok    bool
+	}{
+		{"id = 42", true},
+		{"status IN ('a', 'b') AND (id > 1 OR id < 0)", true},
+		{"note = 'a;b)'", true},
+		{"1=1; COMMIT", false},
+		{"1=1; COMMIT; DELETE FROM users", false},
+		{"1=1) bounded; DROP TABLE users; --", false},
+		{"id IN (1, 2", false},
+	}
+	for _, tt := range tests {
+		if err := CheckWhereFilter(tt.where); (err == nil) != tt.ok {
+			t.Errorf("CheckWhereFilter(%q) = %v, want ok=%v", tt.where, err, tt.ok)
+		}
+	}
+}
+
+func TestDryRunRefusesTransactionControl(t *testing.T) {
+	// Refused before the connection is used: the unconnected adapter would fail otherwise
+	p := NewPostgresAdapter()
+	_, err := p.DryRun([]string{"COMMIT", "DROP TABLE users"})
+	if err == nil || !strings.Contains(err.Error(), "statement 1 refused") {
+		t.Fatalf("expected the COMMIT to be refused, got %v", err)
+	}
+}
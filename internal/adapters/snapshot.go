@@ -0,0 +1,200 @@
+package adapters
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/alexanderritik/dbgraph/internal/graph"
+)
+
+// SnapshotAdapter serves a graph captured by "dbgraph snapshot save"
+type SnapshotAdapter struct {
+	Path     string
+	Snapshot *graph.Snapshot
+}
+
+// NewSnapshotAdapter creates a new snapshot adapter
+func NewSnapshotAdapter() *SnapshotAdapter {
+	return &SnapshotAdapter{}
+}
+
+// snapshotPath extracts the path from snapshot://path (snapshot:///abs/path for absolute paths)
+func snapshotPath(connString string) (string, error) {
+	path := strings.TrimPrefix(connString, "snapshot://")
+	if path == "" || path == connString {
+		return "", fmt.Errorf("invalid snapshot connection string: %s (expected snapshot://prod.json)", connString)
+	}
+	return path, nil
+}
+
+// Connect loads the snapshot file
+func (s *SnapshotAdapter) Connect(connString string) error {
+	path, err := snapshotPath(connString)
+	if err != nil {
+		return err
+	}
+	snap, err := graph.LoadSnapshotFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to load snapshot: %v\n", err)
+		return err
+	}
+
+	s.Path = path
+	s.Snapshot = snap
+	return nil
+}
+
+// Close is a no-op; the snapshot is read once in Connect
+func (s *SnapshotAdapter) Close() {}
+
+// ServerVersion returns the server version recorded when the snapshot was captured
+func (s *SnapshotAdapter) ServerVersion() (string, error) {
+	if s.Snapshot == nil {
+		return "", fmt.Errorf("snapshot not loaded")
+	}
+	return s.Snapshot.ServerVersion, nil
+}
+
+// FetchSchema copies the captured nodes, edges and column lineage into the graph
+func (s *SnapshotAdapter) FetchSchema(g *graph.Graph) error {
+	if s.Snapshot == nil {
+		return fmt.Errorf("snapshot not loaded")
+	}
+
+	for id, n := range s.Snapshot.Graph.Nodes {
+		node := *n
+		node.Indexes = append([][]string(nil), n.Indexes...)
+		if n.Index != nil {
+			info := *n.Index
+			node.Index = &info
+		}
+		g.Nodes[id] = &node
+	}
+	for src, edges := range s.Snapshot.Graph.Edges {
+		for _, e := range edges {
+			edge := *e
+			if e.MetaData != nil {
+				edge.MetaData = make(map[string]string, len(e.MetaData))
+				for k, v := range e.MetaData {
+					edge.MetaData[k] = v
+				}
+			}
+			g.Edges[src] = append(g.Edges[src], &edge)
+		}
+	}
+	for _, l := range s.Snapshot.Graph.Lineage {
+		g.AddLineage(l.Source, l.Target)
+	}
+	return nil
+}
+
+// GetMetrics is not available: a snapshot holds structure, not live activity
+func (s *SnapshotAdapter) GetMetrics() (*graph.DBMetrics, error) {
+	return nil, fmt.Errorf("%w: snapshots do not record server activity", ErrUnsupported)
+}
+
+// GetColumnDependencies is not available: snapshots only record relations, not column definitions
+func (s *SnapshotAdapter) GetColumnDependencies(schema, table, column string) ([]graph.ColumnDependency, error) {
+	return nil, fmt.Errorf("%w: snapshots do not record column definitions (use --drop-table or a live database)", ErrUnsupported)
+}
+
+// GetTableDependencies derives the objects depending on a table from the captured edges
+func (s *SnapshotAdapter) GetTableDependencies(schema, table string) ([]graph.ColumnDependency, error) {
+	if s.Snapshot == nil {
+		return nil, fmt.Errorf("snapshot not loaded")
+	}
+	g := s.Snapshot.Graph
+	targetID := fmt.Sprintf("%s.%s", schema, table)
+	if _, ok := g.Nodes[targetID]; !ok {
+		return nil, fmt.Errorf("table '%s' not found in snapshot", targetID)
+	}
+
+	// Map iteration order is random; keep the report stable
+	sources := make([]string, 0, len(g.Edges))
+	for src := range g.Edges {
+		sources = append(sources, src)
+	}
+	sort.Strings(sources)
+
+	var deps []graph.ColumnDependency
+	for _, src := range sources {
+		if src == targetID {
+			continue
+		}
+		node, ok := g.Nodes[src]
+		if !ok {
+			continue
+		}
+		for _, e := range g.Edges[src] {
+			if e.TargetID != targetID {
+				continue
+			}
+			dep := graph.ColumnDependency{Schema: node.Schema, Name: node.Name}
+			switch e.Type {
+			case graph.ForeignKey:
+				dep.Type = "FOREIGN_KEY"
+				dep.Detail = fmt.Sprintf("Constraint: %s", e.ConstraintName)
+				deps = append(deps, dep)
+				continue
+			case graph.ViewDepends:
+				dep.Type = "VIEW"
+				dep.Detail = "Hard Dependency (view definition)"
+			case graph.TriggerAction:
+				dep.Type = "TRIGGER"
+				dep.Detail = "Trigger on table"
+				if e.ConstraintName == "Function Call" {
+					dep.Detail = "Code Reference (trigger function)"
+				}
+			case graph.SequenceOwned, graph.IndexOn:
+				// Dropped together with the table, like the automatic dependencies Postgres does not report
+				continue
+			case graph.Inheritance:
+				dep.Type = "TABLE"
+				dep.Detail = "Hard Dependency (partition)"
+			case graph.FunctionAccess:
+				dep.Type = "FUNCTION"
+				if node.Type == graph.Trigger {
+					dep.Type = "TRIGGER"
+				}
+				access := "reads"
+				if strings.Contains(e.MetaData["access"], "write") {
+					access = "writes"
+				}
+				dep.Detail = fmt.Sprintf("Code Reference (%s body %s, confidence: %s)", strings.ToLower(dep.Type), access, e.MetaData["confidence"])
+			default:
+				dep.Type = "RELATION"
+				dep.Detail = string(e.Type)
+			}
+			deps = appendDependency(deps, dep)
+		}
+	}
+	return deps, nil
+}
+
+// GetTypeDependencies derives the objects using a type from the captured edges
+func (s *SnapshotAdapter) GetTypeDependencies(schema, name string) ([]graph.ColumnDependency, error) {
+	if s.Snapshot == nil {
+		return nil, fmt.Errorf("snapshot not loaded")
+	}
+	return graphTypeDependencies(s.Snapshot.Graph, schema, name)
+}
+
+// GetIndexDependencies derives the constraints and foreign keys relying on an index from the captured graph
+func (s *SnapshotAdapter) GetIndexDependencies(schema, name string) ([]graph.ColumnDependency, error) {
+	if s.Snapshot == nil {
+		return nil, fmt.Errorf("snapshot not loaded")
+	}
+	return graphIndexDependencies(s.Snapshot.Graph, schema, name)
+}
+
+// GetTopQueries is not available: a snapshot holds structure, not workload
+func (s *SnapshotAdapter) GetTopQueries(limit int, sortBy string) ([]graph.QueryStats, error) {
+	return nil, fmt.Errorf("%w: snapshots do not record query statistics", ErrUnsupported)
+}
+
+// TraceQuery is not available: queries cannot be executed against a snapshot
+func (s *SnapshotAdapter) TraceQuery(query string) (*graph.TraceResult, error) {
+	return nil, fmt.Errorf("%w: queries cannot be traced against a snapshot", ErrUnsupported)
+}
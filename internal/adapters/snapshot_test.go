@@ -0,0 +1,53 @@
+package adapters
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/alexanderritik/dbgraph/internal/graph"
+)
+
+func TestSnapshotAdapter(t *testing.T) {
+	// Capture the Postgres fixture offline, then serve it back from the snapshot
+	src := newFileFixture(t)
+	captured := graph.NewGraph()
+	if err := src.FetchSchema(captured); err != nil {
+		t.Fatalf("FetchSchema: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := graph.SaveSnapshotFile(path, graph.NewSnapshot(captured, "file", "")); err != nil {
+		t.Fatalf("SaveSnapshotFile: %v", err)
+	}
+
+	a := NewSnapshotAdapter()
+	if err := a.Connect("snapshot://" + path); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	g := graph.NewGraph()
+	if err := a.FetchSchema(g); err != nil {
+		t.Fatalf("FetchSchema: %v", err)
+	}
+	if len(g.Nodes) != len(captured.Nodes) || len(g.GetDownstream("public.users")) != len(captured.GetDownstream("public.users")) {
+		t.Errorf("snapshot graph differs from the captured graph")
+	}
+
+	deps, err := a.GetTableDependencies("public", "orders")
+	if err != nil {
+		t.Fatalf("GetTableDependencies: %v", err)
+	}
+	types := make(map[string]bool)
+	for _, d := range deps {
+		types[d.Type] = true
+	}
+	if !types["FOREIGN_KEY"] || !types["VIEW"] || !types["TRIGGER"] {
+		t.Errorf("expected FK, VIEW and TRIGGER dependencies, got %+v", deps)
+	}
+
+	if _, err := a.GetTableDependencies("public", "missing"); err == nil {
+		t.Errorf("expected error for missing table")
+	}
+	if _, err := a.GetColumnDependencies("public", "orders", "id"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("GetColumnDependencies error = %v, want ErrUnsupported", err)
+	}
+}
@@ -0,0 +1,748 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alexanderritik/dbgraph/internal/graph"
+	"github.com/alexanderritik/dbgraph/internal/sqlparse"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" database/sql driver
+)
+
+// sqliteSchema is the schema name SQLite gives the primary database file
+const sqliteSchema = "main"
+
+// SQLiteAdapter handles SQLite database files
+type SQLiteAdapter struct {
+	DB   *sql.DB
+	Path string
+}
+
+// NewSQLiteAdapter creates a new sqlite adapter
+func NewSQLiteAdapter() *SQLiteAdapter {
+	return &SQLiteAdapter{}
+}
+
+// sqlitePath extracts the file path from sqlite://path (sqlite:///abs/path for absolute paths)
+func sqlitePath(connString string) (string, error) {
+	path := strings.TrimPrefix(connString, "sqlite://")
+	if path == "" || path == connString {
+		return "", fmt.Errorf("invalid sqlite connection string: %s (expected sqlite://path)", connString)
+	}
+	return path, nil
+}
+
+// Connect opens the database file read-only
+func (s *SQLiteAdapter) Connect(connString string) error {
+	path, err := sqlitePath(connString)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to open database file: %v\n", err)
+		return err
+	}
+
+	s.Path = path
+	s.DB, err = sql.Open("sqlite", fmt.Sprintf("file:%s?mode=ro", path))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to connect to database: %v\n", err)
+		return err
+	}
+	if err := s.DB.Ping(); err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to connect to database: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+// Close closes the database handle
+func (s *SQLiteAdapter) Close() {
+	if s.DB != nil {
+		s.DB.Close()
+	}
+}
+
+// ServerVersion returns the version of the embedded SQLite library
+func (s *SQLiteAdapter) ServerVersion() (string, error) {
+	if s.DB == nil {
+		return "", fmt.Errorf("database connection not established")
+	}
+	var version string
+	err := s.DB.QueryRowContext(context.Background(), sqliteQueryVersion).Scan(&version)
+	return "SQLite " + version, err
+}
+
+// DefaultSchema returns the schema of the primary database file
+func (s *SQLiteAdapter) DefaultSchema() string {
+	return sqliteSchema
+}
+
+// sqliteObject is a row of sqlite_master
+type sqliteObject struct {
+	Type    string
+	Name    string
+	TblName string
+	SQL     string
+}
+
+// objects loads all user tables, views and triggers from sqlite_master
+func (s *SQLiteAdapter) objects(ctx context.Context) ([]sqliteObject, error) {
+	rows, err := s.DB.QueryContext(ctx, sqliteQueryFetchObjects)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var objects []sqliteObject
+	for rows.Next() {
+		var o sqliteObject
+		if err := rows.Scan(&o.Type, &o.Name, &o.TblName, &o.SQL); err != nil {
+			return nil, err
+		}
+		objects = append(objects, o)
+	}
+	return objects, rows.Err()
+}
+
+// quoteIdent quotes an identifier for use in generated SQL
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// mentionsIdentifier reports whether text references name as a whole identifier
+// (bare, "quoted", `quoted` or [quoted]), ignoring case.
+func mentionsIdentifier(text, name string) bool {
+	re, err := regexp.Compile(`(?i)(?:^|[^\w$])` + regexp.QuoteMeta(name) + `(?:[^\w$]|$)`)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(text)
+}
+
+// sqliteTriggerText returns the statements of a trigger (from BEGIN on)
+func sqliteTriggerText(o sqliteObject) string {
+	if i := strings.Index(strings.ToUpper(o.SQL), "BEGIN"); i >= 0 {
+		return o.SQL[i:]
+	}
+	return o.SQL
+}
+
+// sqliteTriggerBody parses the statements of a trigger
+func sqliteTriggerBody(o sqliteObject) *sqlparse.Body {
+	return sqlparse.ParseBody(sqliteTriggerText(o), sqliteSchema)
+}
+
+// rowEstimate prefers the ANALYZE statistics and falls back to COUNT(*)
+func (s *SQLiteAdapter) rowEstimate(ctx context.Context, table string) int64 {
+	var rows int64
+	if err := s.DB.QueryRowContext(ctx, sqliteQueryRowEstimate, table).Scan(&rows); err == nil {
+		return rows
+	}
+	if err := s.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+quoteIdent(table)).Scan(&rows); err != nil {
+		return 0
+	}
+	return rows
+}
+
+// indexColumns returns the column sets of every index on a table, including the primary key
+func (s *SQLiteAdapter) indexColumns(ctx context.Context, table string) (map[string][]string, error) {
+	indexes := make(map[string][]string)
+
+	rows, err := s.DB.QueryContext(ctx, sqliteQueryIndexList, table)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err == nil {
+			names = append(names, name)
+		}
+	}
+	rows.Close()
+
+	for _, name := range names {
+		colRows, err := s.DB.QueryContext(ctx, sqliteQueryIndexInfo, name)
+		if err != nil {
+			continue
+		}
+		var cols []string
+		for colRows.Next() {
+			var col string
+			if err := colRows.Scan(&col); err == nil && col != "" {
+				cols = append(cols, col)
+			}
+		}
+		colRows.Close()
+		if len(cols) > 0 {
+			indexes[name] = cols
+		}
+	}
+
+	// INTEGER PRIMARY KEY columns alias the rowid and have no pragma_index_list entry
+	pk, err := s.primaryKey(ctx, table)
+	if err == nil && len(pk) > 0 {
+		indexes["PRIMARY KEY"] = pk
+	}
+	return indexes, nil
+}
+
+// nullableColumns returns the columns of a table that accept NULL
+func (s *SQLiteAdapter) nullableColumns(ctx context.Context, table string) map[string]bool {
+	nullable := make(map[string]bool)
+	rows, err := s.DB.QueryContext(ctx, sqliteQueryNullableColumns, table)
+	if err != nil {
+		return nullable
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err == nil {
+			nullable[name] = true
+		}
+	}
+	return nullable
+}
+
+// primaryKey returns the primary key columns of a table in key order
+func (s *SQLiteAdapter) primaryKey(ctx context.Context, table string) ([]string, error) {
+	rows, err := s.DB.QueryContext(ctx, sqliteQueryTableColumns, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	positions := make(map[int]string)
+	for rows.Next() {
+		var name string
+		var pk int
+		if err := rows.Scan(&name, &pk); err != nil {
+			return nil, err
+		}
+		if pk > 0 {
+			positions[pk] = name
+		}
+	}
+	pk := make([]string, 0, len(positions))
+	for i := 1; i <= len(positions); i++ {
+		pk = append(pk, positions[i])
+	}
+	return pk, rows.Err()
+}
+
+// columns lists the columns of a table or view
+func (s *SQLiteAdapter) columns(ctx context.Context, name string) []string {
+	rows, err := s.DB.QueryContext(ctx, sqliteQueryTableColumns, name)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var cols []string
+	for rows.Next() {
+		var col string
+		var pk int
+		if err := rows.Scan(&col, &pk); err == nil {
+			cols = append(cols, col)
+		}
+	}
+	return cols
+}
+
+// addViewLineage records the column lineage of a view from its SQL
+func (s *SQLiteAdapter) addViewLineage(ctx context.Context, g *graph.Graph, view sqliteObject, objects []sqliteObject) {
+	reads := make(map[sqlparse.QualifiedName][]string)
+	for _, ref := range objects {
+		if (ref.Type == "table" || ref.Type == "view") && ref.Name != view.Name && mentionsIdentifier(view.SQL, ref.Name) {
+			reads[sqlparse.QualifiedName{Schema: sqliteSchema, Name: ref.Name}] = s.columns(ctx, ref.Name)
+		}
+	}
+	for _, v := range sqlparse.ParseSchema(view.SQL).Views {
+		addViewLineage(g, sqlparse.QualifiedName{Schema: sqliteSchema, Name: view.Name}, v.Query, v.Columns, reads)
+	}
+}
+
+// sqliteForeignKey is one (possibly composite) FK declared on a table
+type sqliteForeignKey struct {
+	Name       string
+	Table      string
+	From       []string
+	To         []string
+	DeleteRule string
+}
+
+// foreignKeys returns the FKs declared on a table, grouped by constraint
+func (s *SQLiteAdapter) foreignKeys(ctx context.Context, table string) ([]*sqliteForeignKey, error) {
+	rows, err := s.DB.QueryContext(ctx, sqliteQueryForeignKeys, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byID := make(map[int]*sqliteForeignKey)
+	var ids []int
+	for rows.Next() {
+		var id, seq int
+		var target, from, to, onDelete string
+		if err := rows.Scan(&id, &seq, &target, &from, &to, &onDelete); err != nil {
+			return nil, err
+		}
+		fk, ok := byID[id]
+		if !ok {
+			fk = &sqliteForeignKey{Table: target, DeleteRule: onDelete}
+			byID[id] = fk
+			ids = append(ids, id)
+		}
+		fk.From = append(fk.From, from)
+		fk.To = append(fk.To, to)
+	}
+
+	sort.Ints(ids)
+	fks := make([]*sqliteForeignKey, 0, len(ids))
+	for _, id := range ids {
+		fk := byID[id]
+		// SQLite does not expose constraint names, so mimic Postgres' default naming
+		fk.Name = fmt.Sprintf("%s_%s_fkey", table, strings.Join(fk.From, "_"))
+		fks = append(fks, fk)
+	}
+	return fks, rows.Err()
+}
+
+// FetchSchema reads sqlite_master and the pragmas and populates the graph
+func (s *SQLiteAdapter) FetchSchema(g *graph.Graph) error {
+	if s.DB == nil {
+		return fmt.Errorf("database connection not established")
+	}
+
+	ctx := context.Background()
+
+	objects, err := s.objects(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch nodes: %w", err)
+	}
+
+	// 1. Tables & Views (Nodes)
+	for _, o := range objects {
+		switch o.Type {
+		case "table":
+			size := ""
+			var bytes int64
+			if err := s.DB.QueryRowContext(ctx, sqliteQueryTableSize, o.Name, o.Name).Scan(&bytes); err == nil {
+				size = graph.FormatSize(bytes)
+			}
+			g.AddNode(sqliteSchema, o.Name, graph.Table, size, s.rowEstimate(ctx, o.Name))
+		case "view":
+			g.AddNode(sqliteSchema, o.Name, graph.View, "", 0)
+		}
+	}
+
+	for _, o := range objects {
+		if o.Type != "table" {
+			continue
+		}
+
+		// 1.5 Indexes (for Structural Warnings)
+		indexes, err := s.indexColumns(ctx, o.Name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to fetch indexes: %v\n", err)
+		}
+		for _, cols := range indexes {
+			g.AddIndex(sqliteSchema, o.Name, cols)
+		}
+		if pk, err := s.primaryKey(ctx, o.Name); err == nil && len(pk) > 0 {
+			g.SetPrimaryKey(sqliteSchema, o.Name, pk)
+		}
+		nullable := s.nullableColumns(ctx, o.Name)
+
+		// 2. Foreign Keys (Table Dependencies)
+		fks, err := s.foreignKeys(ctx, o.Name)
+		if err != nil {
+			return fmt.Errorf("failed to fetch foreign keys: %w", err)
+		}
+		for _, fk := range fks {
+			g.AddEdge(sqliteSchema, o.Name, sqliteSchema, fk.Table, graph.ForeignKey, fk.Name, fk.DeleteRule)
+
+			edges := g.Edges[fmt.Sprintf("%s.%s", sqliteSchema, o.Name)]
+			lastEdge := edges[len(edges)-1]
+			lastEdge.MetaData = map[string]string{"fk_columns": strings.Join(fk.From, ",")}
+			var nullableCols []string
+			for _, col := range fk.From {
+				if nullable[col] {
+					nullableCols = append(nullableCols, col)
+				}
+			}
+			if len(nullableCols) > 0 {
+				lastEdge.MetaData["nullable_columns"] = strings.Join(nullableCols, ",")
+			}
+		}
+	}
+
+	// 3. View Dependencies (parsed from the view SQL)
+	for _, o := range objects {
+		if o.Type != "view" {
+			continue
+		}
+		for _, ref := range objects {
+			if (ref.Type != "table" && ref.Type != "view") || ref.Name == o.Name {
+				continue
+			}
+			if mentionsIdentifier(o.SQL, ref.Name) {
+				g.AddEdge(sqliteSchema, o.Name, sqliteSchema, ref.Name, graph.ViewDepends, "", "")
+			}
+		}
+		s.addViewLineage(ctx, g, o, objects)
+	}
+
+	// 4. Triggers & Trigger Bodies
+	for _, o := range objects {
+		if o.Type != "trigger" {
+			continue
+		}
+		g.AddTrigger(sqliteSchema, o.TblName, o.Name)
+
+		// SQLite triggers carry their body inline: the trigger reads and writes what its body does
+		addBodyReferences(g, sqlparse.QualifiedName{Schema: sqliteSchema, Name: graph.TriggerKey(o.TblName, o.Name)}, sqliteTriggerText(o))
+	}
+
+	return nil
+}
+
+// GetMetrics is not available: SQLite is embedded and has no server-side activity views
+func (s *SQLiteAdapter) GetMetrics() (*graph.DBMetrics, error) {
+	return nil, fmt.Errorf("%w: SQLite has no server activity or lock statistics", ErrUnsupported)
+}
+
+// GetColumnDependencies identifies all objects that depend on a specific column
+func (s *SQLiteAdapter) GetColumnDependencies(schema, table, column string) ([]graph.ColumnDependency, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not established")
+	}
+	ctx := context.Background()
+	var deps []graph.ColumnDependency
+
+	// 0. Make sure the column exists
+	found := false
+	rows, err := s.DB.QueryContext(ctx, sqliteQueryTableColumns, table)
+	if err != nil {
+		return nil, fmt.Errorf("column '%s.%s' not found: %w", table, column, err)
+	}
+	for rows.Next() {
+		var name string
+		var pk int
+		if err := rows.Scan(&name, &pk); err == nil && strings.EqualFold(name, column) {
+			found = true
+		}
+	}
+	rows.Close()
+	if !found {
+		return nil, fmt.Errorf("column '%s.%s' not found", table, column)
+	}
+
+	objects, err := s.objects(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pk, _ := s.primaryKey(ctx, table)
+
+	// 1. Foreign Keys referencing this column (an empty "to" means the parent's primary key)
+	for _, o := range objects {
+		if o.Type != "table" {
+			continue
+		}
+		fks, err := s.foreignKeys(ctx, o.Name)
+		if err != nil {
+			continue
+		}
+		for _, fk := range fks {
+			if !strings.EqualFold(fk.Table, table) {
+				continue
+			}
+			for i, to := range fk.To {
+				if strings.EqualFold(to, column) || (to == "" && i < len(pk) && strings.EqualFold(pk[i], column)) {
+					deps = append(deps, graph.ColumnDependency{
+						Schema: sqliteSchema,
+						Name:   o.Name,
+						Type:   "FOREIGN_KEY",
+						Detail: fmt.Sprintf("Constraint: %s", fk.Name),
+					})
+					break
+				}
+			}
+		}
+	}
+
+	// 2. Indexes using this column
+	indexes, _ := s.indexColumns(ctx, table)
+	for name, cols := range indexes {
+		for _, col := range cols {
+			if strings.EqualFold(col, column) {
+				deps = append(deps, graph.ColumnDependency{
+					Schema: sqliteSchema,
+					Name:   name,
+					Type:   "INDEX",
+					Detail: "Index covers this column",
+				})
+				break
+			}
+		}
+	}
+
+	// 3. Views & Triggers whose SQL references the column
+	for _, o := range objects {
+		switch o.Type {
+		case "view":
+			if mentionsIdentifier(o.SQL, table) && mentionsIdentifier(o.SQL, column) {
+				deps = appendDependency(deps, graph.ColumnDependency{
+					Schema: sqliteSchema,
+					Name:   o.Name,
+					Type:   "VIEW",
+					Detail: "Deep Dependency (view definition)",
+				})
+			}
+		case "trigger":
+			// The trigger's own table is reachable through NEW./OLD. without being named
+			_, usesColumn := sqliteTriggerBody(o).UsesColumn(sqlparse.QualifiedName{Schema: sqliteSchema, Name: table}, column)
+			if usesColumn || (strings.EqualFold(o.TblName, table) && mentionsIdentifier(o.SQL, column)) {
+				deps = appendDependency(deps, graph.ColumnDependency{
+					Schema: sqliteSchema,
+					Name:   o.Name,
+					Type:   "TRIGGER",
+					Detail: "Deep Dependency (trigger body)",
+				})
+			}
+		}
+	}
+
+	// 4. CHECK constraints referencing the column
+	for _, o := range objects {
+		if o.Type != "table" || !strings.EqualFold(o.Name, table) {
+			continue
+		}
+		for _, t := range sqlparse.ParseSchema(o.SQL).Tables {
+			for _, check := range t.Checks {
+				if mentionsIdentifier(check.Expr, column) {
+					deps = appendDependency(deps, graph.ColumnDependency{
+						Schema: sqliteSchema,
+						Name:   check.Name,
+						Type:   "CONSTRAINT",
+						Detail: "Check Constraint",
+					})
+				}
+			}
+		}
+	}
+
+	return deps, nil
+}
+
+// DerivedViewColumns parses the view SQL for the output columns that read column
+func (s *SQLiteAdapter) DerivedViewColumns(schema, view, column string) ([]string, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not established")
+	}
+	objects, err := s.objects(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range objects {
+		if o.Type != "view" || !strings.EqualFold(o.Name, view) {
+			continue
+		}
+		for _, v := range sqlparse.ParseSchema(o.SQL).Views {
+			return sqlparse.DerivedColumns(v.Tokens, v.Columns, column), nil
+		}
+	}
+	return nil, fmt.Errorf("view '%s' not found", view)
+}
+
+// GetTableDependencies identifies all objects that depend on a specific table
+func (s *SQLiteAdapter) GetTableDependencies(schema, table string) ([]graph.ColumnDependency, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not established")
+	}
+	ctx := context.Background()
+	var deps []graph.ColumnDependency
+
+	objects, err := s.objects(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. Foreign Keys pointing TO this table
+	for _, o := range objects {
+		if o.Type != "table" || strings.EqualFold(o.Name, table) {
+			continue
+		}
+		fks, err := s.foreignKeys(ctx, o.Name)
+		if err != nil {
+			continue
+		}
+		for _, fk := range fks {
+			if strings.EqualFold(fk.Table, table) {
+				deps = append(deps, graph.ColumnDependency{
+					Schema: sqliteSchema,
+					Name:   o.Name,
+					Type:   "FOREIGN_KEY",
+					Detail: fmt.Sprintf("Constraint: %s", fk.Name),
+				})
+			}
+		}
+	}
+
+	// 2. Views & Triggers referencing the table
+	for _, o := range objects {
+		switch o.Type {
+		case "view":
+			if !strings.EqualFold(o.Name, table) && mentionsIdentifier(o.SQL, table) {
+				deps = appendDependency(deps, graph.ColumnDependency{
+					Schema: sqliteSchema,
+					Name:   o.Name,
+					Type:   "VIEW",
+					Detail: "Hard Dependency (view definition)",
+				})
+			}
+		case "trigger":
+			if strings.EqualFold(o.TblName, table) {
+				deps = appendDependency(deps, graph.ColumnDependency{
+					Schema: sqliteSchema,
+					Name:   o.Name,
+					Type:   "TRIGGER",
+					Detail: "Trigger on table",
+				})
+			} else if ref, ok := sqliteTriggerBody(o).Reference(sqlparse.QualifiedName{Schema: sqliteSchema, Name: table}); ok {
+				deps = appendDependency(deps, graph.ColumnDependency{
+					Schema: sqliteSchema,
+					Name:   o.Name,
+					Type:   "TRIGGER",
+					Detail: fmt.Sprintf("Code Reference (trigger body %s, confidence: %s)", ref.Access(), ref.Confidence),
+				})
+			}
+		}
+	}
+
+	return deps, nil
+}
+
+// GetTopQueries is not available: SQLite keeps no statement statistics
+func (s *SQLiteAdapter) GetTopQueries(limit int, sortBy string) ([]graph.QueryStats, error) {
+	return nil, fmt.Errorf("%w: SQLite does not record query statistics", ErrUnsupported)
+}
+
+// TraceQuery maps EXPLAIN QUERY PLAN into an ExplainNode tree and times the query client-side
+func (s *SQLiteAdapter) TraceQuery(query string) (*graph.TraceResult, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("database connection not established")
+	}
+
+	// Kill trace if it looks like it will hang (>5s)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// 1. Query Plan (id, parent, notused, detail)
+	planStart := time.Now()
+	rows, err := s.DB.QueryContext(ctx, "EXPLAIN QUERY PLAN "+query)
+	if err != nil {
+		return nil, fmt.Errorf("trace execution failed: %w", err)
+	}
+
+	nodes := make(map[int]*graph.ExplainNode)
+	var tops []*graph.ExplainNode
+	for rows.Next() {
+		var id, parent, notUsed int
+		var detail string
+		if err := rows.Scan(&id, &parent, &notUsed, &detail); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to parse query plan: %w", err)
+		}
+		node := parseSQLitePlanDetail(detail)
+		nodes[id] = node
+		if p, ok := nodes[parent]; ok {
+			p.Plans = append(p.Plans, node)
+		} else {
+			tops = append(tops, node)
+		}
+	}
+	rows.Close()
+	planningTime := float64(time.Since(planStart).Microseconds()) / 1000.0
+
+	if len(tops) == 0 {
+		return nil, fmt.Errorf("empty explain result")
+	}
+	root := tops[0]
+	if len(tops) > 1 {
+		root = &graph.ExplainNode{Type: "QUERY PLAN", Plans: tops}
+	}
+
+	// 2. Execute & Time (SQLite has no EXPLAIN ANALYZE)
+	execStart := time.Now()
+	dataRows, err := s.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("trace execution failed: %w", err)
+	}
+	var count float64
+	for dataRows.Next() {
+		count++
+	}
+	dataRows.Close()
+	if err := dataRows.Err(); err != nil {
+		return nil, fmt.Errorf("trace execution failed: %w", err)
+	}
+	executionTime := float64(time.Since(execStart).Microseconds()) / 1000.0
+
+	root.ActualRows = count
+	root.ActualLoops = 1
+
+	return &graph.TraceResult{
+		PlanningTime:  planningTime,
+		ExecutionTime: executionTime,
+		TotalTime:     planningTime + executionTime,
+		Root:          root,
+	}, nil
+}
+
+// parseSQLitePlanDetail maps an EXPLAIN QUERY PLAN detail such as
+// "SEARCH o USING INDEX idx_orders_user (user_id=?)" into an ExplainNode
+func parseSQLitePlanDetail(detail string) *graph.ExplainNode {
+	node := &graph.ExplainNode{Type: detail}
+
+	verb, rest, ok := strings.Cut(detail, " ")
+	if !ok || (verb != "SCAN" && verb != "SEARCH") {
+		return node
+	}
+	rest = strings.TrimPrefix(rest, "TABLE ") // SQLite < 3.36 prints "SCAN TABLE t"
+
+	relation, using, hasUsing := strings.Cut(rest, " USING ")
+	node.RelationName = strings.TrimSpace(relation)
+
+	switch {
+	case verb == "SEARCH":
+		node.Type = "Index lookup"
+	case hasUsing && strings.Contains(using, "COVERING INDEX"):
+		node.Type = "Covering index scan"
+	case hasUsing:
+		node.Type = "Index scan"
+	default:
+		node.Type = "Table scan"
+	}
+
+	if hasUsing {
+		if cond, after, ok := strings.Cut(using, " ("); ok {
+			node.IndexCond = strings.TrimSuffix(after, ")")
+			using = cond
+		}
+		if strings.Contains(using, "PRIMARY KEY") {
+			node.IndexName = "PRIMARY KEY"
+		} else if i := strings.Index(using, "INDEX "); i >= 0 {
+			node.IndexName = strings.TrimSpace(using[i+len("INDEX "):])
+		}
+	}
+	return node
+}
@@ -0,0 +1,54 @@
+package adapters
+
+const (
+	// sqliteQueryVersion fetches the SQLite library version
+	sqliteQueryVersion = "SELECT sqlite_version()"
+
+	// sqliteQueryFetchObjects fetches tables, views and triggers with their DDL
+	sqliteQueryFetchObjects = `
+		SELECT type, name, tbl_name, COALESCE(sql, '')
+		FROM sqlite_master
+		WHERE type IN ('table', 'view', 'trigger')
+		  AND name NOT LIKE 'sqlite_%'
+		ORDER BY CASE type WHEN 'table' THEN 0 WHEN 'view' THEN 1 ELSE 2 END`
+
+	// sqliteQueryRowEstimate reads the row estimate recorded by ANALYZE (first number of stat)
+	sqliteQueryRowEstimate = `
+		SELECT CAST(stat AS INTEGER)
+		FROM sqlite_stat1
+		WHERE tbl = ?
+		LIMIT 1`
+
+	// sqliteQueryTableSize sums the pages of a table and its indexes (requires the dbstat vtab)
+	sqliteQueryTableSize = `
+		SELECT COALESCE(SUM(pgsize), 0)
+		FROM dbstat
+		WHERE name = ? OR name IN (SELECT name FROM sqlite_master WHERE type = 'index' AND tbl_name = ?)`
+
+	// sqliteQueryForeignKeys lists the FKs declared on a table
+	sqliteQueryForeignKeys = `
+		SELECT id, seq, "table", "from", COALESCE("to", ''), on_delete
+		FROM pragma_foreign_key_list(?)
+		ORDER BY id, seq`
+
+	// sqliteQueryIndexList lists the indexes of a table
+	sqliteQueryIndexList = `SELECT name FROM pragma_index_list(?)`
+
+	// sqliteQueryIndexInfo lists the columns of an index in key order (NULL for expressions)
+	sqliteQueryIndexInfo = `
+		SELECT COALESCE(name, '')
+		FROM pragma_index_info(?)
+		ORDER BY seqno`
+
+	// sqliteQueryTableColumns lists the columns of a table with their primary key position
+	sqliteQueryTableColumns = `
+		SELECT name, pk
+		FROM pragma_table_info(?)
+		ORDER BY cid`
+
+	// sqliteQueryNullableColumns lists the columns of a table that accept NULL
+	sqliteQueryNullableColumns = `
+		SELECT name
+		FROM pragma_table_info(?)
+		WHERE "notnull" = 0 AND pk = 0`
+)
@@ -0,0 +1,166 @@
+package adapters
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/alexanderritik/dbgraph/internal/graph"
+)
+
+const sqliteFixture = `
+CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT NOT NULL);
+CREATE TABLE orders (
+	id INTEGER PRIMARY KEY,
+	user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	total REAL
+);
+CREATE TABLE audit_logs (id INTEGER PRIMARY KEY, note TEXT);
+CREATE INDEX idx_orders_user ON orders(user_id);
+CREATE VIEW user_emails AS SELECT u.email FROM users u;
+CREATE TRIGGER order_audit AFTER INSERT ON orders
+BEGIN
+	INSERT INTO audit_logs (note) VALUES ('order created');
+END;
+`
+
+// newSQLiteFixture creates a database file and returns a connected adapter
+func newSQLiteFixture(t *testing.T) *SQLiteAdapter {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "fixture.db")
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("open fixture: %v", err)
+	}
+	if _, err := db.Exec(sqliteFixture); err != nil {
+		t.Fatalf("create fixture: %v", err)
+	}
+	db.Close()
+
+	a := NewSQLiteAdapter()
+	if err := a.Connect("sqlite://" + path); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	t.Cleanup(a.Close)
+	return a
+}
+
+func TestSQLiteFetchSchema(t *testing.T) {
+	a := newSQLiteFixture(t)
+	g := graph.NewGraph()
+	if err := a.FetchSchema(g); err != nil {
+		t.Fatalf("FetchSchema: %v", err)
+	}
+
+	for _, id := range []string{"main.users", "main.orders", "main.user_emails", "main.orders.order_audit"} {
+		if _, ok := g.Nodes[id]; !ok {
+			t.Errorf("expected node %s", id)
+		}
+	}
+
+	var fk *graph.Edge
+	for _, e := range g.Edges["main.orders"] {
+		if e.Type == graph.ForeignKey {
+			fk = e
+		}
+	}
+	if fk == nil || fk.TargetID != "main.users" || fk.DeleteRule != "CASCADE" || fk.MetaData["fk_columns"] != "user_id" {
+		t.Fatalf("unexpected FK edge: %+v", fk)
+	}
+
+	if issues := g.CheckIndexCoverage(); len(issues.MissingFKIndexes) != 0 {
+		t.Errorf("expected FK to be indexed, got %v", issues.MissingFKIndexes)
+	}
+
+	impacted := g.GetDownstream("main.users")
+	want := map[string]bool{"main.orders": true, "main.user_emails": true, "main.orders.order_audit": true}
+	for _, id := range impacted {
+		delete(want, id)
+	}
+	if len(want) != 0 {
+		t.Errorf("missing downstream nodes %v (got %v)", want, impacted)
+	}
+
+	hasAuditEdge := false
+	for _, e := range g.Edges["main.orders.order_audit"] {
+		if e.TargetID == "main.audit_logs" {
+			hasAuditEdge = true
+		}
+	}
+	if !hasAuditEdge {
+		t.Errorf("expected trigger body edge to audit_logs")
+	}
+}
+
+func TestSQLiteDependencies(t *testing.T) {
+	a := newSQLiteFixture(t)
+
+	deps, err := a.GetColumnDependencies("public", "users", "id")
+	if err != nil {
+		t.Fatalf("GetColumnDependencies: %v", err)
+	}
+	if len(deps) == 0 || deps[0].Type != "FOREIGN_KEY" || deps[0].Name != "orders" {
+		t.Errorf("expected FK dependency from orders, got %+v", deps)
+	}
+
+	if _, err := a.GetColumnDependencies("public", "users", "missing"); err == nil {
+		t.Errorf("expected error for missing column")
+	}
+
+	deps, err = a.GetTableDependencies("public", "users")
+	if err != nil {
+		t.Fatalf("GetTableDependencies: %v", err)
+	}
+	types := make(map[string]bool)
+	for _, d := range deps {
+		types[d.Type] = true
+	}
+	if !types["FOREIGN_KEY"] || !types["VIEW"] {
+		t.Errorf("expected FK and VIEW dependencies, got %+v", deps)
+	}
+}
+
+func TestSQLiteUnsupportedCapabilities(t *testing.T) {
+	a := newSQLiteFixture(t)
+
+	if _, err := a.GetTopQueries(10, "total"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("GetTopQueries error = %v, want ErrUnsupported", err)
+	}
+	if _, err := a.GetMetrics(); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("GetMetrics error = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestSQLiteTraceQuery(t *testing.T) {
+	a := newSQLiteFixture(t)
+
+	result, err := a.TraceQuery("SELECT * FROM orders WHERE user_id = 1")
+	if err != nil {
+		t.Fatalf("TraceQuery: %v", err)
+	}
+	if result.Root.Type != "Index lookup" || result.Root.IndexName != "idx_orders_user" {
+		t.Errorf("unexpected plan root: %+v", result.Root)
+	}
+}
+
+func TestParseSQLitePlanDetail(t *testing.T) {
+	tests := []struct {
+		detail, wantType, wantRel, wantIndex string
+	}{
+		{"SCAN users", "Table scan", "users", ""},
+		{"SCAN TABLE users", "Table scan", "users", ""},
+		{"SCAN o USING COVERING INDEX idx_orders_user", "Covering index scan", "o", "idx_orders_user"},
+		{"SEARCH o USING INTEGER PRIMARY KEY (rowid=?)", "Index lookup", "o", "PRIMARY KEY"},
+		{"USE TEMP B-TREE FOR ORDER BY", "USE TEMP B-TREE FOR ORDER BY", "", ""},
+	}
+
+	for _, tt := range tests {
+		n := parseSQLitePlanDetail(tt.detail)
+		if n.Type != tt.wantType || n.RelationName != tt.wantRel || n.IndexName != tt.wantIndex {
+			t.Errorf("parseSQLitePlanDetail(%q) = {%q %q %q}, want {%q %q %q}",
+				tt.detail, n.Type, n.RelationName, n.IndexName, tt.wantType, tt.wantRel, tt.wantIndex)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/alexanderritik/dbgraph/internal/graph"
+	"github.com/alexanderritik/dbgraph/internal/lint"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPath is read when --config is not given; it is optional
+const DefaultPath = ".dbgraph.yaml"
+
+// Config is the contents of .dbgraph.yaml
+type Config struct {
+	Rules  map[string]RuleConfig `yaml:"rules"`  // Keyed by rule ID
+	Ignore []Ignore              `yaml:"ignore"` // Findings matching any entry are dropped
+	Risk   Risk                  `yaml:"risk"`   // Thresholds of the RISK column of 'summary'
+}
+
+// RuleConfig overrides the defaults of one rule; every other key is a rule parameter, e.g. threshold: 20
+type RuleConfig struct {
+	Enabled  *bool              `yaml:"enabled"`
+	Severity string             `yaml:"severity"`
+	Params   map[string]float64 `yaml:",inline"`
+}
+
+// Ignore drops findings by rule and by the schema and table they belong to.
+// Each field is a path.Match glob; an empty field matches everything.
+type Ignore struct {
+	Rule   string `yaml:"rule"`
+	Schema string `yaml:"schema"`
+	Table  string `yaml:"table"`
+}
+
+// Risk holds the thresholds summary uses to label objects
+type Risk struct {
+	Medium               float64 `yaml:"medium"`                // Centrality above which an object is MED
+	High                 float64 `yaml:"high"`                  // Centrality above which an object is HIGH
+	CriticalDependents   int     `yaml:"critical_dependents"`   // In-degree above which (with dependencies) it is CRITICAL
+	CriticalDependencies int     `yaml:"critical_dependencies"` // Out-degree above which (with dependents) it is CRITICAL
+}
+
+// Classify returns the risk label of a ranked node
+func (r Risk) Classify(n graph.NodeRank) string {
+	risk := "LOW"
+	if n.Centrality > r.Medium {
+		risk = "MED"
+	}
+	if n.Centrality > r.High {
+		risk = "HIGH"
+	}
+	if n.InDegree > r.CriticalDependents && n.OutDegree > r.CriticalDependencies {
+		risk = "CRITICAL"
+	}
+	return risk
+}
+
+// Default is the configuration used without a .dbgraph.yaml
+func Default() *Config {
+	return &Config{
+		Risk: Risk{Medium: 5, High: 10, CriticalDependents: 5, CriticalDependencies: 2},
+	}
+}
+
+// Load reads a config file; keys it leaves out keep their defaults
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Parse decodes a config document and validates its rules and ignores
+func Parse(data []byte) (*Config, error) {
+	c := Default()
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if _, err := c.LintRules(); err != nil {
+		return nil, err
+	}
+	for _, ig := range c.Ignore {
+		for _, pattern := range []string{ig.Rule, ig.Schema, ig.Table} {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("bad ignore pattern '%s': %w", pattern, err)
+			}
+		}
+	}
+	return c, nil
+}
+
+// LintRules returns the enabled rules with the configured severities and parameters applied
+func (c *Config) LintRules() ([]lint.Rule, error) {
+	defaults := lint.DefaultRules()
+	known := make(map[string]bool, len(defaults))
+	for _, r := range defaults {
+		known[r.ID] = true
+	}
+	for id := range c.Rules {
+		if !known[id] {
+			return nil, fmt.Errorf("unknown rule '%s'", id)
+		}
+	}
+
+	var rules []lint.Rule
+	for _, r := range defaults {
+		rc, ok := c.Rules[r.ID]
+		if !ok {
+			rules = append(rules, r)
+			continue
+		}
+		if rc.Enabled != nil && !*rc.Enabled {
+			continue
+		}
+		if rc.Severity != "" {
+			severity, err := lint.ParseSeverity(rc.Severity)
+			if err != nil {
+				return nil, fmt.Errorf("rule %s: %w", r.ID, err)
+			}
+			r.Severity = severity
+		}
+		for name, value := range rc.Params {
+			if _, ok := r.Params[name]; !ok {
+				return nil, fmt.Errorf("rule %s has no parameter '%s'", r.ID, name)
+			}
+			r.Params[name] = value
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// Ignored reports whether an ignore entry matches the finding
+func (c *Config) Ignored(f lint.Finding) bool {
+	schema, table := f.Node, ""
+	if i := strings.Index(f.Node, "."); i >= 0 {
+		schema, table = f.Node[:i], f.Node[i+1:]
+	}
+	for _, ig := range c.Ignore {
+		if match(ig.Rule, f.Rule) && match(ig.Schema, schema) && match(ig.Table, table) {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter drops the ignored findings
+func (c *Config) Filter(findings []lint.Finding) []lint.Finding {
+	kept := []lint.Finding{}
+	for _, f := range findings {
+		if !c.Ignored(f) {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// match applies a glob, treating an empty pattern as a wildcard
+func match(pattern, s string) bool {
+	if pattern == "" {
+		return true
+	}
+	ok, _ := path.Match(pattern, s)
+	return ok
+}
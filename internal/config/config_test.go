@@ -0,0 +1,107 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/alexanderritik/dbgraph/internal/graph"
+	"github.com/alexanderritik/dbgraph/internal/lint"
+)
+
+const testConfig = `
+rules:
+  god-object:
+    threshold: 3
+    severity: error
+  orphan-table:
+    enabled: false
+ignore:
+  - rule: unindexed-fk
+    schema: audit
+  - table: "tmp_*"
+risk:
+  high: 20
+`
+
+func TestParse(t *testing.T) {
+	c, err := Parse([]byte(testConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules, err := c.LintRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byID := make(map[string]lint.Rule)
+	for _, r := range rules {
+		byID[r.ID] = r
+	}
+	if _, ok := byID["orphan-table"]; ok {
+		t.Error("orphan-table should be disabled")
+	}
+	god := byID["god-object"]
+	if god.Severity != lint.Error || god.Params.Int("threshold") != 3 {
+		t.Errorf("god-object = %s %v, want error threshold 3", god.Severity, god.Params)
+	}
+	if byID["island"].Params.Int("max_size") != graph.DefaultIslandMaxSize {
+		t.Error("unconfigured rules keep their default params")
+	}
+	// Overrides must not leak into the registry
+	for _, r := range lint.DefaultRules() {
+		if r.ID == "god-object" && r.Params.Int("threshold") != graph.DefaultGodObjectThreshold {
+			t.Error("config changed the default god-object threshold")
+		}
+	}
+
+	if c.Risk.High != 20 || c.Risk.Medium != 5 {
+		t.Errorf("risk = %+v, want high 20 and default medium 5", c.Risk)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, doc := range []string{
+		"rules:\n  no-such-rule: {}\n",
+		"rules:\n  god-object:\n    limit: 3\n",
+		"rules:\n  cycle:\n    severity: fatal\n",
+		"ignore:\n  - table: \"[\"\n",
+		"rulez: {}\n",
+	} {
+		if _, err := Parse([]byte(doc)); err == nil {
+			t.Errorf("Parse(%q) should fail", doc)
+		}
+	}
+}
+
+func TestIgnored(t *testing.T) {
+	c, err := Parse([]byte(testConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	findings := []lint.Finding{
+		{Rule: "unindexed-fk", Object: "audit.log (user_id) -> public.users", Node: "audit.log"},
+		{Rule: "unindexed-fk", Object: "public.orders (user_id) -> public.users", Node: "public.orders"},
+		{Rule: "missing-pk", Object: "public.tmp_import", Node: "public.tmp_import"},
+	}
+	kept := c.Filter(findings)
+	if len(kept) != 1 || kept[0].Node != "public.orders" {
+		t.Errorf("kept %+v, want only public.orders", kept)
+	}
+}
+
+func TestRiskClassify(t *testing.T) {
+	r := Default().Risk
+	cases := []struct {
+		rank graph.NodeRank
+		want string
+	}{
+		{graph.NodeRank{Centrality: 2}, "LOW"},
+		{graph.NodeRank{Centrality: 6}, "MED"},
+		{graph.NodeRank{Centrality: 11}, "HIGH"},
+		{graph.NodeRank{Centrality: 9, InDegree: 6, OutDegree: 3}, "CRITICAL"},
+	}
+	for _, c := range cases {
+		if got := r.Classify(c.rank); got != c.want {
+			t.Errorf("Classify(%+v) = %s, want %s", c.rank, got, c.want)
+		}
+	}
+}
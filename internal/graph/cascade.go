@@ -0,0 +1,158 @@
+package graph
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// CascadeAction is what a DELETE does to the rows of a table in the cascade tree
+type CascadeAction string
+
+const (
+	CascadeRoot   CascadeAction = "DELETE"      // The table the DELETE runs on
+	CascadeDelete CascadeAction = "CASCADE"     // Rows removed through ON DELETE CASCADE
+	CascadeNull   CascadeAction = "SET NULL"    // FK columns cleared through ON DELETE SET NULL
+	CascadeReset  CascadeAction = "SET DEFAULT" // FK columns reset through ON DELETE SET DEFAULT
+	CascadeBlock  CascadeAction = "BLOCK"       // RESTRICT / NO ACTION: referencing rows make the DELETE fail
+)
+
+// CascadeNode is a table reached by a DELETE, with the rows affected there
+type CascadeNode struct {
+	Table      string         `json:"table"`
+	Action     CascadeAction  `json:"action"`
+	Constraint string         `json:"constraint,omitempty"` // FK from this table to its parent; empty at the root
+	DeleteRule string         `json:"delete_rule,omitempty"`
+	Columns    []string       `json:"columns,omitempty"`     // FK columns of this table
+	RefColumns []string       `json:"ref_columns,omitempty"` // Parent columns they reference
+	Rows       int64          `json:"rows"`
+	Unknown    bool           `json:"unknown,omitempty"` // No estimate: the rows matching the filter are unknown
+	Counted    bool           `json:"counted,omitempty"` // Rows is an exact COUNT rather than an estimate
+	Bounded    bool           `json:"bounded,omitempty"` // The COUNT stopped at its limit: at least Rows
+	Cycle      bool           `json:"cycle,omitempty"`   // The table is already deleted higher up; not expanded again
+	Children   []*CascadeNode `json:"children,omitempty"`
+}
+
+// Walk visits the node and its descendants depth-first
+func (n *CascadeNode) Walk(fn func(node, parent *CascadeNode)) {
+	var walk func(node, parent *CascadeNode)
+	walk = func(node, parent *CascadeNode) {
+		fn(node, parent)
+		for _, c := range node.Children {
+			walk(c, node)
+		}
+	}
+	walk(n, nil)
+}
+
+// cascadeActions maps FK delete rules to what happens to the referencing rows
+var cascadeActions = map[string]CascadeAction{
+	"CASCADE":     CascadeDelete,
+	"SET NULL":    CascadeNull,
+	"SET DEFAULT": CascadeReset,
+	"RESTRICT":    CascadeBlock,
+	"NO ACTION":   CascadeBlock,
+}
+
+// CascadeTree builds the tree of tables a DELETE of rootRows rows from rootID reaches: rows deleted
+// through ON DELETE CASCADE foreign keys (expanded recursively), rows whose FK is set to NULL or its
+// default, and RESTRICT / NO ACTION foreign keys whose referencing rows would make the DELETE fail.
+// A negative rootRows means the number of matching rows is unknown. Rows are estimated per level from
+// the row counts and, when available, the FK column statistics (n_distinct, null_frac): a deleted
+// parent row takes with it the average number of child rows per distinct FK value.
+func (g *Graph) CascadeTree(rootID string, rootRows int64) *CascadeNode {
+	root := &CascadeNode{Table: rootID, Action: CascadeRoot, Rows: rootRows, Unknown: rootRows < 0}
+	if root.Unknown {
+		root.Rows = 0
+	}
+	g.expandCascade(root, map[string]bool{rootID: true})
+	return root
+}
+
+// expandCascade adds the foreign keys referencing a deleted table as children of its node.
+// onPath holds the tables deleted on the way from the root, to stop on cyclic CASCADE chains.
+func (g *Graph) expandCascade(parent *CascadeNode, onPath map[string]bool) {
+	for _, src := range sortedEdgeSources(g) {
+		for _, e := range g.Edges[src] {
+			if e.Type != ForeignKey || e.TargetID != parent.Table {
+				continue
+			}
+			action, ok := cascadeActions[strings.ToUpper(e.DeleteRule)]
+			if !ok {
+				continue
+			}
+			child := &CascadeNode{
+				Table:      src,
+				Action:     action,
+				Constraint: e.ConstraintName,
+				DeleteRule: e.DeleteRule,
+				Columns:    splitColumns(e.MetaData["fk_columns"]),
+				RefColumns: splitColumns(e.MetaData["referenced_columns"]),
+				Unknown:    parent.Unknown,
+			}
+			if !child.Unknown {
+				child.Rows = g.estimateCascadeRows(parent, e)
+			}
+			parent.Children = append(parent.Children, child)
+
+			if action != CascadeDelete {
+				continue
+			}
+			if onPath[src] {
+				child.Cycle = true
+				continue
+			}
+			onPath[src] = true
+			g.expandCascade(child, onPath)
+			delete(onPath, src)
+		}
+	}
+}
+
+// estimateCascadeRows estimates the rows of e's source table referencing the rows deleted from parent.
+// Without statistics the child rows spread evenly over the parent rows.
+func (g *Graph) estimateCascadeRows(parent *CascadeNode, e *Edge) int64 {
+	childNode, ok := g.Nodes[e.SourceID]
+	if !ok || parent.Rows == 0 {
+		return 0
+	}
+	var parentTotal int64
+	if p, ok := g.Nodes[parent.Table]; ok {
+		parentTotal = p.RowCount
+	}
+	childRows := float64(childNode.RowCount)
+
+	nullFrac, err := strconv.ParseFloat(e.MetaData["null_frac"], 64)
+	if err != nil {
+		nullFrac = 0
+	}
+	referencing := childRows * (1 - nullFrac)
+
+	// n_distinct < 0 is a fraction of the row count (PostgreSQL's convention for columns that grow with the table)
+	distinct, err := strconv.ParseFloat(e.MetaData["n_distinct"], 64)
+	if err != nil || distinct == 0 {
+		distinct = float64(parentTotal)
+	} else if distinct < 0 {
+		distinct = -distinct * childRows
+	}
+	if distinct <= 0 {
+		return 0
+	}
+
+	// Deleted parent rows with at least one child, times the children per referenced key
+	keys := float64(parent.Rows)
+	if parentTotal > 0 {
+		keys *= math.Min(1, distinct/float64(parentTotal))
+	} else {
+		keys = math.Min(keys, distinct)
+	}
+	return int64(math.Round(math.Min(referencing, keys*referencing/distinct)))
+}
+
+// splitColumns splits a comma-separated column list from edge metadata
+func splitColumns(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
@@ -0,0 +1,56 @@
+package graph
+
+import "testing"
+
+func TestCascadeTree(t *testing.T) {
+	g := NewGraph()
+	g.AddNode("public", "users", Table, "", 1000)
+	g.AddNode("public", "posts", Table, "", 10000)
+	g.AddNode("public", "comments", Table, "", 50000)
+	g.AddNode("public", "invoices", Table, "", 500)
+	addFK := func(src, target, name, rule string, meta map[string]string) {
+		g.AddEdge("public", src, "public", target, ForeignKey, name, rule)
+		edges := g.Edges["public."+src]
+		edges[len(edges)-1].MetaData = meta
+	}
+	// 10 posts per user; half the comments have an editor, spread over 100 users
+	addFK("posts", "users", "fk_post_user", "CASCADE", map[string]string{"fk_columns": "user_id", "n_distinct": "1000", "null_frac": "0"})
+	addFK("posts", "posts", "fk_post_parent", "CASCADE", map[string]string{"fk_columns": "parent_id"})
+	addFK("comments", "posts", "fk_comment_post", "CASCADE", map[string]string{"fk_columns": "post_id", "n_distinct": "-0.2"})
+	addFK("comments", "users", "fk_comment_editor", "SET NULL", map[string]string{"fk_columns": "editor_id", "n_distinct": "100", "null_frac": "0.5"})
+	addFK("invoices", "users", "fk_invoice_user", "NO ACTION", map[string]string{"fk_columns": "user_id"})
+
+	tree := g.CascadeTree("public.users", 1)
+	rows := make(map[string]int64)
+	var cycles, blockers int
+	tree.Walk(func(n, parent *CascadeNode) {
+		if n.Cycle {
+			cycles++
+			return
+		}
+		if n.Action == CascadeBlock {
+			blockers++
+		}
+		rows[n.Constraint] = n.Rows
+	})
+
+	want := map[string]int64{
+		"":                  1,  // The deleted user
+		"fk_post_user":      10, // 10000 posts over 1000 authors
+		"fk_comment_post":   50, // 5 comments per post (10000 distinct posts)
+		"fk_comment_editor": 25, // 1 user in 10 is an editor, of 250 comments each
+		"fk_invoice_user":   1,  // No statistics: invoices spread evenly over users
+	}
+	for name, n := range want {
+		if rows[name] != n {
+			t.Errorf("expected %d rows through %q, got %d", n, name, rows[name])
+		}
+	}
+	if cycles != 1 || blockers != 1 {
+		t.Errorf("expected the self-reference to stop as a cycle and one blocker, got %d cycles, %d blockers", cycles, blockers)
+	}
+
+	if unknown := g.CascadeTree("public.users", -1); !unknown.Unknown || !unknown.Children[0].Unknown {
+		t.Errorf("expected an unknown root to leave the whole tree unknown")
+	}
+}
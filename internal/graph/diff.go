@@ -0,0 +1,297 @@
+package graph
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DiffOptions controls when a row count or size change counts as a jump
+type DiffOptions struct {
+	GrowthThreshold float64 // Relative change, e.g. 0.5 = 50%
+	MinRowDelta     int64   // Ignore row changes smaller than this
+	MinSizeDelta    int64   // Ignore size changes smaller than this (bytes)
+}
+
+// DefaultDiffOptions flags tables that grew or shrank by half and at least 1000 rows / 1 MB
+var DefaultDiffOptions = DiffOptions{
+	GrowthThreshold: 0.5,
+	MinRowDelta:     1000,
+	MinSizeDelta:    1024 * 1024,
+}
+
+// NodeChange describes how a node present in both graphs changed
+type NodeChange struct {
+	ID                  string     `json:"id"`
+	Type                NodeType   `json:"type"`
+	TypeBefore          NodeType   `json:"type_before,omitempty"` // Set when the object kind changed
+	RowsBefore          int64      `json:"rows_before"`
+	RowsAfter           int64      `json:"rows_after"`
+	RowsJumped          bool       `json:"rows_jumped,omitempty"`
+	SizeBefore          string     `json:"size_before,omitempty"`
+	SizeAfter           string     `json:"size_after,omitempty"`
+	SizeJumped          bool       `json:"size_jumped,omitempty"`
+	AddedIndexes        [][]string `json:"added_indexes,omitempty"`
+	RemovedIndexes      [][]string `json:"removed_indexes,omitempty"`
+	AddedDependencies   []string   `json:"added_dependencies,omitempty"` // Views: relations now read
+	RemovedDependencies []string   `json:"removed_dependencies,omitempty"`
+}
+
+// EdgeChange describes an edge present in both graphs whose attributes changed
+type EdgeChange struct {
+	Before *Edge `json:"before"`
+	After  *Edge `json:"after"`
+}
+
+// GraphDiff is the difference between a source and a target graph
+type GraphDiff struct {
+	AddedNodes   []*Node      `json:"added_nodes"`
+	RemovedNodes []*Node      `json:"removed_nodes"`
+	ChangedNodes []NodeChange `json:"changed_nodes"`
+	AddedEdges   []*Edge      `json:"added_edges"`
+	RemovedEdges []*Edge      `json:"removed_edges"`
+	ChangedEdges []EdgeChange `json:"changed_edges"`
+}
+
+// IsEmpty reports whether the graphs are equivalent
+func (d *GraphDiff) IsEmpty() bool {
+	return len(d.AddedNodes) == 0 && len(d.RemovedNodes) == 0 && len(d.ChangedNodes) == 0 &&
+		len(d.AddedEdges) == 0 && len(d.RemovedEdges) == 0 && len(d.ChangedEdges) == 0
+}
+
+// edgeKey identifies an edge across graphs. Constraint names are part of the identity so a
+// renamed FK shows up as removed + added.
+func edgeKey(e *Edge) string {
+	return fmt.Sprintf("%s|%s|%s|%s", e.SourceID, e.TargetID, e.Type, e.ConstraintName)
+}
+
+// indexKey identifies an index by its column list
+func indexKey(cols []string) string {
+	return strings.Join(cols, ",")
+}
+
+// Diff compares the source graph with the target graph (what changed going from a to b)
+func Diff(a, b *Graph, opts DiffOptions) *GraphDiff {
+	// Empty (not nil) slices keep the JSON output stable for consumers
+	d := &GraphDiff{
+		AddedNodes:   []*Node{},
+		RemovedNodes: []*Node{},
+		ChangedNodes: []NodeChange{},
+		AddedEdges:   []*Edge{},
+		RemovedEdges: []*Edge{},
+		ChangedEdges: []EdgeChange{},
+	}
+
+	// 1. Nodes. Indexes are compared on their tables (AddedIndexes, RemovedIndexes), so a
+	// snapshot taken before indexes became nodes does not report every index as new.
+	for _, id := range sortedNodeIDs(b) {
+		if b.Nodes[id].Type == Index {
+			continue
+		}
+		if _, ok := a.Nodes[id]; !ok {
+			d.AddedNodes = append(d.AddedNodes, b.Nodes[id])
+		}
+	}
+	for _, id := range sortedNodeIDs(a) {
+		before := a.Nodes[id]
+		if before.Type == Index {
+			continue
+		}
+		after, ok := b.Nodes[id]
+		if !ok {
+			d.RemovedNodes = append(d.RemovedNodes, before)
+			continue
+		}
+		if change, changed := diffNode(a, b, before, after, opts); changed {
+			d.ChangedNodes = append(d.ChangedNodes, change)
+		}
+	}
+
+	// 2. Edges
+	aEdges := edgeIndex(a)
+	bEdges := edgeIndex(b)
+	for _, key := range sortedKeys(bEdges) {
+		if _, ok := aEdges[key]; !ok {
+			d.AddedEdges = append(d.AddedEdges, bEdges[key])
+		}
+	}
+	for _, key := range sortedKeys(aEdges) {
+		before := aEdges[key]
+		after, ok := bEdges[key]
+		if !ok {
+			d.RemovedEdges = append(d.RemovedEdges, before)
+			continue
+		}
+		if before.DeleteRule != after.DeleteRule || before.MetaData["fk_columns"] != after.MetaData["fk_columns"] {
+			d.ChangedEdges = append(d.ChangedEdges, EdgeChange{Before: before, After: after})
+		}
+	}
+
+	return d
+}
+
+func diffNode(a, b *Graph, before, after *Node, opts DiffOptions) (NodeChange, bool) {
+	c := NodeChange{
+		ID:         after.ID,
+		Type:       after.Type,
+		RowsBefore: before.RowCount,
+		RowsAfter:  after.RowCount,
+		SizeBefore: before.Size,
+		SizeAfter:  after.Size,
+	}
+	if before.Type != after.Type {
+		c.TypeBefore = before.Type
+	}
+
+	c.RowsJumped = jumped(before.RowCount, after.RowCount, opts.GrowthThreshold, opts.MinRowDelta)
+	if sb, okB := parseSize(before.Size); okB {
+		if sa, okA := parseSize(after.Size); okA {
+			c.SizeJumped = jumped(sb, sa, opts.GrowthThreshold, opts.MinSizeDelta)
+		}
+	}
+
+	c.AddedIndexes, c.RemovedIndexes = diffIndexes(before.Indexes, after.Indexes)
+
+	if after.Type == View {
+		c.AddedDependencies, c.RemovedDependencies = diffStrings(viewDependencies(a, before.ID), viewDependencies(b, after.ID))
+	}
+
+	changed := c.TypeBefore != "" || c.RowsJumped || c.SizeJumped ||
+		len(c.AddedIndexes) > 0 || len(c.RemovedIndexes) > 0 ||
+		len(c.AddedDependencies) > 0 || len(c.RemovedDependencies) > 0
+	return c, changed
+}
+
+// jumped reports whether a value moved by at least threshold (relative) and minDelta (absolute)
+func jumped(before, after int64, threshold float64, minDelta int64) bool {
+	delta := after - before
+	if delta < 0 {
+		delta = -delta
+	}
+	if delta == 0 || delta < minDelta {
+		return false
+	}
+	base := math.Max(float64(before), 1)
+	return float64(delta)/base >= threshold
+}
+
+func diffIndexes(before, after [][]string) (added, removed [][]string) {
+	beforeSet := make(map[string]bool)
+	for _, cols := range before {
+		beforeSet[indexKey(cols)] = true
+	}
+	afterSet := make(map[string]bool)
+	for _, cols := range after {
+		afterSet[indexKey(cols)] = true
+		if !beforeSet[indexKey(cols)] {
+			added = append(added, cols)
+		}
+	}
+	for _, cols := range before {
+		if !afterSet[indexKey(cols)] {
+			removed = append(removed, cols)
+		}
+	}
+	return added, removed
+}
+
+func diffStrings(before, after []string) (added, removed []string) {
+	beforeSet := make(map[string]bool)
+	for _, s := range before {
+		beforeSet[s] = true
+	}
+	afterSet := make(map[string]bool)
+	for _, s := range after {
+		afterSet[s] = true
+		if !beforeSet[s] {
+			added = append(added, s)
+		}
+	}
+	for _, s := range before {
+		if !afterSet[s] {
+			removed = append(removed, s)
+		}
+	}
+	return added, removed
+}
+
+// viewDependencies returns the sorted relations a view reads
+func viewDependencies(g *Graph, id string) []string {
+	var deps []string
+	for _, e := range g.Edges[id] {
+		if e.Type == ViewDepends {
+			deps = append(deps, e.TargetID)
+		}
+	}
+	sort.Strings(deps)
+	return deps
+}
+
+func edgeIndex(g *Graph) map[string]*Edge {
+	idx := make(map[string]*Edge)
+	for _, edges := range g.Edges {
+		for _, e := range edges {
+			if e.Type == IndexOn {
+				continue
+			}
+			idx[edgeKey(e)] = e
+		}
+	}
+	return idx
+}
+
+func sortedNodeIDs(g *Graph) []string {
+	ids := make([]string, 0, len(g.Nodes))
+	for id := range g.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func sortedKeys(m map[string]*Edge) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// FormatSize renders a byte count the way pg_size_pretty does (e.g. "8192 bytes", "16 kB", "12 MB")
+func FormatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < 10*unit {
+		return fmt.Sprintf("%d bytes", bytes)
+	}
+	units := []string{"kB", "MB", "GB", "TB"}
+	value := float64(bytes) / unit
+	i := 0
+	for value >= 10*unit && i < len(units)-1 {
+		value /= unit
+		i++
+	}
+	return fmt.Sprintf("%.0f %s", value, units[i])
+}
+
+// parseSize converts pg_size_pretty style sizes ("8192 bytes", "16 kB", "12 MB") to bytes
+func parseSize(size string) (int64, bool) {
+	fields := strings.Fields(size)
+	if len(fields) != 2 {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, false
+	}
+	multipliers := map[string]float64{
+		"bytes": 1, "kb": 1 << 10, "mb": 1 << 20, "gb": 1 << 30, "tb": 1 << 40, "pb": 1 << 50,
+	}
+	m, ok := multipliers[strings.ToLower(fields[1])]
+	if !ok {
+		return 0, false
+	}
+	return int64(value * m), true
+}
@@ -0,0 +1,62 @@
+package graph
+
+import (
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	before := NewGraph()
+	before.AddNode("public", "users", Table, "10 MB", 10000)
+	before.AddNode("public", "orders", Table, "20 MB", 50000)
+	before.AddNode("public", "legacy", Table, "", 0)
+	before.AddNode("public", "summary", View, "", 0)
+	before.AddIndex("public", "orders", []string{"user_id"})
+	before.AddEdge("public", "orders", "public", "users", ForeignKey, "fk_user", "CASCADE")
+	before.AddEdge("public", "summary", "public", "orders", ViewDepends, "", "")
+
+	after := NewGraph()
+	after.AddNode("public", "users", Table, "11 MB", 10500)
+	after.AddNode("public", "orders", Table, "60 MB", 200000)
+	after.AddNode("public", "payments", Table, "", 0)
+	after.AddNode("public", "summary", View, "", 0)
+	after.AddEdge("public", "orders", "public", "users", ForeignKey, "fk_user", "RESTRICT")
+	after.AddEdge("public", "payments", "public", "orders", ForeignKey, "fk_order", "NO ACTION")
+	after.AddEdge("public", "summary", "public", "payments", ViewDepends, "", "")
+
+	d := Diff(before, after, DefaultDiffOptions)
+
+	if len(d.AddedNodes) != 1 || d.AddedNodes[0].ID != "public.payments" {
+		t.Errorf("AddedNodes = %+v", d.AddedNodes)
+	}
+	if len(d.RemovedNodes) != 1 || d.RemovedNodes[0].ID != "public.legacy" {
+		t.Errorf("RemovedNodes = %+v", d.RemovedNodes)
+	}
+
+	changes := make(map[string]NodeChange)
+	for _, c := range d.ChangedNodes {
+		changes[c.ID] = c
+	}
+	if _, ok := changes["public.users"]; ok {
+		t.Errorf("users grew by 5%% and should not be reported")
+	}
+	orders := changes["public.orders"]
+	if !orders.RowsJumped || !orders.SizeJumped || len(orders.RemovedIndexes) != 1 {
+		t.Errorf("unexpected orders change: %+v", orders)
+	}
+	summary := changes["public.summary"]
+	if len(summary.AddedDependencies) != 1 || summary.AddedDependencies[0] != "public.payments" ||
+		len(summary.RemovedDependencies) != 1 || summary.RemovedDependencies[0] != "public.orders" {
+		t.Errorf("unexpected view change: %+v", summary)
+	}
+
+	if len(d.ChangedEdges) != 1 || d.ChangedEdges[0].After.DeleteRule != "RESTRICT" {
+		t.Errorf("ChangedEdges = %+v", d.ChangedEdges)
+	}
+	if len(d.AddedEdges) != 2 || len(d.RemovedEdges) != 1 {
+		t.Errorf("got %d added / %d removed edges, want 2 / 1", len(d.AddedEdges), len(d.RemovedEdges))
+	}
+
+	if !Diff(after, after, DefaultDiffOptions).IsEmpty() {
+		t.Errorf("expected no diff between identical graphs")
+	}
+}
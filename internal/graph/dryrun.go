@@ -0,0 +1,23 @@
+package graph
+
+// DryRunResult is the ground truth from executing one statement in a transaction that is rolled back
+type DryRunResult struct {
+	Statement    string          `json:"statement"`
+	Error        string          `json:"error,omitempty"`         // Raised by the statement as written
+	Cascaded     bool            `json:"cascaded,omitempty"`      // It failed on dependencies and was retried with CASCADE
+	CascadeError string          `json:"cascade_error,omitempty"` // Raised by the CASCADE retry
+	Dropped      []DroppedObject `json:"dropped"`                 // Objects that no longer exist afterwards
+}
+
+// DroppedObject is a catalog object removed by a dry-run statement
+type DroppedObject struct {
+	Type   string `json:"type"` // "TABLE", "VIEW", "INDEX", "CONSTRAINT", "TRIGGER", "FUNCTION", ...
+	Schema string `json:"schema"`
+	Name   string `json:"name"`
+	Table  string `json:"table,omitempty"` // Owning table of indexes, constraints and triggers
+}
+
+// Failed reports whether the statement could not run, even with CASCADE
+func (r *DryRunResult) Failed() bool {
+	return r.Error != "" && (!r.Cascaded || r.CascadeError != "")
+}
@@ -2,6 +2,7 @@ package graph
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -9,9 +10,17 @@ import (
 type NodeType string
 
 const (
-	Table   NodeType = "TABLE"
-	View    NodeType = "VIEW"
-	Trigger NodeType = "TRIGGER"
+	Table    NodeType = "TABLE"
+	View     NodeType = "VIEW"
+	Trigger  NodeType = "TRIGGER"
+	Function NodeType = "FUNCTION" // Functions and procedures
+
+	Sequence      NodeType = "SEQUENCE"
+	CompositeType NodeType = "TYPE" // CREATE TYPE ... AS (...)
+	Enum          NodeType = "ENUM"
+	Domain        NodeType = "DOMAIN"
+
+	Index NodeType = "INDEX"
 )
 
 // DependencyType represents the type of relationship between nodes
@@ -22,63 +31,115 @@ const (
 	ViewDepends   DependencyType = "VIEW_DEPENDS"
 	TriggerAction DependencyType = "TRIGGER_ACTION"
 	Inheritance   DependencyType = "INHERITANCE" // For partitions
+
+	FunctionCall   DependencyType = "FUNCTION_CALL"   // Trigger or function -> function it executes
+	FunctionAccess DependencyType = "FUNCTION_ACCESS" // Function or trigger body -> relation it reads or writes (MetaData["access"])
+
+	TypeUsage       DependencyType = "TYPE_USAGE"       // Table, view, type, domain or function -> type it uses (MetaData["columns"])
+	SequenceDefault DependencyType = "SEQUENCE_DEFAULT" // Table -> sequence a column default calls nextval on (MetaData["columns"])
+	SequenceOwned   DependencyType = "SEQUENCE_OWNED"   // Sequence -> table owning it (OWNED BY, serial); dropped with the table
+
+	IndexOn DependencyType = "INDEX_ON" // Index -> table it is built on; dropped with the table
 )
 
 // ColumnDependency represents a database object that depends on a specific column
 type ColumnDependency struct {
-	Schema string
-	Name   string
-	Type   string // "VIEW", "TRIGGER", "INDEX", "FUNCTION", "TABLE"
-	Detail string // e.g., "src code match", "param ref"
+	Schema string `json:"schema"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`   // "VIEW", "TRIGGER", "INDEX", "FUNCTION", "TABLE"
+	Detail string `json:"detail"` // e.g., "src code match", "param ref"
 }
 
 // Node represents a database object (Table or View)
 type Node struct {
-	ID       string // Schema.Name
-	Schema   string
-	Name     string
-	Type     NodeType
-	Size     string     // e.g., "12MB", "400kB"
-	RowCount int64      // Estimated row count
-	Indexes  [][]string // List of indexed column sets
+	ID       string     `json:"id"` // Schema.Name
+	Schema   string     `json:"schema"`
+	Name     string     `json:"name"`
+	Type     NodeType   `json:"type"`
+	Size     string     `json:"size,omitempty"`    // e.g., "12MB", "400kB"
+	RowCount int64      `json:"row_count"`         // Estimated row count
+	Indexes  [][]string `json:"indexes,omitempty"` // List of indexed column sets
+
+	PrimaryKey []string `json:"primary_key,omitempty"` // Empty when the table has none or the adapter cannot tell
+	Writes     int64    `json:"writes,omitempty"`      // Rows inserted, updated and deleted since the stats reset (PostgreSQL)
+
+	Index *IndexInfo `json:"index,omitempty"` // Index nodes only
+}
+
+// IndexInfo describes an index node
+type IndexInfo struct {
+	Table       string   `json:"table"`            // ID of the indexed table
+	Method      string   `json:"method,omitempty"` // e.g. "btree", "gin"
+	Unique      bool     `json:"unique,omitempty"`
+	Primary     bool     `json:"primary,omitempty"`
+	Constraint  string   `json:"constraint,omitempty"`  // PRIMARY KEY / UNIQUE / EXCLUDE constraint the index backs
+	Columns     []string `json:"columns,omitempty"`     // Plain key columns in key order
+	Expressions []string `json:"expressions,omitempty"` // Expression keys, e.g. "lower(email)"
+	Predicate   string   `json:"predicate,omitempty"`   // Partial index WHERE clause
+	SizeBytes   int64    `json:"size_bytes,omitempty"`
+	Scans       int64    `json:"scans"`       // idx_scan since the statistics were reset
+	TuplesRead  int64    `json:"tuples_read"` // idx_tup_read
+	HasStats    bool     `json:"has_stats"`   // False offline: the scan counts are unknown, not zero
+}
+
+// Definition renders the index the way the impact tree shows it, e.g. "UNIQUE btree (email) WHERE deleted_at IS NULL"
+func (i *IndexInfo) Definition() string {
+	var parts []string
+	switch {
+	case i.Primary:
+		parts = append(parts, "PRIMARY KEY")
+	case i.Unique:
+		parts = append(parts, "UNIQUE")
+	}
+	if i.Method != "" {
+		parts = append(parts, i.Method)
+	}
+	keys := append(append([]string(nil), i.Columns...), i.Expressions...)
+	parts = append(parts, "("+strings.Join(keys, ", ")+")")
+	if i.Predicate != "" {
+		parts = append(parts, "WHERE "+i.Predicate)
+	}
+	return strings.Join(parts, " ")
 }
 
 // DBMetrics holds real-time database statistics
 type DBMetrics struct {
-	ActiveLocks    int
-	MaxConns       int
-	UsedConns      int
-	LongestQuery   string // e.g. "4.2s (PID 1294)"
-	ConnSaturation string // e.g. "82%"
+	ActiveLocks    int    `json:"active_locks"`
+	MaxConns       int    `json:"max_conns"`
+	UsedConns      int    `json:"used_conns"`
+	LongestQuery   string `json:"longest_query"`   // e.g. "4.2s (PID 1294)"
+	ConnSaturation string `json:"conn_saturation"` // e.g. "82%"
 }
 
 // QueryStats represents performance statistics for a single query
 type QueryStats struct {
-	QueryID     string
-	Query       string
-	Calls       int64
-	TotalTime   float64 // milliseconds
-	AvgTime     float64 // milliseconds
-	LoadPercent float64
+	QueryID     string  `json:"query_id"`
+	Query       string  `json:"query"`
+	Calls       int64   `json:"calls"`
+	TotalTime   float64 `json:"total_time_ms"` // milliseconds
+	AvgTime     float64 `json:"avg_time_ms"`   // milliseconds
+	LoadPercent float64 `json:"load_percent"`
 }
 
 // Edge represents a dependency: Source -> Target
 // If A has FK to B, A depends on B. So Edge is A -> B.
 type Edge struct {
-	SourceID string
-	TargetID string
-	Type     DependencyType
+	SourceID string         `json:"source"`
+	TargetID string         `json:"target"`
+	Type     DependencyType `json:"type"`
 
 	// Metadata for High-Fidelity Analysis
-	ConstraintName string
-	DeleteRule     string // "CASCADE", "RESTRICT", "SET NULL", "NO ACTION"
-	MetaData       map[string]string
+	ConstraintName string            `json:"constraint_name,omitempty"`
+	DeleteRule     string            `json:"delete_rule,omitempty"` // "CASCADE", "RESTRICT", "SET NULL", "NO ACTION"
+	MetaData       map[string]string `json:"metadata,omitempty"`
 }
 
 // Graph holds the adjacency list of the database schema
 type Graph struct {
-	Nodes map[string]*Node
-	Edges map[string][]*Edge // Adjacency list: SourceID -> List of Edges
+	Nodes map[string]*Node   `json:"nodes"`
+	Edges map[string][]*Edge `json:"edges"` // Adjacency list: SourceID -> List of Edges
+
+	Lineage []*ColumnLineage `json:"lineage,omitempty"` // Column-level edges through view definitions
 }
 
 // NewGraph creates a new empty graph
@@ -112,6 +173,23 @@ func (g *Graph) AddNode(schema, name string, nodeType NodeType, size string, row
 	}
 }
 
+// TriggerKey is the name part of a trigger's node ID: <table>.<trigger>. Trigger names are only unique
+// per table and often match the function the trigger executes, so the table keeps the IDs apart.
+func TriggerKey(table, name string) string {
+	return table + "." + name
+}
+
+// AddTrigger adds a trigger firing on schema.table (Trigger -> Table, TriggerAction). The node is keyed
+// by TriggerKey and keeps the plain trigger name.
+func (g *Graph) AddTrigger(schema, table, name string) {
+	key := TriggerKey(table, name)
+	id := fmt.Sprintf("%s.%s", schema, key)
+	if _, exists := g.Nodes[id]; !exists {
+		g.Nodes[id] = &Node{ID: id, Schema: schema, Name: name, Type: Trigger}
+	}
+	g.AddEdge(schema, key, schema, table, TriggerAction, "", "")
+}
+
 // AddIndex adds an index definition to a node
 func (g *Graph) AddIndex(schema, name string, columns []string) {
 	id := fmt.Sprintf("%s.%s", schema, name)
@@ -120,6 +198,38 @@ func (g *Graph) AddIndex(schema, name string, columns []string) {
 	}
 }
 
+// AddIndexNode adds an index as a node depending on its table (Index -> Table, IndexOn) and records
+// its plain key columns on the table for the FK coverage checks. Index names are unique per schema,
+// and an index lives in the schema of its table. Indexes of unknown tables are ignored.
+func (g *Graph) AddIndexNode(schema, table, name, size string, info IndexInfo) {
+	info.Table = fmt.Sprintf("%s.%s", schema, table)
+	if _, ok := g.Nodes[info.Table]; !ok {
+		return
+	}
+	g.AddNode(schema, name, Index, size, 0)
+	g.Nodes[fmt.Sprintf("%s.%s", schema, name)].Index = &info
+	g.AddEdge(schema, name, schema, table, IndexOn, "", "")
+	if len(info.Columns) > 0 {
+		g.AddIndex(schema, table, info.Columns)
+	}
+}
+
+// SetPrimaryKey records the primary key columns of a node
+func (g *Graph) SetPrimaryKey(schema, name string, columns []string) {
+	id := fmt.Sprintf("%s.%s", schema, name)
+	if node, exists := g.Nodes[id]; exists {
+		node.PrimaryKey = columns
+	}
+}
+
+// SetWrites records the write volume of a table
+func (g *Graph) SetWrites(schema, name string, writes int64) {
+	id := fmt.Sprintf("%s.%s", schema, name)
+	if node, exists := g.Nodes[id]; exists {
+		node.Writes = writes
+	}
+}
+
 // AddEdge adds a directed edge from source to target
 func (g *Graph) AddEdge(sourceSchema, sourceName, targetSchema, targetName string, depType DependencyType, constraintName, deleteRule string) {
 	sourceID := fmt.Sprintf("%s.%s", sourceSchema, sourceName)
@@ -146,6 +256,82 @@ func (g *Graph) AddEdge(sourceSchema, sourceName, targetSchema, targetName strin
 	g.Edges[sourceID] = append(g.Edges[sourceID], edge)
 }
 
+// AddFunctionAccess records that the body of a function (or inline trigger) reads or writes a relation.
+// Repeated calls merge into one edge whose "access" metadata is "read", "write" or "read,write" and
+// whose "confidence" metadata ("high", "medium" or "unknown") is the strongest seen.
+func (g *Graph) AddFunctionAccess(fnSchema, fnName, relSchema, relName string, write bool, confidence string) {
+	access := "read"
+	if write {
+		access = "write"
+	}
+	sourceID := fmt.Sprintf("%s.%s", fnSchema, fnName)
+	targetID := fmt.Sprintf("%s.%s", relSchema, relName)
+	for _, e := range g.Edges[sourceID] {
+		if e.Type == FunctionAccess && e.TargetID == targetID {
+			if e.MetaData["access"] != access {
+				e.MetaData["access"] = "read,write"
+			}
+			if confidenceRank[confidence] > confidenceRank[e.MetaData["confidence"]] {
+				e.MetaData["confidence"] = confidence
+			}
+			return
+		}
+	}
+	g.AddEdge(fnSchema, fnName, relSchema, relName, FunctionAccess, "", "")
+	edges := g.Edges[sourceID]
+	edges[len(edges)-1].MetaData = map[string]string{"access": access, "confidence": confidence}
+}
+
+// AddColumnEdge records that columns of a relation depend on an object (a type, a sequence).
+// Repeated calls between the same pair merge into one edge listing every column in MetaData["columns"];
+// an empty column (a function signature, a domain) only adds the edge.
+func (g *Graph) AddColumnEdge(srcSchema, srcName, targetSchema, targetName string, depType DependencyType, column string) {
+	sourceID := fmt.Sprintf("%s.%s", srcSchema, srcName)
+	targetID := fmt.Sprintf("%s.%s", targetSchema, targetName)
+	var edge *Edge
+	for _, e := range g.Edges[sourceID] {
+		if e.Type == depType && e.TargetID == targetID {
+			edge = e
+			break
+		}
+	}
+	if edge == nil {
+		g.AddEdge(srcSchema, srcName, targetSchema, targetName, depType, "", "")
+		edges := g.Edges[sourceID]
+		edge = edges[len(edges)-1]
+		edge.MetaData = map[string]string{}
+	}
+	if column == "" {
+		return
+	}
+	cols := edge.MetaData["columns"]
+	for _, c := range strings.Split(cols, ",") {
+		if c == column {
+			return
+		}
+	}
+	if cols != "" {
+		cols += ","
+	}
+	edge.MetaData["columns"] = cols + column
+}
+
+// Satellite reports whether a node is a sequence or an index. Both hang off the tables using them,
+// so the topology analyses (degrees, cycles, islands, orphans) leave them and their edges out;
+// otherwise every serial column would add a neighbour, and a cycle, to its table.
+func (g *Graph) Satellite(id string) bool {
+	node, ok := g.Nodes[id]
+	return ok && (node.Type == Sequence || node.Type == Index)
+}
+
+// structural reports whether an edge counts for the topology analyses
+func (g *Graph) structural(e *Edge) bool {
+	return !g.Satellite(e.SourceID) && !g.Satellite(e.TargetID)
+}
+
+// confidenceRank orders the confidence levels of body references
+var confidenceRank = map[string]int{"unknown": 1, "medium": 2, "high": 3}
+
 // GetDownstream returns all nodes that depend on the given node (Reverse dependency)
 // Real impact analysis: If A depends on B (A -> B), and we change B, A is impacted.
 // So we need to look for edges where Target == NodeID.
@@ -185,34 +371,102 @@ func (g *Graph) GetDownstream(nodeID string) []string {
 	return impacted
 }
 
+// GetUpstream returns all nodes the given node depends on: FK parents, the base tables of a
+// view chain, partition parents. maxDepth limits how many hops are followed (0 = unlimited)
+// and edgeTypes restricts the edges walked (none = every type).
+func (g *Graph) GetUpstream(nodeID string, maxDepth int, edgeTypes ...DependencyType) []string {
+	allowed := make(map[DependencyType]bool)
+	for _, t := range edgeTypes {
+		allowed[t] = true
+	}
+
+	queue := []string{nodeID}
+	depth := map[string]int{nodeID: 0}
+
+	idx := 0
+	for idx < len(queue) {
+		current := queue[idx]
+		idx++
+		if maxDepth > 0 && depth[current] >= maxDepth {
+			continue
+		}
+
+		for _, edge := range g.Edges[current] {
+			if len(allowed) > 0 && !allowed[edge.Type] {
+				continue
+			}
+			if _, seen := depth[edge.TargetID]; !seen {
+				depth[edge.TargetID] = depth[current] + 1
+				queue = append(queue, edge.TargetID)
+			}
+		}
+	}
+
+	// queue[0] is the node itself
+	return append([]string{}, queue[1:]...)
+}
+
+// GetDependents returns the nodes depending on the given node through the given edge types,
+// followed transitively (e.g. the views built on a table, the partitions of a partitioned table),
+// nearest first. No edge types means all of them.
+func (g *Graph) GetDependents(nodeID string, edgeTypes ...DependencyType) []string {
+	allowed := make(map[DependencyType]bool)
+	for _, t := range edgeTypes {
+		allowed[t] = true
+	}
+	reverse := make(map[string][]string)
+	for _, src := range sortedEdgeSources(g) {
+		for _, e := range g.Edges[src] {
+			if len(allowed) == 0 || allowed[e.Type] {
+				reverse[e.TargetID] = append(reverse[e.TargetID], src)
+			}
+		}
+	}
+
+	queue := []string{nodeID}
+	seen := map[string]bool{nodeID: true}
+	for idx := 0; idx < len(queue); idx++ {
+		for _, dep := range reverse[queue[idx]] {
+			if !seen[dep] {
+				seen[dep] = true
+				queue = append(queue, dep)
+			}
+		}
+	}
+	return queue[1:]
+}
+
 // NodeRank represents a node's topological importance
 type NodeRank struct {
-	ID         string
-	Type       NodeType
-	InDegree   int
-	OutDegree  int
-	Rows       int64
-	Centrality float64
+	ID         string   `json:"id"`
+	Type       NodeType `json:"type"`
+	InDegree   int      `json:"in_degree"`
+	OutDegree  int      `json:"out_degree"`
+	Rows       int64    `json:"rows"`
+	Centrality float64  `json:"centrality"`
 }
 
 // Stats returns topological metrics of the graph
 type GraphStats struct {
-	Nodes          int
-	Edges          int
-	Density        float64
-	Components     int
-	MaxCentrality  float64
-	CentralNode    string
-	LongestPath    int
-	DeepestChain   []string
-	IsolatedGroups []string
-	TopNodes       []NodeRank // Top nodes by centrality/impact
+	Nodes          int        `json:"nodes"`
+	Edges          int        `json:"edges"`
+	Density        float64    `json:"density"`
+	Components     int        `json:"components"`
+	MaxCentrality  float64    `json:"max_centrality"`
+	CentralNode    string     `json:"central_node"`
+	LongestPath    int        `json:"longest_path"`
+	DeepestChain   []string   `json:"deepest_chain,omitempty"`
+	IsolatedGroups []string   `json:"isolated_groups"`
+	TopNodes       []NodeRank `json:"top_nodes"` // Top nodes by centrality/impact
 }
 
 // AnalyzeTopology computes comprehensive graph metrics
 func (g *Graph) AnalyzeTopology() *GraphStats {
-	stats := &GraphStats{
-		Nodes: len(g.Nodes),
+	stats := &GraphStats{}
+	for id := range g.Nodes {
+		if !g.Satellite(id) {
+			stats.Nodes++
+		}
 	}
 
 	edgeCount := 0
@@ -220,9 +474,12 @@ func (g *Graph) AnalyzeTopology() *GraphStats {
 	outDegree := make(map[string]int)
 
 	for src, edges := range g.Edges {
-		edgeCount += len(edges)
-		outDegree[src] += len(edges)
 		for _, edge := range edges {
+			if !g.structural(edge) {
+				continue
+			}
+			edgeCount++
+			outDegree[src]++
 			inDegree[edge.TargetID]++
 		}
 	}
@@ -239,6 +496,9 @@ func (g *Graph) AnalyzeTopology() *GraphStats {
 	var ranks []NodeRank
 
 	for id, node := range g.Nodes {
+		if g.Satellite(id) {
+			continue
+		}
 		dIn := inDegree[id]
 		dOut := outDegree[id]
 		dTotal := dIn + dOut
@@ -281,13 +541,16 @@ func (g *Graph) AnalyzeTopology() *GraphStats {
 	undirected := make(map[string][]string)
 	for src, edges := range g.Edges {
 		for _, edge := range edges {
+			if !g.structural(edge) {
+				continue
+			}
 			undirected[src] = append(undirected[src], edge.TargetID)
 			undirected[edge.TargetID] = append(undirected[edge.TargetID], src)
 		}
 	}
 
 	for id := range g.Nodes {
-		if !visited[id] {
+		if !visited[id] && !g.Satellite(id) {
 			components++
 			// BFS to find all nodes in this component
 			componentNodes := []string{id}
@@ -314,8 +577,8 @@ func (g *Graph) AnalyzeTopology() *GraphStats {
 				}
 			}
 
-			// Store isolated small clusters (arbitrary size < 3)
-			if len(componentNodes) < 3 && len(componentNodes) > 0 {
+			// Store isolated small clusters
+			if len(componentNodes) <= DefaultIslandMaxSize && len(componentNodes) > 0 {
 				isolated = append(isolated, fmt.Sprintf("%v", componentNodes))
 			}
 		}
@@ -338,6 +601,9 @@ func (g *Graph) AnalyzeTopology() *GraphStats {
 
 		maxD := 0
 		for _, edge := range g.Edges[id] {
+			if !g.structural(edge) {
+				continue
+			}
 			d := getDepth(edge.TargetID, pathStack)
 			if d > maxD {
 				maxD = d
@@ -350,6 +616,9 @@ func (g *Graph) AnalyzeTopology() *GraphStats {
 
 	maxPath := 0
 	for id := range g.Nodes {
+		if g.Satellite(id) {
+			continue
+		}
 		d := getDepth(id, make(map[string]bool))
 		if d > maxPath {
 			maxPath = d
@@ -383,6 +652,9 @@ func (g *Graph) CheckCycles() [][]string {
 		// Edge Source -> Target means Source depends on Target
 		if edges, ok := g.Edges[v]; ok {
 			for _, wEdge := range edges {
+				if !g.structural(wEdge) {
+					continue
+				}
 				w := wEdge.TargetID
 				if _, ok := indices[w]; !ok {
 					strongconnect(w)
@@ -433,7 +705,7 @@ func (g *Graph) CheckCycles() [][]string {
 	}
 
 	for nodeID := range g.Nodes {
-		if _, ok := indices[nodeID]; !ok {
+		if _, ok := indices[nodeID]; !ok && !g.Satellite(nodeID) {
 			strongconnect(nodeID)
 		}
 	}
@@ -443,9 +715,9 @@ func (g *Graph) CheckCycles() [][]string {
 
 // IndexIssues represents the result of an index hygiene check
 type IndexIssues struct {
-	MissingFKIndexes []string // List of FK constraints without a supporting index
-	TotalFKs         int
-	IndexedFKs       int
+	MissingFKIndexes []string `json:"missing_fk_indexes"` // List of FK constraints without a supporting index
+	TotalFKs         int      `json:"total_fks"`
+	IndexedFKs       int      `json:"indexed_fks"`
 }
 
 // CheckIndexCoverage identifies foreign keys that lack a supporting index
@@ -475,19 +747,7 @@ func (g *Graph) CheckIndexCoverage() *IndexIssues {
 
 				isIndexed := false
 				for _, idx := range srcNode.Indexes {
-					// Check if index covers FK columns as a prefix
-					if len(idx) < len(fkCols) {
-						continue
-					}
-
-					match := true
-					for i, col := range fkCols {
-						if idx[i] != col {
-							match = false
-							break
-						}
-					}
-					if match {
+					if indexCovers(idx, fkCols) {
 						isIndexed = true
 						break
 					}
@@ -506,31 +766,171 @@ func (g *Graph) CheckIndexCoverage() *IndexIssues {
 	return issues
 }
 
+// indexCovers reports whether an index on idx can serve lookups on cols: cols is a prefix of its key
+func indexCovers(idx, cols []string) bool {
+	if len(idx) < len(cols) {
+		return false
+	}
+	for i, col := range cols {
+		if idx[i] != col {
+			return false
+		}
+	}
+	return true
+}
+
+// ReferencingForeignKeys returns the FK edges whose referenced key is enforced by the unique index
+// indexID (MetaData["referenced_index"]), sorted by referencing table. The index cannot be dropped
+// while they exist.
+func (g *Graph) ReferencingForeignKeys(indexID string) []*Edge {
+	var fks []*Edge
+	for _, edges := range g.Edges {
+		for _, e := range edges {
+			if e.Type == ForeignKey && e.MetaData["referenced_index"] == indexID {
+				fks = append(fks, e)
+			}
+		}
+	}
+	sortEdges(fks)
+	return fks
+}
+
+// SupportedForeignKeys returns the FK edges of the index's table that no other index covers;
+// dropping indexID leaves them unindexed
+func (g *Graph) SupportedForeignKeys(indexID string) []*Edge {
+	node, ok := g.Nodes[indexID]
+	if !ok || node.Index == nil {
+		return nil
+	}
+	table, ok := g.Nodes[node.Index.Table]
+	if !ok {
+		return nil
+	}
+	var fks []*Edge
+	for _, e := range g.Edges[table.ID] {
+		cols := e.MetaData["fk_columns"]
+		if e.Type != ForeignKey || cols == "" {
+			continue
+		}
+		fkCols := strings.Split(cols, ",")
+		if !indexCovers(node.Index.Columns, fkCols) {
+			continue
+		}
+		covering := 0
+		for _, idx := range table.Indexes {
+			if indexCovers(idx, fkCols) {
+				covering++
+			}
+		}
+		if covering <= 1 {
+			fks = append(fks, e)
+		}
+	}
+	sortEdges(fks)
+	return fks
+}
+
+// sortEdges orders edges by source, then constraint name, to keep reports stable
+func sortEdges(edges []*Edge) {
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].SourceID != edges[j].SourceID {
+			return edges[i].SourceID < edges[j].SourceID
+		}
+		return edges[i].ConstraintName < edges[j].ConstraintName
+	})
+}
+
 // GodMod represents a node identified as a High Coupling Risk / God Object
 type GodMod struct {
-	ID           string
-	Degree       int
-	Dependents   int // Fan-in
-	Dependencies int // Fan-out
+	ID           string `json:"id"`
+	Degree       int    `json:"degree"`
+	Dependents   int    `json:"dependents"`   // Fan-in
+	Dependencies int    `json:"dependencies"` // Fan-out
 }
 
+// DefaultIslandMaxSize is the largest weakly connected cluster AnalyzeTopology reports as an island
+const DefaultIslandMaxSize = 2
+
+// Islands returns the weakly connected components with between 2 and maxSize nodes,
+// each sorted by ID. The largest component is the schema itself and never an island;
+// single unconnected nodes are left to the orphan checks.
+func (g *Graph) Islands(maxSize int) [][]string {
+	undirected := make(map[string][]string)
+	for src, edges := range g.Edges {
+		for _, edge := range edges {
+			if !g.structural(edge) {
+				continue
+			}
+			undirected[src] = append(undirected[src], edge.TargetID)
+			undirected[edge.TargetID] = append(undirected[edge.TargetID], src)
+		}
+	}
+
+	visited := make(map[string]bool)
+	var components [][]string
+	largest := 0
+	for id := range g.Nodes {
+		if visited[id] || g.Satellite(id) {
+			continue
+		}
+		visited[id] = true
+		component := []string{id}
+		for i := 0; i < len(component); i++ {
+			for _, neighbor := range undirected[component[i]] {
+				if !visited[neighbor] {
+					visited[neighbor] = true
+					component = append(component, neighbor)
+				}
+			}
+		}
+		sort.Strings(component)
+		components = append(components, component)
+		if main := components[largest]; len(component) > len(main) || (len(component) == len(main) && component[0] < main[0]) {
+			largest = len(components) - 1
+		}
+	}
+
+	var islands [][]string
+	for i, component := range components {
+		if i != largest && len(component) >= 2 && len(component) <= maxSize {
+			islands = append(islands, component)
+		}
+	}
+	sort.Slice(islands, func(i, j int) bool { return islands[i][0] < islands[j][0] })
+	return islands
+}
+
+// DefaultGodObjectThreshold is the degree at which DetectGodObjects flags a node.
+// Lowered slightly for the test DB context, usually 20-30.
+const DefaultGodObjectThreshold = 15
+
 // DetectGodObjects identifies nodes with excessive connectivity
-// Threshold: If Degree (In+Out) > 20 (heuristic), it is potential technical debt.
+// Threshold: If Degree (In+Out) >= DefaultGodObjectThreshold (heuristic), it is potential technical debt.
 func (g *Graph) DetectGodObjects() []GodMod {
+	return g.FindGodObjects(DefaultGodObjectThreshold)
+}
+
+// FindGodObjects returns the nodes whose degree (In+Out) is at least threshold
+func (g *Graph) FindGodObjects(threshold int) []GodMod {
 	var gods []GodMod
-	threshold := 15 // Lowered slightly for the test DB context, usually 20-30
 
 	inDegree := make(map[string]int)
 	outDegree := make(map[string]int)
 
 	for src, edges := range g.Edges {
-		outDegree[src] += len(edges)
 		for _, edge := range edges {
+			if !g.structural(edge) {
+				continue
+			}
+			outDegree[src]++
 			inDegree[edge.TargetID]++
 		}
 	}
 
 	for id := range g.Nodes {
+		if g.Satellite(id) {
+			continue
+		}
 		in := inDegree[id]
 		out := outDegree[id]
 		total := in + out
@@ -52,3 +52,147 @@ func TestGetDownstream(t *testing.T) {
 		t.Errorf("Expected impacted for B %v, got %v", expectedB, impactedB)
 	}
 }
+
+func TestGetUpstream(t *testing.T) {
+	g := NewGraph()
+
+	// report (view) -> orders -> users, orders -> products; orders_2024 is a partition of orders
+	g.AddNode("public", "users", Table, "", 0)
+	g.AddNode("public", "products", Table, "", 0)
+	g.AddNode("public", "orders", Table, "", 0)
+	g.AddNode("public", "orders_2024", Table, "", 0)
+	g.AddNode("public", "report", View, "", 0)
+
+	g.AddEdge("public", "orders", "public", "users", ForeignKey, "fk_user", "CASCADE")
+	g.AddEdge("public", "orders", "public", "products", ForeignKey, "fk_product", "NO ACTION")
+	g.AddEdge("public", "orders_2024", "public", "orders", Inheritance, "", "")
+	g.AddEdge("public", "report", "public", "orders", ViewDepends, "", "")
+
+	cases := []struct {
+		node     string
+		depth    int
+		types    []DependencyType
+		expected []string
+	}{
+		{"public.report", 0, nil, []string{"public.orders", "public.products", "public.users"}},
+		{"public.report", 1, nil, []string{"public.orders"}},
+		{"public.report", 0, []DependencyType{ForeignKey}, []string{}},
+		{"public.orders_2024", 0, []DependencyType{Inheritance}, []string{"public.orders"}},
+		{"public.users", 0, nil, []string{}},
+	}
+	for _, c := range cases {
+		got := g.GetUpstream(c.node, c.depth, c.types...)
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("GetUpstream(%s, %d, %v) = %v, want %v", c.node, c.depth, c.types, got, c.expected)
+		}
+	}
+}
+
+func TestGetDependents(t *testing.T) {
+	g := NewGraph()
+
+	// report (view) -> orders -> users; orders_2024 is a partition of orders
+	g.AddNode("public", "users", Table, "", 0)
+	g.AddNode("public", "orders", Table, "", 0)
+	g.AddNode("public", "orders_2024", Table, "", 0)
+	g.AddNode("public", "report", View, "", 0)
+
+	g.AddEdge("public", "orders", "public", "users", ForeignKey, "fk_user", "CASCADE")
+	g.AddEdge("public", "orders_2024", "public", "orders", Inheritance, "", "")
+	g.AddEdge("public", "report", "public", "orders", ViewDepends, "", "")
+
+	cases := []struct {
+		node     string
+		types    []DependencyType
+		expected []string
+	}{
+		{"public.users", nil, []string{"public.orders", "public.orders_2024", "public.report"}},
+		{"public.users", []DependencyType{ForeignKey}, []string{"public.orders"}},
+		{"public.orders", []DependencyType{Inheritance}, []string{"public.orders_2024"}},
+		{"public.report", nil, []string{}},
+	}
+	for _, c := range cases {
+		got := g.GetDependents(c.node, c.types...)
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("GetDependents(%s, %v) = %v, want %v", c.node, c.types, got, c.expected)
+		}
+	}
+}
+
+func TestAddFunctionAccess(t *testing.T) {
+	g := NewGraph()
+	g.AddNode("public", "log_order_changes", Function, "", 0)
+	g.AddNode("public", "audit_logs", Table, "", 0)
+
+	g.AddFunctionAccess("public", "log_order_changes", "public", "audit_logs", true, "unknown")
+	g.AddFunctionAccess("public", "log_order_changes", "public", "audit_logs", true, "high")
+	edges := g.Edges["public.log_order_changes"]
+	if len(edges) != 1 || edges[0].MetaData["access"] != "write" || edges[0].MetaData["confidence"] != "high" {
+		t.Fatalf("expected one high-confidence write edge, got %+v", edges)
+	}
+
+	g.AddFunctionAccess("public", "log_order_changes", "public", "audit_logs", false, "medium")
+	if meta := edges[0].MetaData; meta["access"] != "read,write" || meta["confidence"] != "high" {
+		t.Errorf("expected merged access read,write with high confidence, got %v", meta)
+	}
+}
+
+func TestAddColumnEdge(t *testing.T) {
+	g := NewGraph()
+	g.AddNode("public", "orders", Table, "", 0)
+	g.AddNode("public", "order_status", Enum, "", 0)
+	g.AddNode("public", "orders_id_seq", Sequence, "", 0)
+
+	g.AddColumnEdge("public", "orders", "public", "order_status", TypeUsage, "status")
+	g.AddColumnEdge("public", "orders", "public", "order_status", TypeUsage, "previous_status")
+	g.AddColumnEdge("public", "orders", "public", "order_status", TypeUsage, "status")
+	edges := g.Edges["public.orders"]
+	if len(edges) != 1 || edges[0].MetaData["columns"] != "status,previous_status" {
+		t.Fatalf("expected one type edge for both columns, got %+v", edges)
+	}
+
+	// A serial column: the default uses the sequence, the sequence is owned by the table
+	g.AddColumnEdge("public", "orders", "public", "orders_id_seq", SequenceDefault, "id")
+	g.AddColumnEdge("public", "orders_id_seq", "public", "orders", SequenceOwned, "id")
+	if cycles := g.CheckCycles(); len(cycles) != 0 {
+		t.Errorf("sequence ownership should not count as a cycle, got %v", cycles)
+	}
+}
+
+func TestIndexNodes(t *testing.T) {
+	g := NewGraph()
+	g.AddNode("public", "users", Table, "", 0)
+	g.AddNode("public", "orders", Table, "", 0)
+	g.AddIndexNode("public", "users", "users_pkey", "16 kB", IndexInfo{Unique: true, Primary: true, Columns: []string{"id"}})
+	g.AddIndexNode("public", "orders", "orders_user_idx", "", IndexInfo{Columns: []string{"user_id", "created_at"}})
+	g.AddIndexNode("public", "missing", "missing_idx", "", IndexInfo{Columns: []string{"id"}})
+	g.AddEdge("public", "orders", "public", "users", ForeignKey, "fk_user", "CASCADE")
+	g.Edges["public.orders"][0].MetaData = map[string]string{"fk_columns": "user_id", "referenced_index": "public.users_pkey"}
+
+	if _, ok := g.Nodes["public.missing_idx"]; ok {
+		t.Errorf("index of an unknown table should be ignored")
+	}
+	if idx := g.Nodes["public.users_pkey"]; idx == nil || idx.Type != Index || idx.Index.Table != "public.users" ||
+		idx.Index.Definition() != "PRIMARY KEY (id)" {
+		t.Fatalf("unexpected index node: %+v", idx)
+	}
+	if !reflect.DeepEqual(g.Nodes["public.orders"].Indexes, [][]string{{"user_id", "created_at"}}) {
+		t.Errorf("expected index columns recorded on the table, got %v", g.Nodes["public.orders"].Indexes)
+	}
+	if stats := g.AnalyzeTopology(); stats.Nodes != 2 {
+		t.Errorf("indexes should not count as topology nodes, got %d", stats.Nodes)
+	}
+
+	if fks := g.ReferencingForeignKeys("public.users_pkey"); len(fks) != 1 || fks[0].ConstraintName != "fk_user" {
+		t.Errorf("expected fk_user to reference users_pkey, got %v", fks)
+	}
+	if fks := g.SupportedForeignKeys("public.orders_user_idx"); len(fks) != 1 || fks[0].ConstraintName != "fk_user" {
+		t.Errorf("expected orders_user_idx to be the only index covering fk_user, got %v", fks)
+	}
+	g.AddIndexNode("public", "orders", "orders_user_id_idx", "", IndexInfo{Columns: []string{"user_id"}})
+	if fks := g.SupportedForeignKeys("public.orders_user_idx"); len(fks) != 0 {
+		t.Errorf("a second covering index should lift the sole-support flag, got %v", fks)
+	}
+}
@@ -0,0 +1,332 @@
+package graph
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+)
+
+// IndexFindingKind is why an index is a candidate for dropping
+type IndexFindingKind string
+
+const (
+	IndexUnused    IndexFindingKind = "unused"    // No scans since the statistics were reset
+	IndexDuplicate IndexFindingKind = "duplicate" // Same definition as another index of the table
+	IndexRedundant IndexFindingKind = "redundant" // Its key is a left prefix of another index's key
+)
+
+// IndexFinding is an index that can be dropped
+type IndexFinding struct {
+	ID         string           `json:"id"`
+	Table      string           `json:"table"`
+	Kind       IndexFindingKind `json:"kind"`
+	Definition string           `json:"definition"`
+	Size       string           `json:"size,omitempty"`
+	SizeBytes  int64            `json:"size_bytes"`
+	Scans      int64            `json:"scans"`
+	CoveredBy  string           `json:"covered_by,omitempty"` // Duplicates and redundant indexes: the index serving the same lookups
+}
+
+// ProtectedIndex is an index that must not be dropped, whatever its scan count
+type ProtectedIndex struct {
+	ID         string   `json:"id"`
+	Table      string   `json:"table"`
+	Definition string   `json:"definition"`
+	Scans      int64    `json:"scans"`
+	Reasons    []string `json:"reasons"`
+}
+
+// IndexAudit is the result of AuditIndexes
+type IndexAudit struct {
+	Indexes      int              `json:"indexes"`   // Index nodes examined
+	HasStats     bool             `json:"has_stats"` // Scan counts were available, so unused indexes could be reported
+	Findings     []IndexFinding   `json:"findings"`
+	Protected    []ProtectedIndex `json:"protected"`
+	SavingsBytes int64            `json:"savings_bytes"` // Combined size of the findings
+	Savings      string           `json:"savings"`
+}
+
+// indexProtection lists why an index must be kept: the constraint it enforces, the foreign keys
+// referencing its key, and the foreign keys no other index covers
+func (g *Graph) indexProtection(n *Node) []string {
+	var reasons []string
+	switch {
+	case n.Index.Primary:
+		reasons = append(reasons, fmt.Sprintf("enforces primary key %s", n.Index.Constraint))
+	case n.Index.Constraint != "":
+		reasons = append(reasons, fmt.Sprintf("enforces constraint %s", n.Index.Constraint))
+	case n.Index.Unique:
+		reasons = append(reasons, "enforces uniqueness")
+	}
+	for _, e := range g.ReferencingForeignKeys(n.ID) {
+		reasons = append(reasons, fmt.Sprintf("referenced by FK %s on %s", e.ConstraintName, e.SourceID))
+	}
+	for _, e := range g.SupportedForeignKeys(n.ID) {
+		reasons = append(reasons, fmt.Sprintf("only index covering FK %s (%s)", e.ConstraintName, e.MetaData["fk_columns"]))
+	}
+	return reasons
+}
+
+// definitionKey identifies indexes that serve exactly the same lookups
+func definitionKey(i *IndexInfo) string {
+	return strings.Join([]string{i.Table, i.Method, strings.Join(i.Columns, ","), strings.Join(i.Expressions, ","), i.Predicate}, "|")
+}
+
+// AuditIndexes reports the indexes that can be dropped: exact duplicates, indexes whose key is a left
+// prefix of another index on the same table (reusing the prefix matching of CheckIndexCoverage), and,
+// when scan counts are known, indexes never scanned. Indexes enforcing a constraint or uniqueness,
+// referenced by a foreign key, or alone in covering one are protected and never reported.
+func (g *Graph) AuditIndexes() *IndexAudit {
+	audit := &IndexAudit{Findings: []IndexFinding{}, Protected: []ProtectedIndex{}}
+
+	var indexes []*Node
+	for _, id := range sortedNodeIDs(g) {
+		if n := g.Nodes[id]; n.Type == Index && n.Index != nil {
+			indexes = append(indexes, n)
+			audit.HasStats = audit.HasStats || n.Index.HasStats
+		}
+	}
+	audit.Indexes = len(indexes)
+
+	protected := make(map[string]bool)
+	for _, n := range indexes {
+		if reasons := g.indexProtection(n); len(reasons) > 0 {
+			protected[n.ID] = true
+			audit.Protected = append(audit.Protected, ProtectedIndex{
+				ID: n.ID, Table: n.Index.Table, Definition: n.Index.Definition(), Scans: n.Index.Scans, Reasons: reasons,
+			})
+		}
+	}
+
+	flagged := make(map[string]bool)
+	coverOf := make(map[string]bool) // Indexes a finding relies on to serve its lookups
+	// lastCover lists the foreign keys of n's table that no index but n covers once the findings
+	// so far are dropped: protection is decided again as indexes get flagged
+	lastCover := func(n *Node) []*Edge {
+		var fks []*Edge
+		for _, e := range g.Edges[n.Index.Table] {
+			cols := e.MetaData["fk_columns"]
+			if e.Type != ForeignKey || cols == "" || !indexCovers(n.Index.Columns, strings.Split(cols, ",")) {
+				continue
+			}
+			covered := false
+			for _, other := range indexes {
+				covered = covered || (other != n && !flagged[other.ID] && other.Index.Table == n.Index.Table &&
+					indexCovers(other.Index.Columns, strings.Split(cols, ",")))
+			}
+			if !covered {
+				fks = append(fks, e)
+			}
+		}
+		return fks
+	}
+	flag := func(n *Node, kind IndexFindingKind, coveredBy string) {
+		if fks := lastCover(n); len(fks) > 0 {
+			p := ProtectedIndex{ID: n.ID, Table: n.Index.Table, Definition: n.Index.Definition(), Scans: n.Index.Scans}
+			for _, e := range fks {
+				p.Reasons = append(p.Reasons, fmt.Sprintf("last index covering FK %s (%s) once the other findings are dropped", e.ConstraintName, e.MetaData["fk_columns"]))
+			}
+			protected[n.ID] = true
+			audit.Protected = append(audit.Protected, p)
+			return
+		}
+		flagged[n.ID] = true
+		if coveredBy != "" {
+			coverOf[coveredBy] = true
+		}
+		size := n.Index.SizeBytes
+		if size == 0 {
+			size, _ = parseSize(n.Size)
+		}
+		audit.Findings = append(audit.Findings, IndexFinding{
+			ID: n.ID, Table: n.Index.Table, Kind: kind, Definition: n.Index.Definition(),
+			Size: n.Size, SizeBytes: size, Scans: n.Index.Scans, CoveredBy: coveredBy,
+		})
+		audit.SavingsBytes += size
+	}
+
+	// 1. Exact duplicates: keep the protected, unique or most scanned index of each group
+	groups := make(map[string][]*Node)
+	var keys []string
+	for _, n := range indexes {
+		key := definitionKey(n.Index)
+		if len(groups[key]) == 0 {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], n)
+	}
+	for _, key := range keys {
+		group := groups[key]
+		if len(group) < 2 {
+			continue
+		}
+		sort.SliceStable(group, func(i, j int) bool {
+			a, b := group[i], group[j]
+			if protected[a.ID] != protected[b.ID] {
+				return protected[a.ID]
+			}
+			if a.Index.Unique != b.Index.Unique {
+				return a.Index.Unique
+			}
+			return a.Index.Scans > b.Index.Scans
+		})
+		for _, n := range group[1:] {
+			if !protected[n.ID] {
+				flag(n, IndexDuplicate, group[0].ID)
+			}
+		}
+	}
+
+	// 2. Left-prefix redundancy: a plain b-tree index whose columns start a longer one on the same
+	// table. A unique index is kept: uniqueness on fewer columns is a stronger constraint.
+	plain := func(i *IndexInfo) bool {
+		return i.Method == "btree" && i.Predicate == "" && len(i.Expressions) == 0 && len(i.Columns) > 0
+	}
+	for _, n := range indexes {
+		if flagged[n.ID] || protected[n.ID] || n.Index.Unique || !plain(n.Index) {
+			continue
+		}
+		for _, other := range indexes {
+			if other == n || other.Index.Table != n.Index.Table || flagged[other.ID] || !plain(other.Index) ||
+				len(other.Index.Columns) <= len(n.Index.Columns) {
+				continue
+			}
+			if indexCovers(other.Index.Columns, n.Index.Columns) {
+				flag(n, IndexRedundant, other.ID)
+				break
+			}
+		}
+	}
+
+	// 3. Unused: never scanned since the statistics were reset. An index kept in place of a duplicate
+	// or redundant one is not reported: dropping both would lose the lookups they serve.
+	for _, n := range indexes {
+		if flagged[n.ID] || protected[n.ID] || coverOf[n.ID] || !n.Index.HasStats || n.Index.Scans > 0 {
+			continue
+		}
+		flag(n, IndexUnused, "")
+	}
+
+	audit.Savings = FormatSize(audit.SavingsBytes)
+	return audit
+}
+
+// IndexSuggestion is an index to create for foreign keys that no index covers
+type IndexSuggestion struct {
+	Name        string   `json:"name"`  // Deterministic: <table>_<columns>_idx, shortened with a hash past 63 bytes
+	Table       string   `json:"table"` // ID of the referencing (child) table
+	Columns     []string `json:"columns"`
+	ForeignKeys []string `json:"foreign_keys"` // Constraints the index covers
+	Parents     []string `json:"parents"`      // IDs of the referenced tables
+	ParentRows  int64    `json:"parent_rows"`  // Largest row count among the parents
+	ChildWrites int64    `json:"child_writes"` // Rows the child table inserted, updated and deleted since the stats reset
+}
+
+// maxIdentifierLength is the longest identifier PostgreSQL keeps (NAMEDATALEN - 1)
+const maxIdentifierLength = 63
+
+// suggestedIndexName builds <table>_<columns>_idx, replacing the tail with a hash of the full name
+// when it would be truncated, so the same FK always yields the same name
+func suggestedIndexName(table string, columns []string) string {
+	name := fmt.Sprintf("%s_%s_idx", table, strings.Join(columns, "_"))
+	if len(name) <= maxIdentifierLength {
+		return name
+	}
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	suffix := fmt.Sprintf("_%08x_idx", h.Sum32())
+	return name[:maxIdentifierLength-len(suffix)] + suffix
+}
+
+// SuggestIndexes proposes an index for every foreign key CheckIndexCoverage reports as unindexed.
+// Foreign keys of a table sharing columns get one index: one whose columns are a prefix of a wider
+// suggestion's is merged into the widest one. Suggestions come most urgent first: by the row count
+// of the referenced table (each delete there scans the child), then by the child's write volume.
+func (g *Graph) SuggestIndexes() []IndexSuggestion {
+	byKey := make(map[string]*IndexSuggestion)
+	var keys []string
+	for _, src := range sortedEdgeSources(g) {
+		node := g.Nodes[src]
+		for _, e := range g.Edges[src] {
+			cols := e.MetaData["fk_columns"]
+			if e.Type != ForeignKey || cols == "" || node == nil {
+				continue
+			}
+			fkCols := strings.Split(cols, ",")
+			covered := false
+			for _, idx := range node.Indexes {
+				covered = covered || indexCovers(idx, fkCols)
+			}
+			if covered {
+				continue
+			}
+
+			key := src + "|" + cols
+			s, ok := byKey[key]
+			if !ok {
+				s = &IndexSuggestion{Name: suggestedIndexName(node.Name, fkCols), Table: src, Columns: fkCols, ChildWrites: node.Writes}
+				byKey[key] = s
+				keys = append(keys, key)
+			}
+			s.ForeignKeys = append(s.ForeignKeys, e.ConstraintName)
+			s.Parents = append(s.Parents, e.TargetID)
+			if parent, ok := g.Nodes[e.TargetID]; ok && parent.RowCount > s.ParentRows {
+				s.ParentRows = parent.RowCount
+			}
+		}
+	}
+
+	// Merge every suggestion into the widest one on the same table whose columns start with its
+	// columns, so chains (a ⊂ a,b ⊂ a,b,c) collapse into one index. All merges finish before the
+	// suggestions are copied out, whatever order the foreign keys came in.
+	subsumed := make(map[string]bool)
+	for _, key := range keys {
+		s := byKey[key]
+		var widest *IndexSuggestion
+		for _, otherKey := range keys {
+			other := byKey[otherKey]
+			if other != s && other.Table == s.Table && len(other.Columns) > len(s.Columns) && indexCovers(other.Columns, s.Columns) &&
+				(widest == nil || len(other.Columns) > len(widest.Columns)) {
+				widest = other
+			}
+		}
+		if widest == nil {
+			continue
+		}
+		widest.ForeignKeys = append(widest.ForeignKeys, s.ForeignKeys...)
+		widest.Parents = append(widest.Parents, s.Parents...)
+		if s.ParentRows > widest.ParentRows {
+			widest.ParentRows = s.ParentRows
+		}
+		subsumed[key] = true
+	}
+
+	suggestions := []IndexSuggestion{}
+	for _, key := range keys {
+		if !subsumed[key] {
+			suggestions = append(suggestions, *byKey[key])
+		}
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if a.ParentRows != b.ParentRows {
+			return a.ParentRows > b.ParentRows
+		}
+		if a.ChildWrites != b.ChildWrites {
+			return a.ChildWrites > b.ChildWrites
+		}
+		return a.Name < b.Name
+	})
+	return suggestions
+}
+
+// sortedEdgeSources returns the IDs of the nodes with outgoing edges, sorted
+func sortedEdgeSources(g *Graph) []string {
+	sources := make([]string, 0, len(g.Edges))
+	for src := range g.Edges {
+		sources = append(sources, src)
+	}
+	sort.Strings(sources)
+	return sources
+}
@@ -0,0 +1,175 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAuditIndexes(t *testing.T) {
+	g := NewGraph()
+	g.AddNode("public", "users", Table, "", 0)
+	g.AddNode("public", "orders", Table, "", 0)
+	addIndex := func(table, name string, scans int64, info IndexInfo) {
+		info.Method, info.Scans, info.SizeBytes, info.HasStats = "btree", scans, 1<<20, true
+		g.AddIndexNode("public", table, name, "1024 kB", info)
+	}
+	addIndex("users", "users_pkey", 0, IndexInfo{Unique: true, Primary: true, Constraint: "users_pkey", Columns: []string{"id"}})
+	addIndex("users", "users_email_idx", 0, IndexInfo{Columns: []string{"email"}})
+	addIndex("users", "users_email_idx2", 5, IndexInfo{Columns: []string{"email"}})
+	addIndex("orders", "orders_user_idx", 3, IndexInfo{Columns: []string{"user_id"}})
+	addIndex("orders", "orders_user_created_idx", 9, IndexInfo{Columns: []string{"user_id", "created_at"}})
+	addIndex("orders", "orders_status_idx", 0, IndexInfo{Columns: []string{"status"}, Predicate: "status <> 'done'"})
+	addIndex("orders", "orders_ref_idx", 0, IndexInfo{Columns: []string{"ref_id"}})
+	g.AddEdge("public", "orders", "public", "users", ForeignKey, "fk_user", "CASCADE")
+	g.Edges["public.orders"][0].MetaData = map[string]string{"fk_columns": "user_id", "referenced_index": "public.users_pkey"}
+	g.AddEdge("public", "orders", "public", "refs", ForeignKey, "fk_ref", "NO ACTION")
+	g.Edges["public.orders"][1].MetaData = map[string]string{"fk_columns": "ref_id"}
+
+	audit := g.AuditIndexes()
+	if audit.Indexes != 7 || !audit.HasStats {
+		t.Fatalf("unexpected audit totals: %+v", audit)
+	}
+
+	got := make(map[string]IndexFinding)
+	for _, f := range audit.Findings {
+		got[f.ID] = f
+	}
+	want := map[string]IndexFindingKind{
+		"public.users_email_idx":   IndexDuplicate, // The more scanned copy is kept
+		"public.orders_user_idx":   IndexRedundant,
+		"public.orders_status_idx": IndexUnused,
+	}
+	if len(got) != len(want) {
+		t.Errorf("expected %d findings, got %+v", len(want), audit.Findings)
+	}
+	for id, kind := range want {
+		if got[id].Kind != kind {
+			t.Errorf("expected %s to be %s, got %+v", id, kind, got[id])
+		}
+	}
+	if got["public.users_email_idx"].CoveredBy != "public.users_email_idx2" || got["public.orders_user_idx"].CoveredBy != "public.orders_user_created_idx" {
+		t.Errorf("unexpected covering indexes: %+v", audit.Findings)
+	}
+	if audit.SavingsBytes != 3<<20 || audit.Savings != "3072 kB" {
+		t.Errorf("expected 3 MB of savings, got %d (%s)", audit.SavingsBytes, audit.Savings)
+	}
+
+	protected := make(map[string]bool)
+	for _, p := range audit.Protected {
+		protected[p.ID] = true
+	}
+	if len(protected) != 2 || !protected["public.users_pkey"] || !protected["public.orders_ref_idx"] {
+		t.Errorf("expected the primary key and the only index covering fk_ref to be protected, got %+v", audit.Protected)
+	}
+}
+
+func TestAuditIndexesKeepsFKCover(t *testing.T) {
+	g := NewGraph()
+	g.AddNode("public", "users", Table, "", 0)
+	g.AddNode("public", "orders", Table, "", 0)
+	g.AddNode("public", "items", Table, "", 0)
+	addIndex := func(table, name string, cols ...string) {
+		g.AddIndexNode("public", table, name, "", IndexInfo{Method: "btree", Columns: cols, HasStats: true})
+	}
+	// Two indexes cover each FK, and none was ever scanned
+	addIndex("orders", "orders_user_idx", "user_id")
+	addIndex("orders", "orders_user_created_idx", "user_id", "created_at")
+	addIndex("items", "items_order_idx", "order_id")
+	addIndex("items", "items_order_idx2", "order_id")
+	// Neither duplicate nor redundant (other method): the second unused one is the FK's last cover
+	g.AddNode("public", "payments", Table, "", 0)
+	addIndex("payments", "payments_order_idx", "order_id")
+	g.AddIndexNode("public", "payments", "payments_order_hash", "", IndexInfo{Method: "hash", Columns: []string{"order_id"}, HasStats: true})
+	g.AddEdge("public", "payments", "public", "orders", ForeignKey, "fk_payment_order", "NO ACTION")
+	g.Edges["public.payments"][0].MetaData = map[string]string{"fk_columns": "order_id"}
+	g.AddEdge("public", "orders", "public", "users", ForeignKey, "fk_user", "CASCADE")
+	g.Edges["public.orders"][0].MetaData = map[string]string{"fk_columns": "user_id"}
+	g.AddEdge("public", "items", "public", "orders", ForeignKey, "fk_order", "CASCADE")
+	g.Edges["public.items"][0].MetaData = map[string]string{"fk_columns": "order_id"}
+
+	audit := g.AuditIndexes()
+	got := make(map[string]IndexFindingKind)
+	for _, f := range audit.Findings {
+		got[f.ID] = f.Kind
+	}
+	want := map[string]IndexFindingKind{
+		"public.orders_user_idx":     IndexRedundant, // orders_user_created_idx is kept for fk_user
+		"public.items_order_idx2":    IndexDuplicate, // items_order_idx is kept for fk_order
+		"public.payments_order_hash": IndexUnused,    // payments_order_idx is kept for fk_payment_order
+	}
+	if len(got) != len(want) {
+		t.Errorf("expected %d findings, got %+v", len(want), audit.Findings)
+	}
+	for id, kind := range want {
+		if got[id] != kind {
+			t.Errorf("expected %s to be %s, got %+v", id, kind, audit.Findings)
+		}
+	}
+	protected := false
+	for _, p := range audit.Protected {
+		protected = protected || p.ID == "public.payments_order_idx"
+	}
+	if !protected {
+		t.Errorf("expected payments_order_idx to be protected as the last cover of its FK, got %+v", audit.Protected)
+	}
+}
+
+func TestSuggestIndexes(t *testing.T) {
+	g := NewGraph()
+	g.AddNode("public", "users", Table, "", 1000)
+	g.AddNode("public", "teams", Table, "", 10)
+	g.AddNode("public", "orders", Table, "", 0)
+	g.AddNode("public", "memberships", Table, "", 0)
+	g.SetWrites("public", "orders", 50)
+	g.AddIndex("public", "orders", []string{"id"})
+	addFK := func(src, target, name, cols string) {
+		g.AddEdge("public", src, "public", target, ForeignKey, name, "NO ACTION")
+		edges := g.Edges["public."+src]
+		edges[len(edges)-1].MetaData = map[string]string{"fk_columns": cols}
+	}
+	addFK("orders", "users", "fk_order_user", "user_id")
+	addFK("memberships", "teams", "fk_member_team", "team_id")
+	addFK("memberships", "teams", "fk_member_team_user", "team_id,user_id")
+	addFK("orders", "teams", "fk_order_id", "id") // Covered by the index on id
+
+	got := g.SuggestIndexes()
+	if len(got) != 2 {
+		t.Fatalf("expected 2 suggestions, got %+v", got)
+	}
+	if got[0].Name != "orders_user_id_idx" || got[0].ParentRows != 1000 || got[0].ChildWrites != 50 {
+		t.Errorf("expected the index referencing the largest table first, got %+v", got[0])
+	}
+	if got[1].Name != "memberships_team_id_user_id_idx" || len(got[1].ForeignKeys) != 2 {
+		t.Errorf("expected one index covering both membership FKs, got %+v", got[1])
+	}
+
+	long := suggestedIndexName(strings.Repeat("t", 40), []string{strings.Repeat("c", 40)})
+	if len(long) != maxIdentifierLength || long != suggestedIndexName(strings.Repeat("t", 40), []string{strings.Repeat("c", 40)}) {
+		t.Errorf("expected a stable name of %d bytes, got %q", maxIdentifierLength, long)
+	}
+}
+
+func TestSuggestIndexesMergesWiderFirst(t *testing.T) {
+	g := NewGraph()
+	g.AddNode("public", "small", Table, "", 5)
+	g.AddNode("public", "big", Table, "", 1000000)
+	g.AddNode("public", "mid", Table, "", 100)
+	g.AddNode("public", "orders", Table, "", 0)
+	addFK := func(target, name, cols string) {
+		g.AddEdge("public", "orders", "public", target, ForeignKey, name, "NO ACTION")
+		edges := g.Edges["public.orders"]
+		edges[len(edges)-1].MetaData = map[string]string{"fk_columns": cols}
+	}
+	addFK("small", "fk_ab", "a,b") // The wider FK comes first
+	addFK("big", "fk_a", "a")
+	addFK("mid", "fk_abc", "a,b,c")
+
+	got := g.SuggestIndexes()
+	if len(got) != 1 {
+		t.Fatalf("expected the chain to collapse into 1 suggestion, got %+v", got)
+	}
+	s := got[0]
+	if s.Name != "orders_a_b_c_idx" || len(s.ForeignKeys) != 3 || s.ParentRows != 1000000 {
+		t.Errorf("expected the widest index to cover all 3 FKs with the 1M-row priority, got %+v", s)
+	}
+}
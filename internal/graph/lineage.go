@@ -0,0 +1,53 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ColumnLineage records that a view column is computed from a column of another relation.
+// Column IDs are "schema.relation.column". Lineage is kept apart from Nodes and Edges so
+// relation-level analyses (cycles, rankings, islands) are unaffected by it.
+type ColumnLineage struct {
+	Source string `json:"source"` // Column read by the view
+	Target string `json:"target"` // View column computed from it
+}
+
+// ColumnID builds the ID of a column node
+func ColumnID(schema, relation, column string) string {
+	return fmt.Sprintf("%s.%s.%s", schema, relation, column)
+}
+
+// AddLineage records that target is computed from source, ignoring duplicates
+func (g *Graph) AddLineage(source, target string) {
+	for _, l := range g.Lineage {
+		if l.Source == source && l.Target == target {
+			return
+		}
+	}
+	g.Lineage = append(g.Lineage, &ColumnLineage{Source: source, Target: target})
+}
+
+// ColumnSources returns the columns a column is directly computed from, sorted
+func (g *Graph) ColumnSources(columnID string) []string {
+	var sources []string
+	for _, l := range g.Lineage {
+		if l.Target == columnID {
+			sources = append(sources, l.Source)
+		}
+	}
+	sort.Strings(sources)
+	return sources
+}
+
+// ColumnConsumers returns the view columns directly computed from a column, sorted
+func (g *Graph) ColumnConsumers(columnID string) []string {
+	var consumers []string
+	for _, l := range g.Lineage {
+		if l.Source == columnID {
+			consumers = append(consumers, l.Target)
+		}
+	}
+	sort.Strings(consumers)
+	return consumers
+}
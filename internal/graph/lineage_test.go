@@ -0,0 +1,34 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestColumnLineage(t *testing.T) {
+	g := NewGraph()
+
+	// order_items.quantity, order_items.price -> order_totals.total -> high_value_orders.total_value
+	g.AddLineage("public.order_items.quantity", "public.order_totals.total")
+	g.AddLineage("public.order_items.price", "public.order_totals.total")
+	g.AddLineage("public.order_totals.total", "public.high_value_orders.total_value")
+	g.AddLineage("public.order_items.price", "public.order_totals.total") // duplicate
+
+	if len(g.Lineage) != 3 {
+		t.Errorf("Expected 3 lineage edges, got %d", len(g.Lineage))
+	}
+
+	sources := g.ColumnSources("public.order_totals.total")
+	if want := []string{"public.order_items.price", "public.order_items.quantity"}; !reflect.DeepEqual(sources, want) {
+		t.Errorf("Expected sources %v, got %v", want, sources)
+	}
+
+	consumers := g.ColumnConsumers("public.order_totals.total")
+	if want := []string{"public.high_value_orders.total_value"}; !reflect.DeepEqual(consumers, want) {
+		t.Errorf("Expected consumers %v, got %v", want, consumers)
+	}
+
+	if got := g.ColumnSources("public.order_items.price"); got != nil {
+		t.Errorf("Expected no sources for a base column, got %v", got)
+	}
+}
@@ -0,0 +1,149 @@
+package graph
+
+import "sort"
+
+// LockSession is a database session holding or waiting for relation locks
+type LockSession struct {
+	PID        int            `json:"pid"`
+	User       string         `json:"user,omitempty"`
+	State      string         `json:"state"`                // pg_stat_activity state: active, idle in transaction, ...
+	Query      string         `json:"query"`                // Current or last statement
+	DurationMs float64        `json:"duration_ms"`          // Since the transaction (or, outside one, the query) started
+	WaitEvent  string         `json:"wait_event,omitempty"` // e.g. "Lock: relation"
+	BlockedBy  []int          `json:"blocked_by,omitempty"` // PIDs holding the locks it waits for (pg_blocking_pids)
+	Locks      []RelationLock `json:"locks"`
+}
+
+// RelationLock is a table-level lock held or requested by a session
+type RelationLock struct {
+	Relation string   `json:"relation"` // schema.name, the graph node ID
+	Mode     string   `json:"mode"`     // e.g. "ACCESS EXCLUSIVE"
+	Granted  bool     `json:"granted"`
+	Type     NodeType `json:"type,omitempty"`  // Set by ResolveLocks when the relation is a graph node
+	Table    string   `json:"table,omitempty"` // Index locks: the indexed table
+}
+
+// Waiting reports whether the session waits for a lock
+func (s *LockSession) Waiting() bool {
+	for _, l := range s.Locks {
+		if !l.Granted {
+			return true
+		}
+	}
+	return len(s.BlockedBy) > 0
+}
+
+// LockNode is a session in a blocker → waiter tree: its children wait for locks it holds
+type LockNode struct {
+	LockSession
+	Waiters  int         `json:"waiters"`         // Distinct sessions waiting on it, directly or not
+	Cycle    bool        `json:"cycle,omitempty"` // Already on the path from the root: the sessions deadlock
+	Children []*LockNode `json:"children,omitempty"`
+}
+
+// Walk visits the node and its descendants depth-first
+func (n *LockNode) Walk(fn func(node, parent *LockNode)) {
+	var walk func(node, parent *LockNode)
+	walk = func(node, parent *LockNode) {
+		fn(node, parent)
+		for _, c := range node.Children {
+			walk(c, node)
+		}
+	}
+	walk(n, nil)
+}
+
+// BuildLockTree arranges sessions into blocker → waiter trees. Roots are the sessions blocking others
+// while not waiting themselves; a session blocked by several others appears under each of them. A
+// blocker missing from sessions (e.g. a backend of another kind) becomes a root with only its PID.
+// Sessions waiting on each other with no root, a deadlock the server has not broken yet, are rooted
+// at their lowest PID. Roots come with the most waiters first, then the longest running.
+func BuildLockTree(sessions []LockSession) []*LockNode {
+	byPID := make(map[int]LockSession)
+	waiters := make(map[int][]int)
+	for _, s := range sessions {
+		byPID[s.PID] = s
+	}
+	for _, s := range sessions {
+		for _, b := range s.BlockedBy {
+			if _, ok := byPID[b]; !ok {
+				byPID[b] = LockSession{PID: b, State: "unknown", Locks: []RelationLock{}}
+			}
+			waiters[b] = append(waiters[b], s.PID)
+		}
+	}
+	pids := make([]int, 0, len(byPID))
+	for pid := range byPID {
+		pids = append(pids, pid)
+	}
+	sort.Ints(pids)
+
+	var build func(pid int, onPath map[int]bool) *LockNode
+	build = func(pid int, onPath map[int]bool) *LockNode {
+		node := &LockNode{LockSession: byPID[pid]}
+		if onPath[pid] {
+			node.Cycle = true
+			return node
+		}
+		onPath[pid] = true
+		for _, w := range waiters[pid] {
+			node.Children = append(node.Children, build(w, onPath))
+		}
+		delete(onPath, pid)
+
+		// Count each waiter once, even when it waits on several sessions of the subtree
+		below := make(map[int]bool)
+		node.Walk(func(n, _ *LockNode) { below[n.PID] = true })
+		node.Waiters = len(below) - 1
+		sortLockNodes(node.Children)
+		return node
+	}
+
+	var roots []*LockNode
+	placed := make(map[int]bool)
+	place := func(pid int) {
+		root := build(pid, map[int]bool{})
+		root.Walk(func(n, _ *LockNode) { placed[n.PID] = true })
+		roots = append(roots, root)
+	}
+	for _, pid := range pids {
+		if len(waiters[pid]) > 0 && len(byPID[pid].BlockedBy) == 0 {
+			place(pid)
+		}
+	}
+	for _, pid := range pids {
+		if len(waiters[pid]) > 0 && !placed[pid] {
+			place(pid)
+		}
+	}
+	sortLockNodes(roots)
+	return roots
+}
+
+// sortLockNodes orders sessions by the waiters they hold up, then by duration and PID
+func sortLockNodes(nodes []*LockNode) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].Waiters != nodes[j].Waiters {
+			return nodes[i].Waiters > nodes[j].Waiters
+		}
+		if nodes[i].DurationMs != nodes[j].DurationMs {
+			return nodes[i].DurationMs > nodes[j].DurationMs
+		}
+		return nodes[i].PID < nodes[j].PID
+	})
+}
+
+// ResolveLocks sets the node type of the relations the sessions lock, and the table of locked indexes
+func (g *Graph) ResolveLocks(sessions []LockSession) {
+	for i := range sessions {
+		for j := range sessions[i].Locks {
+			l := &sessions[i].Locks[j]
+			if n, ok := g.Nodes[l.Relation]; ok {
+				l.Type = n.Type
+				if n.Index != nil {
+					l.Table = n.Index.Table
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuildLockTree(t *testing.T) {
+	sessions := []LockSession{
+		{PID: 10, State: "idle in transaction", DurationMs: 60000},
+		{PID: 20, State: "active", DurationMs: 5000, BlockedBy: []int{10}},
+		{PID: 30, State: "active", DurationMs: 1000, BlockedBy: []int{20}},
+		{PID: 40, State: "active", DurationMs: 2000, BlockedBy: []int{99}}, // Blocker not listed
+		{PID: 50, State: "active"},                                         // Holds locks, blocks nobody
+		{PID: 60, State: "active", BlockedBy: []int{70}},                   // Deadlock
+		{PID: 70, State: "active", BlockedBy: []int{60}},
+	}
+
+	roots := BuildLockTree(sessions)
+	var got []int
+	for _, r := range roots {
+		got = append(got, r.PID)
+	}
+	if want := []int{10, 60, 99}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("roots = %v, want %v", got, want)
+	}
+
+	if roots[0].Waiters != 2 || len(roots[0].Children) != 1 || roots[0].Children[0].Children[0].PID != 30 {
+		t.Errorf("expected 10 -> 20 -> 30, got %+v", roots[0])
+	}
+	if deadlock := roots[1]; deadlock.Waiters != 1 || len(deadlock.Children) != 1 || !deadlock.Children[0].Children[0].Cycle {
+		t.Errorf("expected 60 -> 70 -> 60 (cycle), got %+v", deadlock)
+	}
+	if unknown := roots[2]; unknown.State != "unknown" || unknown.Children[0].PID != 40 {
+		t.Errorf("expected the unlisted blocker 99 above 40, got %+v", unknown)
+	}
+}
+
+func TestResolveLocks(t *testing.T) {
+	g := NewGraph()
+	g.AddNode("public", "orders", Table, "", 0)
+	g.AddIndexNode("public", "orders", "orders_pkey", "", IndexInfo{Columns: []string{"id"}})
+
+	sessions := []LockSession{{PID: 1, Locks: []RelationLock{
+		{Relation: "public.orders"}, {Relation: "public.orders_pkey"}, {Relation: "public.gone"},
+	}}}
+	g.ResolveLocks(sessions)
+
+	locks := sessions[0].Locks
+	if locks[0].Type != Table || locks[1].Type != Index || locks[1].Table != "public.orders" || locks[2].Type != "" {
+		t.Errorf("unexpected resolution: %+v", locks)
+	}
+}
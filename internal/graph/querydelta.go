@@ -0,0 +1,108 @@
+package graph
+
+import (
+	"sort"
+	"time"
+)
+
+// QueryDelta is a query's activity within one sampling interval: Calls, TotalTime, AvgTime and
+// LoadPercent cover the interval only
+type QueryDelta struct {
+	QueryStats
+	CallsPerSec float64 `json:"calls_per_sec,omitempty"`
+	TimePerSec  float64 `json:"ms_per_sec,omitempty"` // Milliseconds of execution per second of wall time
+	New         bool    `json:"new,omitempty"`        // Absent from the previous sample: counted from zero
+	Reset       bool    `json:"reset,omitempty"`      // Counters went backwards (reset, or evicted and re-added): counted from zero
+}
+
+// QuerySampler turns cumulative query statistics (pg_stat_statements totals spanning weeks) into the
+// activity between two samples. It keeps the previous sample keyed by QueryID.
+type QuerySampler struct {
+	prev     map[string]QueryStats
+	prevAt   time.Time
+	complete bool
+}
+
+// Sample records the statistics taken at a time and returns the queries active since the previous
+// sample, sorted by "total", "calls" or "avg_time" as GetTopQueries does, with the interval length.
+// The first sample only sets the baseline and returns nil.
+//
+// complete reports whether queries holds every statement rather than the top of a ranking. A query
+// missing from a complete previous sample is new and counted from zero; missing from a truncated
+// one, its earlier activity is unknown, so it is only counted from the next sample on. A query whose
+// counters went backwards was reset (pg_stat_statements_reset) or evicted and re-added, and is counted
+// from zero too. Queries gone from the statistics are dropped.
+func (s *QuerySampler) Sample(queries []QueryStats, at time.Time, complete bool, sortBy string) ([]QueryDelta, time.Duration) {
+	// pg_stat_statements keeps one entry per user: merge entries sharing a QueryID
+	cur := make(map[string]QueryStats, len(queries))
+	var order []string
+	for _, q := range queries {
+		if existing, ok := cur[q.QueryID]; ok {
+			existing.Calls += q.Calls
+			existing.TotalTime += q.TotalTime
+			cur[q.QueryID] = existing
+			continue
+		}
+		cur[q.QueryID] = q
+		order = append(order, q.QueryID)
+	}
+
+	prev, prevAt, prevComplete := s.prev, s.prevAt, s.complete
+	s.prev, s.prevAt, s.complete = cur, at, complete
+	if prev == nil {
+		return nil, 0
+	}
+	elapsed := at.Sub(prevAt)
+	seconds := elapsed.Seconds()
+	if seconds <= 0 {
+		return nil, elapsed
+	}
+
+	var deltas []QueryDelta
+	var totalTime float64
+	for _, id := range order {
+		q := cur[id]
+		d := QueryDelta{QueryStats: q}
+		p, seen := prev[id]
+		switch {
+		case !seen && !prevComplete:
+			continue
+		case !seen:
+			d.New = true
+		case q.Calls < p.Calls || q.TotalTime < p.TotalTime:
+			d.Reset = true
+		default:
+			d.Calls -= p.Calls
+			d.TotalTime -= p.TotalTime
+		}
+		if d.Calls == 0 {
+			continue
+		}
+		d.AvgTime = d.TotalTime / float64(d.Calls)
+		d.CallsPerSec = float64(d.Calls) / seconds
+		d.TimePerSec = d.TotalTime / seconds
+		totalTime += d.TotalTime
+		deltas = append(deltas, d)
+	}
+
+	for i := range deltas {
+		deltas[i].LoadPercent = 0
+		if totalTime > 0 {
+			deltas[i].LoadPercent = deltas[i].TotalTime * 100 / totalTime
+		}
+	}
+	sort.SliceStable(deltas, func(i, j int) bool { return QueryLess(deltas[i].QueryStats, deltas[j].QueryStats, sortBy) })
+	return deltas, elapsed
+}
+
+// QueryLess orders queries by "total" time, "calls" or "avg_time", highest first
+func QueryLess(a, b QueryStats, sortBy string) bool {
+	switch sortBy {
+	case "calls":
+		return a.Calls > b.Calls
+	case "avg_time":
+		return a.AvgTime > b.AvgTime
+	default:
+		return a.TotalTime > b.TotalTime
+	}
+}
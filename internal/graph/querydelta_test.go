@@ -0,0 +1,57 @@
+package graph
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuerySampler(t *testing.T) {
+	var s QuerySampler
+	t0 := time.Unix(1000, 0)
+
+	first := []QueryStats{
+		{QueryID: "a", Calls: 100, TotalTime: 1000},
+		{QueryID: "b", Calls: 50, TotalTime: 5000},
+		{QueryID: "c", Calls: 10, TotalTime: 10},
+	}
+	if deltas, _ := s.Sample(first, t0, true, "total"); deltas != nil {
+		t.Fatalf("expected no deltas from the baseline, got %+v", deltas)
+	}
+
+	second := []QueryStats{
+		{QueryID: "a", Calls: 120, TotalTime: 1100}, // 20 calls, 100 ms
+		{QueryID: "b", Calls: 50, TotalTime: 5000},  // Idle
+		{QueryID: "c", Calls: 4, TotalTime: 40},     // Reset: counted from zero
+		{QueryID: "d", Calls: 2, TotalTime: 60},     // New
+		{QueryID: "d", Calls: 1, TotalTime: 40},     // Same statement, another user
+	}
+	deltas, elapsed := s.Sample(second, t0.Add(10*time.Second), false, "total")
+	if elapsed != 10*time.Second {
+		t.Errorf("elapsed = %v, want 10s", elapsed)
+	}
+	if len(deltas) != 3 {
+		t.Fatalf("expected 3 active queries, got %+v", deltas)
+	}
+	a, d, c := deltas[0], deltas[1], deltas[2]
+	if a.QueryID != "a" || a.Calls != 20 || a.TotalTime != 100 || a.AvgTime != 5 || a.CallsPerSec != 2 || a.TimePerSec != 10 {
+		t.Errorf("unexpected delta for a: %+v", a)
+	}
+	if d.QueryID != "d" || !d.New || d.Calls != 3 || d.TotalTime != 100 {
+		t.Errorf("expected d new with its merged totals, got %+v", d)
+	}
+	if c.QueryID != "c" || !c.Reset || c.Calls != 4 {
+		t.Errorf("expected c reset, got %+v", c)
+	}
+	if a.LoadPercent != 100.0*100/240 || c.LoadPercent != 100.0*40/240 {
+		t.Errorf("expected the load shares of the interval, got a %.1f%%, c %.1f%%", a.LoadPercent, c.LoadPercent)
+	}
+
+	// The previous sample was truncated: e may predate it, so it only counts from the next sample
+	third := append(second[:4:4], QueryStats{QueryID: "e", Calls: 1000, TotalTime: 1e6})
+	deltas, _ = s.Sample(third, t0.Add(20*time.Second), true, "calls")
+	for _, q := range deltas {
+		if q.QueryID == "e" {
+			t.Errorf("expected e to be skipped after a truncated sample, got %+v", q)
+		}
+	}
+}
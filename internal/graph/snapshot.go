@@ -0,0 +1,83 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// SnapshotFormatVersion is bumped whenever the snapshot JSON layout changes incompatibly
+const SnapshotFormatVersion = 1
+
+// Snapshot is a captured graph that can be reloaded without a database connection
+type Snapshot struct {
+	FormatVersion int       `json:"format_version"`
+	CapturedAt    time.Time `json:"captured_at"`
+	ServerVersion string    `json:"server_version,omitempty"`
+	Source        string    `json:"source,omitempty"` // Database type, e.g. "postgres"
+	Graph         *Graph    `json:"graph"`
+}
+
+// NewSnapshot wraps a graph with capture metadata
+func NewSnapshot(g *Graph, source, serverVersion string) *Snapshot {
+	return &Snapshot{
+		FormatVersion: SnapshotFormatVersion,
+		CapturedAt:    time.Now().UTC(),
+		ServerVersion: serverVersion,
+		Source:        source,
+		Graph:         g,
+	}
+}
+
+// WriteSnapshot encodes the snapshot as indented JSON
+func WriteSnapshot(w io.Writer, s *Snapshot) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// ReadSnapshot decodes and validates a snapshot
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	var s Snapshot
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return nil, fmt.Errorf("invalid snapshot: %w", err)
+	}
+	if s.FormatVersion == 0 || s.FormatVersion > SnapshotFormatVersion {
+		return nil, fmt.Errorf("unsupported snapshot format version %d (this build reads up to %d)", s.FormatVersion, SnapshotFormatVersion)
+	}
+	if s.Graph == nil {
+		return nil, fmt.Errorf("invalid snapshot: missing graph")
+	}
+	if s.Graph.Nodes == nil {
+		s.Graph.Nodes = make(map[string]*Node)
+	}
+	if s.Graph.Edges == nil {
+		s.Graph.Edges = make(map[string][]*Edge)
+	}
+	return &s, nil
+}
+
+// SaveSnapshotFile writes the snapshot to path
+func SaveSnapshotFile(path string, s *Snapshot) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteSnapshot(f, s); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadSnapshotFile reads a snapshot from path
+func LoadSnapshotFile(path string) (*Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadSnapshot(f)
+}
@@ -0,0 +1,40 @@
+package graph
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	g := NewGraph()
+	g.AddNode("public", "users", Table, "16 kB", 120)
+	g.AddNode("public", "orders", Table, "48 kB", 900)
+	g.AddIndex("public", "orders", []string{"user_id", "created_at"})
+	g.AddEdge("public", "orders", "public", "users", ForeignKey, "fk_user", "CASCADE")
+	g.Edges["public.orders"][0].MetaData = map[string]string{"fk_columns": "user_id"}
+
+	var buf bytes.Buffer
+	if err := WriteSnapshot(&buf, NewSnapshot(g, "postgres", "16.2")); err != nil {
+		t.Fatalf("WriteSnapshot: %v", err)
+	}
+
+	snap, err := ReadSnapshot(&buf)
+	if err != nil {
+		t.Fatalf("ReadSnapshot: %v", err)
+	}
+	if snap.ServerVersion != "16.2" || snap.Source != "postgres" || snap.CapturedAt.IsZero() {
+		t.Errorf("unexpected snapshot metadata: %+v", snap)
+	}
+	if !reflect.DeepEqual(snap.Graph, g) {
+		t.Errorf("graph changed in round trip:\n got %+v\nwant %+v", snap.Graph, g)
+	}
+}
+
+func TestReadSnapshotRejectsNewerFormat(t *testing.T) {
+	_, err := ReadSnapshot(strings.NewReader(`{"format_version": 99, "graph": {"nodes": {}, "edges": {}}}`))
+	if err == nil || !strings.Contains(err.Error(), "unsupported snapshot format version 99") {
+		t.Errorf("expected format version error, got %v", err)
+	}
+}
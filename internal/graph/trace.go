@@ -2,13 +2,13 @@ package graph
 
 // TraceResult holds the parsed performance data from an EXPLAIN ANALYZE
 type TraceResult struct {
-	PlanningTime  float64
-	ExecutionTime float64
-	TotalTime     float64
-	CacheHits     int64
-	DiskReads     int64
-	MemoryUsage   int64 // in bytes (approximated from shared/temp buffers)
-	Root          *ExplainNode
+	PlanningTime  float64      `json:"planning_time_ms"`
+	ExecutionTime float64      `json:"execution_time_ms"`
+	TotalTime     float64      `json:"total_time_ms"`
+	CacheHits     int64        `json:"cache_hits"`
+	DiskReads     int64        `json:"disk_reads"`
+	MemoryUsage   int64        `json:"memory_usage"` // in bytes (approximated from shared/temp buffers)
+	Root          *ExplainNode `json:"plan"`         // Keeps the EXPLAIN (FORMAT JSON) field names
 }
 
 // ExplainNode represents a node in the Postgres execution plan tree
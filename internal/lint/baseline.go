@@ -0,0 +1,62 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// BaselineFormatVersion is bumped when the baseline file layout changes incompatibly
+const BaselineFormatVersion = 1
+
+// Baseline is the set of accepted findings; check only fails on findings not listed here
+type Baseline struct {
+	FormatVersion int       `json:"format_version"`
+	Findings      []Finding `json:"findings"`
+
+	index map[string]bool
+}
+
+// NewBaseline accepts the given findings
+func NewBaseline(findings []Finding) *Baseline {
+	return &Baseline{FormatVersion: BaselineFormatVersion, Findings: findings}
+}
+
+// Contains reports whether a finding was accepted
+func (b *Baseline) Contains(f Finding) bool {
+	if b == nil {
+		return false
+	}
+	if b.index == nil {
+		b.index = make(map[string]bool)
+		for _, accepted := range b.Findings {
+			b.index[accepted.Fingerprint()] = true
+		}
+	}
+	return b.index[f.Fingerprint()]
+}
+
+// LoadBaseline reads a baseline file written by SaveBaseline
+func LoadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("invalid baseline %s: %w", path, err)
+	}
+	if b.FormatVersion > BaselineFormatVersion {
+		return nil, fmt.Errorf("baseline %s has format version %d, this dbgraph supports up to %d", path, b.FormatVersion, BaselineFormatVersion)
+	}
+	return &b, nil
+}
+
+// SaveBaseline writes the baseline as indented JSON so it diffs well in review
+func SaveBaseline(path string, b *Baseline) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
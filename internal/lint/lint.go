@@ -0,0 +1,117 @@
+package lint
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/alexanderritik/dbgraph/internal/graph"
+)
+
+// Severity ranks findings; check fails only on new findings at or above a threshold
+type Severity string
+
+const (
+	Info    Severity = "info"
+	Warning Severity = "warning"
+	Error   Severity = "error"
+)
+
+// rank orders severities from least to most severe
+func (s Severity) rank() int {
+	switch s {
+	case Error:
+		return 2
+	case Warning:
+		return 1
+	}
+	return 0
+}
+
+// AtLeast reports whether s is as severe as min
+func (s Severity) AtLeast(min Severity) bool {
+	return s.rank() >= min.rank()
+}
+
+// ParseSeverity validates a severity name
+func ParseSeverity(s string) (Severity, error) {
+	switch Severity(s) {
+	case Info, Warning, Error:
+		return Severity(s), nil
+	}
+	return "", fmt.Errorf("unknown severity '%s' (expected info, warning or error)", s)
+}
+
+// Finding is one problem reported by a rule
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Object   string   `json:"object"`         // Stable identity of what the finding is about, e.g. "public.orders"
+	Node     string   `json:"node,omitempty"` // Graph node the finding belongs to, matched by ignores
+	Message  string   `json:"message"`
+}
+
+// Fingerprint identifies a finding across runs; the message may change, the fingerprint does not
+func (f Finding) Fingerprint() string {
+	return f.Rule + ":" + f.Object
+}
+
+// Params are the numeric thresholds of a rule, keyed by name (e.g. "threshold")
+type Params map[string]float64
+
+// Int returns a parameter truncated to an integer
+func (p Params) Int(name string) int {
+	return int(p[name])
+}
+
+// Rule is a named graph check
+type Rule struct {
+	ID          string
+	Description string
+	Severity    Severity
+	Params      Params                                   // Defaults; .dbgraph.yaml may override them
+	Check       func(g *graph.Graph, p Params) []Finding // Findings without Rule/Severity; Run fills them in
+}
+
+var registry []Rule
+
+// Register adds a rule to the set returned by DefaultRules
+func Register(r Rule) {
+	for _, existing := range registry {
+		if existing.ID == r.ID {
+			panic(fmt.Sprintf("lint: rule %s registered twice", r.ID))
+		}
+	}
+	registry = append(registry, r)
+}
+
+// DefaultRules returns a copy of every registered rule with its default severity and params
+func DefaultRules() []Rule {
+	rules := make([]Rule, len(registry))
+	for i, r := range registry {
+		rules[i] = r
+		rules[i].Params = make(Params, len(r.Params))
+		for k, v := range r.Params {
+			rules[i].Params[k] = v
+		}
+	}
+	return rules
+}
+
+// Run applies the rules to the graph and returns the findings sorted by rule and object
+func Run(g *graph.Graph, rules []Rule) []Finding {
+	findings := []Finding{}
+	for _, r := range rules {
+		for _, f := range r.Check(g, r.Params) {
+			f.Rule = r.ID
+			f.Severity = r.Severity
+			findings = append(findings, f)
+		}
+	}
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Rule != findings[j].Rule {
+			return findings[i].Rule < findings[j].Rule
+		}
+		return findings[i].Object < findings[j].Object
+	})
+	return findings
+}
@@ -0,0 +1,129 @@
+package lint
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/alexanderritik/dbgraph/internal/graph"
+)
+
+func testGraph() *graph.Graph {
+	g := graph.NewGraph()
+	g.AddNode("public", "users", graph.Table, "", 0)
+	g.AddNode("public", "orders", graph.Table, "", 0)
+	g.AddNode("public", "audit", graph.Table, "", 0)
+	g.AddEdge("public", "orders", "public", "users", graph.ForeignKey, "fk_user", "CASCADE")
+	edges := g.Edges["public.orders"]
+	edges[0].MetaData = map[string]string{"fk_columns": "user_id"}
+	// Self reference makes a cycle; it is indexed so it is not an unindexed FK
+	g.AddEdge("public", "users", "public", "users", graph.ForeignKey, "fk_manager", "NO ACTION")
+	g.Edges["public.users"][0].MetaData = map[string]string{"fk_columns": "manager_id"}
+	g.AddIndex("public", "users", []string{"manager_id"})
+	return g
+}
+
+func TestRun(t *testing.T) {
+	findings := Run(testGraph(), DefaultRules())
+
+	want := []struct {
+		rule   string
+		object string
+	}{
+		{"cascade-delete", "public.orders.fk_user"},
+		{"cycle", "public.users"},
+		{"orphan-table", "public.audit"},
+		{"unindexed-fk", "public.orders (user_id) -> public.users"},
+	}
+	if len(findings) != len(want) {
+		t.Fatalf("got %d findings, want %d: %+v", len(findings), len(want), findings)
+	}
+	for i, w := range want {
+		if findings[i].Rule != w.rule || findings[i].Object != w.object {
+			t.Errorf("finding %d = %s %s, want %s %s", i, findings[i].Rule, findings[i].Object, w.rule, w.object)
+		}
+	}
+	if findings[1].Severity != Error {
+		t.Errorf("cycle severity = %s, want error", findings[1].Severity)
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	if !Error.AtLeast(Warning) || Info.AtLeast(Warning) || !Warning.AtLeast(Warning) {
+		t.Error("severity ordering is wrong")
+	}
+	if _, err := ParseSeverity("fatal"); err == nil {
+		t.Error("ParseSeverity should reject unknown severities")
+	}
+}
+
+func TestBaseline(t *testing.T) {
+	findings := Run(testGraph(), DefaultRules())
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	if err := SaveBaseline(path, NewBaseline(findings[:2])); err != nil {
+		t.Fatal(err)
+	}
+	b, err := LoadBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Messages may change between versions; only the fingerprint matters
+	accepted := findings[0]
+	accepted.Message = "reworded"
+	if !b.Contains(accepted) || !b.Contains(findings[1]) {
+		t.Error("baselined findings should be accepted")
+	}
+	if b.Contains(findings[2]) {
+		t.Error("new finding should not be accepted")
+	}
+
+	var none *Baseline
+	if none.Contains(findings[0]) {
+		t.Error("a nil baseline accepts nothing")
+	}
+}
+
+func TestSchemaRules(t *testing.T) {
+	g := testGraph()
+	g.SetPrimaryKey("public", "users", []string{"id"})
+	g.Edges["public.orders"][0].MetaData["nullable_columns"] = "user_id"
+	// A detached pair next to the users/orders component is an island
+	g.AddNode("public", "tags", graph.Table, "", 0)
+	g.AddNode("public", "tag_aliases", graph.Table, "", 0)
+	g.AddNode("public", "invoices", graph.Table, "", 0)
+	g.AddEdge("public", "tag_aliases", "public", "tags", graph.ForeignKey, "fk_tag", "NO ACTION")
+	g.AddEdge("public", "invoices", "public", "orders", graph.ForeignKey, "fk_order", "NO ACTION")
+
+	rules := map[string]Rule{}
+	for _, r := range DefaultRules() {
+		rules[r.ID] = r
+	}
+	objects := func(id string, p Params) []string {
+		var out []string
+		for _, f := range Run(g, []Rule{{ID: id, Params: p, Check: rules[id].Check}}) {
+			out = append(out, f.Object)
+		}
+		return out
+	}
+
+	if got := objects("missing-pk", nil); len(got) != 5 {
+		t.Errorf("missing-pk = %v, want every table but users", got)
+	}
+	if got := objects("nullable-fk", nil); len(got) != 1 || got[0] != "public.orders.fk_user" {
+		t.Errorf("nullable-fk = %v", got)
+	}
+	if got := objects("island", rules["island"].Params); len(got) != 1 || got[0] != "public.tag_aliases,public.tags" {
+		t.Errorf("island = %v", got)
+	}
+	if got := objects("god-object", Params{"threshold": 3}); len(got) != 1 || got[0] != "public.users" {
+		t.Errorf("god-object with threshold 3 = %v, want users", got)
+	}
+	if got := objects("god-object", rules["god-object"].Params); len(got) != 0 {
+		t.Errorf("god-object with the default threshold = %v, want none", got)
+	}
+
+	// Without any primary key information the rule cannot tell and stays quiet
+	if got := Run(testGraph(), []Rule{rules["missing-pk"]}); len(got) != 0 {
+		t.Errorf("missing-pk without PK info = %v", got)
+	}
+}
@@ -0,0 +1,193 @@
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alexanderritik/dbgraph/internal/graph"
+)
+
+func init() {
+	Register(Rule{
+		ID:          "cycle",
+		Description: "Circular dependency between tables or views",
+		Severity:    Error,
+		Check:       checkCycles,
+	})
+	Register(Rule{
+		ID:          "unindexed-fk",
+		Description: "Foreign key columns without a supporting index",
+		Severity:    Warning,
+		Check:       checkUnindexedFKs,
+	})
+	Register(Rule{
+		ID:          "god-object",
+		Description: "Object coupled to too many others",
+		Severity:    Warning,
+		Params:      Params{"threshold": graph.DefaultGodObjectThreshold},
+		Check:       checkGodObjects,
+	})
+	Register(Rule{
+		ID:          "cascade-delete",
+		Description: "Foreign key with ON DELETE CASCADE",
+		Severity:    Info,
+		Check:       checkCascades,
+	})
+	Register(Rule{
+		ID:          "orphan-table",
+		Description: "Table with no relationships to any other object",
+		Severity:    Info,
+		Check:       checkOrphans,
+	})
+	Register(Rule{
+		ID:          "missing-pk",
+		Description: "Table without a primary key",
+		Severity:    Warning,
+		Check:       checkMissingPKs,
+	})
+	Register(Rule{
+		ID:          "nullable-fk",
+		Description: "Foreign key whose columns accept NULL",
+		Severity:    Info,
+		Check:       checkNullableFKs,
+	})
+	Register(Rule{
+		ID:          "island",
+		Description: "Small cluster of objects disconnected from the rest of the schema",
+		Severity:    Info,
+		Params:      Params{"max_size": graph.DefaultIslandMaxSize},
+		Check:       checkIslands,
+	})
+}
+
+func checkCycles(g *graph.Graph, _ Params) []Finding {
+	var findings []Finding
+	for _, cycle := range g.CheckCycles() {
+		members := append([]string(nil), cycle...)
+		sort.Strings(members)
+		findings = append(findings, Finding{
+			Object:  strings.Join(members, ","),
+			Node:    members[0],
+			Message: fmt.Sprintf("Circular dependency: %s", strings.Join(members, " ↔ ")),
+		})
+	}
+	return findings
+}
+
+func checkUnindexedFKs(g *graph.Graph, _ Params) []Finding {
+	var findings []Finding
+	for _, miss := range g.CheckIndexCoverage().MissingFKIndexes {
+		findings = append(findings, Finding{
+			Object:  miss,
+			Node:    strings.SplitN(miss, " ", 2)[0],
+			Message: fmt.Sprintf("FK %s is not indexed; deletes on the referenced table scan it", miss),
+		})
+	}
+	return findings
+}
+
+func checkGodObjects(g *graph.Graph, p Params) []Finding {
+	var findings []Finding
+	for _, god := range g.FindGodObjects(p.Int("threshold")) {
+		findings = append(findings, Finding{
+			Object:  god.ID,
+			Node:    god.ID,
+			Message: fmt.Sprintf("%s is connected to %d others (%d in, %d out)", god.ID, god.Degree, god.Dependents, god.Dependencies),
+		})
+	}
+	return findings
+}
+
+func checkCascades(g *graph.Graph, _ Params) []Finding {
+	var findings []Finding
+	for _, edges := range g.Edges {
+		for _, e := range edges {
+			if e.Type == graph.ForeignKey && e.DeleteRule == "CASCADE" {
+				findings = append(findings, Finding{
+					Object:  fmt.Sprintf("%s.%s", e.SourceID, e.ConstraintName),
+					Node:    e.SourceID,
+					Message: fmt.Sprintf("Deleting from %s cascades into %s [FK: %s]", e.TargetID, e.SourceID, e.ConstraintName),
+				})
+			}
+		}
+	}
+	return findings
+}
+
+func checkOrphans(g *graph.Graph, _ Params) []Finding {
+	connected := make(map[string]bool)
+	for src, edges := range g.Edges {
+		for _, e := range edges {
+			// A table is not attached to anything by the sequence of its serial column
+			if g.Satellite(src) || g.Satellite(e.TargetID) {
+				continue
+			}
+			connected[src] = true
+			connected[e.TargetID] = true
+		}
+	}
+	var findings []Finding
+	for id, n := range g.Nodes {
+		if n.Type == graph.Table && !connected[id] {
+			findings = append(findings, Finding{
+				Object:  id,
+				Node:    id,
+				Message: fmt.Sprintf("%s has no foreign keys, views or triggers attached", id),
+			})
+		}
+	}
+	return findings
+}
+
+func checkMissingPKs(g *graph.Graph, _ Params) []Finding {
+	// Snapshots taken before primary keys were recorded have none at all; stay quiet for them
+	known := false
+	for _, n := range g.Nodes {
+		known = known || len(n.PrimaryKey) > 0
+	}
+	if !known {
+		return nil
+	}
+
+	var findings []Finding
+	for id, n := range g.Nodes {
+		if n.Type == graph.Table && len(n.PrimaryKey) == 0 {
+			findings = append(findings, Finding{
+				Object:  id,
+				Node:    id,
+				Message: fmt.Sprintf("%s has no primary key; rows cannot be addressed reliably by replication or ORMs", id),
+			})
+		}
+	}
+	return findings
+}
+
+func checkNullableFKs(g *graph.Graph, _ Params) []Finding {
+	var findings []Finding
+	for _, edges := range g.Edges {
+		for _, e := range edges {
+			if e.Type != graph.ForeignKey || e.MetaData["nullable_columns"] == "" {
+				continue
+			}
+			findings = append(findings, Finding{
+				Object:  fmt.Sprintf("%s.%s", e.SourceID, e.ConstraintName),
+				Node:    e.SourceID,
+				Message: fmt.Sprintf("FK %s on %s allows NULL in (%s); rows may reference no %s", e.ConstraintName, e.SourceID, e.MetaData["nullable_columns"], e.TargetID),
+			})
+		}
+	}
+	return findings
+}
+
+func checkIslands(g *graph.Graph, p Params) []Finding {
+	var findings []Finding
+	for _, island := range g.Islands(p.Int("max_size")) {
+		findings = append(findings, Finding{
+			Object:  strings.Join(island, ","),
+			Node:    island[0],
+			Message: fmt.Sprintf("%d objects are disconnected from the rest of the schema: %s", len(island), strings.Join(island, ", ")),
+		})
+	}
+	return findings
+}
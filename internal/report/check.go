@@ -0,0 +1,179 @@
+package report
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/alexanderritik/dbgraph/internal/lint"
+)
+
+// CheckFinding is a finding with its baseline status
+type CheckFinding struct {
+	lint.Finding
+	New bool `json:"new"` // Not accepted in the baseline
+}
+
+// Check is the result of 'check'
+type Check struct {
+	Database string         `json:"database"`
+	FailOn   lint.Severity  `json:"fail_on"`
+	Findings []CheckFinding `json:"findings"`
+	New      int            `json:"new"`
+	Baseline int            `json:"baselined"`
+	Failed   bool           `json:"failed"` // A new finding is at or above FailOn
+}
+
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Type    string `xml:"type,attr"`
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// WriteJUnit writes one test suite per rule: a failing test case per new finding at or above
+// FailOn, a skipped one per baselined or lower-severity finding, and a passing case for clean rules
+func WriteJUnit(w io.Writer, c *Check, rules []lint.Rule) error {
+	suites := junitSuites{}
+	for _, r := range rules {
+		suite := junitSuite{Name: "dbgraph." + r.ID}
+		for _, f := range c.Findings {
+			if f.Rule != r.ID {
+				continue
+			}
+			tc := junitCase{ClassName: suite.Name, Name: f.Object}
+			switch {
+			case !f.New:
+				tc.Skipped = &junitSkipped{Message: "accepted in baseline"}
+				suite.Skipped++
+			case f.Severity.AtLeast(c.FailOn):
+				tc.Failure = &junitFailure{Type: string(f.Severity), Message: f.Message, Text: r.Description}
+				suite.Failures++
+			default:
+				tc.Skipped = &junitSkipped{Message: fmt.Sprintf("%s below fail-on %s: %s", f.Severity, c.FailOn, f.Message)}
+				suite.Skipped++
+			}
+			suite.Cases = append(suite.Cases, tc)
+		}
+		if len(suite.Cases) == 0 {
+			suite.Cases = append(suite.Cases, junitCase{ClassName: suite.Name, Name: "no findings"})
+		}
+		suite.Tests = len(suite.Cases)
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// sarifLevel maps a severity to the SARIF result level
+func sarifLevel(s lint.Severity) string {
+	switch s {
+	case lint.Error:
+		return "error"
+	case lint.Warning:
+		return "warning"
+	}
+	return "note"
+}
+
+// WriteSARIF writes a SARIF 2.1.0 log. Findings are located by the database object they are
+// about (logical locations) and carry their fingerprint so review tools can track them.
+func WriteSARIF(w io.Writer, c *Check, rules []lint.Rule, version string) error {
+	type message struct {
+		Text string `json:"text"`
+	}
+	type rule struct {
+		ID                   string            `json:"id"`
+		ShortDescription     message           `json:"shortDescription"`
+		DefaultConfiguration map[string]string `json:"defaultConfiguration"`
+	}
+	type logicalLocation struct {
+		FullyQualifiedName string `json:"fullyQualifiedName"`
+	}
+	type location struct {
+		LogicalLocations []logicalLocation `json:"logicalLocations"`
+	}
+	type result struct {
+		RuleID              string            `json:"ruleId"`
+		Level               string            `json:"level"`
+		Message             message           `json:"message"`
+		Locations           []location        `json:"locations"`
+		PartialFingerprints map[string]string `json:"partialFingerprints"`
+		BaselineState       string            `json:"baselineState"`
+	}
+
+	driverRules := []rule{}
+	for _, r := range rules {
+		driverRules = append(driverRules, rule{
+			ID:                   r.ID,
+			ShortDescription:     message{Text: r.Description},
+			DefaultConfiguration: map[string]string{"level": sarifLevel(r.Severity)},
+		})
+	}
+
+	results := []result{}
+	for _, f := range c.Findings {
+		state := "new"
+		if !f.New {
+			state = "unchanged"
+		}
+		results = append(results, result{
+			RuleID:              f.Rule,
+			Level:               sarifLevel(f.Severity),
+			Message:             message{Text: f.Message},
+			Locations:           []location{{LogicalLocations: []logicalLocation{{FullyQualifiedName: f.Object}}}},
+			PartialFingerprints: map[string]string{"dbgraph/v1": f.Fingerprint()},
+			BaselineState:       state,
+		})
+	}
+
+	log := map[string]any{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": []any{map[string]any{
+			"tool": map[string]any{"driver": map[string]any{
+				"name":           "dbgraph",
+				"version":        version,
+				"informationUri": "https://github.com/alexanderritik/dbgraph",
+				"rules":          driverRules,
+			}},
+			"results": results,
+		}},
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log)
+}
@@ -0,0 +1,65 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/alexanderritik/dbgraph/internal/lint"
+)
+
+func testCheck() *Check {
+	return &Check{
+		FailOn: lint.Warning,
+		Findings: []CheckFinding{
+			{Finding: lint.Finding{Rule: "cycle", Severity: lint.Error, Object: "public.a,public.b", Message: "cycle"}, New: true},
+			{Finding: lint.Finding{Rule: "unindexed-fk", Severity: lint.Warning, Object: "public.orders (user_id) -> public.users", Message: "fk"}},
+		},
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, testCheck(), lint.DefaultRules()); err != nil {
+		t.Fatal(err)
+	}
+	var suites junitSuites
+	if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, buf.String())
+	}
+	if len(suites.Suites) != len(lint.DefaultRules()) {
+		t.Fatalf("got %d suites, want one per rule", len(suites.Suites))
+	}
+	cycle, fk := suites.Suites[0], suites.Suites[1]
+	if cycle.Failures != 1 || fk.Failures != 0 || fk.Skipped != 1 {
+		t.Errorf("cycle failures=%d, fk failures=%d skipped=%d; want 1, 0, 1", cycle.Failures, fk.Failures, fk.Skipped)
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSARIF(&buf, testCheck(), lint.DefaultRules(), "dev"); err != nil {
+		t.Fatal(err)
+	}
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Results []struct {
+				RuleID        string `json:"ruleId"`
+				Level         string `json:"level"`
+				BaselineState string `json:"baselineState"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 || len(log.Runs[0].Results) != 2 {
+		t.Fatalf("unexpected SARIF: %s", buf.String())
+	}
+	r := log.Runs[0].Results
+	if r[0].Level != "error" || r[0].BaselineState != "new" || r[1].BaselineState != "unchanged" {
+		t.Errorf("unexpected results: %+v", r)
+	}
+}
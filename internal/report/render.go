@@ -0,0 +1,99 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format is an output format selected with --output
+type Format string
+
+const (
+	Text Format = "text"
+	JSON Format = "json"
+	YAML Format = "yaml"
+)
+
+// ParseFormat validates a --output value
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case Text, JSON, YAML:
+		return Format(s), nil
+	}
+	return "", fmt.Errorf("unknown output format '%s' (expected text, json or yaml)", s)
+}
+
+// Renderer writes a command result to w in one output format
+type Renderer interface {
+	Render(w io.Writer, result any) error
+}
+
+// TextRenderer adapts a command's human-readable printer to Renderer
+type TextRenderer func(w io.Writer, result any) error
+
+// Render calls the printer
+func (f TextRenderer) Render(w io.Writer, result any) error {
+	return f(w, result)
+}
+
+// JSONRenderer writes indented JSON using the results' json tags
+type JSONRenderer struct{}
+
+// Render encodes result as JSON
+func (JSONRenderer) Render(w io.Writer, result any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(result)
+}
+
+// YAMLRenderer writes YAML with the same field names as the JSON output.
+// Every document starts with "---" so repeated results (top --watch) form a stream.
+type YAMLRenderer struct{}
+
+// Render encodes result as YAML by way of its JSON form, so one set of tags drives both formats
+func (YAMLRenderer) Render(w io.Writer, result any) error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(result); err != nil {
+		return err
+	}
+
+	// JSON is valid YAML: decoding into a node keeps the field order
+	var doc yaml.Node
+	if err := yaml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		return err
+	}
+	blockStyle(&doc)
+
+	if _, err := io.WriteString(w, "---\n"); err != nil {
+		return err
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// blockStyle clears the flow/quoted styles inherited from the JSON source
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+}
+
+// New returns the renderer for a format; text output uses the command's own printer
+func New(format Format, text TextRenderer) Renderer {
+	switch format {
+	case JSON:
+		return JSONRenderer{}
+	case YAML:
+		return YAMLRenderer{}
+	}
+	return text
+}
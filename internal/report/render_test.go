@@ -0,0 +1,54 @@
+package report
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestParseFormat(t *testing.T) {
+	for _, s := range []string{"text", "json", "yaml"} {
+		if _, err := ParseFormat(s); err != nil {
+			t.Errorf("ParseFormat(%q) unexpected error: %v", s, err)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("ParseFormat(\"xml\") should fail")
+	}
+}
+
+func TestRenderers(t *testing.T) {
+	result := &Summary{
+		Objects: []SummaryRow{{Risk: "LOW"}},
+		Total:   1,
+	}
+	result.Objects[0].ID = "public.users"
+
+	var buf bytes.Buffer
+	if err := New(JSON, nil).Render(&buf, result); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"id": "public.users"`) || !strings.Contains(buf.String(), `"risk": "LOW"`) {
+		t.Errorf("unexpected JSON:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := New(YAML, nil).Render(&buf, &Trace{Query: "123", HitRate: 50}); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	// Field names follow the json tags and strings that look like numbers stay strings
+	if !strings.HasPrefix(got, "---\n") || !strings.Contains(got, `query: "123"`) || !strings.Contains(got, "cache_hit_rate: 50") {
+		t.Errorf("unexpected YAML:\n%s", got)
+	}
+
+	buf.Reset()
+	text := TextRenderer(func(w io.Writer, v any) error {
+		_, err := io.WriteString(w, v.(*Summary).Objects[0].ID)
+		return err
+	})
+	if err := New(Text, text).Render(&buf, result); err != nil || buf.String() != "public.users" {
+		t.Errorf("text renderer wrote %q (err %v)", buf.String(), err)
+	}
+}
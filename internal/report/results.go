@@ -0,0 +1,286 @@
+package report
+
+import (
+	"github.com/alexanderritik/dbgraph/internal/graph"
+	"github.com/alexanderritik/dbgraph/internal/lint"
+)
+
+// The result structs below are the stable --output json|yaml contract of each command.
+// Fields are only ever added; renaming or removing one is a breaking change.
+
+// Impact is the result of 'impact': everything that depends on the target
+type Impact struct {
+	Database      string                 `json:"database"`
+	Target        string                 `json:"target"`
+	Column        string                 `json:"column,omitempty"` // Set for table.column targets; Target is then schema.table.column
+	RowCount      int64                  `json:"row_count"`
+	Depth         int                    `json:"depth"`          // Levels below the target
+	TotalAffected int                    `json:"total_affected"` // Objects in the tree, excluding the target
+	AffectedTypes map[graph.NodeType]int `json:"affected_types"`
+	Tree          *ImpactNode            `json:"tree"` // Dependents of the target; nil for --direction up
+	Warnings      []Warning              `json:"warnings"`
+	Direction     string                 `json:"direction"`          // "down", "up" or "both"
+	Upstream      *ImpactNode            `json:"upstream,omitempty"` // What the target depends on; set for up and both
+	UpstreamDepth int                    `json:"upstream_depth"`     // Levels above the target
+	TotalUpstream int                    `json:"total_upstream"`     // Objects in the upstream tree, excluding the target
+	Metrics       *graph.DBMetrics       `json:"metrics,omitempty"`  // Omitted when the database has no server stats
+}
+
+// ImpactNode is one object of the impact tree with the edge that links it to its parent
+type ImpactNode struct {
+	ID       string         `json:"id"`
+	Type     graph.NodeType `json:"type"`
+	Size     string         `json:"size,omitempty"`
+	RowCount int64          `json:"row_count"`
+	Level    int            `json:"level"`
+	Edge     *graph.Edge    `json:"edge,omitempty"` // Nil for the root
+	Children []*ImpactNode  `json:"children,omitempty"`
+
+	Dependency *graph.ColumnDependency `json:"dependency,omitempty"` // Column impact only: how the object uses the column
+	Columns    []string                `json:"columns,omitempty"`    // Column impact only: view columns carrying the column
+
+	Index *graph.IndexInfo `json:"index,omitempty"` // Index nodes only: definition, size and scan counts
+}
+
+// Warning is a structural risk found while walking the graph
+type Warning struct {
+	Severity string `json:"severity"` // "High", "Med"
+	Kind     string `json:"kind"`     // e.g. "Cascade Delete", "Missing Index"
+	Message  string `json:"message"`
+}
+
+// Change is the schema change a simulation analyzed
+type Change struct {
+	Kind      string `json:"kind"` // e.g. "DROP COLUMN", "RENAME TABLE"
+	Target    string `json:"target"`
+	Column    string `json:"column,omitempty"`
+	NewName   string `json:"new_name,omitempty"`
+	NewType   string `json:"new_type,omitempty"`
+	Cascade   bool   `json:"cascade,omitempty"`
+	Statement string `json:"statement,omitempty"` // Migration mode only
+	Line      int    `json:"line,omitempty"`
+}
+
+// DependencyVerdict is a dependent object and what the change does to it
+type DependencyVerdict struct {
+	graph.ColumnDependency
+	Impact string `json:"impact"` // "BREAKS", "REWRITE", "AUTO-UPDATED", "DROPPED", "REVIEW"
+	Reason string `json:"reason,omitempty"`
+}
+
+// Simulation is the result of simulating one change
+type Simulation struct {
+	Change            Change              `json:"change"`
+	Label             string              `json:"label"`
+	Dependencies      []DependencyVerdict `json:"dependencies"`
+	Note              string              `json:"note,omitempty"` // Cost of the change itself (rewrites, scans)
+	Breaks            bool                `json:"breaks"`
+	AlreadyDropped    int                 `json:"already_dropped,omitempty"` // Dependents dropped earlier in the migration
+	Error             string              `json:"error,omitempty"`           // Set when the change could not be analyzed
+	DryRun            *graph.DryRunResult `json:"dry_run,omitempty"`
+	DryRunMissed      []string            `json:"dry_run_missed,omitempty"`      // Dropped in the dry run but not predicted
+	DryRunUnconfirmed []string            `json:"dry_run_unconfirmed,omitempty"` // Predicted to break but not dropped
+}
+
+// MigrationSimulation is the result of 'simulate --migration'
+type MigrationSimulation struct {
+	File        string               `json:"file"`
+	Simulations []Simulation         `json:"simulations"`
+	Skipped     int                  `json:"skipped_statements"`
+	DryRun      []graph.DryRunResult `json:"dry_run,omitempty"`
+	NotExecuted []string             `json:"dry_run_not_executed,omitempty"` // Statements the dry run refused, with the reason
+	Unsafe      int                  `json:"unsafe"`                         // Changes that break dependents
+	Unknown     int                  `json:"unknown"`                        // Changes that could not be analyzed
+	Failed      int                  `json:"dry_run_failed"`                 // Statements that failed in the dry run
+	Verdict     string               `json:"verdict"`                        // "UNSAFE", "SAFE WITH GAPS", "SAFE"
+}
+
+// Analyze is the result of 'analyze'
+type Analyze struct {
+	Database      string             `json:"database"`
+	Stats         *graph.GraphStats  `json:"stats"`
+	Tables        int                `json:"tables"`
+	Views         int                `json:"views"`
+	Triggers      int                `json:"triggers"`
+	Functions     int                `json:"functions"`
+	Types         int                `json:"types"` // Enums, composite types and domains
+	Sequences     int                `json:"sequences"`
+	IndexNodes    int                `json:"index_nodes"` // Indexes modeled as graph nodes (PostgreSQL, schema files)
+	ForeignKeys   int                `json:"foreign_key_edges"`
+	ViewEdges     int                `json:"view_edges"`
+	TriggerEdges  int                `json:"trigger_edges"`
+	FunctionEdges int                `json:"function_edges"` // Function or trigger body -> relation it reads or writes
+	CallEdges     int                `json:"call_edges"`     // Trigger or function -> function it executes
+	TypeEdges     int                `json:"type_edges"`     // Column, domain or signature -> type it uses
+	SequenceEdges int                `json:"sequence_edges"` // Column defaults drawing from a sequence, and sequence ownership
+	Cycles        [][]string         `json:"cycles"`
+	Indexes       *graph.IndexIssues `json:"indexes"`
+	GodObjects    []graph.GodMod     `json:"god_objects"`
+	Rules         []string           `json:"rules"` // IDs of the lint rules that ran
+	Findings      []lint.Finding     `json:"findings"`
+}
+
+// Indexes is the result of 'indexes': droppable indexes and the ones that must be kept
+type Indexes struct {
+	Database string `json:"database"`
+	graph.IndexAudit
+}
+
+// Lineage is the result of 'lineage': the columns a column is computed from and the view columns computed from it
+type Lineage struct {
+	Database    string       `json:"database"`
+	Column      string       `json:"column"`                 // schema.relation.column
+	Direction   string       `json:"direction"`              // "up", "down" or "both"
+	Sources     *LineageNode `json:"sources,omitempty"`      // Set for up and both
+	BaseColumns []string     `json:"base_columns,omitempty"` // Leaves of Sources: the table columns the data originates from
+	Consumers   *LineageNode `json:"consumers,omitempty"`    // Set for down and both
+}
+
+// LineageNode is one column of a lineage tree
+type LineageNode struct {
+	ID       string         `json:"id"`   // schema.relation.column
+	Type     graph.NodeType `json:"type"` // Type of the relation holding the column
+	Level    int            `json:"level"`
+	Children []*LineageNode `json:"children,omitempty"`
+}
+
+// Lint is the result of 'lint'
+type Lint struct {
+	Database string         `json:"database"`
+	Rules    []string       `json:"rules"` // IDs of the rules that ran
+	Findings []lint.Finding `json:"findings"`
+	FailOn   lint.Severity  `json:"fail_on"`
+	Failed   bool           `json:"failed"` // A finding is at or above FailOn
+}
+
+// SummaryRow is one ranked object of 'summary'
+type SummaryRow struct {
+	graph.NodeRank
+	Risk string `json:"risk"` // "LOW", "MED", "HIGH", "CRITICAL"
+}
+
+// Summary is the result of 'summary'
+type Summary struct {
+	Objects []SummaryRow `json:"objects"`
+	Total   int          `json:"total"` // Objects before --limit
+}
+
+// TopQuery is a query of 'top' with the schema objects it mentions. In delta mode the statistics
+// cover the sampling interval and the rates are set.
+type TopQuery struct {
+	graph.QueryDelta
+	Rank    int      `json:"rank"`
+	Context []string `json:"context,omitempty"`
+}
+
+// Top is one sample of 'top'
+type Top struct {
+	Interval int        `json:"interval_seconds"`
+	Sort     string     `json:"sort"`
+	Mode     string     `json:"mode"`                      // "cumulative" or "delta"
+	Elapsed  float64    `json:"elapsed_seconds,omitempty"` // Delta mode: time since the previous sample
+	Queries  []TopQuery `json:"queries"`
+}
+
+// Locks is one sample of 'locks': who blocks whom
+type Locks struct {
+	Database  string            `json:"database"`
+	Sessions  int               `json:"sessions"` // Sessions holding or waiting for relation locks
+	Waiting   int               `json:"waiting"`
+	Roots     []*graph.LockNode `json:"roots"`               // Blocker → waiter trees, rooted at the sessions blocking the others
+	Terminate []string          `json:"terminate,omitempty"` // With --terminate: statements ending the root blockers
+}
+
+// Trace is the result of 'trace'
+type Trace struct {
+	Query   string             `json:"query"`
+	HitRate float64            `json:"cache_hit_rate"` // Percent of buffers found in shared buffers
+	Result  *graph.TraceResult `json:"result"`
+}
+
+// IndexSuggestions is the result of 'suggest-indexes': indexes to create for unindexed foreign keys
+type IndexSuggestions struct {
+	Database    string                  `json:"database"`
+	Suggestions []graph.IndexSuggestion `json:"suggestions"`
+	Migration   string                  `json:"migration"` // The suggestions as a ready-to-run SQL script
+}
+
+// Cascade is the result of 'cascade': the rows a DELETE would remove, update or be blocked by
+type Cascade struct {
+	Database string             `json:"database"`
+	Table    string             `json:"table"`
+	Where    string             `json:"where,omitempty"`
+	Counted  bool               `json:"counted"` // Rows are bounded COUNTs rather than estimates
+	Limit    int64              `json:"limit,omitempty"`
+	Deleted  int64              `json:"deleted"` // Rows of the table and of the tables reached through CASCADE
+	Nulled   int64              `json:"nulled"`  // Rows whose FK columns are SET NULL / SET DEFAULT
+	Unknown  bool               `json:"unknown,omitempty"`
+	Tree     *graph.CascadeNode `json:"tree"`
+	Blockers []CascadeBlocker   `json:"blockers"`
+}
+
+// CascadeBlocker is a RESTRICT / NO ACTION foreign key on a table the DELETE reaches
+type CascadeBlocker struct {
+	Table      string `json:"table"` // Referencing table
+	Constraint string `json:"constraint"`
+	DeleteRule string `json:"delete_rule"`
+	Parent     string `json:"parent"` // Table losing rows
+	Rows       int64  `json:"rows"`   // Referencing rows: the DELETE fails unless this is 0
+	Unknown    bool   `json:"unknown,omitempty"`
+	Counted    bool   `json:"counted,omitempty"`
+}
+
+// LocksPlan is the result of 'locks-plan': the locks planned statements take and what they would block
+type LocksPlan struct {
+	Database        string              `json:"database"`
+	SessionsChecked bool                `json:"sessions_checked"` // Live locks were read (pg_locks, pg_stat_activity)
+	WorkloadChecked bool                `json:"workload_checked"` // Frequent queries were read (pg_stat_statements)
+	Statements      []LockPlanStatement `json:"statements"`
+}
+
+// LockPlanStatement is the lock analysis of one planned statement
+type LockPlanStatement struct {
+	Statement string             `json:"statement"`
+	Line      int                `json:"line"`
+	Locks     []PlannedLock      `json:"locks"`
+	Affected  []AffectedRelation `json:"affected"` // Relations whose queries queue behind the locks without being locked
+	Running   []LockConflict     `json:"running"`  // Sessions the statement would wait for
+	Queued    []QueuedQuery      `json:"queued"`   // Frequent queries that would wait for the statement
+	Notes     []string           `json:"notes"`
+}
+
+// PlannedLock is a lock a planned statement takes, directly or through a related relation
+type PlannedLock struct {
+	Relation string `json:"relation"`
+	Mode     string `json:"mode"`
+	Reason   string `json:"reason"`
+	Via      string `json:"via,omitempty"` // The relation that led here: partition parent, FK child, index
+}
+
+// AffectedRelation is a relation whose queries queue behind a planned lock on another one
+type AffectedRelation struct {
+	Relation string `json:"relation"`
+	Via      string `json:"via"`     // The locked relation
+	Blocked  string `json:"blocked"` // "reads and writes" (views) or "writes" (FK children)
+}
+
+// LockConflict is a live session holding or awaiting a lock that conflicts with a planned one
+type LockConflict struct {
+	PID        int     `json:"pid"`
+	State      string  `json:"state"`
+	Query      string  `json:"query"`
+	DurationMs float64 `json:"duration_ms"`
+	Relation   string  `json:"relation"`
+	Mode       string  `json:"mode"`
+	Granted    bool    `json:"granted"` // False: the session is itself waiting, ahead in the queue
+}
+
+// QueuedQuery is a frequent query that takes a lock conflicting with a planned one
+type QueuedQuery struct {
+	QueryID  string  `json:"query_id"`
+	Query    string  `json:"query"`
+	Calls    int64   `json:"calls"`
+	AvgTime  float64 `json:"avg_time_ms"`
+	Relation string  `json:"relation"`
+	Mode     string  `json:"mode"`
+}
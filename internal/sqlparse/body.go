@@ -0,0 +1,202 @@
+package sqlparse
+
+import "strings"
+
+// Confidence grades how certain a reference found in a routine body is
+type Confidence string
+
+const (
+	ConfidenceHigh    Confidence = "high"    // A statement of the body names the object
+	ConfidenceMedium  Confidence = "medium"  // Named inside a constant string run with EXECUTE
+	ConfidenceUnknown Confidence = "unknown" // Appears in SQL the body builds at run time
+)
+
+// confidenceRank orders confidences so the strongest evidence wins when references merge
+var confidenceRank = map[Confidence]int{ConfidenceUnknown: 0, ConfidenceMedium: 1, ConfidenceHigh: 2}
+
+// weaker returns the lower of two confidences
+func weaker(a, b Confidence) Confidence {
+	if confidenceRank[a] < confidenceRank[b] {
+		return a
+	}
+	return b
+}
+
+// BodyReference is a relation a routine body reads or writes
+type BodyReference struct {
+	Name       QualifiedName
+	Write      bool // INSERT, UPDATE, DELETE, MERGE or TRUNCATE
+	Confidence Confidence
+}
+
+// Access returns "reads" or "writes"
+func (r BodyReference) Access() string {
+	if r.Write {
+		return "writes"
+	}
+	return "reads"
+}
+
+// Body is the parsed content of a function, procedure or inline trigger body
+type Body struct {
+	References []BodyReference // One per relation, in order of first use
+	Calls      []QualifiedName // Functions invoked as name(...) or CALL name(...)
+	Dynamic    bool            // The body runs SQL built at run time (EXECUTE, PREPARE)
+
+	identifiers   map[string]Confidence        // Strongest confidence each identifier appears with
+	calls         map[QualifiedName]Confidence // Strongest confidence each call appears with
+	defaultSchema string
+}
+
+// ParseBody analyzes a SQL, PL/pgSQL or MySQL routine body. Statements of the body yield
+// high-confidence references; comments and ordinary string literals are ignored. SQL run through
+// EXECUTE (PL/pgSQL) or PREPARE ... FROM (MySQL) marks the body dynamic: a constant string is
+// parsed with medium confidence, anything concatenated or formatted at run time with unknown.
+func ParseBody(body, defaultSchema string) *Body {
+	b := &Body{
+		identifiers:   make(map[string]Confidence),
+		calls:         make(map[QualifiedName]Confidence),
+		defaultSchema: defaultSchema,
+	}
+	toks := Tokenize(body)
+	b.add(toks, ConfidenceHigh)
+
+	for i := 0; i < len(toks); i++ {
+		t := toks[i]
+		isExecute := t.IsKeyword("EXECUTE") && i+1 < len(toks) &&
+			!toks[i+1].IsKeyword("FUNCTION") && !toks[i+1].IsKeyword("PROCEDURE")
+		isPrepare := t.IsKeyword("PREPARE") && i+2 < len(toks) && toks[i+2].IsKeyword("FROM")
+		if !isExecute && !isPrepare {
+			continue
+		}
+		b.Dynamic = true
+		start := i + 1
+		if isPrepare {
+			start = i + 3
+		}
+		end := dynamicEnd(toks, start)
+
+		var literals []string
+		for _, lt := range toks[start:end] {
+			if lt.Kind == String || lt.Kind == DollarString {
+				literals = append(literals, lt.Value)
+			}
+		}
+		confidence := ConfidenceUnknown
+		if end-start == 1 && len(literals) == 1 {
+			confidence = ConfidenceMedium
+		}
+		if len(literals) > 0 {
+			b.add(Tokenize(strings.Join(literals, " ")), confidence)
+		}
+		i = end - 1
+	}
+	return b
+}
+
+// dynamicEnd returns the index just past the SQL expression of EXECUTE / PREPARE:
+// the statement ends at ";" or at a top-level USING or INTO clause
+func dynamicEnd(toks []Token, start int) int {
+	depth := 0
+	for i := start; i < len(toks); i++ {
+		t := toks[i]
+		switch {
+		case t.IsPunct("("):
+			depth++
+		case t.IsPunct(")"):
+			depth--
+		case depth == 0 && (t.IsPunct(";") || t.IsKeyword("USING") || t.IsKeyword("INTO")):
+			return i
+		}
+	}
+	return len(toks)
+}
+
+// add records the references, calls and identifiers of tokens found with the given confidence
+func (b *Body) add(toks []Token, confidence Confidence) {
+	for _, ref := range StatementReferences(toks, b.defaultSchema) {
+		write := ref.Op != "SELECT"
+		merged := false
+		for i := range b.References {
+			existing := &b.References[i]
+			if existing.Name != ref.Name {
+				continue
+			}
+			existing.Write = existing.Write || write
+			if confidenceRank[confidence] > confidenceRank[existing.Confidence] {
+				existing.Confidence = confidence
+			}
+			merged = true
+		}
+		if !merged {
+			b.References = append(b.References, BodyReference{Name: ref.Name, Write: write, Confidence: confidence})
+		}
+	}
+
+	for _, call := range FunctionCalls(toks, b.defaultSchema) {
+		c, ok := b.calls[call]
+		if !ok {
+			b.Calls = append(b.Calls, call)
+		}
+		if !ok || confidenceRank[confidence] > confidenceRank[c] {
+			b.calls[call] = confidence
+		}
+	}
+
+	for _, t := range toks {
+		if !t.IsIdent() {
+			continue
+		}
+		name := strings.ToLower(t.Ident())
+		if c, ok := b.identifiers[name]; !ok || confidenceRank[confidence] > confidenceRank[c] {
+			b.identifiers[name] = confidence
+		}
+	}
+}
+
+// Reference returns how the body uses a relation. Names in the routine's own schema also match
+// relations of other schemas, since an unqualified name may resolve through the search path.
+// A dynamic body that only mentions the relation's name in a string fragment yields an
+// unknown-confidence read.
+func (b *Body) Reference(name QualifiedName) (BodyReference, bool) {
+	for _, ref := range b.References {
+		if strings.EqualFold(ref.Name.Schema, name.Schema) && strings.EqualFold(ref.Name.Name, name.Name) {
+			return ref, true
+		}
+	}
+	for _, ref := range b.References {
+		if ref.Name.Schema == b.defaultSchema && strings.EqualFold(ref.Name.Name, name.Name) {
+			ref.Name = name
+			return ref, true
+		}
+	}
+	if c, ok := b.identifiers[strings.ToLower(name.Name)]; ok && b.Dynamic && c != ConfidenceHigh {
+		return BodyReference{Name: name, Confidence: ConfidenceUnknown}, true
+	}
+	return BodyReference{}, false
+}
+
+// UsesColumn reports whether the body touches table.column: it references the table and names
+// the column outside comments and ordinary strings. The confidence is the weaker of the two.
+func (b *Body) UsesColumn(table QualifiedName, column string) (BodyReference, bool) {
+	ref, ok := b.Reference(table)
+	if !ok {
+		return BodyReference{}, false
+	}
+	c, ok := b.identifiers[strings.ToLower(column)]
+	if !ok {
+		return BodyReference{}, false
+	}
+	ref.Confidence = weaker(ref.Confidence, c)
+	return ref, true
+}
+
+// CallsFunction reports whether the body invokes a function and how certain that is
+func (b *Body) CallsFunction(name QualifiedName) (Confidence, bool) {
+	for call, c := range b.calls {
+		if strings.EqualFold(call.Schema, name.Schema) && strings.EqualFold(call.Name, name.Name) {
+			return c, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,67 @@
+package sqlparse
+
+import "testing"
+
+func TestParseBody(t *testing.T) {
+	body := `
+DECLARE
+	n int;
+BEGIN
+	-- users is mentioned in a comment only
+	RAISE NOTICE 'cleaning up orders for users';
+	SELECT count(*) INTO n FROM public.orders WHERE status = 'stale';
+	INSERT INTO audit_logs (note, total) VALUES ('purged', n);
+	PERFORM notify_admins(n);
+	EXECUTE 'DELETE FROM carts';
+	EXECUTE format('TRUNCATE %I', 'tmp_' || TG_ARGV[0]) USING n;
+	RETURN NEW;
+END;`
+	b := ParseBody(body, "public")
+
+	if !b.Dynamic {
+		t.Errorf("expected EXECUTE to mark the body dynamic")
+	}
+
+	cases := []struct {
+		name       string
+		found      bool
+		write      bool
+		confidence Confidence
+	}{
+		{"orders", true, false, ConfidenceHigh},
+		{"audit_logs", true, true, ConfidenceHigh},
+		{"carts", true, true, ConfidenceMedium},
+		{"users", false, false, ""},
+	}
+	for _, c := range cases {
+		ref, ok := b.Reference(QualifiedName{Schema: "public", Name: c.name})
+		if ok != c.found || ref.Write != c.write || ref.Confidence != c.confidence {
+			t.Errorf("Reference(%s) = %+v, %v; want found=%v write=%v confidence=%s", c.name, ref, ok, c.found, c.write, c.confidence)
+		}
+	}
+
+	if ref, ok := b.UsesColumn(QualifiedName{Schema: "public", Name: "audit_logs"}, "total"); !ok || ref.Confidence != ConfidenceHigh {
+		t.Errorf("expected audit_logs.total to be used, got %+v, %v", ref, ok)
+	}
+	if _, ok := b.UsesColumn(QualifiedName{Schema: "public", Name: "orders"}, "cleaning"); ok {
+		t.Errorf("string literal contents must not count as column references")
+	}
+	if c, ok := b.CallsFunction(QualifiedName{Schema: "public", Name: "notify_admins"}); !ok || c != ConfidenceHigh {
+		t.Errorf("expected notify_admins call, got %s, %v", c, ok)
+	}
+}
+
+func TestParseBodyDynamicFragments(t *testing.T) {
+	// The relation is only visible in a fragment of SQL built at run time
+	b := ParseBody(`BEGIN EXECUTE 'UPDATE ' || 'invoices' || ' SET paid = true WHERE id = $1' USING id; END`, "public")
+
+	ref, ok := b.Reference(QualifiedName{Schema: "public", Name: "invoices"})
+	if !ok || ref.Confidence != ConfidenceUnknown {
+		t.Errorf("Reference(invoices) = %+v, %v; want unknown confidence", ref, ok)
+	}
+
+	// MySQL: PREPARE ... FROM a variable gives nothing to parse, but the body is still dynamic
+	if !ParseBody(`BEGIN PREPARE stmt FROM @sql; EXECUTE stmt; END`, "app").Dynamic {
+		t.Errorf("expected PREPARE to mark the body dynamic")
+	}
+}
@@ -0,0 +1,783 @@
+package sqlparse
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Column is a column definition
+type Column struct {
+	Name    string
+	Type    string
+	TypeRef QualifiedName // Type name resolved like a relation name; matters for user-defined types
+	NotNull bool
+	Default string
+	// Sequence the default draws from (nextval or a serial type), if any
+	Sequence *QualifiedName
+}
+
+// ForeignKey is a FOREIGN KEY / REFERENCES constraint
+type ForeignKey struct {
+	Name       string
+	Table      QualifiedName
+	Columns    []string
+	RefTable   QualifiedName
+	RefColumns []string // Empty means the referenced table's primary key
+	DeleteRule string   // "CASCADE", "RESTRICT", "SET NULL", "SET DEFAULT", "NO ACTION"
+}
+
+// Index is a CREATE INDEX statement or the index backing a PRIMARY KEY / UNIQUE constraint
+type Index struct {
+	Name        string
+	Table       QualifiedName
+	Columns     []string // Plain key columns in key order
+	Expressions []string // Expression keys, e.g. "lower(email)"
+	Unique      bool
+	Primary     bool
+	Constraint  string // PRIMARY KEY / UNIQUE constraint the index backs, if any
+	Method      string // "btree" unless specified
+	Predicate   string // Partial index WHERE clause
+}
+
+// Check is a CHECK constraint
+type Check struct {
+	Name string
+	Expr string
+}
+
+// Table is a CREATE TABLE statement (plus columns/constraints added by ALTER TABLE)
+type Table struct {
+	QualifiedName
+	Columns     []Column
+	Checks      []Check
+	PrimaryKey  []string
+	Parent      *QualifiedName // PARTITION OF / INHERITS / ATTACH PARTITION parent
+	Partitioned bool           // PARTITION BY ...
+}
+
+// Column returns the named column or nil
+func (t *Table) Column(name string) *Column {
+	for i := range t.Columns {
+		if t.Columns[i].Name == name {
+			return &t.Columns[i]
+		}
+	}
+	return nil
+}
+
+// View is a CREATE [MATERIALIZED] VIEW statement
+type View struct {
+	QualifiedName
+	Materialized bool
+	Columns      []string // Explicit column list, if any
+	Query        string
+	Tokens       []Token
+	References   []QualifiedName
+}
+
+// Function is a CREATE FUNCTION / PROCEDURE statement
+type Function struct {
+	QualifiedName
+	Language    string
+	Body        string
+	IsProcedure bool
+}
+
+// UserType kinds
+const (
+	EnumType      = "enum"
+	CompositeType = "composite"
+	DomainType    = "domain"
+)
+
+// UserType is a CREATE TYPE ... AS ENUM / AS (...) or CREATE DOMAIN statement
+type UserType struct {
+	QualifiedName
+	Kind       string
+	Labels     []string      // Enum values
+	Attributes []Column      // Composite type attributes
+	BaseType   string        // Domain base type
+	BaseRef    QualifiedName // Domain base type name, resolved like Column.TypeRef
+}
+
+// Sequence is a CREATE SEQUENCE statement or the sequence behind a serial column
+type Sequence struct {
+	QualifiedName
+	OwnedBy *ColumnRef // OWNED BY table.column
+}
+
+// Trigger is a CREATE TRIGGER statement
+type Trigger struct {
+	Name       string
+	Table      QualifiedName
+	Function   QualifiedName
+	Definition string
+}
+
+// Schema is the set of objects declared by a DDL script
+type Schema struct {
+	Tables      []*Table
+	Views       []*View
+	Functions   []*Function
+	Triggers    []*Trigger
+	Indexes     []*Index
+	ForeignKeys []*ForeignKey
+	Types       []*UserType
+	Sequences   []*Sequence
+
+	tables map[QualifiedName]*Table
+}
+
+// Table returns the table with the given name, or nil
+func (s *Schema) Table(name QualifiedName) *Table {
+	return s.tables[name]
+}
+
+// Type returns the enum, composite type or domain with the given name, or nil
+func (s *Schema) Type(name QualifiedName) *UserType {
+	for _, t := range s.Types {
+		if t.QualifiedName == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// sequence returns the named sequence, creating it when it was not declared
+func (s *Schema) sequence(name QualifiedName) *Sequence {
+	for _, seq := range s.Sequences {
+		if seq.QualifiedName == name {
+			return seq
+		}
+	}
+	seq := &Sequence{QualifiedName: name}
+	s.Sequences = append(s.Sequences, seq)
+	return seq
+}
+
+// table returns the named table, creating a placeholder when it was not declared
+func (s *Schema) table(name QualifiedName) *Table {
+	if t, ok := s.tables[name]; ok {
+		return t
+	}
+	t := &Table{QualifiedName: name}
+	s.tables[name] = t
+	s.Tables = append(s.Tables, t)
+	return t
+}
+
+// ParseSchema parses a DDL script (hand written or pg_dump --schema-only output).
+// Statements it does not understand (GRANT, COMMENT, SET ...) are skipped.
+func ParseSchema(sql string) *Schema {
+	s := &Schema{tables: make(map[QualifiedName]*Table)}
+	defaultSchema := "public"
+
+	for _, stmt := range SplitStatements(sql) {
+		p := newParser(stmt.Tokens)
+
+		switch {
+		case p.acceptKeyword("SET", "search_path"):
+			if !p.acceptKeyword("TO") {
+				p.acceptPunct("=")
+			}
+			if t := p.peek(); t.IsIdent() || (t.Kind == String && t.Value != "") {
+				defaultSchema = strings.TrimSpace(strings.Split(t.Ident(), ",")[0])
+				if t.Kind == String {
+					defaultSchema = strings.TrimSpace(strings.Split(t.Value, ",")[0])
+				}
+			}
+
+		case p.acceptKeyword("CREATE"):
+			p.acceptKeyword("OR", "REPLACE")
+			for p.acceptKeyword("TEMP") || p.acceptKeyword("TEMPORARY") || p.acceptKeyword("UNLOGGED") ||
+				p.acceptKeyword("GLOBAL") || p.acceptKeyword("LOCAL") {
+			}
+			switch {
+			case p.acceptKeyword("TABLE"):
+				s.parseCreateTable(p, defaultSchema)
+			case p.acceptKeyword("MATERIALIZED", "VIEW"):
+				s.parseCreateView(p, stmt, defaultSchema, true)
+			case p.acceptKeyword("VIEW"), p.acceptKeyword("RECURSIVE", "VIEW"):
+				s.parseCreateView(p, stmt, defaultSchema, false)
+			case p.acceptKeyword("UNIQUE", "INDEX"):
+				s.parseCreateIndex(p, defaultSchema, true)
+			case p.acceptKeyword("INDEX"):
+				s.parseCreateIndex(p, defaultSchema, false)
+			case p.acceptKeyword("FUNCTION"):
+				s.parseCreateFunction(p, stmt, defaultSchema, false)
+			case p.acceptKeyword("PROCEDURE"):
+				s.parseCreateFunction(p, stmt, defaultSchema, true)
+			case p.acceptKeyword("TRIGGER"), p.acceptKeyword("CONSTRAINT", "TRIGGER"):
+				s.parseCreateTrigger(p, stmt, defaultSchema)
+			case p.acceptKeyword("TYPE"):
+				s.parseCreateType(p, defaultSchema)
+			case p.acceptKeyword("DOMAIN"):
+				s.parseCreateDomain(p, defaultSchema)
+			case p.acceptKeyword("SEQUENCE"):
+				s.parseSequence(p, defaultSchema, true)
+			}
+
+		case p.acceptKeyword("ALTER", "TABLE"):
+			s.parseAlterTable(p, defaultSchema)
+		case p.acceptKeyword("ALTER", "SEQUENCE"):
+			s.parseSequence(p, defaultSchema, false)
+		}
+	}
+	return s
+}
+
+// sourceFrom returns the statement text starting at token t
+func sourceFrom(stmt Statement, t Token) string {
+	if len(stmt.Tokens) == 0 || t.Pos < stmt.Tokens[0].Pos {
+		return ""
+	}
+	offset := t.Pos - stmt.Tokens[0].Pos
+	if offset > len(stmt.Text) {
+		return ""
+	}
+	return strings.TrimSpace(stmt.Text[offset:])
+}
+
+func (s *Schema) parseCreateTable(p *parser, defaultSchema string) {
+	p.acceptKeyword("IF", "NOT", "EXISTS")
+	name, ok := p.qualifiedName(defaultSchema)
+	if !ok {
+		return
+	}
+	t := s.table(name)
+
+	if p.acceptKeyword("PARTITION", "OF") {
+		parent, ok := p.qualifiedName(defaultSchema)
+		if ok {
+			t.Parent = &parent
+		}
+	}
+
+	if elems, ok := p.parenGroup(); ok {
+		for _, elem := range SplitTopLevel(elems, ",") {
+			s.parseTableElement(t, elem, defaultSchema)
+		}
+	}
+
+	for !p.eof() {
+		switch {
+		case p.acceptKeyword("INHERITS"):
+			if parents, ok := p.parenGroup(); ok {
+				pp := newParser(parents)
+				if parent, ok := pp.qualifiedName(defaultSchema); ok {
+					t.Parent = &parent
+				}
+			}
+		case p.acceptKeyword("PARTITION", "BY"):
+			t.Partitioned = true
+		default:
+			p.next()
+		}
+	}
+}
+
+// parseTableElement handles a column definition or a table constraint
+func (s *Schema) parseTableElement(t *Table, elem []Token, defaultSchema string) {
+	if len(elem) == 0 {
+		return
+	}
+	p := newParser(elem)
+
+	constraintName := ""
+	if p.acceptKeyword("CONSTRAINT") {
+		constraintName = p.next().Ident()
+	}
+
+	switch {
+	case p.peek().IsKeyword("PRIMARY"), p.peek().IsKeyword("UNIQUE"), p.peek().IsKeyword("FOREIGN"),
+		p.peek().IsKeyword("CHECK"), p.peek().IsKeyword("EXCLUDE"):
+		s.parseTableConstraint(t, constraintName, p, defaultSchema)
+	case p.peek().IsKeyword("LIKE"):
+		return
+	default:
+		s.parseColumnDef(t, p, defaultSchema)
+	}
+}
+
+// columnConstraintWords end the type of a column definition
+var columnConstraintWords = map[string]bool{
+	"constraint": true, "not": true, "null": true, "default": true, "primary": true, "unique": true,
+	"references": true, "check": true, "collate": true, "generated": true,
+}
+
+func (s *Schema) parseColumnDef(t *Table, p *parser, defaultSchema string) {
+	if !p.peek().IsIdent() {
+		return
+	}
+	col := Column{Name: p.next().Ident()}
+
+	typ := untilColumnConstraint(p)
+	col.Type = JoinTokens(typ)
+	col.TypeRef, _ = newParser(typ).qualifiedName(defaultSchema)
+	if serialTypes[strings.ToLower(col.Type)] {
+		// Postgres creates table_column_seq, owned by the column, and makes it the default
+		seq := s.sequence(QualifiedName{Schema: t.Schema, Name: fmt.Sprintf("%s_%s_seq", t.Name, col.Name)})
+		seq.OwnedBy = &ColumnRef{Relation: t.QualifiedName, Column: col.Name}
+		col.Sequence = &seq.QualifiedName
+		col.Default = fmt.Sprintf("nextval('%s'::regclass)", seq.Name)
+	}
+
+	constraintName := ""
+	for !p.eof() {
+		switch {
+		case p.acceptKeyword("CONSTRAINT"):
+			constraintName = p.next().Ident()
+		case p.acceptKeyword("NOT", "NULL"):
+			col.NotNull = true
+		case p.acceptKeyword("DEFAULT"):
+			setDefault(&col, p, defaultSchema)
+		case p.acceptKeyword("PRIMARY", "KEY"):
+			col.NotNull = true
+			t.PrimaryKey = []string{col.Name}
+			s.addConstraintIndex(t, constraintName, []string{col.Name}, true)
+			constraintName = ""
+		case p.acceptKeyword("UNIQUE"):
+			s.addConstraintIndex(t, constraintName, []string{col.Name}, false)
+			constraintName = ""
+		case p.acceptKeyword("REFERENCES"):
+			s.parseReferences(t, constraintName, []string{col.Name}, p, defaultSchema)
+			constraintName = ""
+		case p.acceptKeyword("CHECK"):
+			if constraintName == "" {
+				constraintName = fmt.Sprintf("%s_%s_check", t.Name, col.Name)
+			}
+			expr, _ := p.parenGroup()
+			t.Checks = append(t.Checks, Check{Name: constraintName, Expr: JoinTokens(expr)})
+			constraintName = ""
+		case p.peek().IsPunct("("):
+			p.parenGroup()
+		default:
+			p.next()
+		}
+	}
+	if existing := t.Column(col.Name); existing != nil {
+		*existing = col
+	} else {
+		t.Columns = append(t.Columns, col)
+	}
+}
+
+// untilColumnConstraint consumes tokens up to the next column constraint (a type or a default expression)
+func untilColumnConstraint(p *parser) []Token {
+	start := p.pos
+	for !p.eof() && !(p.peek().Kind == Word && columnConstraintWords[p.peek().Ident()]) {
+		if p.peek().IsPunct("(") {
+			p.parenGroup()
+			continue
+		}
+		p.next()
+	}
+	return p.toks[start:p.pos]
+}
+
+// serialTypes are the pseudo-types that create a sequence for the column
+var serialTypes = map[string]bool{
+	"serial": true, "serial4": true, "bigserial": true, "serial8": true, "smallserial": true, "serial2": true,
+}
+
+// setDefault reads the expression following DEFAULT (up to the next column constraint) into col
+func setDefault(col *Column, p *parser, defaultSchema string) {
+	start := p.pos
+	p.next() // DEFAULT NULL: the expression may itself be a constraint word
+	untilColumnConstraint(p)
+	expr := p.toks[start:p.pos]
+	col.Default = JoinTokens(expr)
+	col.Sequence = nil
+	if seq, ok := nextvalSequence(expr, defaultSchema); ok {
+		col.Sequence = &seq
+	}
+}
+
+// nextvalSequence finds the sequence of a nextval('name') call
+func nextvalSequence(toks []Token, defaultSchema string) (QualifiedName, bool) {
+	for i := 0; i+2 < len(toks); i++ {
+		if toks[i].IsKeyword("nextval") && toks[i+1].IsPunct("(") && toks[i+2].Kind == String {
+			if name, ok := newParser(Tokenize(toks[i+2].Value)).qualifiedName(defaultSchema); ok {
+				return name, true
+			}
+		}
+	}
+	return QualifiedName{}, false
+}
+
+func (s *Schema) parseTableConstraint(t *Table, name string, p *parser, defaultSchema string) {
+	switch {
+	case p.acceptKeyword("PRIMARY", "KEY"):
+		cols, _ := p.parenGroup()
+		t.PrimaryKey = identList(cols)
+		for _, c := range t.PrimaryKey {
+			if col := t.Column(c); col != nil {
+				col.NotNull = true
+			}
+		}
+		s.addConstraintIndex(t, name, t.PrimaryKey, true)
+	case p.acceptKeyword("UNIQUE"):
+		p.acceptKeyword("NULLS", "NOT", "DISTINCT")
+		p.acceptKeyword("NULLS", "DISTINCT")
+		cols, _ := p.parenGroup()
+		s.addConstraintIndex(t, name, identList(cols), false)
+	case p.acceptKeyword("FOREIGN", "KEY"):
+		cols, _ := p.parenGroup()
+		if p.acceptKeyword("REFERENCES") {
+			s.parseReferences(t, name, identList(cols), p, defaultSchema)
+		}
+	case p.acceptKeyword("CHECK"):
+		if name == "" {
+			name = fmt.Sprintf("%s_check", t.Name)
+		}
+		expr, _ := p.parenGroup()
+		t.Checks = append(t.Checks, Check{Name: name, Expr: JoinTokens(expr)})
+	}
+}
+
+// addConstraintIndex records the unique index Postgres creates for PRIMARY KEY / UNIQUE
+func (s *Schema) addConstraintIndex(t *Table, name string, cols []string, primary bool) {
+	if len(cols) == 0 {
+		return
+	}
+	if name == "" {
+		if primary {
+			name = fmt.Sprintf("%s_pkey", t.Name)
+		} else {
+			name = fmt.Sprintf("%s_%s_key", t.Name, strings.Join(cols, "_"))
+		}
+	}
+	s.Indexes = append(s.Indexes, &Index{
+		Name:       name,
+		Table:      t.QualifiedName,
+		Columns:    cols,
+		Unique:     true,
+		Primary:    primary,
+		Constraint: name,
+		Method:     "btree",
+	})
+}
+
+// parseReferences parses "REFERENCES table [(cols)] [MATCH ...] [ON DELETE action] [ON UPDATE action]"
+func (s *Schema) parseReferences(t *Table, name string, cols []string, p *parser, defaultSchema string) {
+	ref, ok := p.qualifiedName(defaultSchema)
+	if !ok {
+		return
+	}
+	fk := &ForeignKey{
+		Name:       name,
+		Table:      t.QualifiedName,
+		Columns:    cols,
+		RefTable:   ref,
+		DeleteRule: "NO ACTION",
+	}
+	if refCols, ok := p.parenGroup(); ok {
+		fk.RefColumns = identList(refCols)
+	}
+	if fk.Name == "" {
+		fk.Name = fmt.Sprintf("%s_%s_fkey", t.Name, strings.Join(cols, "_"))
+	}
+
+	for !p.eof() {
+		switch {
+		case p.acceptKeyword("MATCH"):
+			p.next()
+		case p.acceptKeyword("ON", "DELETE"):
+			fk.DeleteRule = parseReferentialAction(p)
+		case p.acceptKeyword("ON", "UPDATE"):
+			parseReferentialAction(p)
+		default:
+			s.ForeignKeys = append(s.ForeignKeys, fk)
+			return
+		}
+	}
+	s.ForeignKeys = append(s.ForeignKeys, fk)
+}
+
+func parseReferentialAction(p *parser) string {
+	switch {
+	case p.acceptKeyword("CASCADE"):
+		return "CASCADE"
+	case p.acceptKeyword("RESTRICT"):
+		return "RESTRICT"
+	case p.acceptKeyword("NO", "ACTION"):
+		return "NO ACTION"
+	case p.acceptKeyword("SET", "NULL"):
+		p.parenGroup()
+		return "SET NULL"
+	case p.acceptKeyword("SET", "DEFAULT"):
+		p.parenGroup()
+		return "SET DEFAULT"
+	}
+	return "NO ACTION"
+}
+
+func (s *Schema) parseCreateView(p *parser, stmt Statement, defaultSchema string, materialized bool) {
+	p.acceptKeyword("IF", "NOT", "EXISTS")
+	name, ok := p.qualifiedName(defaultSchema)
+	if !ok {
+		return
+	}
+	v := &View{QualifiedName: name, Materialized: materialized}
+	if cols, ok := p.parenGroup(); ok {
+		v.Columns = identList(cols)
+	}
+	if !p.skipUntilKeyword("AS") {
+		return
+	}
+	p.next()
+
+	body := p.rest()
+	// Materialized views end with WITH [NO] DATA
+	if n := len(body); n >= 2 && body[n-1].IsKeyword("DATA") {
+		cut := n - 2
+		if body[cut].IsKeyword("NO") {
+			cut--
+		}
+		if cut >= 0 && body[cut].IsKeyword("WITH") {
+			body = body[:cut]
+		}
+	}
+	if len(body) > 0 {
+		v.Query = sourceFrom(stmt, body[0])
+		if end := body[len(body)-1]; end.Pos+len(end.Text)-body[0].Pos <= len(v.Query) {
+			v.Query = v.Query[:end.Pos+len(end.Text)-body[0].Pos]
+		}
+	}
+	v.Tokens = body
+	v.References = RelationReferences(body, defaultSchema)
+	s.Views = append(s.Views, v)
+}
+
+func (s *Schema) parseCreateIndex(p *parser, defaultSchema string, unique bool) {
+	p.acceptKeyword("CONCURRENTLY")
+	p.acceptKeyword("IF", "NOT", "EXISTS")
+
+	idx := &Index{Unique: unique, Method: "btree"}
+	if !p.peek().IsKeyword("ON") {
+		idx.Name = p.next().Ident()
+	}
+	if !p.acceptKeyword("ON") {
+		return
+	}
+	p.acceptKeyword("ONLY")
+	table, ok := p.qualifiedName(defaultSchema)
+	if !ok {
+		return
+	}
+	idx.Table = table
+	if p.acceptKeyword("USING") {
+		idx.Method = strings.ToLower(p.next().Text)
+	}
+
+	elems, _ := p.parenGroup()
+	for _, elem := range SplitTopLevel(elems, ",") {
+		if len(elem) == 0 {
+			continue
+		}
+		// A plain column is an identifier optionally followed by COLLATE/opclass/ASC/DESC/NULLS
+		if elem[0].IsIdent() && (len(elem) == 1 || elem[1].Kind == Word || elem[1].Kind == QuotedIdent) {
+			idx.Columns = append(idx.Columns, elem[0].Ident())
+		} else {
+			idx.Expressions = append(idx.Expressions, JoinTokens(elem))
+		}
+	}
+
+	if p.skipUntilKeyword("WHERE") {
+		p.next()
+		idx.Predicate = JoinTokens(p.rest())
+	}
+
+	if idx.Name == "" {
+		idx.Name = fmt.Sprintf("%s_%s_idx", table.Name, strings.Join(idx.Columns, "_"))
+	}
+	// An index shares its table's schema
+	s.Indexes = append(s.Indexes, idx)
+}
+
+func (s *Schema) parseCreateFunction(p *parser, stmt Statement, defaultSchema string, procedure bool) {
+	name, ok := p.qualifiedName(defaultSchema)
+	if !ok {
+		return
+	}
+	fn := &Function{QualifiedName: name, IsProcedure: procedure, Language: "sql"}
+	p.parenGroup() // arguments
+
+	for !p.eof() {
+		switch {
+		case p.acceptKeyword("LANGUAGE"):
+			fn.Language = strings.ToLower(p.next().Value)
+		case p.acceptKeyword("AS"):
+			if t := p.next(); t.Kind == DollarString || t.Kind == String {
+				fn.Body = t.Value
+			}
+		case p.peek().IsKeyword("BEGIN") && p.peekAt(1).IsKeyword("ATOMIC"):
+			fn.Body = sourceFrom(stmt, p.peek())
+			p.pos = len(p.toks)
+		case p.acceptKeyword("RETURN"):
+			// SQL-standard single statement body: RETURN expr
+			fn.Body = sourceFrom(stmt, p.peekAt(-1))
+			p.pos = len(p.toks)
+		default:
+			p.next()
+		}
+	}
+	s.Functions = append(s.Functions, fn)
+}
+
+func (s *Schema) parseCreateTrigger(p *parser, stmt Statement, defaultSchema string) {
+	if !p.peek().IsIdent() {
+		return
+	}
+	trg := &Trigger{Name: p.next().Ident(), Definition: stmt.Text}
+
+	if !p.skipUntilKeyword("ON") {
+		return
+	}
+	p.next()
+	table, ok := p.qualifiedName(defaultSchema)
+	if !ok {
+		return
+	}
+	trg.Table = table
+
+	if !p.skipUntilKeyword("EXECUTE") {
+		return
+	}
+	p.next()
+	if !p.acceptKeyword("FUNCTION") {
+		p.acceptKeyword("PROCEDURE")
+	}
+	if fn, ok := p.qualifiedName(defaultSchema); ok {
+		trg.Function = fn
+	}
+	s.Triggers = append(s.Triggers, trg)
+}
+
+func (s *Schema) parseCreateType(p *parser, defaultSchema string) {
+	name, ok := p.qualifiedName(defaultSchema)
+	if !ok || !p.acceptKeyword("AS") {
+		return // Base and shell types
+	}
+	typ := &UserType{QualifiedName: name}
+	switch {
+	case p.acceptKeyword("ENUM"):
+		typ.Kind = EnumType
+		labels, _ := p.parenGroup()
+		for _, part := range SplitTopLevel(labels, ",") {
+			if len(part) == 1 && part[0].Kind == String {
+				typ.Labels = append(typ.Labels, part[0].Value)
+			}
+		}
+	case p.peek().IsPunct("("):
+		typ.Kind = CompositeType
+		attrs, _ := p.parenGroup()
+		t := &Table{QualifiedName: name}
+		for _, elem := range SplitTopLevel(attrs, ",") {
+			s.parseColumnDef(t, newParser(elem), defaultSchema)
+		}
+		typ.Attributes = t.Columns
+	default:
+		return // Range and multirange types
+	}
+	s.Types = append(s.Types, typ)
+}
+
+func (s *Schema) parseCreateDomain(p *parser, defaultSchema string) {
+	name, ok := p.qualifiedName(defaultSchema)
+	if !ok {
+		return
+	}
+	p.acceptKeyword("AS")
+	base := untilColumnConstraint(p)
+	if len(base) == 0 {
+		return
+	}
+	typ := &UserType{QualifiedName: name, Kind: DomainType, BaseType: JoinTokens(base)}
+	typ.BaseRef, _ = newParser(base).qualifiedName(defaultSchema)
+	s.Types = append(s.Types, typ)
+}
+
+// parseSequence handles CREATE SEQUENCE and ALTER SEQUENCE; only OWNED BY matters to the graph
+func (s *Schema) parseSequence(p *parser, defaultSchema string, create bool) {
+	if create {
+		p.acceptKeyword("IF", "NOT", "EXISTS")
+	} else {
+		p.acceptKeyword("IF", "EXISTS")
+	}
+	name, ok := p.qualifiedName(defaultSchema)
+	if !ok {
+		return
+	}
+	var seq *Sequence
+	if create {
+		seq = s.sequence(name)
+	}
+	for !p.eof() {
+		if !p.acceptKeyword("OWNED", "BY") {
+			p.next()
+			continue
+		}
+		if seq == nil {
+			seq = s.sequence(name)
+		}
+		if p.acceptKeyword("NONE") {
+			seq.OwnedBy = nil
+			continue
+		}
+		var parts []string
+		for p.peek().IsIdent() {
+			parts = append(parts, p.next().Ident())
+			if !p.acceptPunct(".") {
+				break
+			}
+		}
+		switch len(parts) {
+		case 0, 1:
+		case 2:
+			seq.OwnedBy = &ColumnRef{Relation: QualifiedName{Schema: defaultSchema, Name: parts[0]}, Column: parts[1]}
+		default:
+			n := len(parts)
+			seq.OwnedBy = &ColumnRef{Relation: QualifiedName{Schema: parts[n-3], Name: parts[n-2]}, Column: parts[n-1]}
+		}
+	}
+}
+
+func (s *Schema) parseAlterTable(p *parser, defaultSchema string) {
+	p.acceptKeyword("IF", "EXISTS")
+	p.acceptKeyword("ONLY")
+	name, ok := p.qualifiedName(defaultSchema)
+	if !ok {
+		return
+	}
+	p.acceptPunct("*")
+	t := s.table(name)
+
+	for _, action := range SplitTopLevel(p.rest(), ",") {
+		ap := newParser(action)
+		switch {
+		case ap.acceptKeyword("ADD", "CONSTRAINT"):
+			constraintName := ap.next().Ident()
+			s.parseTableConstraint(t, constraintName, ap, defaultSchema)
+		case ap.peekAt(1).IsKeyword("PRIMARY") || ap.peekAt(1).IsKeyword("UNIQUE") || ap.peekAt(1).IsKeyword("FOREIGN") ||
+			ap.peekAt(1).IsKeyword("CHECK"):
+			ap.acceptKeyword("ADD")
+			s.parseTableConstraint(t, "", ap, defaultSchema)
+		case ap.acceptKeyword("ADD"):
+			ap.acceptKeyword("COLUMN")
+			ap.acceptKeyword("IF", "NOT", "EXISTS")
+			s.parseColumnDef(t, ap, defaultSchema)
+		case ap.acceptKeyword("ALTER"):
+			ap.acceptKeyword("COLUMN")
+			if col := t.Column(ap.next().Ident()); col != nil && ap.acceptKeyword("SET", "DEFAULT") {
+				setDefault(col, ap, defaultSchema)
+			}
+		case ap.acceptKeyword("ATTACH", "PARTITION"):
+			if child, ok := ap.qualifiedName(defaultSchema); ok {
+				c := s.table(child)
+				parent := t.QualifiedName
+				c.Parent = &parent
+			}
+		}
+	}
+}
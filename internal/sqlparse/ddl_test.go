@@ -0,0 +1,192 @@
+package sqlparse
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const pgDumpFixture = `
+SET search_path = app, pg_catalog;
+
+CREATE FUNCTION touch() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $_$
+BEGIN
+  UPDATE accounts SET touched_at = now() WHERE id = NEW.account_id;
+  RETURN NEW;
+END;
+$_$;
+
+CREATE TABLE accounts (
+    id bigint NOT NULL,
+    email character varying(255) NOT NULL UNIQUE,
+    touched_at timestamp without time zone
+);
+
+CREATE TABLE invoices (
+    id bigint PRIMARY KEY,
+    account_id bigint REFERENCES accounts ON DELETE CASCADE,
+    amount numeric(10,2) DEFAULT 0.00
+);
+
+CREATE TABLE events (id bigint, created date) PARTITION BY RANGE (created);
+CREATE TABLE events_2024 PARTITION OF events FOR VALUES FROM ('2024-01-01') TO ('2025-01-01');
+
+CREATE MATERIALIZED VIEW totals AS
+ SELECT invoices.account_id, sum(invoices.amount) AS total
+   FROM (invoices
+     JOIN accounts ON ((accounts.id = invoices.account_id)))
+  GROUP BY invoices.account_id
+  WITH NO DATA;
+
+ALTER TABLE ONLY accounts
+    ADD CONSTRAINT accounts_pkey PRIMARY KEY (id);
+CREATE INDEX invoices_lower_idx ON invoices USING gin (account_id, lower((amount)::text)) WHERE (amount > 0);
+CREATE TRIGGER invoices_touch AFTER INSERT OR UPDATE ON invoices FOR EACH ROW EXECUTE FUNCTION touch();
+`
+
+func TestParseSchema(t *testing.T) {
+	s := ParseSchema(pgDumpFixture)
+
+	accounts := s.Table(QualifiedName{"app", "accounts"})
+	if accounts == nil || len(accounts.Columns) != 3 || accounts.Columns[1].Type != "character varying(255)" {
+		t.Fatalf("unexpected accounts table: %+v", accounts)
+	}
+	if strings.Join(accounts.PrimaryKey, ",") != "id" || !accounts.Column("id").NotNull {
+		t.Errorf("expected primary key on accounts.id, got %v", accounts.PrimaryKey)
+	}
+
+	if len(s.ForeignKeys) != 1 {
+		t.Fatalf("got %d foreign keys, want 1", len(s.ForeignKeys))
+	}
+	fk := s.ForeignKeys[0]
+	if fk.Name != "invoices_account_id_fkey" || fk.RefTable.String() != "app.accounts" || fk.DeleteRule != "CASCADE" {
+		t.Errorf("unexpected foreign key: %+v", fk)
+	}
+
+	events := s.Table(QualifiedName{"app", "events_2024"})
+	if events == nil || events.Parent == nil || events.Parent.Name != "events" {
+		t.Errorf("expected events_2024 to be a partition of events: %+v", events)
+	}
+	if !s.Table(QualifiedName{"app", "events"}).Partitioned {
+		t.Errorf("expected events to be partitioned")
+	}
+
+	if len(s.Views) != 1 || !s.Views[0].Materialized || len(s.Views[0].References) != 2 {
+		t.Fatalf("unexpected views: %+v", s.Views)
+	}
+	if strings.Contains(s.Views[0].Query, "WITH NO DATA") {
+		t.Errorf("view query should not include WITH NO DATA: %q", s.Views[0].Query)
+	}
+
+	indexes := make(map[string]*Index)
+	for _, idx := range s.Indexes {
+		indexes[idx.Name] = idx
+	}
+	if idx := indexes["invoices_lower_idx"]; idx == nil || idx.Method != "gin" ||
+		strings.Join(idx.Columns, ",") != "account_id" || len(idx.Expressions) != 1 || idx.Predicate == "" {
+		t.Errorf("unexpected expression index: %+v", idx)
+	}
+	for _, name := range []string{"accounts_pkey", "accounts_email_key", "invoices_pkey"} {
+		if indexes[name] == nil || !indexes[name].Unique || indexes[name].Constraint != name {
+			t.Errorf("expected unique constraint index %s", name)
+		}
+	}
+
+	if len(s.Functions) != 1 || s.Functions[0].Language != "plpgsql" || !strings.Contains(s.Functions[0].Body, "UPDATE accounts") {
+		t.Fatalf("unexpected functions: %+v", s.Functions)
+	}
+	if len(s.Triggers) != 1 || s.Triggers[0].Table.String() != "app.invoices" || s.Triggers[0].Function.String() != "app.touch" {
+		t.Errorf("unexpected triggers: %+v", s.Triggers)
+	}
+}
+
+func TestParseChecks(t *testing.T) {
+	s := ParseSchema(`
+CREATE TABLE products (
+    price numeric CHECK (price > 0),
+    discount numeric,
+    CONSTRAINT sane_discount CHECK (discount < price)
+);
+ALTER TABLE products ADD CHECK (discount >= 0);
+`)
+	products := s.Table(QualifiedName{"public", "products"})
+	if products == nil {
+		t.Fatal("products not parsed")
+	}
+	want := []Check{
+		{Name: "products_price_check", Expr: "price > 0"},
+		{Name: "sane_discount", Expr: "discount < price"},
+		{Name: "products_check", Expr: "discount >= 0"},
+	}
+	if len(products.Checks) != len(want) {
+		t.Fatalf("got checks %+v, want %+v", products.Checks, want)
+	}
+	for i, c := range want {
+		if products.Checks[i] != c {
+			t.Errorf("check %d = %+v, want %+v", i, products.Checks[i], c)
+		}
+	}
+}
+
+func TestParseTypesAndSequences(t *testing.T) {
+	s := ParseSchema(`
+CREATE TYPE order_status AS ENUM ('pending', 'shipped');
+CREATE TYPE app.address AS (street text, status order_status);
+CREATE DOMAIN positive_int AS integer CHECK (VALUE > 0);
+CREATE TYPE price_range AS RANGE (subtype = numeric);
+
+CREATE TABLE orders (
+    id serial PRIMARY KEY,
+    status order_status DEFAULT 'pending' NOT NULL,
+    history order_status[],
+    qty positive_int
+);
+
+CREATE SEQUENCE invoice_no_seq START WITH 1000;
+CREATE TABLE invoices (no bigint, ref bigint);
+ALTER SEQUENCE invoice_no_seq OWNED BY public.invoices.no;
+ALTER TABLE ONLY invoices ALTER COLUMN no SET DEFAULT nextval('public.invoice_no_seq'::regclass);
+`)
+
+	if len(s.Types) != 3 {
+		t.Fatalf("got types %+v, want enum, composite and domain", s.Types)
+	}
+	if status := s.Type(QualifiedName{"public", "order_status"}); status == nil || status.Kind != EnumType ||
+		strings.Join(status.Labels, ",") != "pending,shipped" {
+		t.Errorf("unexpected enum: %+v", status)
+	}
+	if addr := s.Type(QualifiedName{"app", "address"}); addr == nil || addr.Kind != CompositeType ||
+		len(addr.Attributes) != 2 || addr.Attributes[1].TypeRef.String() != "public.order_status" {
+		t.Errorf("unexpected composite type: %+v", addr)
+	}
+	if dom := s.Type(QualifiedName{"public", "positive_int"}); dom == nil || dom.Kind != DomainType || dom.BaseType != "integer" {
+		t.Errorf("unexpected domain: %+v", dom)
+	}
+
+	orders := s.Table(QualifiedName{"public", "orders"})
+	if col := orders.Column("status"); col.TypeRef.Name != "order_status" || col.Default != "'pending'" || !col.NotNull {
+		t.Errorf("unexpected status column: %+v", col)
+	}
+	if col := orders.Column("history"); col.TypeRef.Name != "order_status" {
+		t.Errorf("array column should reference its element type, got %+v", col.TypeRef)
+	}
+	if col := orders.Column("id"); col.Sequence == nil || col.Sequence.String() != "public.orders_id_seq" {
+		t.Errorf("serial column should draw from orders_id_seq, got %+v", col)
+	}
+
+	owners := make(map[string]string)
+	for _, seq := range s.Sequences {
+		if seq.OwnedBy != nil {
+			owners[seq.String()] = seq.OwnedBy.String()
+		}
+	}
+	want := map[string]string{"public.orders_id_seq": "public.orders.id", "public.invoice_no_seq": "public.invoices.no"}
+	if !reflect.DeepEqual(owners, want) {
+		t.Errorf("sequence owners = %v, want %v", owners, want)
+	}
+	if col := s.Table(QualifiedName{"public", "invoices"}).Column("no"); col.Sequence == nil || col.Sequence.Name != "invoice_no_seq" {
+		t.Errorf("SET DEFAULT nextval should link invoices.no to its sequence, got %+v", col)
+	}
+}
@@ -0,0 +1,475 @@
+package sqlparse
+
+import "strings"
+
+// LockMode is a PostgreSQL table-level lock mode
+type LockMode string
+
+const (
+	AccessShare          LockMode = "ACCESS SHARE"           // SELECT
+	RowShare             LockMode = "ROW SHARE"              // SELECT FOR UPDATE / FOR SHARE, FK checks
+	RowExclusive         LockMode = "ROW EXCLUSIVE"          // INSERT, UPDATE, DELETE, MERGE
+	ShareUpdateExclusive LockMode = "SHARE UPDATE EXCLUSIVE" // VACUUM, ANALYZE, CREATE INDEX CONCURRENTLY
+	Share                LockMode = "SHARE"                  // CREATE INDEX
+	ShareRowExclusive    LockMode = "SHARE ROW EXCLUSIVE"    // CREATE TRIGGER, ADD FOREIGN KEY
+	Exclusive            LockMode = "EXCLUSIVE"              // REFRESH MATERIALIZED VIEW CONCURRENTLY
+	AccessExclusive      LockMode = "ACCESS EXCLUSIVE"       // Most ALTER TABLE forms, DROP, TRUNCATE
+)
+
+// lockModes lists the modes from weakest to strongest
+var lockModes = []LockMode{AccessShare, RowShare, RowExclusive, ShareUpdateExclusive, Share, ShareRowExclusive, Exclusive, AccessExclusive}
+
+// lockConflicts is the conflict table of the PostgreSQL documentation (Explicit Locking)
+var lockConflicts = map[LockMode][]LockMode{
+	AccessShare:          {AccessExclusive},
+	RowShare:             {Exclusive, AccessExclusive},
+	RowExclusive:         {Share, ShareRowExclusive, Exclusive, AccessExclusive},
+	ShareUpdateExclusive: {ShareUpdateExclusive, Share, ShareRowExclusive, Exclusive, AccessExclusive},
+	Share:                {RowExclusive, ShareUpdateExclusive, ShareRowExclusive, Exclusive, AccessExclusive},
+	ShareRowExclusive:    {RowExclusive, ShareUpdateExclusive, Share, ShareRowExclusive, Exclusive, AccessExclusive},
+	Exclusive:            {RowShare, RowExclusive, ShareUpdateExclusive, Share, ShareRowExclusive, Exclusive, AccessExclusive},
+	AccessExclusive:      lockModes,
+}
+
+// Conflicts reports whether a lock in mode m cannot be held at the same time as one in mode o
+func (m LockMode) Conflicts(o LockMode) bool {
+	for _, c := range lockConflicts[m] {
+		if c == o {
+			return true
+		}
+	}
+	return false
+}
+
+// Stronger reports whether m conflicts with more modes than o
+func (m LockMode) Stronger(o LockMode) bool {
+	return m.rank() > o.rank()
+}
+
+func (m LockMode) rank() int {
+	for i, l := range lockModes {
+		if l == m {
+			return i
+		}
+	}
+	return -1
+}
+
+// ParseLockMode reads a lock mode as written in LOCK TABLE ("ACCESS EXCLUSIVE") or as pg_locks
+// reports it ("AccessExclusiveLock")
+func ParseLockMode(s string) (LockMode, bool) {
+	key := strings.ToLower(strings.NewReplacer(" ", "", "_", "").Replace(strings.TrimSuffix(s, "Lock")))
+	for _, m := range lockModes {
+		if strings.ToLower(strings.ReplaceAll(string(m), " ", "")) == key {
+			return m, true
+		}
+	}
+	return "", false
+}
+
+// LockRequest is a lock a statement takes on one relation
+type LockRequest struct {
+	Relation   QualifiedName
+	Mode       LockMode
+	Reason     string   // e.g. "ALTER COLUMN TYPE", "referenced by the new foreign key"
+	Index      bool     // The relation is an index (DROP INDEX, REINDEX INDEX, ALTER INDEX)
+	TableMode  LockMode // Index locks: the lock also taken on the index's table, if any
+	Only       bool     // ONLY: partitions and inheritance children are not locked
+	Constraint string   // DROP / VALIDATE CONSTRAINT: the constraint named
+}
+
+// StatementLocks is the lock analysis of one statement
+type StatementLocks struct {
+	Statement string
+	Line      int // 1-based line where the statement starts
+	Locks     []LockRequest
+	Cascade   bool     // The statement uses CASCADE
+	Notes     []string // Table rewrites, full scans, transaction restrictions
+}
+
+// Add records a lock, keeping the strongest mode per relation
+func (s *StatementLocks) Add(l LockRequest) {
+	for i := range s.Locks {
+		existing := &s.Locks[i]
+		if existing.Relation != l.Relation {
+			continue
+		}
+		if l.Mode.Stronger(existing.Mode) {
+			existing.Mode = l.Mode
+			existing.Reason = l.Reason
+		}
+		if existing.Constraint == "" {
+			existing.Constraint = l.Constraint
+		}
+		return
+	}
+	s.Locks = append(s.Locks, l)
+}
+
+// note records a note once
+func (s *StatementLocks) note(n string) {
+	for _, existing := range s.Notes {
+		if existing == n {
+			return
+		}
+	}
+	s.Notes = append(s.Notes, n)
+}
+
+// PlanLocks classifies the table-level locks each statement of a script acquires, following the
+// lock levels documented for PostgreSQL DDL. Statements without table locks worth planning for
+// (CREATE TABLE without foreign keys, GRANT, ...) come back with no locks.
+func PlanLocks(sql, defaultSchema string) []StatementLocks {
+	var plans []StatementLocks
+	for _, stmt := range SplitStatements(sql) {
+		plan := StatementLocks{
+			Statement: stmt.Text,
+			Line:      1 + strings.Count(sql[:stmt.Tokens[0].Pos], "\n"),
+			Cascade:   stmt.Tokens[len(stmt.Tokens)-1].IsKeyword("CASCADE"),
+		}
+		p := newParser(stmt.Tokens)
+		switch {
+		case p.acceptKeyword("ALTER", "TABLE"):
+			planAlterTable(&plan, p, defaultSchema)
+		case p.acceptKeyword("ALTER", "INDEX"):
+			p.acceptKeyword("IF", "EXISTS")
+			if name, ok := p.qualifiedName(defaultSchema); ok {
+				mode := AccessExclusive
+				if p.peek().IsKeyword("RENAME") || p.peek().IsKeyword("SET") && !p.peekAt(1).IsKeyword("TABLESPACE") {
+					mode = ShareUpdateExclusive
+				}
+				plan.Add(LockRequest{Relation: name, Mode: mode, Reason: "ALTER INDEX", Index: true})
+			}
+		case p.acceptKeyword("CREATE", "INDEX"), p.acceptKeyword("CREATE", "UNIQUE", "INDEX"):
+			mode, reason := Share, "CREATE INDEX blocks writes while it builds"
+			if p.acceptKeyword("CONCURRENTLY") {
+				mode, reason = ShareUpdateExclusive, "CREATE INDEX CONCURRENTLY"
+				plan.note("CONCURRENTLY cannot run inside a transaction block")
+			}
+			if p.skipUntilKeyword("ON") {
+				p.next()
+				only := p.acceptKeyword("ONLY")
+				if name, ok := p.qualifiedName(defaultSchema); ok {
+					plan.Add(LockRequest{Relation: name, Mode: mode, Reason: reason, Only: only})
+				}
+			}
+		case p.acceptKeyword("DROP", "INDEX"):
+			mode, reason := AccessExclusive, "DROP INDEX"
+			if p.acceptKeyword("CONCURRENTLY") {
+				mode, reason = ShareUpdateExclusive, "DROP INDEX CONCURRENTLY"
+				plan.note("CONCURRENTLY cannot run inside a transaction block")
+			}
+			for _, name := range dropNames(p, defaultSchema) {
+				plan.Add(LockRequest{Relation: name, Mode: mode, Reason: reason, Index: true, TableMode: mode})
+			}
+		case p.acceptKeyword("REINDEX"):
+			p.parenGroup()
+			index := p.acceptKeyword("INDEX")
+			if !index && !p.acceptKeyword("TABLE") {
+				break
+			}
+			mode, reason := Share, "REINDEX blocks writes while it rebuilds"
+			if p.acceptKeyword("CONCURRENTLY") {
+				mode, reason = ShareUpdateExclusive, "REINDEX CONCURRENTLY"
+			}
+			if name, ok := p.qualifiedName(defaultSchema); ok {
+				lock := LockRequest{Relation: name, Mode: mode, Reason: reason}
+				if index && mode == Share {
+					// The index itself is locked exclusively: queries planning on the table wait for it
+					lock = LockRequest{Relation: name, Mode: AccessExclusive, Reason: reason, Index: true, TableMode: Share}
+				} else if index {
+					lock.Index, lock.TableMode = true, mode
+				}
+				plan.Add(lock)
+			}
+		case p.acceptKeyword("DROP", "TABLE"), p.acceptKeyword("DROP", "VIEW"), p.acceptKeyword("DROP", "MATERIALIZED", "VIEW"):
+			for _, name := range dropNames(p, defaultSchema) {
+				plan.Add(LockRequest{Relation: name, Mode: AccessExclusive, Reason: "DROP"})
+			}
+		case p.acceptKeyword("TRUNCATE"):
+			p.acceptKeyword("TABLE")
+			only := p.acceptKeyword("ONLY")
+			for _, name := range dropNames(p, defaultSchema) {
+				plan.Add(LockRequest{Relation: name, Mode: AccessExclusive, Reason: "TRUNCATE", Only: only})
+			}
+		case p.acceptKeyword("CREATE", "TRIGGER"), p.acceptKeyword("CREATE", "OR", "REPLACE", "TRIGGER"),
+			p.acceptKeyword("CREATE", "CONSTRAINT", "TRIGGER"):
+			if p.skipUntilKeyword("ON") {
+				p.next()
+				if name, ok := p.qualifiedName(defaultSchema); ok {
+					plan.Add(LockRequest{Relation: name, Mode: ShareRowExclusive, Reason: "CREATE TRIGGER"})
+				}
+			}
+		case p.acceptKeyword("DROP", "TRIGGER"):
+			if p.skipUntilKeyword("ON") {
+				p.next()
+				if name, ok := p.qualifiedName(defaultSchema); ok {
+					plan.Add(LockRequest{Relation: name, Mode: AccessExclusive, Reason: "DROP TRIGGER"})
+				}
+			}
+		case p.acceptKeyword("REFRESH", "MATERIALIZED", "VIEW"):
+			mode, reason := AccessExclusive, "REFRESH MATERIALIZED VIEW"
+			if p.acceptKeyword("CONCURRENTLY") {
+				mode, reason = Exclusive, "REFRESH MATERIALIZED VIEW CONCURRENTLY"
+			}
+			if name, ok := p.qualifiedName(defaultSchema); ok {
+				plan.Add(LockRequest{Relation: name, Mode: mode, Reason: reason})
+			}
+		case p.acceptKeyword("VACUUM"), p.acceptKeyword("ANALYZE"), p.acceptKeyword("CLUSTER"):
+			full := p.peekAt(-1).IsKeyword("CLUSTER")
+			if opts, ok := p.parenGroup(); ok {
+				for _, t := range opts {
+					full = full || t.IsKeyword("FULL")
+				}
+			}
+			for p.acceptKeyword("FULL") || p.acceptKeyword("VERBOSE") || p.acceptKeyword("ANALYZE") || p.acceptKeyword("FREEZE") {
+				full = full || p.peekAt(-1).IsKeyword("FULL")
+			}
+			mode, reason := ShareUpdateExclusive, strings.ToUpper(stmt.Tokens[0].Text)
+			if full {
+				mode, reason = AccessExclusive, reason+" rewrites the table"
+				plan.note("the table is rewritten: expect downtime proportional to its size")
+			}
+			for _, name := range dropNames(p, defaultSchema) {
+				plan.Add(LockRequest{Relation: name, Mode: mode, Reason: reason})
+			}
+		case p.acceptKeyword("LOCK"):
+			p.acceptKeyword("TABLE")
+			only := p.acceptKeyword("ONLY")
+			toks := p.rest()
+			mode := AccessExclusive
+			for i, t := range toks {
+				if t.IsKeyword("IN") {
+					words := make([]string, 0, 3)
+					for _, w := range toks[i+1:] {
+						if w.IsKeyword("MODE") {
+							break
+						}
+						words = append(words, w.Text)
+					}
+					if m, ok := ParseLockMode(strings.Join(words, " ")); ok {
+						mode = m
+					}
+					toks = toks[:i]
+					break
+				}
+			}
+			for _, name := range dropNames(newParser(toks), defaultSchema) {
+				plan.Add(LockRequest{Relation: name, Mode: mode, Reason: "LOCK TABLE", Only: only})
+			}
+		case p.acceptKeyword("COMMENT", "ON", "TABLE"):
+			if name, ok := p.qualifiedName(defaultSchema); ok {
+				plan.Add(LockRequest{Relation: name, Mode: ShareUpdateExclusive, Reason: "COMMENT"})
+			}
+		}
+
+		// New foreign keys (CREATE TABLE, ADD COLUMN ... REFERENCES, ADD FOREIGN KEY) lock their parent
+		if !stmt.Tokens[0].IsKeyword("DROP") {
+			for _, parent := range referencedTables(stmt.Tokens, defaultSchema) {
+				plan.Add(LockRequest{Relation: parent, Mode: ShareRowExclusive, Reason: "referenced by the new foreign key"})
+			}
+		}
+		plans = append(plans, plan)
+	}
+	return plans
+}
+
+// dropNames reads "[IF EXISTS] name [, ...]" up to CASCADE / RESTRICT or the end of the statement
+func dropNames(p *parser, defaultSchema string) []QualifiedName {
+	p.acceptKeyword("IF", "EXISTS")
+	var names []QualifiedName
+	for _, part := range SplitTopLevel(p.rest(), ",") {
+		pp := newParser(part)
+		pp.acceptKeyword("ONLY")
+		if name, ok := pp.qualifiedName(defaultSchema); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// referencedTables returns the tables named after REFERENCES, at any nesting level
+func referencedTables(toks []Token, defaultSchema string) []QualifiedName {
+	var names []QualifiedName
+	for i, t := range toks {
+		if !t.IsKeyword("REFERENCES") {
+			continue
+		}
+		if name, ok := newParser(toks[i+1:]).qualifiedName(defaultSchema); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// volatileDefaults are functions whose DEFAULT makes ADD COLUMN rewrite the table
+var volatileDefaults = map[string]bool{
+	"random": true, "clock_timestamp": true, "timeofday": true, "gen_random_uuid": true,
+	"uuid_generate_v4": true, "nextval": true,
+}
+
+// planAlterTable classifies the actions of ALTER TABLE; the table gets the strongest of their locks
+func planAlterTable(plan *StatementLocks, p *parser, defaultSchema string) {
+	p.acceptKeyword("IF", "EXISTS")
+	only := p.acceptKeyword("ONLY")
+	table, ok := p.qualifiedName(defaultSchema)
+	if !ok {
+		return
+	}
+	p.acceptPunct("*")
+
+	for _, action := range SplitTopLevel(p.rest(), ",") {
+		if len(action) == 0 {
+			continue
+		}
+		ap := newParser(action)
+		lock := LockRequest{Relation: table, Mode: AccessExclusive, Reason: "ALTER TABLE " + strings.ToUpper(action[0].Text), Only: only}
+
+		switch {
+		case ap.acceptKeyword("ADD"):
+			if ap.acceptKeyword("CONSTRAINT") {
+				ap.next() // Constraint name
+			}
+			switch {
+			case ap.peek().IsKeyword("FOREIGN"):
+				lock.Mode, lock.Reason = ShareRowExclusive, "ADD FOREIGN KEY"
+				if !containsKeyword(action, "NOT", "VALID") {
+					plan.note("adding a foreign key scans the table to validate it: add it NOT VALID, then VALIDATE CONSTRAINT")
+				}
+			case ap.peek().IsKeyword("CHECK"):
+				lock.Reason = "ADD CHECK"
+				if !containsKeyword(action, "NOT", "VALID") {
+					plan.note("adding a CHECK constraint scans the table under ACCESS EXCLUSIVE: add it NOT VALID, then VALIDATE CONSTRAINT")
+				}
+			case ap.peek().IsKeyword("PRIMARY"), ap.peek().IsKeyword("UNIQUE"), ap.peek().IsKeyword("EXCLUDE"):
+				lock.Reason = "ADD " + strings.ToUpper(ap.peek().Text)
+				if !containsKeyword(action, "USING", "INDEX") {
+					plan.note("the constraint builds its index under ACCESS EXCLUSIVE: CREATE UNIQUE INDEX CONCURRENTLY first, then ADD ... USING INDEX")
+				}
+			default:
+				lock.Reason = "ADD COLUMN"
+				if containsKeyword(action, "GENERATED", "ALWAYS") && containsKeyword(action, "STORED") {
+					plan.note("a stored generated column rewrites the table")
+				} else if i := keywordIndex(action, "DEFAULT"); i >= 0 && i+1 < len(action) && volatileDefaults[action[i+1].Ident()] {
+					plan.note("a volatile DEFAULT (e.g. random(), clock_timestamp()) rewrites the table")
+				}
+			}
+		case ap.acceptKeyword("ALTER"):
+			ap.acceptKeyword("COLUMN")
+			ap.next() // Column name
+			switch {
+			case ap.peek().IsKeyword("TYPE"), ap.acceptKeyword("SET", "DATA"):
+				lock.Reason = "ALTER COLUMN TYPE"
+				plan.note("changing a column type usually rewrites the table and its indexes")
+			case ap.acceptKeyword("SET", "NOT", "NULL"):
+				lock.Reason = "SET NOT NULL"
+				plan.note("SET NOT NULL scans the table unless a validated CHECK (col IS NOT NULL) constraint exists")
+			case ap.acceptKeyword("SET", "STATISTICS"):
+				lock.Mode, lock.Reason = ShareUpdateExclusive, "SET STATISTICS"
+			case ap.peek().IsKeyword("SET") && ap.peekAt(1).IsPunct("("), ap.peek().IsKeyword("RESET"):
+				lock.Mode, lock.Reason = ShareUpdateExclusive, "SET attribute option"
+			default:
+				lock.Reason = "ALTER COLUMN"
+			}
+		case ap.acceptKeyword("DROP"):
+			if ap.acceptKeyword("CONSTRAINT") {
+				ap.acceptKeyword("IF", "EXISTS")
+				lock.Reason = "DROP CONSTRAINT"
+				if ap.peek().IsIdent() {
+					lock.Constraint = ap.next().Ident()
+				}
+			} else {
+				lock.Reason = "DROP COLUMN"
+			}
+		case ap.acceptKeyword("VALIDATE", "CONSTRAINT"):
+			lock.Mode, lock.Reason = ShareUpdateExclusive, "VALIDATE CONSTRAINT"
+			if ap.peek().IsIdent() {
+				lock.Constraint = ap.next().Ident()
+			}
+		case ap.acceptKeyword("SET", "TABLESPACE"), ap.acceptKeyword("SET", "LOGGED"), ap.acceptKeyword("SET", "UNLOGGED"):
+			lock.Reason = "SET " + strings.ToUpper(ap.peekAt(-1).Text)
+			plan.note("the table is rewritten: expect downtime proportional to its size")
+		case ap.acceptKeyword("ATTACH", "PARTITION"):
+			lock.Mode, lock.Reason = ShareUpdateExclusive, "ATTACH PARTITION"
+			if part, ok := ap.qualifiedName(defaultSchema); ok {
+				plan.Add(LockRequest{Relation: part, Mode: AccessExclusive, Reason: "partition being attached (scanned against the bound)"})
+			}
+		case ap.acceptKeyword("DETACH", "PARTITION"):
+			lock.Reason = "DETACH PARTITION"
+			if part, ok := ap.qualifiedName(defaultSchema); ok {
+				mode := AccessExclusive
+				if ap.acceptKeyword("CONCURRENTLY") {
+					mode, lock.Mode, lock.Reason = ShareUpdateExclusive, ShareUpdateExclusive, "DETACH PARTITION CONCURRENTLY"
+					plan.note("CONCURRENTLY cannot run inside a transaction block")
+				}
+				plan.Add(LockRequest{Relation: part, Mode: mode, Reason: "partition being detached"})
+			}
+		case ap.acceptKeyword("RENAME"):
+			lock.Reason = "RENAME"
+		case ap.peek().IsKeyword("SET") && ap.peekAt(1).IsPunct("("), ap.peek().IsKeyword("RESET") && ap.peekAt(1).IsPunct("("),
+			ap.acceptKeyword("CLUSTER", "ON"), ap.acceptKeyword("SET", "WITHOUT", "CLUSTER"):
+			lock.Mode, lock.Reason = ShareUpdateExclusive, "storage parameters"
+			if ap.peekAt(-1).IsKeyword("ON") || ap.peekAt(-1).IsKeyword("CLUSTER") {
+				lock.Reason = "CLUSTER setting"
+			}
+		case (ap.peek().IsKeyword("ENABLE") || ap.peek().IsKeyword("DISABLE")) && containsKeyword(action, "TRIGGER"):
+			lock.Mode, lock.Reason = ShareRowExclusive, strings.ToUpper(ap.peek().Text)+" TRIGGER"
+		}
+		plan.Add(lock)
+	}
+}
+
+// matchWords reports whether the tokens start with the keywords (or punctuation)
+func matchWords(toks []Token, words []string) bool {
+	if len(toks) < len(words) {
+		return false
+	}
+	for i, w := range words {
+		if !toks[i].IsKeyword(w) && !toks[i].IsPunct(w) {
+			return false
+		}
+	}
+	return true
+}
+
+// keywordIndex returns the position of the first top-level keyword kw, or -1
+func keywordIndex(toks []Token, kw string) int {
+	p := newParser(toks)
+	if p.skipUntilKeyword(kw) {
+		return p.pos
+	}
+	return -1
+}
+
+// containsKeyword reports whether the keyword sequence appears in the tokens
+func containsKeyword(toks []Token, kws ...string) bool {
+	for i := range toks {
+		if matchWords(toks[i:], kws) {
+			return true
+		}
+	}
+	return false
+}
+
+// QueryLocks returns the lock a DML statement takes on each relation it touches: ACCESS SHARE to
+// read, ROW SHARE for SELECT ... FOR UPDATE / FOR SHARE, ROW EXCLUSIVE to write
+func QueryLocks(toks []Token, defaultSchema string) map[QualifiedName]LockMode {
+	locking := containsKeyword(toks, "FOR", "UPDATE") || containsKeyword(toks, "FOR", "SHARE") ||
+		containsKeyword(toks, "FOR", "NO", "KEY", "UPDATE") || containsKeyword(toks, "FOR", "KEY", "SHARE")
+	locks := make(map[QualifiedName]LockMode)
+	for _, ref := range StatementReferences(toks, defaultSchema) {
+		mode := AccessShare
+		switch {
+		case ref.Op == "TRUNCATE":
+			mode = AccessExclusive
+		case ref.Op != "SELECT":
+			mode = RowExclusive
+		case locking:
+			mode = RowShare
+		}
+		if existing, ok := locks[ref.Name]; !ok || mode.Stronger(existing) {
+			locks[ref.Name] = mode
+		}
+	}
+	return locks
+}
@@ -0,0 +1,86 @@
+package sqlparse
+
+import (
+	"testing"
+)
+
+func TestPlanLocks(t *testing.T) {
+	plans := PlanLocks(`
+		ALTER TABLE orders ADD COLUMN note text;
+		ALTER TABLE orders ADD CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES app.users (id) NOT VALID;
+		ALTER TABLE orders VALIDATE CONSTRAINT fk_user, SET (fillfactor = 90);
+		ALTER TABLE ONLY orders ENABLE TRIGGER audit, ALTER COLUMN total TYPE numeric;
+		CREATE INDEX CONCURRENTLY orders_total_idx ON orders (total);
+		DROP INDEX app.orders_total_idx;
+		REINDEX INDEX orders_pkey;
+		REFRESH MATERIALIZED VIEW CONCURRENTLY daily;
+		LOCK TABLE a, b IN ROW EXCLUSIVE MODE;
+		CREATE TABLE payments (id int, order_id int REFERENCES orders);
+		ALTER TABLE orders DROP CONSTRAINT IF EXISTS fk_user;
+		VACUUM (FULL) orders;
+		GRANT SELECT ON orders TO reporting;
+	`, "public")
+
+	type lock struct {
+		relation string
+		mode     LockMode
+	}
+	want := [][]lock{
+		{{"public.orders", AccessExclusive}},
+		{{"public.orders", ShareRowExclusive}, {"app.users", ShareRowExclusive}},
+		{{"public.orders", ShareUpdateExclusive}},
+		{{"public.orders", AccessExclusive}}, // The strongest action wins
+		{{"public.orders", ShareUpdateExclusive}},
+		{{"app.orders_total_idx", AccessExclusive}},
+		{{"public.orders_pkey", AccessExclusive}},
+		{{"public.daily", Exclusive}},
+		{{"public.a", RowExclusive}, {"public.b", RowExclusive}},
+		{{"public.orders", ShareRowExclusive}},
+		{{"public.orders", AccessExclusive}},
+		{{"public.orders", AccessExclusive}},
+		nil,
+	}
+	if len(plans) != len(want) {
+		t.Fatalf("expected %d statements, got %d", len(want), len(plans))
+	}
+	for i, plan := range plans {
+		if len(plan.Locks) != len(want[i]) {
+			t.Errorf("statement %d (%s): expected %v, got %+v", i+1, plan.Statement, want[i], plan.Locks)
+			continue
+		}
+		for j, l := range plan.Locks {
+			if l.Relation.String() != want[i][j].relation || l.Mode != want[i][j].mode {
+				t.Errorf("statement %d (%s): expected %v, got %+v", i+1, plan.Statement, want[i][j], l)
+			}
+		}
+	}
+
+	if !plans[3].Locks[0].Only || plans[3].Locks[0].Reason != "ALTER COLUMN TYPE" || len(plans[3].Notes) != 1 {
+		t.Errorf("expected an ONLY table rewrite, got %+v", plans[3])
+	}
+	if l := plans[6].Locks[0]; !l.Index || l.TableMode != Share {
+		t.Errorf("expected REINDEX INDEX to take SHARE on the table, got %+v", l)
+	}
+	if plans[10].Locks[0].Constraint != "fk_user" {
+		t.Errorf("expected the dropped constraint to be recorded, got %+v", plans[10].Locks[0])
+	}
+	if plans[1].Notes != nil || len(plans[4].Notes) != 1 {
+		t.Errorf("unexpected notes: %v / %v", plans[1].Notes, plans[4].Notes)
+	}
+}
+
+func TestLockModes(t *testing.T) {
+	if !AccessExclusive.Conflicts(AccessShare) || ShareUpdateExclusive.Conflicts(RowExclusive) || !Share.Conflicts(RowExclusive) {
+		t.Errorf("unexpected conflict table")
+	}
+	for _, s := range []string{"AccessExclusiveLock", "ACCESS EXCLUSIVE", "access exclusive"} {
+		if m, ok := ParseLockMode(s); !ok || m != AccessExclusive {
+			t.Errorf("expected %q to parse as ACCESS EXCLUSIVE, got %q", s, m)
+		}
+	}
+
+	locks := QueryLocks(Tokenize("UPDATE orders SET total = 0 FROM users WHERE users.id = orders.user_id"), "public")
+	if locks[QualifiedName{"public", "orders"}] != RowExclusive || locks[QualifiedName{"public", "users"}] != AccessShare {
+		t.Errorf("unexpected query locks: %v", locks)
+	}
+}
@@ -0,0 +1,236 @@
+package sqlparse
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ChangeKind is a schema change that simulate knows how to analyze
+type ChangeKind string
+
+const (
+	DropTable       ChangeKind = "DROP TABLE"
+	DropView        ChangeKind = "DROP VIEW"
+	DropFunction    ChangeKind = "DROP FUNCTION"
+	DropType        ChangeKind = "DROP TYPE" // Enums, composite types and domains
+	DropIndex       ChangeKind = "DROP INDEX"
+	DropColumn      ChangeKind = "DROP COLUMN"
+	AlterColumnType ChangeKind = "ALTER COLUMN TYPE"
+	RenameTable     ChangeKind = "RENAME TABLE"
+	RenameColumn    ChangeKind = "RENAME COLUMN"
+	SetNotNull      ChangeKind = "SET NOT NULL"
+)
+
+// Change is one analyzable action of a migration statement
+type Change struct {
+	Kind      ChangeKind
+	Target    QualifiedName // Table, view, function, type or index
+	Column    string        // Column changes only
+	NewName   string        // Renames only
+	NewType   string        // Type changes only
+	Cascade   bool          // The statement uses CASCADE
+	Statement string        // Source text of the statement
+	Line      int           // 1-based line where the statement starts
+}
+
+// SQL renders the change as a PostgreSQL statement (without CASCADE)
+func (c Change) SQL() string {
+	table := QuoteIdent(c.Target.Schema) + "." + QuoteIdent(c.Target.Name)
+	switch c.Kind {
+	case DropTable, DropView, DropFunction, DropType, DropIndex:
+		// DROP TYPE also drops domains
+		return fmt.Sprintf("%s %s", c.Kind, table)
+	case DropColumn:
+		return fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", table, QuoteIdent(c.Column))
+	case AlterColumnType:
+		return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s", table, QuoteIdent(c.Column), c.NewType)
+	case SetNotNull:
+		return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET NOT NULL", table, QuoteIdent(c.Column))
+	case RenameTable:
+		return fmt.Sprintf("ALTER TABLE %s RENAME TO %s", table, QuoteIdent(c.NewName))
+	case RenameColumn:
+		return fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", table, QuoteIdent(c.Column), QuoteIdent(c.NewName))
+	}
+	return ""
+}
+
+// QuoteIdent double-quotes an identifier unless it is a plain lower-case name
+func QuoteIdent(name string) string {
+	plain := name != ""
+	for i, r := range name {
+		if !(r == '_' || (r >= 'a' && r <= 'z') || (i > 0 && r >= '0' && r <= '9')) {
+			plain = false
+			break
+		}
+	}
+	if plain {
+		return name
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// Migration is the result of parsing a migration script
+type Migration struct {
+	Changes []Change
+	Skipped []Statement // Statements with nothing to analyze (CREATE, INSERT, ...)
+}
+
+// ParseMigration extracts the destructive and renaming changes from a migration script.
+// Unqualified names resolve to defaultSchema.
+func ParseMigration(sql, defaultSchema string) *Migration {
+	m := &Migration{}
+
+	for _, stmt := range SplitStatements(sql) {
+		line := 1 + strings.Count(sql[:stmt.Tokens[0].Pos], "\n")
+		p := newParser(stmt.Tokens)
+		cascade := len(stmt.Tokens) > 0 && stmt.Tokens[len(stmt.Tokens)-1].IsKeyword("CASCADE")
+
+		var changes []Change
+		switch {
+		case p.acceptKeyword("DROP", "TABLE"):
+			changes = parseDropList(p, DropTable, defaultSchema)
+		case p.acceptKeyword("DROP", "VIEW"), p.acceptKeyword("DROP", "MATERIALIZED", "VIEW"):
+			changes = parseDropList(p, DropView, defaultSchema)
+		case p.acceptKeyword("DROP", "FUNCTION"), p.acceptKeyword("DROP", "PROCEDURE"):
+			changes = parseDropList(p, DropFunction, defaultSchema)
+		case p.acceptKeyword("DROP", "TYPE"), p.acceptKeyword("DROP", "DOMAIN"):
+			changes = parseDropList(p, DropType, defaultSchema)
+		case p.acceptKeyword("DROP", "INDEX"):
+			p.acceptKeyword("CONCURRENTLY")
+			changes = parseDropList(p, DropIndex, defaultSchema)
+		case p.acceptKeyword("ALTER", "TABLE"):
+			changes = parseAlterTableChanges(p, defaultSchema)
+		case p.acceptKeyword("RENAME", "TABLE"):
+			// MySQL: RENAME TABLE a TO b [, c TO d]
+			for _, part := range SplitTopLevel(p.rest(), ",") {
+				pp := newParser(part)
+				if name, ok := pp.qualifiedName(defaultSchema); ok && pp.acceptKeyword("TO") {
+					if newName, ok := pp.qualifiedName(defaultSchema); ok {
+						changes = append(changes, Change{Kind: RenameTable, Target: name, NewName: newName.Name})
+					}
+				}
+			}
+		}
+
+		if len(changes) == 0 {
+			m.Skipped = append(m.Skipped, stmt)
+			continue
+		}
+		for _, c := range changes {
+			c.Cascade = cascade
+			c.Statement = stmt.Text
+			c.Line = line
+			m.Changes = append(m.Changes, c)
+		}
+	}
+	return m
+}
+
+// parseDropList reads "[IF EXISTS] name [(args)] [, ...]"
+func parseDropList(p *parser, kind ChangeKind, defaultSchema string) []Change {
+	p.acceptKeyword("IF", "EXISTS")
+	var changes []Change
+	for _, part := range SplitTopLevel(p.rest(), ",") {
+		pp := newParser(part)
+		if name, ok := pp.qualifiedName(defaultSchema); ok {
+			changes = append(changes, Change{Kind: kind, Target: name})
+		}
+	}
+	return changes
+}
+
+// parseAlterTableChanges reads the comma separated actions of ALTER TABLE
+func parseAlterTableChanges(p *parser, defaultSchema string) []Change {
+	p.acceptKeyword("IF", "EXISTS")
+	p.acceptKeyword("ONLY")
+	table, ok := p.qualifiedName(defaultSchema)
+	if !ok {
+		return nil
+	}
+	p.acceptPunct("*")
+
+	var changes []Change
+	for _, action := range SplitTopLevel(p.rest(), ",") {
+		ap := newParser(action)
+		switch {
+		case ap.acceptKeyword("DROP"):
+			if ap.peek().IsKeyword("CONSTRAINT") || ap.peek().IsKeyword("INDEX") || ap.peek().IsKeyword("PRIMARY") ||
+				ap.peek().IsKeyword("FOREIGN") || ap.peek().IsKeyword("KEY") {
+				continue
+			}
+			ap.acceptKeyword("COLUMN")
+			ap.acceptKeyword("IF", "EXISTS")
+			if ap.peek().IsIdent() {
+				changes = append(changes, Change{Kind: DropColumn, Target: table, Column: ap.next().Ident()})
+			}
+
+		case ap.acceptKeyword("ALTER"):
+			ap.acceptKeyword("COLUMN")
+			if !ap.peek().IsIdent() {
+				continue
+			}
+			column := ap.next().Ident()
+			if ap.acceptKeyword("SET", "NOT", "NULL") {
+				changes = append(changes, Change{Kind: SetNotNull, Target: table, Column: column})
+				continue
+			}
+			ap.acceptKeyword("SET", "DATA")
+			if ap.acceptKeyword("TYPE") {
+				changes = append(changes, Change{Kind: AlterColumnType, Target: table, Column: column, NewType: columnType(ap)})
+			}
+
+		case ap.acceptKeyword("MODIFY"):
+			// MySQL: MODIFY [COLUMN] col type
+			ap.acceptKeyword("COLUMN")
+			if ap.peek().IsIdent() {
+				column := ap.next().Ident()
+				changes = append(changes, Change{Kind: AlterColumnType, Target: table, Column: column, NewType: columnType(ap)})
+			}
+
+		case ap.acceptKeyword("CHANGE"):
+			// MySQL: CHANGE [COLUMN] old new type
+			ap.acceptKeyword("COLUMN")
+			if ap.peek().IsIdent() && ap.peekAt(1).IsIdent() {
+				oldName, newName := ap.next().Ident(), ap.next().Ident()
+				if oldName != newName {
+					changes = append(changes, Change{Kind: RenameColumn, Target: table, Column: oldName, NewName: newName})
+				}
+				changes = append(changes, Change{Kind: AlterColumnType, Target: table, Column: newName, NewType: columnType(ap)})
+			}
+
+		case ap.acceptKeyword("RENAME"):
+			switch {
+			case ap.acceptKeyword("TO"):
+				if newName, ok := ap.qualifiedName(defaultSchema); ok {
+					changes = append(changes, Change{Kind: RenameTable, Target: table, NewName: newName.Name})
+				}
+			case ap.peek().IsKeyword("CONSTRAINT"):
+				continue
+			default:
+				ap.acceptKeyword("COLUMN")
+				if !ap.peek().IsIdent() {
+					continue
+				}
+				column := ap.next().Ident()
+				if ap.acceptKeyword("TO") && ap.peek().IsIdent() {
+					changes = append(changes, Change{Kind: RenameColumn, Target: table, Column: column, NewName: ap.next().Ident()})
+				}
+			}
+		}
+	}
+	return changes
+}
+
+// columnType reads a type name up to USING, a column constraint or a MySQL FIRST/AFTER position
+func columnType(p *parser) string {
+	start := p.pos
+	for !p.eof() && !p.peek().IsKeyword("USING") && !p.peek().IsKeyword("FIRST") && !p.peek().IsKeyword("AFTER") &&
+		!(p.peek().Kind == Word && columnConstraintWords[p.peek().Ident()]) {
+		if p.peek().IsPunct("(") {
+			p.parenGroup()
+			continue
+		}
+		p.next()
+	}
+	return JoinTokens(p.toks[start:p.pos])
+}
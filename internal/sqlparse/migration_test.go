@@ -0,0 +1,91 @@
+package sqlparse
+
+import (
+	"testing"
+)
+
+func TestParseMigration(t *testing.T) {
+	m := ParseMigration(`
+		DROP VIEW IF EXISTS reporting.daily, weekly;
+		ALTER TABLE ONLY users
+			DROP COLUMN IF EXISTS legacy_flag,
+			ALTER COLUMN email SET DATA TYPE citext USING email::citext,
+			RENAME COLUMN username TO handle,
+			ALTER COLUMN created_at SET NOT NULL,
+			DROP CONSTRAINT users_email_key;
+		CREATE INDEX idx_users_handle ON users (handle);
+		ALTER TABLE orders RENAME TO purchases;
+		DROP FUNCTION audit.log_change(text, integer) CASCADE;
+		ALTER TABLE accounts MODIFY COLUMN balance DECIMAL(12,2) NOT NULL;
+		DROP TYPE IF EXISTS order_status, app.address;
+		DROP DOMAIN positive_int;
+		DROP INDEX CONCURRENTLY IF EXISTS app.users_email_key;
+	`, "public")
+
+	want := []Change{
+		{Kind: DropView, Target: QualifiedName{"reporting", "daily"}, Line: 2},
+		{Kind: DropView, Target: QualifiedName{"public", "weekly"}, Line: 2},
+		{Kind: DropColumn, Target: QualifiedName{"public", "users"}, Column: "legacy_flag", Line: 3},
+		{Kind: AlterColumnType, Target: QualifiedName{"public", "users"}, Column: "email", NewType: "citext", Line: 3},
+		{Kind: RenameColumn, Target: QualifiedName{"public", "users"}, Column: "username", NewName: "handle", Line: 3},
+		{Kind: SetNotNull, Target: QualifiedName{"public", "users"}, Column: "created_at", Line: 3},
+		{Kind: RenameTable, Target: QualifiedName{"public", "orders"}, NewName: "purchases", Line: 10},
+		{Kind: DropFunction, Target: QualifiedName{"audit", "log_change"}, Cascade: true, Line: 11},
+		{Kind: AlterColumnType, Target: QualifiedName{"public", "accounts"}, Column: "balance", NewType: "DECIMAL(12, 2)", Line: 12},
+		{Kind: DropType, Target: QualifiedName{"public", "order_status"}, Line: 13},
+		{Kind: DropType, Target: QualifiedName{"app", "address"}, Line: 13},
+		{Kind: DropType, Target: QualifiedName{"public", "positive_int"}, Line: 14},
+		{Kind: DropIndex, Target: QualifiedName{"app", "users_email_key"}, Line: 15},
+	}
+
+	if len(m.Changes) != len(want) {
+		t.Fatalf("got %d changes, want %d: %+v", len(m.Changes), len(want), m.Changes)
+	}
+	for i, w := range want {
+		got := m.Changes[i]
+		got.Statement = ""
+		if got != w {
+			t.Errorf("change %d = %+v, want %+v", i, got, w)
+		}
+	}
+	if len(m.Skipped) != 1 {
+		t.Errorf("expected the CREATE INDEX statement to be skipped, got %d skipped", len(m.Skipped))
+	}
+}
+
+func TestChangeSQL(t *testing.T) {
+	users := QualifiedName{"public", "users"}
+	tests := []struct {
+		change Change
+		want   string
+	}{
+		{Change{Kind: DropTable, Target: QualifiedName{"sales", "Orders"}}, `DROP TABLE sales."Orders"`},
+		{Change{Kind: DropType, Target: QualifiedName{"public", "order_status"}}, `DROP TYPE public.order_status`},
+		{Change{Kind: DropIndex, Target: QualifiedName{"public", "users_email_key"}}, `DROP INDEX public.users_email_key`},
+		{Change{Kind: DropColumn, Target: users, Column: "email"}, `ALTER TABLE public.users DROP COLUMN email`},
+		{Change{Kind: AlterColumnType, Target: users, Column: "email", NewType: "citext"}, `ALTER TABLE public.users ALTER COLUMN email TYPE citext`},
+		{Change{Kind: SetNotNull, Target: users, Column: "created_at"}, `ALTER TABLE public.users ALTER COLUMN created_at SET NOT NULL`},
+		{Change{Kind: RenameTable, Target: users, NewName: "accounts"}, `ALTER TABLE public.users RENAME TO accounts`},
+		{Change{Kind: RenameColumn, Target: users, Column: "user name", NewName: "handle"}, `ALTER TABLE public.users RENAME COLUMN "user name" TO handle`},
+	}
+	for _, tt := range tests {
+		if got := tt.change.SQL(); got != tt.want {
+			t.Errorf("%s SQL() = %q, want %q", tt.change.Kind, got, tt.want)
+		}
+	}
+}
+
+func TestFunctionCalls(t *testing.T) {
+	toks := Tokenize(`INSERT INTO audit_logs (id, at) SELECT audit.next_id(), now() WHERE id IN (1, 2) AND coalesce(x, 1) > 0`)
+	calls := FunctionCalls(toks, "public")
+
+	want := []QualifiedName{{"audit", "next_id"}, {"public", "now"}, {"public", "coalesce"}}
+	if len(calls) != len(want) {
+		t.Fatalf("got %v, want %v", calls, want)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Errorf("call %d = %v, want %v", i, calls[i], want[i])
+		}
+	}
+}
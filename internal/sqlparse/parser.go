@@ -0,0 +1,176 @@
+package sqlparse
+
+import (
+	"strings"
+)
+
+// parser is a cursor over the tokens of a single statement
+type parser struct {
+	toks []Token
+	pos  int
+}
+
+func newParser(toks []Token) *parser {
+	return &parser{toks: toks}
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.toks)
+}
+
+// peek returns the current token (a zero Token at the end of input)
+func (p *parser) peek() Token {
+	return p.peekAt(0)
+}
+
+func (p *parser) peekAt(offset int) Token {
+	if p.pos+offset >= len(p.toks) || p.pos+offset < 0 {
+		return Token{Kind: Punct, Pos: -1}
+	}
+	return p.toks[p.pos+offset]
+}
+
+func (p *parser) next() Token {
+	t := p.peek()
+	if !p.eof() {
+		p.pos++
+	}
+	return t
+}
+
+// acceptKeyword consumes the keyword sequence if it is next in the input
+func (p *parser) acceptKeyword(kws ...string) bool {
+	for i, kw := range kws {
+		if !p.peekAt(i).IsKeyword(kw) {
+			return false
+		}
+	}
+	p.pos += len(kws)
+	return true
+}
+
+// acceptPunct consumes the punctuation if it is next in the input
+func (p *parser) acceptPunct(s string) bool {
+	if p.peek().IsPunct(s) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// skipUntilKeyword advances to the next top-level occurrence of kw (not consuming it)
+func (p *parser) skipUntilKeyword(kw string) bool {
+	depth := 0
+	for !p.eof() {
+		t := p.peek()
+		switch {
+		case t.IsPunct("("):
+			depth++
+		case t.IsPunct(")"):
+			depth--
+		case depth == 0 && t.IsKeyword(kw):
+			return true
+		}
+		p.pos++
+	}
+	return false
+}
+
+// qualifiedName reads ident [. ident [. ident]] and resolves it against defaultSchema.
+// Three-part names (db.schema.name) drop the database part.
+func (p *parser) qualifiedName(defaultSchema string) (QualifiedName, bool) {
+	if !p.peek().IsIdent() {
+		return QualifiedName{}, false
+	}
+	parts := []string{p.next().Ident()}
+	for p.peek().IsPunct(".") && p.peekAt(1).IsIdent() {
+		p.pos++
+		parts = append(parts, p.next().Ident())
+	}
+	switch len(parts) {
+	case 1:
+		return QualifiedName{Schema: defaultSchema, Name: parts[0]}, true
+	default:
+		return QualifiedName{Schema: parts[len(parts)-2], Name: parts[len(parts)-1]}, true
+	}
+}
+
+// parenGroup consumes a parenthesized group and returns the tokens inside it
+func (p *parser) parenGroup() ([]Token, bool) {
+	if !p.peek().IsPunct("(") {
+		return nil, false
+	}
+	start := p.pos + 1
+	depth := 0
+	for !p.eof() {
+		t := p.next()
+		if t.IsPunct("(") {
+			depth++
+		} else if t.IsPunct(")") {
+			depth--
+			if depth == 0 {
+				return p.toks[start : p.pos-1], true
+			}
+		}
+	}
+	return p.toks[start:], true
+}
+
+// rest returns the unconsumed tokens
+func (p *parser) rest() []Token {
+	if p.eof() {
+		return nil
+	}
+	return p.toks[p.pos:]
+}
+
+// SplitTopLevel splits tokens on sep outside of parentheses and brackets
+func SplitTopLevel(toks []Token, sep string) [][]Token {
+	var parts [][]Token
+	depth := 0
+	start := 0
+	for i, t := range toks {
+		switch {
+		case t.IsPunct("(") || t.IsPunct("["):
+			depth++
+		case t.IsPunct(")") || t.IsPunct("]"):
+			depth--
+		case depth == 0 && t.IsPunct(sep):
+			parts = append(parts, toks[start:i])
+			start = i + 1
+		}
+	}
+	if start < len(toks) {
+		parts = append(parts, toks[start:])
+	}
+	return parts
+}
+
+// identList reads a comma separated list of plain identifiers
+func identList(toks []Token) []string {
+	var names []string
+	for _, part := range SplitTopLevel(toks, ",") {
+		if len(part) > 0 && part[0].IsIdent() {
+			names = append(names, part[0].Ident())
+		}
+	}
+	return names
+}
+
+// JoinTokens renders tokens back to compact SQL text
+func JoinTokens(toks []Token) string {
+	var b strings.Builder
+	for i, t := range toks {
+		if i > 0 {
+			prev := toks[i-1]
+			noSpace := t.IsPunct(")") || t.IsPunct(",") || t.IsPunct(".") || t.IsPunct("::") || t.IsPunct("[") || t.IsPunct("]") ||
+				prev.IsPunct("(") || prev.IsPunct(".") || prev.IsPunct("::") || prev.IsPunct("[") ||
+				(t.IsPunct("(") && prev.IsIdent())
+			if !noSpace {
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteString(t.Text)
+	}
+	return b.String()
+}
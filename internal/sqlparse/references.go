@@ -0,0 +1,236 @@
+package sqlparse
+
+import (
+	"fmt"
+)
+
+// QualifiedName is a schema-qualified object name
+type QualifiedName struct {
+	Schema string
+	Name   string
+}
+
+// String returns schema.name, the ID format used by graph nodes
+func (q QualifiedName) String() string {
+	return fmt.Sprintf("%s.%s", q.Schema, q.Name)
+}
+
+// Reference is a relation touched by a statement
+type Reference struct {
+	Name QualifiedName
+	Op   string // "SELECT", "INSERT", "UPDATE", "DELETE", "MERGE" or "TRUNCATE"
+}
+
+// aliasStopWords are keywords that can follow a FROM item and must not be read as its alias
+var aliasStopWords = map[string]bool{
+	"where": true, "join": true, "inner": true, "left": true, "right": true, "full": true,
+	"cross": true, "natural": true, "on": true, "using": true, "group": true, "order": true,
+	"limit": true, "offset": true, "union": true, "except": true, "intersect": true,
+	"having": true, "window": true, "returning": true, "set": true, "fetch": true, "for": true,
+	"lateral": true, "tablesample": true, "values": true, "select": true, "default": true,
+	"when": true, "then": true, "else": true, "end": true, "loop": true, "into": true,
+	"do": true, "as": true, "outer": true,
+}
+
+// functionParenWords introduce "(x FROM y)" syntax where FROM is not a relation source
+var functionParenWords = map[string]bool{
+	"extract": true, "substring": true, "trim": true, "overlay": true, "position": true,
+}
+
+// RelationReferences returns the distinct relations a statement reads or writes
+func RelationReferences(toks []Token, defaultSchema string) []QualifiedName {
+	seen := make(map[QualifiedName]bool)
+	var names []QualifiedName
+	for _, ref := range StatementReferences(toks, defaultSchema) {
+		if !seen[ref.Name] {
+			seen[ref.Name] = true
+			names = append(names, ref.Name)
+		}
+	}
+	return names
+}
+
+// StatementReferences walks the tokens and reports FROM/JOIN sources (SELECT) and the targets of
+// INSERT INTO, UPDATE, DELETE FROM, MERGE INTO and TRUNCATE. Names defined by WITH are excluded.
+func StatementReferences(toks []Token, defaultSchema string) []Reference {
+	ctes := cteNames(toks)
+	var refs []Reference
+
+	add := func(name QualifiedName, op string) {
+		if name.Schema == defaultSchema && ctes[name.Name] {
+			return
+		}
+		refs = append(refs, Reference{Name: name, Op: op})
+	}
+
+	// Track whether each open parenthesis belongs to EXTRACT(... FROM ...) style syntax
+	var parenStack []bool
+	inFunctionSyntax := func() bool {
+		return len(parenStack) > 0 && parenStack[len(parenStack)-1]
+	}
+
+	p := newParser(toks)
+	for !p.eof() {
+		prev := p.peekAt(-1)
+		t := p.next()
+
+		switch {
+		case t.IsPunct("("):
+			parenStack = append(parenStack, prev.Kind == Word && functionParenWords[prev.Ident()])
+		case t.IsPunct(")"):
+			if len(parenStack) > 0 {
+				parenStack = parenStack[:len(parenStack)-1]
+			}
+
+		case t.IsKeyword("FROM"):
+			if inFunctionSyntax() || prev.IsKeyword("DISTINCT") {
+				continue
+			}
+			op := "SELECT"
+			if prev.IsKeyword("DELETE") {
+				op = "DELETE"
+			}
+			readSources(p, defaultSchema, op, true, add)
+
+		case t.IsKeyword("JOIN"):
+			readSources(p, defaultSchema, "SELECT", false, add)
+
+		case t.IsKeyword("INTO") && (prev.IsKeyword("INSERT") || prev.IsKeyword("MERGE")):
+			op := "INSERT"
+			if prev.IsKeyword("MERGE") {
+				op = "MERGE"
+			}
+			readSources(p, defaultSchema, op, false, add)
+
+		case t.IsKeyword("UPDATE"):
+			// FOR UPDATE, ON UPDATE, DO UPDATE and OF are not statement targets
+			if prev.IsKeyword("FOR") || prev.IsKeyword("ON") || prev.IsKeyword("DO") || prev.IsKeyword("KEY") ||
+				prev.IsKeyword("OR") || prev.IsKeyword("BEFORE") || prev.IsKeyword("AFTER") || prev.IsKeyword("THEN") {
+				continue
+			}
+			readSources(p, defaultSchema, "UPDATE", false, add)
+
+		case t.IsKeyword("TRUNCATE"):
+			p.acceptKeyword("TABLE")
+			readSources(p, defaultSchema, "TRUNCATE", true, add)
+		}
+	}
+	return refs
+}
+
+// readSources reads one relation (or a comma separated list when list is true)
+func readSources(p *parser, defaultSchema, op string, list bool, add func(QualifiedName, string)) {
+	for {
+		// pg_dump wraps join trees in parentheses: FROM (a JOIN b ON ...)
+		for p.peek().IsPunct("(") && !p.peekAt(1).IsKeyword("SELECT") && !p.peekAt(1).IsKeyword("WITH") && !p.peekAt(1).IsKeyword("VALUES") {
+			p.pos++
+		}
+		p.acceptKeyword("ONLY")
+		p.acceptKeyword("LATERAL")
+
+		if !p.peek().IsIdent() || (p.peek().Kind == Word && aliasStopWords[p.peek().Ident()]) {
+			return
+		}
+		name, _ := p.qualifiedName(defaultSchema)
+		if op == "SELECT" && p.peek().IsPunct("(") {
+			return // set-returning function call, not a relation
+		}
+		p.acceptPunct("*")
+		add(name, op)
+
+		if !list {
+			return
+		}
+
+		// Optional alias: [AS] alias [(column aliases)]
+		if p.acceptKeyword("AS") || (p.peek().IsIdent() && !(p.peek().Kind == Word && aliasStopWords[p.peek().Ident()])) {
+			if p.peek().IsIdent() {
+				p.next()
+			}
+			if p.peek().IsPunct("(") {
+				p.parenGroup()
+			}
+		}
+		if !p.acceptPunct(",") {
+			return
+		}
+	}
+}
+
+// cteNames collects the names defined by WITH clauses
+func cteNames(toks []Token) map[string]bool {
+	names := make(map[string]bool)
+	for i := 1; i+1 < len(toks); i++ {
+		prev := toks[i-1]
+		if !toks[i].IsIdent() || !(prev.IsKeyword("WITH") || prev.IsKeyword("RECURSIVE") || prev.IsPunct(",")) {
+			continue
+		}
+		j := i + 1
+		// Optional column list
+		if toks[j].IsPunct("(") {
+			depth := 0
+			for ; j < len(toks); j++ {
+				if toks[j].IsPunct("(") {
+					depth++
+				} else if toks[j].IsPunct(")") {
+					depth--
+					if depth == 0 {
+						break
+					}
+				}
+			}
+			j++
+		}
+		if j < len(toks) && toks[j].IsKeyword("AS") {
+			k := j + 1
+			for k < len(toks) && (toks[k].IsKeyword("NOT") || toks[k].IsKeyword("MATERIALIZED")) {
+				k++
+			}
+			if k < len(toks) && toks[k].IsPunct("(") {
+				names[toks[i].Ident()] = true
+			}
+		}
+	}
+	return names
+}
+
+// notCallWords are keywords that can be followed by "(" without being function calls
+var notCallWords = map[string]bool{
+	"in": true, "values": true, "as": true, "exists": true, "any": true, "all": true, "some": true,
+	"using": true, "on": true, "over": true, "filter": true, "within": true, "and": true, "or": true,
+	"not": true, "when": true, "then": true, "else": true, "select": true, "where": true, "from": true,
+	"returning": true, "into": true, "key": true, "unique": true, "check": true, "primary": true,
+	"exclude": true, "default": true, "cast": true, "row": true, "array": true, "if": true, "elsif": true,
+	"while": true, "return": true, "by": true, "partition": true, "with": true, "join": true, "set": true,
+}
+
+// relationPrefixWords precede a relation (not a call) that is followed by a column list
+var relationPrefixWords = map[string]bool{
+	"into": true, "table": true, "references": true, "on": true, "index": true, "view": true, "only": true,
+}
+
+// FunctionCalls returns the distinct functions invoked as name(...) or schema.name(...)
+func FunctionCalls(toks []Token, defaultSchema string) []QualifiedName {
+	seen := make(map[QualifiedName]bool)
+	var calls []QualifiedName
+	for i := 0; i+1 < len(toks); i++ {
+		t := toks[i]
+		if !t.IsIdent() || !toks[i+1].IsPunct("(") || (t.Kind == Word && notCallWords[t.Ident()]) {
+			continue
+		}
+		name := QualifiedName{Schema: defaultSchema, Name: t.Ident()}
+		start := i
+		if i >= 2 && toks[i-1].IsPunct(".") && toks[i-2].IsIdent() {
+			name.Schema = toks[i-2].Ident()
+			start = i - 2
+		}
+		if start > 0 && toks[start-1].Kind == Word && relationPrefixWords[toks[start-1].Ident()] {
+			continue
+		}
+		if !seen[name] {
+			seen[name] = true
+			calls = append(calls, name)
+		}
+	}
+	return calls
+}
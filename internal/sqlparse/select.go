@@ -0,0 +1,237 @@
+package sqlparse
+
+import (
+	"sort"
+	"strings"
+)
+
+// OutputColumn is one entry of a SELECT list
+type OutputColumn struct {
+	Name   string  // Alias, or the column of a plain reference; "*" for a star; "?column?" when unnamed
+	Tokens []Token // The expression
+}
+
+// selectListEnd are the keywords that end a SELECT list at the top level
+var selectListEnd = map[string]bool{
+	"from": true, "into": true, "where": true, "group": true, "having": true, "window": true,
+	"union": true, "intersect": true, "except": true, "order": true, "limit": true,
+}
+
+// SelectList returns the output columns of the first top-level SELECT of a query (CTEs are skipped)
+func SelectList(toks []Token) []OutputColumn {
+	start := -1
+	depth := 0
+	for i := 0; i < len(toks) && start < 0; i++ {
+		switch t := toks[i]; {
+		case t.IsPunct("("):
+			depth++
+		case t.IsPunct(")"):
+			depth--
+		case depth == 0 && t.IsKeyword("SELECT"):
+			start = i + 1
+		}
+	}
+	if start < 0 {
+		return nil
+	}
+
+	p := newParser(toks[start:])
+	if p.acceptKeyword("DISTINCT") {
+		if p.acceptKeyword("ON") {
+			p.parenGroup()
+		}
+	}
+	p.acceptKeyword("ALL")
+
+	list := p.rest()
+	end := len(list)
+	depth = 0
+	for i := 0; i < end; i++ {
+		switch t := list[i]; {
+		case t.IsPunct("(") || t.IsPunct("["):
+			depth++
+		case t.IsPunct(")") || t.IsPunct("]"):
+			depth--
+		case depth == 0 && t.Kind == Word && selectListEnd[strings.ToLower(t.Text)]:
+			end = i
+		}
+	}
+	list = list[:end]
+
+	var cols []OutputColumn
+	for _, item := range SplitTopLevel(list, ",") {
+		if len(item) > 0 {
+			cols = append(cols, outputColumn(item))
+		}
+	}
+	return cols
+}
+
+// outputColumn names a select list item the way Postgres does
+func outputColumn(item []Token) OutputColumn {
+	n := len(item)
+	last := item[n-1]
+	switch {
+	case last.IsPunct("*"):
+		return OutputColumn{Name: "*", Tokens: item}
+	case n >= 3 && item[n-2].IsKeyword("AS") && last.IsIdent():
+		return OutputColumn{Name: last.Ident(), Tokens: item[:n-2]}
+	case n == 1 && last.IsIdent():
+		return OutputColumn{Name: last.Ident(), Tokens: item}
+	case last.IsIdent() && item[n-2].IsPunct("."):
+		// table.column
+		return OutputColumn{Name: last.Ident(), Tokens: item}
+	case last.IsIdent() && !last.IsKeyword("END") && (item[n-2].IsIdent() || item[n-2].IsPunct(")") ||
+		item[n-2].Kind == String || item[n-2].Kind == Number):
+		// Implicit alias: expr name
+		return OutputColumn{Name: last.Ident(), Tokens: item[:n-1]}
+	case item[0].IsIdent() && n > 1 && item[1].IsPunct("("):
+		// Unaliased function call: Postgres names the column after the function
+		return OutputColumn{Name: item[0].Ident(), Tokens: item}
+	}
+	return OutputColumn{Name: "?column?", Tokens: item}
+}
+
+// DerivedColumns returns the output columns of a view query whose expression reads column.
+// names is the view's explicit column list, if any, and renames the outputs by position.
+// A star passes every column through under its own name.
+func DerivedColumns(toks []Token, names []string, column string) []string {
+	var derived []string
+	for i, col := range SelectList(toks) {
+		name := col.Name
+		if i < len(names) {
+			name = names[i]
+		}
+		if col.Name == "*" {
+			derived = append(derived, column)
+			continue
+		}
+		for _, t := range col.Tokens {
+			if t.IsIdent() && strings.EqualFold(t.Ident(), column) {
+				derived = append(derived, name)
+				break
+			}
+		}
+	}
+	return derived
+}
+
+// ColumnRef is a column of a relation
+type ColumnRef struct {
+	Relation QualifiedName
+	Column   string
+}
+
+// String returns schema.relation.column, the ID format of lineage columns
+func (c ColumnRef) String() string {
+	return c.Relation.String() + "." + c.Column
+}
+
+// OutputLineage is the set of source columns one output column is computed from
+type OutputLineage struct {
+	Column  string
+	Sources []ColumnRef
+}
+
+// FromAliases maps the aliases (and bare names) of the FROM and JOIN sources of a query to their relations
+func FromAliases(toks []Token, defaultSchema string) map[string]QualifiedName {
+	aliases := make(map[string]QualifiedName)
+	p := newParser(toks)
+	for !p.eof() {
+		t := p.next()
+		if !t.IsKeyword("FROM") && !t.IsKeyword("JOIN") {
+			continue
+		}
+		for {
+			for p.peek().IsPunct("(") && !p.peekAt(1).IsKeyword("SELECT") && !p.peekAt(1).IsKeyword("WITH") {
+				p.pos++
+			}
+			p.acceptKeyword("ONLY")
+			if !p.peek().IsIdent() || (p.peek().Kind == Word && aliasStopWords[p.peek().Ident()]) {
+				break
+			}
+			name, _ := p.qualifiedName(defaultSchema)
+			if p.peek().IsPunct("(") {
+				break // set-returning function
+			}
+			aliases[name.Name] = name
+			if p.acceptKeyword("AS") || (p.peek().IsIdent() && !(p.peek().Kind == Word && aliasStopWords[p.peek().Ident()])) {
+				if p.peek().IsIdent() {
+					aliases[p.next().Ident()] = name
+				}
+			}
+			if !t.IsKeyword("FROM") || !p.acceptPunct(",") {
+				break
+			}
+		}
+	}
+	return aliases
+}
+
+// ColumnLineage maps each output column of a view query to the source columns its expression reads.
+// alias.column and schema.relation.column resolve through the FROM clause; a bare column resolves to
+// the FROM relations that have it according to columnsOf. References to columns columnsOf does not
+// list for their relation are dropped, so callers with authoritative column lists (e.g. pg_depend)
+// only get real columns. names is the view's explicit column list, if any.
+func ColumnLineage(toks []Token, names []string, defaultSchema string, columnsOf func(QualifiedName) []string) []OutputLineage {
+	aliases := FromAliases(toks, defaultSchema)
+	has := func(rel QualifiedName, column string) bool {
+		for _, c := range columnsOf(rel) {
+			if strings.EqualFold(c, column) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var lineage []OutputLineage
+	for i, col := range SelectList(toks) {
+		if col.Name == "*" {
+			continue
+		}
+		out := OutputLineage{Column: col.Name}
+		if i < len(names) {
+			out.Column = names[i]
+		}
+		seen := make(map[ColumnRef]bool)
+		add := func(ref ColumnRef) {
+			if has(ref.Relation, ref.Column) && !seen[ref] {
+				seen[ref] = true
+				out.Sources = append(out.Sources, ref)
+			}
+		}
+
+		expr := col.Tokens
+		for j := 0; j < len(expr); j++ {
+			t := expr[j]
+			if !t.IsIdent() || (j > 0 && (expr[j-1].IsPunct(".") || expr[j-1].IsPunct("::"))) {
+				continue
+			}
+			// Collect a dotted chain: column, alias.column or schema.relation.column
+			chain := []string{t.Ident()}
+			for j+2 < len(expr) && expr[j+1].IsPunct(".") && expr[j+2].IsIdent() {
+				chain = append(chain, expr[j+2].Ident())
+				j += 2
+			}
+			if j+1 < len(expr) && expr[j+1].IsPunct("(") {
+				continue // function call
+			}
+
+			switch len(chain) {
+			case 1:
+				for _, rel := range aliases {
+					add(ColumnRef{Relation: rel, Column: chain[0]})
+				}
+			case 2:
+				if rel, ok := aliases[chain[0]]; ok {
+					add(ColumnRef{Relation: rel, Column: chain[1]})
+				}
+			case 3:
+				add(ColumnRef{Relation: QualifiedName{Schema: chain[0], Name: chain[1]}, Column: chain[2]})
+			}
+		}
+		sort.Slice(out.Sources, func(a, b int) bool { return out.Sources[a].String() < out.Sources[b].String() })
+		lineage = append(lineage, out)
+	}
+	return lineage
+}
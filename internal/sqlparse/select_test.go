@@ -0,0 +1,64 @@
+package sqlparse
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSelectList(t *testing.T) {
+	query := `WITH recent AS (SELECT id FROM orders)
+		SELECT DISTINCT u.email, lower(u.email) AS email_lc, u.name display, count(*), o.*
+		FROM users u JOIN orders o ON o.user_id = u.id GROUP BY 1`
+
+	var names []string
+	for _, c := range SelectList(Tokenize(query)) {
+		names = append(names, c.Name)
+	}
+	want := []string{"email", "email_lc", "display", "count", "*"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("SelectList names = %v, want %v", names, want)
+	}
+}
+
+func TestDerivedColumns(t *testing.T) {
+	toks := Tokenize("SELECT u.id, u.email AS contact, upper(u.email) FROM users u")
+	if got := DerivedColumns(toks, nil, "email"); !reflect.DeepEqual(got, []string{"contact", "upper"}) {
+		t.Errorf("DerivedColumns = %v", got)
+	}
+	// An explicit view column list renames the outputs by position
+	if got := DerivedColumns(toks, []string{"a", "b", "c"}, "email"); !reflect.DeepEqual(got, []string{"b", "c"}) {
+		t.Errorf("DerivedColumns with names = %v", got)
+	}
+	if got := DerivedColumns(Tokenize("SELECT * FROM users"), nil, "email"); !reflect.DeepEqual(got, []string{"email"}) {
+		t.Errorf("DerivedColumns through a star = %v", got)
+	}
+}
+
+func TestColumnLineage(t *testing.T) {
+	query := `SELECT o.id, u.email, (oi.quantity * oi.price) AS total_value, status
+		FROM (orders o JOIN public.users u ON u.id = o.user_id)
+		JOIN order_items AS oi ON oi.order_id = o.id
+		WHERE o.status = 'paid'`
+	columns := map[string][]string{
+		"orders":      {"id", "user_id", "status"},
+		"users":       {"id", "email"},
+		"order_items": {"order_id", "quantity", "price"},
+	}
+	columnsOf := func(rel QualifiedName) []string { return columns[rel.Name] }
+
+	got := make(map[string][]string)
+	for _, out := range ColumnLineage(Tokenize(query), nil, "public", columnsOf) {
+		for _, src := range out.Sources {
+			got[out.Column] = append(got[out.Column], src.String())
+		}
+	}
+	want := map[string][]string{
+		"id":          {"public.orders.id"},
+		"email":       {"public.users.email"},
+		"total_value": {"public.order_items.price", "public.order_items.quantity"},
+		"status":      {"public.orders.status"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ColumnLineage = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,275 @@
+package sqlparse
+
+import (
+	"strings"
+	"unicode"
+)
+
+// TokenKind classifies a lexical token
+type TokenKind int
+
+const (
+	Word         TokenKind = iota // Keyword or unquoted identifier
+	QuotedIdent                   // "Quoted" identifier (also `backticks` and [brackets])
+	String                        // 'string literal' (including E'' and N'')
+	DollarString                  // $tag$ body $tag$
+	Number                        // Numeric literal
+	Param                         // $1 positional parameter or ? placeholder
+	Punct                         // Operators and punctuation
+)
+
+// Token is a lexical unit of SQL. Comments and whitespace are dropped.
+type Token struct {
+	Kind  TokenKind
+	Text  string // Raw text as written
+	Value string // Unquoted value (identifiers keep case when quoted, strings are unescaped)
+	Pos   int    // Byte offset in the source
+}
+
+// IsKeyword reports whether the token is the given (case-insensitive) keyword
+func (t Token) IsKeyword(kw string) bool {
+	return t.Kind == Word && strings.EqualFold(t.Text, kw)
+}
+
+// IsPunct reports whether the token is the given punctuation
+func (t Token) IsPunct(p string) bool {
+	return t.Kind == Punct && t.Text == p
+}
+
+// IsIdent reports whether the token can name an object
+func (t Token) IsIdent() bool {
+	return t.Kind == Word || t.Kind == QuotedIdent
+}
+
+// Ident returns the identifier as Postgres resolves it: unquoted names fold to lower case
+func (t Token) Ident() string {
+	if t.Kind == QuotedIdent {
+		return t.Value
+	}
+	return strings.ToLower(t.Text)
+}
+
+// Tokenize splits SQL into tokens, skipping whitespace and comments.
+// It understands Postgres dollar quoting, E'...' escapes and nested block comments.
+func Tokenize(sql string) []Token {
+	var tokens []Token
+	i := 0
+	n := len(sql)
+
+	for i < n {
+		c := sql[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			i++
+
+		// -- line comment
+		case c == '-' && i+1 < n && sql[i+1] == '-':
+			for i < n && sql[i] != '\n' {
+				i++
+			}
+
+		// /* block comment */ (nesting allowed in Postgres)
+		case c == '/' && i+1 < n && sql[i+1] == '*':
+			depth := 0
+			for i < n {
+				if i+1 < n && sql[i] == '/' && sql[i+1] == '*' {
+					depth++
+					i += 2
+				} else if i+1 < n && sql[i] == '*' && sql[i+1] == '/' {
+					depth--
+					i += 2
+					if depth == 0 {
+						break
+					}
+				} else {
+					i++
+				}
+			}
+
+		// 'string', E'string', N'string'
+		case c == '\'' || ((c == 'E' || c == 'e' || c == 'N' || c == 'n') && i+1 < n && sql[i+1] == '\''):
+			start := i
+			escapes := c == 'E' || c == 'e'
+			if c != '\'' {
+				i++
+			}
+			i++ // opening quote
+			var b strings.Builder
+			for i < n {
+				if escapes && sql[i] == '\\' && i+1 < n {
+					b.WriteByte(sql[i+1])
+					i += 2
+					continue
+				}
+				if sql[i] == '\'' {
+					if i+1 < n && sql[i+1] == '\'' {
+						b.WriteByte('\'')
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				b.WriteByte(sql[i])
+				i++
+			}
+			tokens = append(tokens, Token{Kind: String, Text: sql[start:i], Value: b.String(), Pos: start})
+
+		// "identifier", `identifier`, [identifier]
+		case c == '"' || c == '`' || (c == '[' && i+1 < n && isIdentStart(rune(sql[i+1]))):
+			start := i
+			closer := c
+			if c == '[' {
+				closer = ']'
+			}
+			i++
+			var b strings.Builder
+			for i < n {
+				if sql[i] == closer {
+					if closer != ']' && i+1 < n && sql[i+1] == closer {
+						b.WriteByte(closer)
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				b.WriteByte(sql[i])
+				i++
+			}
+			tokens = append(tokens, Token{Kind: QuotedIdent, Text: sql[start:i], Value: b.String(), Pos: start})
+
+		// $tag$ ... $tag$ or $1
+		case c == '$':
+			start := i
+			if i+1 < n && sql[i+1] >= '0' && sql[i+1] <= '9' {
+				i++
+				for i < n && sql[i] >= '0' && sql[i] <= '9' {
+					i++
+				}
+				tokens = append(tokens, Token{Kind: Param, Text: sql[start:i], Value: sql[start:i], Pos: start})
+				continue
+			}
+			j := i + 1
+			for j < n && sql[j] != '$' && isIdentPart(rune(sql[j])) {
+				j++
+			}
+			if j < n && sql[j] == '$' {
+				tag := sql[i : j+1]
+				bodyStart := j + 1
+				end := strings.Index(sql[bodyStart:], tag)
+				if end < 0 {
+					tokens = append(tokens, Token{Kind: DollarString, Text: sql[start:], Value: sql[bodyStart:], Pos: start})
+					i = n
+					continue
+				}
+				i = bodyStart + end + len(tag)
+				tokens = append(tokens, Token{Kind: DollarString, Text: sql[start:i], Value: sql[bodyStart : bodyStart+end], Pos: start})
+				continue
+			}
+			i++
+			tokens = append(tokens, Token{Kind: Punct, Text: "$", Value: "$", Pos: start})
+
+		case c >= '0' && c <= '9' || (c == '.' && i+1 < n && sql[i+1] >= '0' && sql[i+1] <= '9'):
+			start := i
+			for i < n && (sql[i] >= '0' && sql[i] <= '9' || sql[i] == '.' || sql[i] == 'e' || sql[i] == 'E' ||
+				((sql[i] == '+' || sql[i] == '-') && (sql[i-1] == 'e' || sql[i-1] == 'E'))) {
+				i++
+			}
+			tokens = append(tokens, Token{Kind: Number, Text: sql[start:i], Value: sql[start:i], Pos: start})
+
+		case isIdentStart(rune(c)) || c >= 0x80:
+			start := i
+			for i < n && (isIdentPart(rune(sql[i])) || sql[i] >= 0x80) {
+				i++
+			}
+			tokens = append(tokens, Token{Kind: Word, Text: sql[start:i], Value: sql[start:i], Pos: start})
+
+		case c == '?':
+			tokens = append(tokens, Token{Kind: Param, Text: "?", Value: "?", Pos: i})
+			i++
+
+		default:
+			start := i
+			// Multi-character operators that matter to the parsers
+			for _, op := range []string{"::", ":=", "<>", "!=", ">=", "<=", "||", "->>", "->"} {
+				if strings.HasPrefix(sql[i:], op) {
+					i += len(op)
+					break
+				}
+			}
+			if i == start {
+				i++
+			}
+			tokens = append(tokens, Token{Kind: Punct, Text: sql[start:i], Value: sql[start:i], Pos: start})
+		}
+	}
+	return tokens
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentPart(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// Statement is one top-level SQL statement
+type Statement struct {
+	Text   string  // Source text without the trailing semicolon
+	Tokens []Token // Tokens of the statement
+}
+
+// SplitStatements splits a script on top-level semicolons.
+// Semicolons inside strings, dollar quotes, comments and BEGIN ATOMIC bodies are ignored.
+func SplitStatements(sql string) []Statement {
+	tokens := Tokenize(sql)
+	var statements []Statement
+
+	start := 0
+	atomicDepth := 0
+	caseDepth := 0
+	flush := func(end int, endPos int) {
+		if end > start {
+			first := tokens[start].Pos
+			statements = append(statements, Statement{
+				Text:   strings.TrimSpace(sql[first:endPos]),
+				Tokens: tokens[start:end],
+			})
+		}
+	}
+
+	for i, t := range tokens {
+		switch {
+		case t.IsKeyword("ATOMIC") && i > 0 && tokens[i-1].IsKeyword("BEGIN"):
+			atomicDepth++
+		case atomicDepth > 0 && t.IsKeyword("CASE"):
+			caseDepth++
+		case atomicDepth > 0 && t.IsKeyword("END"):
+			if caseDepth > 0 {
+				caseDepth--
+			} else {
+				atomicDepth--
+			}
+		case t.IsPunct(";") && atomicDepth == 0:
+			flush(i, t.Pos)
+			start = i + 1
+		}
+	}
+	if start < len(tokens) {
+		flush(len(tokens), len(sql))
+	}
+	return statements
+}
+
+// OneLine collapses the whitespace of a statement so it fits on one line and cuts it to at most
+// max runes, ending in "..." when cut
+func OneLine(stmt string, max int) string {
+	stmt = strings.Join(strings.Fields(stmt), " ")
+	if r := []rune(stmt); len(r) > max {
+		return string(r[:max-3]) + "..."
+	}
+	return stmt
+}
@@ -0,0 +1,79 @@
+package sqlparse
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTokenize(t *testing.T) {
+	toks := Tokenize(`SELECT "User"."Email", E'it\'s' -- comment
+		/* outer /* nested */ still comment */ FROM $fn$ body; $fn$ WHERE id = $1`)
+
+	want := []struct {
+		kind TokenKind
+		text string
+	}{
+		{Word, "SELECT"}, {QuotedIdent, `"User"`}, {Punct, "."}, {QuotedIdent, `"Email"`}, {Punct, ","},
+		{String, `E'it\'s'`}, {Word, "FROM"}, {DollarString, "$fn$ body; $fn$"}, {Word, "WHERE"},
+		{Word, "id"}, {Punct, "="}, {Param, "$1"},
+	}
+	if len(toks) != len(want) {
+		t.Fatalf("got %d tokens, want %d: %+v", len(toks), len(want), toks)
+	}
+	for i, w := range want {
+		if toks[i].Kind != w.kind || toks[i].Text != w.text {
+			t.Errorf("token %d = {%d %q}, want {%d %q}", i, toks[i].Kind, toks[i].Text, w.kind, w.text)
+		}
+	}
+	if toks[1].Ident() != "User" || toks[6].Ident() != "from" || toks[5].Value != "it's" {
+		t.Errorf("unexpected identifier/string values: %q %q %q", toks[1].Ident(), toks[6].Ident(), toks[5].Value)
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	stmts := SplitStatements(`
+		CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql;
+		CREATE FUNCTION g() RETURNS int LANGUAGE sql BEGIN ATOMIC SELECT CASE WHEN true THEN 1 END; SELECT 2; END;
+		SELECT ';'`)
+
+	if len(stmts) != 3 {
+		t.Fatalf("got %d statements, want 3: %+v", len(stmts), stmts)
+	}
+	if stmts[2].Text != "SELECT ';'" {
+		t.Errorf("unexpected last statement %q", stmts[2].Text)
+	}
+}
+
+func TestStatementReferences(t *testing.T) {
+	sql := `WITH recent AS (SELECT * FROM orders WHERE created > now())
+		INSERT INTO audit.log (id)
+		SELECT r.id FROM recent r JOIN ONLY users u ON u.id = r.user_id, generate_series(1, 3) g
+		WHERE extract(year FROM r.created) = 2024`
+
+	refs := StatementReferences(Tokenize(sql), "public")
+	want := []Reference{
+		{QualifiedName{"public", "orders"}, "SELECT"},
+		{QualifiedName{"audit", "log"}, "INSERT"},
+		{QualifiedName{"public", "users"}, "SELECT"},
+	}
+	if len(refs) != len(want) {
+		t.Fatalf("got %+v, want %+v", refs, want)
+	}
+	for i := range want {
+		if refs[i] != want[i] {
+			t.Errorf("reference %d = %+v, want %+v", i, refs[i], want[i])
+		}
+	}
+}
+
+func TestOneLine(t *testing.T) {
+	if got := OneLine("SELECT *\n\t FROM  orders", 60); got != "SELECT * FROM orders" {
+		t.Errorf("expected collapsed whitespace, got %q", got)
+	}
+	if got := OneLine("SELECT 'héllo wörld'", 12); got != "SELECT 'h..." {
+		t.Errorf("expected a cut at 12 runes, got %q", got)
+	}
+	if got := OneLine("SELECT 'ééééééé'", 12); got != "SELECT 'é..." || !utf8.ValidString(got) {
+		t.Errorf("expected a cut on a rune boundary, got %q", got)
+	}
+}
@@ -0,0 +1,75 @@
+-- MySQL 8 fixture mirroring the core of init.sql
+
+CREATE TABLE users (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    username VARCHAR(50) NOT NULL UNIQUE,
+    email VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE products (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    price DECIMAL(10, 2) NOT NULL,
+    category VARCHAR(50)
+);
+
+CREATE TABLE orders (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    status VARCHAR(20) DEFAULT 'pending',
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE order_items (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    order_id INT NOT NULL,
+    product_id INT NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    CONSTRAINT fk_order FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+    CONSTRAINT fk_product FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+CREATE TABLE audit_logs (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    action VARCHAR(50),
+    table_name VARCHAR(50),
+    record_id INT
+);
+
+CREATE TRIGGER order_audit_trigger
+AFTER INSERT ON orders
+FOR EACH ROW
+    INSERT INTO audit_logs (action, table_name, record_id) VALUES ('INSERT', 'orders', NEW.id);
+
+-- Partitioned Table
+CREATE TABLE app_logs (
+    id INT NOT NULL,
+    log_date DATE NOT NULL,
+    message TEXT
+)
+PARTITION BY RANGE (YEAR(log_date)) (
+    PARTITION p2023 VALUES LESS THAN (2024),
+    PARTITION p2024 VALUES LESS THAN (2025)
+);
+
+CREATE VIEW user_order_summary AS
+SELECT u.username, COUNT(o.id) AS total_orders
+FROM users u
+JOIN orders o ON u.id = o.user_id
+GROUP BY u.id, u.username;
+
+CREATE VIEW vip_customers AS
+SELECT username FROM user_order_summary WHERE total_orders > 5;
+
+INSERT INTO users (username, email) VALUES
+('alice', 'alice@example.com'),
+('bob', 'bob@example.com');
+
+INSERT INTO products (name, price, category) VALUES
+('Laptop', 1200.00, 'Electronics'),
+('Mouse', 25.00, 'Electronics');
+
+INSERT INTO orders (user_id, status) VALUES (1, 'completed'), (2, 'pending');
+
+INSERT INTO order_items (order_id, product_id, quantity) VALUES (1, 1, 1), (2, 2, 3);